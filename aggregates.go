@@ -0,0 +1,135 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// AggregateFunction is a SQL aggregate function.
+type AggregateFunction string
+
+const (
+	// AggregateCount counts rows, or the non-null values of a field.
+	AggregateCount AggregateFunction = "COUNT"
+	// AggregateSum sums the values of a numeric field.
+	AggregateSum AggregateFunction = "SUM"
+	// AggregateAvg averages the values of a numeric field, as a double.
+	AggregateAvg AggregateFunction = "AVG"
+	// AggregateMin is the smallest value of a field.
+	AggregateMin AggregateFunction = "MIN"
+	// AggregateMax is the largest value of a field.
+	AggregateMax AggregateFunction = "MAX"
+)
+
+// Aggregate declares a pseudo-field filtered by ConvertHaving, computed by
+// an aggregate function over a declared field, e.g.
+// {Function: AggregateSum, Field: "amount"} for SUM(amount).
+type Aggregate struct {
+	// Function is the aggregate function.
+	Function AggregateFunction
+	// Field is the aggregated field. Empty counts rows with COUNT(*).
+	Field string
+	// Distinct aggregates distinct values only, e.g. COUNT(DISTINCT col).
+	Distinct bool
+}
+
+// aggregateMapping resolves an aggregate into the declaration of its
+// pseudo-field, whose column is the aggregate SQL expression.
+func aggregateMapping(name string, aggregate Aggregate, fields map[string]ColumnMapping, columns map[string]string) (ColumnMapping, error) {
+	if _, clash := fields[name]; clash {
+		return ColumnMapping{}, fmt.Errorf("aggregate %s conflicts with a field of the same name", name)
+	}
+
+	if aggregate.Field == "" {
+		if aggregate.Function != AggregateCount || aggregate.Distinct {
+			return ColumnMapping{}, fmt.Errorf("aggregate %s: %s requires a field", name, aggregate.Function)
+		}
+		return ColumnMapping{Type: cel.IntType, Column: "COUNT(*)"}, nil
+	}
+
+	field, ok := fields[aggregate.Field]
+	if !ok || field.Type == nil {
+		return ColumnMapping{}, fmt.Errorf("aggregate %s: field %s is not declared", name, aggregate.Field)
+	}
+
+	var resultType *cel.Type
+	kind := field.Type.Kind()
+	numeric := kind == types.IntKind || kind == types.UintKind || kind == types.DoubleKind
+	switch aggregate.Function {
+	case AggregateCount:
+		resultType = cel.IntType
+	case AggregateSum:
+		if numeric {
+			resultType = field.Type
+		}
+	case AggregateAvg:
+		if numeric {
+			resultType = cel.DoubleType
+		}
+	case AggregateMin, AggregateMax:
+		if numeric || kind == types.StringKind || kind == types.TimestampKind {
+			resultType = field.Type
+		}
+	default:
+		return ColumnMapping{}, fmt.Errorf("aggregate %s: unknown function %q", name, aggregate.Function)
+	}
+	if resultType == nil {
+		return ColumnMapping{}, fmt.Errorf("aggregate %s: %s does not apply to %s field %s",
+			name, aggregate.Function, field.Type, aggregate.Field)
+	}
+
+	distinct := ""
+	if aggregate.Distinct {
+		distinct = "DISTINCT "
+	}
+	column := fmt.Sprintf("%s(%s%s)", aggregate.Function, distinct, columns[aggregate.Field])
+	return ColumnMapping{Type: resultType, Column: column}, nil
+}
+
+// newHavingConverter creates the converter of the expressions over the
+// aggregates of config, sharing its options.
+func newHavingConverter(config Config, columns map[string]string) (*Converter, error) {
+	havingConfig := config
+	havingConfig.FieldDeclarations = make(map[string]ColumnMapping, len(config.AggregateFields))
+	for name, aggregate := range config.AggregateFields {
+		mapping, err := aggregateMapping(name, aggregate, config.FieldDeclarations, columns)
+		if err != nil {
+			return nil, err
+		}
+		havingConfig.FieldDeclarations[name] = mapping
+	}
+
+	// Aggregate columns are SQL expressions over already qualified columns
+	havingConfig.TableAlias = ""
+	havingConfig.AggregateFields = nil
+	havingConfig.Collections = nil
+	havingConfig.FlagGroups = nil
+	havingConfig.Fallbacks = nil
+	havingConfig.PublicFields = nil
+	havingConfig.FieldACL = nil
+	havingConfig.Stats = nil
+	havingConfig.KeyFields = nil
+	havingConfig.Quota = nil
+	havingConfig.Corpus = nil
+	havingConfig.SoftDeleteField = ""
+	havingConfig.MandatoryConditions = nil
+	havingConfig.MandatoryConditionsFunc = nil
+	return NewConverter(havingConfig)
+}
+
+// ConvertHaving converts a CEL expression over the aggregates declared in
+// Config.AggregateFields into a Sqlizer for the HAVING clause, e.g.
+// `count > 10 && sum_amount >= 100.0` into (COUNT(*) > ? AND SUM(amount) >= ?).
+func (c *Converter) ConvertHaving(celExpr string) (*ConvertResult, error) {
+	c = c.current()
+	if c.having == nil {
+		return nil, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("no aggregates declared"),
+		)
+	}
+	return c.having.Convert(celExpr)
+}
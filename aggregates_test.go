@@ -0,0 +1,174 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ConvertHaving(t *testing.T) {
+	converter, err := NewConverter(Config{
+		TableAlias: "o",
+		FieldDeclarations: map[string]ColumnMapping{
+			"amount":   {Type: cel.DoubleType},
+			"quantity": {Type: cel.IntType, Column: "qty"},
+			"customer": {Type: cel.StringType, Column: "customer_id"},
+		},
+		AggregateFields: map[string]Aggregate{
+			"count":          {Function: AggregateCount},
+			"sum_amount":     {Function: AggregateSum, Field: "amount"},
+			"avg_quantity":   {Function: AggregateAvg, Field: "quantity"},
+			"max_quantity":   {Function: AggregateMax, Field: "quantity"},
+			"customer_count": {Function: AggregateCount, Field: "customer", Distinct: true},
+		},
+		AuditSQL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "count and sum",
+			celExpr:  `count > 10 && sum_amount >= 100.0`,
+			wantSQL:  "(COUNT(*) > ? AND SUM(o.amount) >= ?)",
+			wantArgs: []any{int64(10), 100.0},
+		},
+		{
+			name:     "average",
+			celExpr:  `avg_quantity < 2.5 || max_quantity == 1`,
+			wantSQL:  "(AVG(o.qty) < ? OR MAX(o.qty) = ?)",
+			wantArgs: []any{2.5, int64(1)},
+		},
+		{
+			name:     "distinct",
+			celExpr:  `customer_count >= 3`,
+			wantSQL:  "COUNT(DISTINCT o.customer_id) >= ?",
+			wantArgs: []any{int64(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertHaving(tt.celExpr)
+			if err != nil {
+				t.Fatalf("ConvertHaving() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	t.Run("type checked", func(t *testing.T) {
+		if _, err := converter.ConvertHaving(`avg_quantity > 2`); err == nil {
+			t.Error("ConvertHaving() expected error comparing a double aggregate with an int")
+		}
+	})
+
+	t.Run("row fields", func(t *testing.T) {
+		if _, err := converter.ConvertHaving(`amount > 2.0`); err == nil {
+			t.Error("ConvertHaving() expected error for a row field")
+		}
+		if _, err := converter.Convert(`count > 2`); err == nil {
+			t.Error("Convert() expected error for an aggregate")
+		}
+	})
+}
+
+func TestConverter_ConvertHaving_Errors(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"amount": {Type: cel.DoubleType},
+		"name":   {Type: cel.StringType},
+		"tags":   {Type: cel.ListType(cel.StringType)},
+	}
+
+	t.Run("no aggregates", func(t *testing.T) {
+		converter, err := NewConverter(Config{FieldDeclarations: fields})
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+		_, err = converter.ConvertHaving(`count > 1`)
+		if err == nil || err.(*ConversionError).ErrorCode != "UNSUPPORTED_OPERATION" {
+			t.Errorf("ConvertHaving() error = %v, want UNSUPPORTED_OPERATION", err)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		aggregate Aggregate
+	}{
+		{name: "sum of rows", aggregate: Aggregate{Function: AggregateSum}},
+		{name: "sum of strings", aggregate: Aggregate{Function: AggregateSum, Field: "name"}},
+		{name: "max of lists", aggregate: Aggregate{Function: AggregateMax, Field: "tags"}},
+		{name: "undeclared field", aggregate: Aggregate{Function: AggregateMin, Field: "price"}},
+		{name: "unknown function", aggregate: Aggregate{Function: "MEDIAN", Field: "amount"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				FieldDeclarations: fields,
+				AggregateFields:   map[string]Aggregate{"agg": tt.aggregate},
+			})
+			if err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+
+	t.Run("name clash", func(t *testing.T) {
+		_, err := NewConverter(Config{
+			FieldDeclarations: fields,
+			AggregateFields:   map[string]Aggregate{"amount": {Function: AggregateSum, Field: "amount"}},
+		})
+		if err == nil {
+			t.Fatal("NewConverter() expected error")
+		}
+	})
+}
+
+func TestGroupedConverter_AggregateFields(t *testing.T) {
+	converter, err := NewGroupedConverter(GroupedConfig{
+		Config: Config{
+			FieldDeclarations: map[string]ColumnMapping{
+				"amount":     {Type: cel.DoubleType},
+				"customerId": {Type: cel.StringType, Column: "customer_id"},
+			},
+			AggregateFields: map[string]Aggregate{
+				"count": {Function: AggregateCount},
+			},
+		},
+		GroupBy: []string{"customerId"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create grouped converter: %v", err)
+	}
+
+	result, err := converter.Convert(`amount > 0.0`, `count > 1`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, _, err := result.ApplyTo(squirrel.Select("customer_id").From("orders")).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "SELECT customer_id FROM orders WHERE amount > ? GROUP BY customer_id HAVING COUNT(*) > ?"; sql != want {
+		t.Errorf("ToSql() = %v, want %v", sql, want)
+	}
+}
@@ -0,0 +1,63 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// expandAliases declares the aliases of the configured fields as fields
+// reading the same column, authorized as the field they alias. The aliases
+// are returned with the field they alias.
+func expandAliases(config Config) (Config, map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(config.FieldDeclarations)) {
+		for _, alias := range config.FieldDeclarations[name].Aliases {
+			if _, ok := config.FieldDeclarations[alias]; ok {
+				return config, nil, fmt.Errorf("invalid field declaration: alias %s of field %s is already declared", alias, name)
+			}
+			if _, ok := aliases[alias]; ok {
+				return config, nil, fmt.Errorf("invalid field declaration: alias %s of field %s is already declared", alias, name)
+			}
+			aliases[alias] = name
+		}
+	}
+	if len(aliases) == 0 {
+		return config, nil, nil
+	}
+
+	declarations := maps.Clone(config.FieldDeclarations)
+	acl := maps.Clone(config.FieldACL)
+	public := slices.Clone(config.PublicFields)
+	for alias, field := range aliases {
+		mapping := declarations[field]
+		if mapping.DeprecatedAliases {
+			mapping.Deprecated = true
+			mapping.ReplacedBy = field
+		}
+		mapping.Aliases = nil
+		mapping.DeprecatedAliases = false
+		if mapping.Column == "" && mapping.Expr == "" {
+			mapping.Column = field
+		}
+		declarations[alias] = mapping
+		if roles, ok := acl[field]; ok {
+			acl[alias] = roles
+		}
+		if slices.Contains(config.PublicFields, field) {
+			public = append(public, alias)
+		}
+	}
+	config.FieldDeclarations = declarations
+	config.FieldACL = acl
+	config.PublicFields = public
+	return config, aliases, nil
+}
+
+// canonicalField returns the field an alias stands for, or name itself.
+func (c *Converter) canonicalField(name string) string {
+	if field, ok := c.aliases[name]; ok {
+		return field
+	}
+	return name
+}
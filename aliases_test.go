@@ -0,0 +1,113 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Aliases(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"create_time": {Type: cel.TimestampType, Column: "created_at", Aliases: []string{"created"}, DeprecatedAliases: true},
+			"owner":       {Type: cel.StringType, Aliases: []string{"user"}},
+			"deleted_at":  {Type: cel.TimestampType, Aliases: []string{"delete_time"}},
+		},
+		PublicFields:    []string{"create_time", "deleted_at"},
+		FieldACL:        map[string][]string{"owner": {"admin"}},
+		SoftDeleteField: "deleted_at",
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		celExpr      string
+		roles        []string
+		wantSQL      string
+		wantWarnings []string
+		wantCode     string
+	}{
+		{
+			name:    "field",
+			celExpr: `create_time > timestamp("2024-01-01T00:00:00Z")`,
+			wantSQL: "(created_at > ? AND deleted_at IS NULL)",
+		},
+		{
+			name:         "deprecated alias",
+			celExpr:      `created > timestamp("2024-01-01T00:00:00Z")`,
+			wantSQL:      "(created_at > ? AND deleted_at IS NULL)",
+			wantWarnings: []string{"field created is deprecated, use create_time"},
+		},
+		{
+			name:    "alias without column",
+			celExpr: `user == "bob"`,
+			roles:   []string{"admin"},
+			wantSQL: "(owner = ? AND deleted_at IS NULL)",
+		},
+		{
+			name:     "alias authorized as its field",
+			celExpr:  `user == "bob"`,
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:    "alias of the soft delete field",
+			celExpr: `delete_time != null`,
+			wantSQL: "deleted_at IS NOT NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertWithAuth(tt.celExpr, tt.roles)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ConvertWithAuth() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ConvertWithAuth() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(warningMessages(result.Warnings), tt.wantWarnings) {
+				t.Errorf("Warnings = %v, want %v", result.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestConverter_Aliases_Conflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]ColumnMapping
+	}{
+		{
+			name: "alias of a declared field",
+			fields: map[string]ColumnMapping{
+				"create_time": {Type: cel.TimestampType, Aliases: []string{"created"}},
+				"created":     {Type: cel.TimestampType},
+			},
+		},
+		{
+			name: "alias of two fields",
+			fields: map[string]ColumnMapping{
+				"create_time": {Type: cel.TimestampType, Aliases: []string{"created"}},
+				"creation":    {Type: cel.TimestampType, Aliases: []string{"created"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConverter(Config{FieldDeclarations: tt.fields}); err == nil {
+				t.Error("NewConverter() succeeded, want an alias conflict error")
+			}
+		})
+	}
+}
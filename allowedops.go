@@ -0,0 +1,63 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"slices"
+
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+)
+
+// checkAllowedOps rejects the operators and functions applied to fields
+// outside of their AllowedOps.
+func (c *Converter) checkAllowedOps(expr celast.Expr) error {
+	var err error
+	walkExpr(expr, func(e celast.Expr) {
+		if err != nil || e.Kind() != celast.CallKind {
+			return
+		}
+		call := e.AsCall()
+		if call.FunctionName() == operators.LogicalAnd || call.FunctionName() == operators.LogicalOr {
+			return
+		}
+		op := displayOperator(call.FunctionName())
+
+		check := func(operand celast.Expr, op string) bool {
+			field := operand.AsIdent()
+			allowed := c.fieldDeclarations[field].AllowedOps
+			if len(allowed) > 0 && !slices.Contains(allowed, op) {
+				err = newConversionError(
+					"operator not allowed in filter",
+					CodeOperatorNotAllowed,
+					fmt.Errorf("operator %s is not allowed on field %s", op, field),
+				)
+				return false
+			}
+			return true
+		}
+		if call.IsMemberFunction() && !check(call.Target(), op) {
+			return
+		}
+		for i, operand := range call.Args() {
+			// Fields compared on the right are checked against the
+			// comparison of their canonical form: `1 < age` is `age > 1`
+			operandOp := op
+			if mirrored, ok := mirroredComparisons[call.FunctionName()]; ok && i == 1 {
+				operandOp = displayOperator(mirrored)
+			}
+			if !check(operand, operandOp) {
+				return
+			}
+		}
+	})
+	return err
+}
+
+// displayOperator returns the CEL syntax of an operator, or the name of a
+// function.
+func displayOperator(function string) string {
+	if display, ok := operators.FindReverse(function); ok && display != "" {
+		return display
+	}
+	return function
+}
@@ -0,0 +1,61 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_AllowedOps(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"id":          {Type: cel.StringType, AllowedOps: []string{"==", "in"}},
+			"description": {Type: cel.StringType, AllowedOps: []string{"contains"}},
+			"age":         {Type: cel.IntType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCode string
+	}{
+		{name: "allowed equality", celExpr: `id == "a"`},
+		{name: "allowed in", celExpr: `id in ["a", "b"] && age > 3`},
+		{name: "allowed function", celExpr: `description.contains("x") || age < 3`},
+		{name: "unrestricted field", celExpr: `age >= 18 && age + 1 < 65`},
+		{name: "range scan", celExpr: `id > "a"`, wantCode: "OPERATOR_NOT_ALLOWED"},
+		{name: "prefix match", celExpr: `id.startsWith("a")`, wantCode: "OPERATOR_NOT_ALLOWED"},
+		{name: "equality", celExpr: `description == "x"`, wantCode: "OPERATOR_NOT_ALLOWED"},
+		{name: "nested", celExpr: `age > 1 && !(description.endsWith("x"))`, wantCode: "OPERATOR_NOT_ALLOWED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Errorf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if got := errorCode(converter.Validate(tt.celExpr)); got != tt.wantCode {
+				t.Errorf("Validate() error code = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+
+	field, ok := converter.Field("id")
+	if !ok {
+		t.Fatal("Field(id) not found")
+	}
+	if !reflect.DeepEqual(field.AllowedOps, []string{"==", "in"}) {
+		t.Errorf("Field(id).AllowedOps = %v", field.AllowedOps)
+	}
+	for _, capability := range converter.Capabilities().Fields {
+		if capability.Name == "id" && !reflect.DeepEqual(capability.Operators, []string{"==", "in"}) {
+			t.Errorf("Capabilities() id operators = %v, want [== in]", capability.Operators)
+		}
+	}
+}
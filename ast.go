@@ -0,0 +1,194 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// Env returns the CEL environment filter expressions are compiled in, for
+// callers compiling expressions themselves before ConvertAst, e.g. to cache
+// them or to evaluate them in memory. TimestampStrings only applies to the
+// expressions compiled by Convert.
+func (c *Converter) Env() *cel.Env {
+	c = c.current()
+	return c.env
+}
+
+// ConvertAst converts an expression already compiled by the caller,
+// skipping the parse and type-check of Convert. ASTs only parsed are
+// type-checked first. ASTs checked in another environment must declare
+// the variables they reference with the converter's types, so that only
+// declared fields reach SQL. The limits of Convert are enforced, the
+// length one only when the AST carries its source.
+func (c *Converter) ConvertAst(ast *cel.Ast, opts ...ConvertOption) (*ConvertResult, error) {
+	return c.ConvertAstContext(context.Background(), ast, opts...)
+}
+
+// ConvertAstContext is like ConvertAst, passing ctx to the SecurityLogger.
+func (c *Converter) ConvertAstContext(ctx context.Context, ast *cel.Ast, opts ...ConvertOption) (*ConvertResult, error) {
+	c = c.current()
+	return c.convertAst(ctx, ast, nil, opts)
+}
+
+// ConvertAstWithAuth is like ConvertAst, authorizing the fields referenced
+// as ConvertWithAuth does.
+func (c *Converter) ConvertAstWithAuth(ast *cel.Ast, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	return c.ConvertAstWithAuthContext(context.Background(), ast, userRoles, opts...)
+}
+
+// ConvertAstWithAuthContext is like ConvertAstWithAuth, passing ctx to the
+// SecurityLogger.
+func (c *Converter) ConvertAstWithAuthContext(ctx context.Context, ast *cel.Ast, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	c = c.current()
+	if !c.authorization() {
+		return c.ConvertAstContext(ctx, ast, opts...)
+	}
+	if userRoles == nil {
+		userRoles = []string{}
+	}
+	return c.convertAst(ctx, ast, userRoles, opts)
+}
+
+// convertAst checks and converts a compiled expression, authorizing its
+// fields unless userRoles is nil.
+func (c *Converter) convertAst(ctx context.Context, ast *cel.Ast, userRoles []string, opts []ConvertOption) (result *ConvertResult, err error) {
+	if ast == nil {
+		return nil, fmt.Errorf("nil AST")
+	}
+	c, err = c.withOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	celExpr := ast.Source().Content()
+	if celExpr == "" {
+		celExpr, _ = cel.AstToString(ast)
+	}
+	defer c.logAttempt(ctx, celExpr, time.Now(), &err)
+
+	checkedExpr, err := c.checkAst(ctx, celExpr, ast)
+	if err == nil && userRoles != nil {
+		err = c.authorize(ctx, celExpr, checkedExpr.Expr(), userRoles, nil)
+	}
+	if err != nil {
+		return c.maskOutput(celExpr, nil, err)
+	}
+
+	result, err = c.convertChecked(ctx, checkedExpr.Expr(), checkedExpr.cost)
+	result, err = c.finalize(ctx, celExpr, result, err)
+	return c.maskOutput(celExpr, result, err)
+}
+
+// checkAst type-checks a compiled expression when needed, verifies the
+// variables it references and enforces the limits.
+func (c *Converter) checkAst(ctx context.Context, celExpr string, ast *cel.Ast) (*checkedFilter, error) {
+	if ast.Source().Content() != "" {
+		if err := c.checkLength(celExpr); err != nil {
+			return nil, err
+		}
+	}
+
+	if !ast.IsChecked() {
+		checked, issues := c.env.Check(ast)
+		if issues != nil && issues.Err() != nil {
+			return nil, compileError(ast.Source().Content(), "CEL type-check", issues)
+		}
+		ast = checked
+	} else if err := c.checkDeclared(ast); err != nil {
+		return nil, newConversionError("invalid filter expression syntax", CodeInvalidSyntax, err)
+	}
+
+	return c.checkCompiled(ctx, celExpr, ast)
+}
+
+// checkDeclared verifies that the variables referenced by an AST checked
+// in any environment are declared by the converter with the same types.
+func (c *Converter) checkDeclared(ast *cel.Ast) error {
+	declared := make(map[string]*types.Type)
+	for _, variable := range c.env.Variables() {
+		declared[variable.Name()] = variable.Type()
+	}
+	typeMap := ast.NativeRep().TypeMap()
+
+	var check func(expr celast.Expr, locals map[string]bool) error
+	check = func(expr celast.Expr, locals map[string]bool) error {
+		switch expr.Kind() {
+		case celast.IdentKind:
+			name := expr.AsIdent()
+			if locals[name] {
+				return nil
+			}
+			t, ok := declared[name]
+			if !ok {
+				return fmt.Errorf("variable %s is not declared", name)
+			}
+			if got := typeMap[expr.ID()]; got == nil || !t.IsExactType(got) {
+				return fmt.Errorf("variable %s has type %v, declared as %v", name, got, t)
+			}
+		case celast.SelectKind:
+			return check(expr.AsSelect().Operand(), locals)
+		case celast.CallKind:
+			call := expr.AsCall()
+			if call.IsMemberFunction() {
+				if err := check(call.Target(), locals); err != nil {
+					return err
+				}
+			}
+			for _, arg := range call.Args() {
+				if err := check(arg, locals); err != nil {
+					return err
+				}
+			}
+		case celast.ListKind:
+			for _, elem := range expr.AsList().Elements() {
+				if err := check(elem, locals); err != nil {
+					return err
+				}
+			}
+		case celast.MapKind:
+			for _, entry := range expr.AsMap().Entries() {
+				if err := check(entry.AsMapEntry().Key(), locals); err != nil {
+					return err
+				}
+				if err := check(entry.AsMapEntry().Value(), locals); err != nil {
+					return err
+				}
+			}
+		case celast.StructKind:
+			for _, field := range expr.AsStruct().Fields() {
+				if err := check(field.AsStructField().Value(), locals); err != nil {
+					return err
+				}
+			}
+		case celast.ComprehensionKind:
+			comp := expr.AsComprehension()
+			if err := check(comp.IterRange(), locals); err != nil {
+				return err
+			}
+			if err := check(comp.AccuInit(), locals); err != nil {
+				return err
+			}
+			scope := make(map[string]bool, len(locals)+3)
+			for name := range locals {
+				scope[name] = true
+			}
+			scope[comp.IterVar()] = true
+			scope[comp.AccuVar()] = true
+			if comp.HasIterVar2() {
+				scope[comp.IterVar2()] = true
+			}
+			for _, e := range []celast.Expr{comp.LoopCondition(), comp.LoopStep(), comp.Result()} {
+				if err := check(e, scope); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return check(ast.NativeRep().Expr(), nil)
+}
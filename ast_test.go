@@ -0,0 +1,189 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ConvertAst(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "state"},
+			"age":    {Type: cel.IntType},
+		},
+		MaxExpressionLength: 50,
+		MaxExpressionDepth:  3,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	foreign, err := cel.NewEnv(
+		cel.Variable("status", cel.StringType),
+		cel.Variable("age", cel.StringType),
+		cel.Variable("password", cel.StringType),
+	)
+	if err != nil {
+		t.Fatalf("failed to create environment: %v", err)
+	}
+
+	compile := func(env *cel.Env, celExpr string) *cel.Ast {
+		ast, issues := env.Compile(celExpr)
+		if issues != nil && issues.Err() != nil {
+			t.Fatalf("Compile(%q) error = %v", celExpr, issues.Err())
+		}
+		return ast
+	}
+	parse := func(celExpr string) *cel.Ast {
+		ast, issues := converter.Env().Parse(celExpr)
+		if issues != nil && issues.Err() != nil {
+			t.Fatalf("Parse(%q) error = %v", celExpr, issues.Err())
+		}
+		return ast
+	}
+
+	tests := []struct {
+		name     string
+		ast      *cel.Ast
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode string
+	}{
+		{
+			name:     "compiled in the converter environment",
+			ast:      compile(converter.Env(), `status == "active" && age > 18`),
+			wantSQL:  "(state = ? AND age > ?)",
+			wantArgs: []interface{}{"active", int64(18)},
+		},
+		{
+			name:     "parsed only",
+			ast:      parse(`status in ["a", "b"]`),
+			wantSQL:  "state IN (?,?)",
+			wantArgs: []interface{}{"a", "b"},
+		},
+		{
+			name:     "checked in a foreign environment",
+			ast:      compile(foreign, `status == "active"`),
+			wantSQL:  "state = ?",
+			wantArgs: []interface{}{"active"},
+		},
+		{
+			name:     "parsed undeclared field",
+			ast:      parse(`password == "x"`),
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "foreign undeclared field",
+			ast:      compile(foreign, `password == "x"`),
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "foreign field type",
+			ast:      compile(foreign, `age == "18"`),
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "not boolean",
+			ast:      compile(converter.Env(), `age + 1`),
+			wantCode: "INVALID_TYPE",
+		},
+		{
+			name:     "length",
+			ast:      compile(converter.Env(), `status == "`+strings.Repeat("x", 50)+`"`),
+			wantCode: "LIMIT_LENGTH",
+		},
+		{
+			name:     "depth",
+			ast:      compile(converter.Env(), `age == 1 || (age == 2 && (age == 3 || age == 4))`),
+			wantCode: "LIMIT_DEPTH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertAst(tt.ast)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ConvertAst() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ConvertAst() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ConvertAst() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	if _, err := converter.ConvertAst(nil); err == nil {
+		t.Error("ConvertAst(nil) error = nil, want error")
+	}
+}
+
+func TestConverter_ConvertAst_Comprehension(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{})
+	celExpr := `comments.exists(c, c.flagged)`
+	ast, issues := converter.Env().Compile(celExpr)
+	if issues != nil && issues.Err() != nil {
+		t.Fatalf("Compile() error = %v", issues.Err())
+	}
+
+	fromAst, err := converter.ConvertAst(ast)
+	if err != nil {
+		t.Fatalf("ConvertAst() error = %v", err)
+	}
+	fromString, err := converter.Convert(celExpr)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	gotSQL, _, _ := fromAst.Where.ToSql()
+	wantSQL, _, _ := fromString.Where.ToSql()
+	if gotSQL != wantSQL {
+		t.Errorf("ConvertAst() SQL = %q, want %q as Convert", gotSQL, wantSQL)
+	}
+}
+
+func TestConverter_ConvertAstWithAuth(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"salary": {Type: cel.IntType},
+		},
+		PublicFields: []string{"status"},
+		FieldACL:     map[string][]string{"salary": {"hr"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	ast, issues := converter.Env().Compile(`salary > 1000`)
+	if issues != nil && issues.Err() != nil {
+		t.Fatalf("Compile() error = %v", issues.Err())
+	}
+
+	tests := []struct {
+		name     string
+		roles    []string
+		wantCode string
+	}{
+		{name: "authorized", roles: []string{"hr"}},
+		{name: "unauthorized", roles: []string{"viewer"}, wantCode: "UNAUTHORIZED_FIELD"},
+		{name: "no roles", wantCode: "UNAUTHORIZED_FIELD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.ConvertAstWithAuth(ast, tt.roles)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Errorf("ConvertAstWithAuth() error = %v, want %q", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// sqlKeywords are the keywords, type names and functions the converter may
+// emit, in upper case.
+var sqlKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "IN": true,
+	"LIKE": true, "ILIKE": true, "ESCAPE": true, "BETWEEN": true,
+	"TRUE": true, "FALSE": true, "CAST": true, "AS": true,
+	"BIGINT": true, "DOUBLE": true, "PRECISION": true, "VARCHAR": true, "TEXT": true,
+	"SIGNED": true, "CHAR": true, "INTEGER": true, "REAL": true,
+	"FLOAT": true, "NVARCHAR": true, "MAX": true,
+	"LOWER": true, "CONCAT": true, "COALESCE": true, "ANY": true, "ARRAY": true,
+	"CARDINALITY": true, "JSONB_ARRAY_LENGTH": true, "JSON_LENGTH": true,
+	"JSON_ARRAY_LENGTH": true, "JSON_UNQUOTE": true, "JSON_EXTRACT": true,
+	"ST_DWITHIN": true, "ST_MAKEPOINT": true, "GEOGRAPHY": true,
+	"ST_DISTANCE_SPHERE": true, "POINT": true,
+	"TRIM": true, "UPPER": true, "REPLACE": true, "STRPOS": true, "LOCATE": true,
+	"INSTR": true, "POSITION": true, "CHARINDEX": true, "IIF": true, "DATALENGTH": true,
+	"RIGHT": true, "LENGTH": true, "CHAR_LENGTH": true, "LEN": true, "SUBSTR": true,
+	"SUBSTRING": true, "FROM": true, "TRUNC": true, "TRUNCATE": true, "CASE": true,
+	"WHEN": true, "THEN": true, "ELSE": true, "END": true, "CEILING": true, "FLOOR": true,
+}
+
+// sqlOperators are the operators and punctuation the converter may emit.
+var sqlOperators = map[string]bool{
+	"->>": true, "<>": true, "<=": true, ">=": true, "||": true, "@>": true, "&&": true,
+	"=": true, "<": true, ">": true, "+": true, "-": true, "*": true, "/": true, "%": true,
+	"(": true, ")": true, ",": true, ".": true, "[": true, "]": true, "::": true,
+}
+
+// sqlPunctuation are the characters always tokenized on their own.
+const sqlPunctuation = "(),.[]"
+
+// sqlSymbols are the characters forming operators.
+const sqlSymbols = "=<>!|&@+-*/%~^#:"
+
+// sqlTokenKind classifies SQL tokens.
+type sqlTokenKind int
+
+const (
+	tokenIdentifier sqlTokenKind = iota
+	tokenLiteral
+	tokenNumber
+	tokenPlaceholder
+	tokenOperator
+)
+
+// sqlToken is a lexical token of generated SQL. The text of literals is
+// their unquoted content.
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// sqlAuditor re-verifies generated SQL: outside of placeholders, it may only
+// contain known keywords, operators, numbers, mapped column tokens and
+// literals the converter generates itself.
+type sqlAuditor struct {
+	identifiers map[string]bool
+	operators   map[string]bool
+	literals    map[string]bool
+}
+
+// newSQLAuditor creates an auditor trusting the tokens of the configured
+// column mappings, function templates and other trusted SQL fragments.
+func newSQLAuditor(columns map[string]string, functions map[string]*sqlTemplate, fragments ...string) *sqlAuditor {
+	auditor := &sqlAuditor{
+		identifiers: make(map[string]bool),
+		operators:   make(map[string]bool),
+		// LIKE wildcards and escape characters, and the suffix sentinel of
+		// SQL Server
+		literals: map[string]bool{"%": true, `\`: true, `\\`: true, ".": true},
+	}
+
+	trusted := append([]string(nil), fragments...)
+	for _, column := range columns {
+		trusted = append(trusted, column)
+	}
+	for _, tmpl := range functions {
+		for _, part := range tmpl.parts {
+			trusted = append(trusted, part.literal)
+		}
+	}
+
+	auditor.trust(trusted...)
+	return auditor
+}
+
+// trust adds the tokens of trusted SQL fragments to those the auditor
+// accepts.
+func (a *sqlAuditor) trust(fragments ...string) {
+	for _, fragment := range fragments {
+		tokens, err := tokenizeSQL(fragment)
+		if err != nil {
+			// Untokenizable configuration is reported by audit
+			continue
+		}
+		for _, token := range tokens {
+			switch token.kind {
+			case tokenIdentifier:
+				a.identifiers[strings.ToUpper(token.text)] = true
+			case tokenOperator:
+				a.operators[token.text] = true
+			case tokenLiteral:
+				a.literals[token.text] = true
+			}
+		}
+	}
+}
+
+// with returns a copy of the auditor also trusting the tokens of fragments.
+func (a *sqlAuditor) with(fragments ...string) *sqlAuditor {
+	clone := &sqlAuditor{
+		identifiers: maps.Clone(a.identifiers),
+		operators:   maps.Clone(a.operators),
+		literals:    maps.Clone(a.literals),
+	}
+	clone.trust(fragments...)
+	return clone
+}
+
+// audit verifies the SQL rendered by sqlizer, also trusting the tokens of
+// the given fragments, such as the subqueries rendered by the conversion.
+func (a *sqlAuditor) audit(sqlizer squirrel.Sqlizer, trusted ...string) error {
+	if len(trusted) > 0 {
+		a = a.with(trusted...)
+	}
+
+	sql, _, err := sqlizer.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to render SQL: %w", err)
+	}
+
+	tokens, err := tokenizeSQL(sql)
+	if err != nil {
+		return a.violation(sql, err)
+	}
+
+	for i, token := range tokens {
+		switch token.kind {
+		case tokenIdentifier:
+			upper := strings.ToUpper(token.text)
+			if !sqlKeywords[upper] && !a.identifiers[upper] {
+				return a.violation(sql, fmt.Errorf("unexpected identifier %q", token.text))
+			}
+		case tokenOperator:
+			if !sqlOperators[token.text] && !a.operators[token.text] {
+				return a.violation(sql, fmt.Errorf("unexpected operator %q", token.text))
+			}
+		case tokenLiteral:
+			var previous sqlToken
+			if i > 0 {
+				previous = tokens[i-1]
+			}
+			if !a.literals[token.text] && !isJSONKeyLiteral(previous, token.text) {
+				return a.violation(sql, fmt.Errorf("unexpected literal %q", token.text))
+			}
+		}
+	}
+	return nil
+}
+
+// violation reports generated SQL failing the audit.
+func (a *sqlAuditor) violation(sql string, err error) error {
+	return newConversionError(
+		"filter expression failed SQL audit",
+		CodeAuditViolation,
+		fmt.Errorf("generated SQL %q: %w", sql, err),
+	)
+}
+
+// isJSONKeyLiteral reports whether a literal is a JSON key extraction
+// generated from a validated key: col->>'region' or JSON_EXTRACT(col,
+// '$.region').
+func isJSONKeyLiteral(previous sqlToken, text string) bool {
+	if previous.kind != tokenOperator {
+		return false
+	}
+	switch previous.text {
+	case "->>":
+		return isJSONKey(text)
+	case ",":
+		path, ok := strings.CutPrefix(text, "$.")
+		return ok && isJSONKey(path)
+	default:
+		return false
+	}
+}
+
+// tokenizeSQL splits SQL into tokens. Anything but identifiers (bare or
+// quoted with " or `), single-quoted literals, numbers, ? placeholders,
+// punctuation and operators is rejected.
+func tokenizeSQL(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+
+		case ch == '?':
+			tokens = append(tokens, sqlToken{kind: tokenPlaceholder, text: "?"})
+			i++
+
+		case ch == '\'':
+			end := strings.IndexByte(sql[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated literal at offset %d", i)
+			}
+			tokens = append(tokens, sqlToken{kind: tokenLiteral, text: sql[i+1 : i+1+end]})
+			i += end + 2
+
+		case ch == '"' || ch == '`':
+			end := strings.IndexByte(sql[i+1:], ch)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated identifier at offset %d", i)
+			}
+			tokens = append(tokens, sqlToken{kind: tokenIdentifier, text: sql[i : i+end+2]})
+			i += end + 2
+
+		case isIdentifierStart(ch):
+			j := i + 1
+			for j < len(sql) && (isIdentifierStart(sql[j]) || isDigit(sql[j]) || sql[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenIdentifier, text: sql[i:j]})
+			i = j
+
+		case isDigit(ch):
+			j := i + 1
+			for j < len(sql) && (isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenNumber, text: sql[i:j]})
+			i = j
+
+		case strings.IndexByte(sqlPunctuation, ch) >= 0:
+			tokens = append(tokens, sqlToken{kind: tokenOperator, text: string(ch)})
+			i++
+
+		case strings.IndexByte(sqlSymbols, ch) >= 0:
+			j := i + 1
+			for j < len(sql) && strings.IndexByte(sqlSymbols, sql[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenOperator, text: sql[i:j]})
+			i = j
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", ch, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentifierStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
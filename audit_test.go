@@ -0,0 +1,100 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func newTestAuditConverter(t *testing.T, dialect Dialect) *Converter {
+	t.Helper()
+
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":     {Type: cel.StringType, Column: "u.full_name"},
+			"other":    {Type: cel.StringType, Column: "other"},
+			"age":      {Type: cel.IntType, Column: "age"},
+			"score":    {Type: cel.DoubleType, Column: "score"},
+			"active":   {Type: cel.BoolType, Column: "is_active"},
+			"created":  {Type: cel.TimestampType, Column: "created_at"},
+			"tags":     {Type: cel.ListType(cel.StringType), Column: "tags"},
+			"labels":   {Type: cel.ListType(cel.StringType), Column: "labels", Kind: KindArray},
+			"metadata": {Type: cel.MapType(cel.StringType, cel.StringType), Column: "meta"},
+		},
+		Functions: []FunctionTemplate{{
+			Name:         "matchesText",
+			ReceiverType: cel.StringType,
+			ArgTypes:     []*cel.Type{cel.StringType},
+			SQL:          "to_tsvector('english', {col}) @@ plainto_tsquery('english', {arg0})",
+		}},
+		Dialect:  dialect,
+		AuditSQL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_AuditSQL(t *testing.T) {
+	expressions := []string{
+		`name == "x" && (age > 3 || !active)`,
+		`age in [1, 2, 3] && score <= 4.5`,
+		`name.contains("'; DROP TABLE users; --") || name.startsWith(other)`,
+		`name.endsWith("x") && name.equalsIgnoreCase("Y")`,
+		`created >= timestamp("2024-01-01T00:00:00Z")`,
+		`int(score) == 3 && string(age) == "3" && age * 2 + 1 > 10`,
+		`name.matchesText("hello")`,
+		`true`,
+	}
+	postgres := []string{
+		`size(tags) == 0 && "go" in labels && labels.containsAny(["a"])`,
+		`metadata[?"region"].orValue("us") == "eu"`,
+	}
+
+	for _, dialect := range []Dialect{DialectDefault, DialectPostgreSQL, DialectMySQL, DialectSQLite, DialectSQLServer} {
+		converter := newTestAuditConverter(t, dialect)
+
+		celExprs := expressions
+		if dialect == DialectPostgreSQL {
+			celExprs = append(celExprs, postgres...)
+		}
+		for _, celExpr := range celExprs {
+			if _, err := converter.Convert(celExpr); err != nil {
+				t.Errorf("Convert(%q) with dialect %q error = %v", celExpr, dialect, err)
+			}
+		}
+	}
+}
+
+func TestSQLAuditor_Violations(t *testing.T) {
+	converter := newTestAuditConverter(t, DialectPostgreSQL)
+
+	tests := []struct {
+		name    string
+		sqlizer squirrel.Sqlizer
+	}{
+		{name: "unmapped identifier", sqlizer: squirrel.Eq{"password": "x"}},
+		{name: "statement separator", sqlizer: squirrel.Expr("age = ?; DROP TABLE users", 1)},
+		{name: "comment", sqlizer: squirrel.Expr("age = ? -- x", 1)},
+		{name: "user literal", sqlizer: squirrel.Expr("age = 1 OR 'a' = 'a'")},
+		{name: "unterminated literal", sqlizer: squirrel.Expr("age = 'x")},
+		{name: "quoted identifier", sqlizer: squirrel.Expr(`"secret" = ?`, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := converter.auditor.audit(tt.sqlizer)
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) || convErr.ErrorCode != "AUDIT_VIOLATION" {
+				t.Errorf("audit() error = %v, want AUDIT_VIOLATION", err)
+			}
+		})
+	}
+
+	if err := converter.auditor.audit(squirrel.Expr("u.full_name = ? AND labels @> ARRAY[?]", "a", "b")); err != nil {
+		t.Errorf("audit() error = %v", err)
+	}
+}
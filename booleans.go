@@ -0,0 +1,71 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// BooleanStyle selects how a standalone boolean field, such as `is_draft`
+// in `is_draft && age > 18`, is tested.
+type BooleanStyle string
+
+const (
+	// BooleanBound compares the column with a bound true: is_draft = ?. It
+	// is the default.
+	BooleanBound BooleanStyle = ""
+	// BooleanIsTrue tests the column with IS TRUE: is_draft IS TRUE.
+	BooleanIsTrue BooleanStyle = "is_true"
+	// BooleanLiteral compares the column with the TRUE literal:
+	// is_draft = TRUE, which planners can match against partial indexes.
+	BooleanLiteral BooleanStyle = "literal"
+	// BooleanBare uses the column as the predicate: is_draft.
+	BooleanBare BooleanStyle = "bare"
+)
+
+// validate checks that the style is known.
+func (s BooleanStyle) validate() error {
+	switch s {
+	case BooleanBound, BooleanIsTrue, BooleanLiteral, BooleanBare:
+		return nil
+	}
+	return fmt.Errorf("unknown boolean style %q", s)
+}
+
+// boolColumn tests a boolean column in a style other than BooleanBound.
+type boolColumn struct {
+	column string
+	style  BooleanStyle
+	not    bool
+}
+
+// ToSql implements squirrel.Sqlizer.
+func (b *boolColumn) ToSql() (string, []interface{}, error) {
+	switch {
+	case b.style == BooleanIsTrue && b.not:
+		return b.column + " IS NOT TRUE", nil, nil
+	case b.style == BooleanIsTrue:
+		return b.column + " IS TRUE", nil, nil
+	case b.style == BooleanLiteral && b.not:
+		return b.column + " <> TRUE", nil, nil
+	case b.style == BooleanLiteral:
+		return b.column + " = TRUE", nil, nil
+	case b.not:
+		return "NOT " + b.column, nil, nil
+	default:
+		return b.column, nil, nil
+	}
+}
+
+// boolField renders the test of a standalone boolean field.
+func (c *Converter) boolField(field string) (squirrel.Sqlizer, error) {
+	column := c.mapFieldName(field)
+	if c.booleanStyle != BooleanBound {
+		return &boolColumn{column: column, style: c.booleanStyle}, nil
+	}
+
+	if err := c.charge(0, 1, approxValueBytes); err != nil {
+		return nil, err
+	}
+	return squirrel.Eq{column: true}, nil
+}
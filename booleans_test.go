@@ -0,0 +1,66 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_BooleanStyle(t *testing.T) {
+	tests := []struct {
+		name        string
+		style       BooleanStyle
+		pushDownNot bool
+		celExpr     string
+		wantSQL     string
+		wantArgs    int
+	}{
+		{name: "bound", style: BooleanBound, celExpr: `is_draft`, wantSQL: "is_draft = ?", wantArgs: 1},
+		{name: "IS TRUE", style: BooleanIsTrue, celExpr: `is_draft`, wantSQL: "is_draft IS TRUE"},
+		{name: "literal", style: BooleanLiteral, celExpr: `is_draft`, wantSQL: "is_draft = TRUE"},
+		{name: "bare", style: BooleanBare, celExpr: `is_draft && age > 18`, wantSQL: "(is_draft AND age > ?)", wantArgs: 1},
+		{name: "negated IS TRUE", style: BooleanIsTrue, pushDownNot: true, celExpr: `!is_draft`, wantSQL: "is_draft IS NOT TRUE"},
+		{name: "negated literal", style: BooleanLiteral, pushDownNot: true, celExpr: `!is_draft`, wantSQL: "is_draft <> TRUE"},
+		{name: "negated bare", style: BooleanBare, pushDownNot: true, celExpr: `!is_draft`, wantSQL: "NOT is_draft"},
+		{name: "wrapped negation", style: BooleanBare, celExpr: `!is_draft`, wantSQL: "NOT (is_draft)"},
+		{name: "comparison unaffected", style: BooleanBare, celExpr: `is_draft == false`, wantSQL: "is_draft = ?", wantArgs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{
+					"is_draft": {Type: cel.BoolType},
+					"age":      {Type: cel.IntType},
+				},
+				BooleanStyle: tt.style,
+				PushDownNot:  tt.pushDownNot,
+				AuditSQL:     true,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if len(args) != tt.wantArgs {
+				t.Errorf("ToSql() args = %v, want %d", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNewConverter_UnknownBooleanStyle(t *testing.T) {
+	if _, err := NewConverter(Config{BooleanStyle: "yes"}); err == nil {
+		t.Error("NewConverter() should reject an unknown boolean style")
+	}
+}
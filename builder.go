@@ -0,0 +1,84 @@
+package cel2squirrel
+
+import (
+	"github.com/Masterminds/squirrel"
+)
+
+// Sqlizer is a SQL fragment with its bound values. Its method set matches
+// squirrel.Sqlizer, and the Sqlizer interfaces of other squirrel versions
+// and forks, so the Sqlizers of conversion results can be passed to their
+// builders as is.
+type Sqlizer interface {
+	ToSql() (string, []interface{}, error)
+}
+
+// Builder adapts a select query builder of type B, so that conversion
+// results can be applied to builders other than squirrel v1's, e.g. those
+// of another squirrel version or fork. Like squirrel's, its methods return
+// the updated builder.
+type Builder[B any] interface {
+	// LeftJoin adds a LEFT JOIN clause, given without the JOIN keyword.
+	LeftJoin(builder B, join string) B
+	// Where adds a WHERE predicate, ANDed with the existing ones.
+	Where(builder B, pred Sqlizer) B
+	// GroupBy adds GROUP BY columns.
+	GroupBy(builder B, columns ...string) B
+	// Having adds a HAVING predicate, ANDed with the existing ones.
+	Having(builder B, pred Sqlizer) B
+}
+
+// SquirrelBuilder adapts squirrel v1 select builders.
+type SquirrelBuilder struct{}
+
+var _ Builder[squirrel.SelectBuilder] = SquirrelBuilder{}
+
+// LeftJoin implements Builder.
+func (SquirrelBuilder) LeftJoin(builder squirrel.SelectBuilder, join string) squirrel.SelectBuilder {
+	return builder.LeftJoin(join)
+}
+
+// Where implements Builder.
+func (SquirrelBuilder) Where(builder squirrel.SelectBuilder, pred Sqlizer) squirrel.SelectBuilder {
+	return builder.Where(pred)
+}
+
+// GroupBy implements Builder.
+func (SquirrelBuilder) GroupBy(builder squirrel.SelectBuilder, columns ...string) squirrel.SelectBuilder {
+	return builder.GroupBy(columns...)
+}
+
+// Having implements Builder.
+func (SquirrelBuilder) Having(builder squirrel.SelectBuilder, pred Sqlizer) squirrel.SelectBuilder {
+	return builder.Having(pred)
+}
+
+// Apply adds the joins and WHERE predicate of a conversion result to a
+// builder through its adapter.
+func Apply[B any](adapter Builder[B], builder B, result *ConvertResult) B {
+	for _, join := range result.Joins {
+		builder = adapter.LeftJoin(builder, join.Clause())
+	}
+	if result.Where != nil {
+		builder = adapter.Where(builder, result.Where)
+	}
+	return builder
+}
+
+// ApplyGrouped adds the joins, WHERE, GROUP BY and HAVING clauses of a
+// grouped conversion result to a builder through its adapter, skipping
+// those that are empty.
+func ApplyGrouped[B any](adapter Builder[B], builder B, result *GroupedResult) B {
+	for _, join := range result.Joins {
+		builder = adapter.LeftJoin(builder, join.Clause())
+	}
+	if result.Where != nil {
+		builder = adapter.Where(builder, result.Where)
+	}
+	if len(result.GroupBy) > 0 {
+		builder = adapter.GroupBy(builder, result.GroupBy...)
+	}
+	if result.Having != nil {
+		builder = adapter.Having(builder, result.Having)
+	}
+	return builder
+}
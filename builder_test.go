@@ -0,0 +1,140 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+// Results are accepted by any builder taking squirrel-like Sqlizers.
+var _ Sqlizer = squirrel.Sqlizer(nil)
+
+// forkQuery stands for the select builder of another squirrel version,
+// taking SQL strings and values.
+type forkQuery struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (q forkQuery) add(clause string, args ...interface{}) forkQuery {
+	return forkQuery{
+		clauses: append(q.clauses[:len(q.clauses):len(q.clauses)], clause),
+		args:    append(q.args[:len(q.args):len(q.args)], args...),
+	}
+}
+
+// forkBuilder adapts forkQuery.
+type forkBuilder struct{}
+
+func (forkBuilder) LeftJoin(q forkQuery, join string) forkQuery {
+	return q.add("LEFT JOIN " + join)
+}
+
+func (forkBuilder) Where(q forkQuery, pred Sqlizer) forkQuery {
+	sql, args, _ := pred.ToSql()
+	return q.add("WHERE "+sql, args...)
+}
+
+func (forkBuilder) GroupBy(q forkQuery, columns ...string) forkQuery {
+	return q.add("GROUP BY " + strings.Join(columns, ", "))
+}
+
+func (forkBuilder) Having(q forkQuery, pred Sqlizer) forkQuery {
+	sql, args, _ := pred.ToSql()
+	return q.add("HAVING "+sql, args...)
+}
+
+func TestApply(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"author.name": {Type: cel.StringType, Column: "name", Join: &JoinSpec{
+				Table: "users", Alias: "u", On: "p.author_id = u.id",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`status == "published" && author.name == "ada"`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	query := Apply(forkBuilder{}, forkQuery{}, result)
+	wantClauses := []string{"LEFT JOIN users u ON p.author_id = u.id", "WHERE (status = ? AND u.name = ?)"}
+	if !reflect.DeepEqual(query.clauses, wantClauses) {
+		t.Errorf("clauses = %q, want %q", query.clauses, wantClauses)
+	}
+	if want := []interface{}{"published", "ada"}; !reflect.DeepEqual(query.args, want) {
+		t.Errorf("args = %v, want %v", query.args, want)
+	}
+
+	sql, _, err := Apply(SquirrelBuilder{}, squirrel.Select("p.id").From("prompts p"), result).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "SELECT p.id FROM prompts p LEFT JOIN users u ON p.author_id = u.id WHERE (status = ? AND u.name = ?)"; sql != want {
+		t.Errorf("ToSql() = %v, want %v", sql, want)
+	}
+}
+
+func TestApplyGrouped(t *testing.T) {
+	converter, err := NewGroupedConverter(GroupedConfig{
+		Config: Config{FieldDeclarations: map[string]ColumnMapping{
+			"status":      {Type: cel.StringType},
+			"customer_id": {Type: cel.StringType},
+		}},
+		Aggregates: map[string]ColumnMapping{"orderCount": {Type: cel.IntType, Column: "COUNT(*)"}},
+		GroupBy:    []string{"customer_id"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`status == "paid"`, `orderCount > 3`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	query := ApplyGrouped(forkBuilder{}, forkQuery{}, result)
+	wantClauses := []string{"WHERE status = ?", "GROUP BY customer_id", "HAVING COUNT(*) > ?"}
+	if !reflect.DeepEqual(query.clauses, wantClauses) {
+		t.Errorf("clauses = %q, want %q", query.clauses, wantClauses)
+	}
+	if got, want := fmt.Sprint(query.args), "[paid 3]"; got != want {
+		t.Errorf("args = %v, want %v", got, want)
+	}
+}
+
+func TestSqlizerResults(t *testing.T) {
+	sqlizer := reflect.TypeFor[Sqlizer]()
+	tests := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{"ConvertResult.Where", fieldType[ConvertResult]("Where")},
+		{"GroupedResult.Where", fieldType[GroupedResult]("Where")},
+		{"GroupedResult.Having", fieldType[GroupedResult]("Having")},
+		{"Converter.Sqlizer", reflect.TypeOf((*Converter).Sqlizer).Out(0)},
+		{"PreparedFilter.Where", reflect.TypeOf((*PreparedFilter).Where).Out(0)},
+		{"Keyset.After", reflect.TypeOf(Keyset.After).Out(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.typ != sqlizer {
+				t.Errorf("%s is a %v, want %v", tt.name, tt.typ, sqlizer)
+			}
+		})
+	}
+}
+
+func fieldType[T any](name string) reflect.Type {
+	field, _ := reflect.TypeFor[T]().FieldByName(name)
+	return field.Type
+}
@@ -0,0 +1,170 @@
+package cel2squirrel
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CacheStats reports the activity of the conversion cache of a converter.
+type CacheStats struct {
+	// Hits counts the conversions served from the cache.
+	Hits uint64
+	// Misses counts the conversions not found in the cache.
+	Misses uint64
+	// Evictions counts the least recently used results dropped to make
+	// room for new ones.
+	Evictions uint64
+	// Expirations counts the results dropped past Config.CacheTTL.
+	Expirations uint64
+	// Entries is the number of cached results.
+	Entries int
+}
+
+// resultCache is an LRU cache of conversion results keyed by expression.
+type resultCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+	stats   CacheStats
+}
+
+// cacheEntry is a cached conversion result.
+type cacheEntry struct {
+	expr    string
+	result  *ConvertResult
+	expires time.Time
+}
+
+// newResultCache returns a cache of size results, or nil when size is not
+// positive.
+func newResultCache(size int, ttl time.Duration) *resultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &resultCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns a copy of the cached result of celExpr.
+func (rc *resultCache) get(celExpr string) (*ConvertResult, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	element, ok := rc.entries[celExpr]
+	if !ok {
+		rc.stats.Misses++
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if rc.ttl > 0 && !rc.now().Before(entry.expires) {
+		rc.remove(element)
+		rc.stats.Expirations++
+		rc.stats.Misses++
+		return nil, false
+	}
+	rc.order.MoveToFront(element)
+	rc.stats.Hits++
+	return entry.result.clone(), true
+}
+
+// add caches a copy of the result of celExpr, evicting the least recently
+// used result when the cache is full.
+func (rc *resultCache) add(celExpr string, result *ConvertResult) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry := &cacheEntry{expr: celExpr, result: result.clone()}
+	if rc.ttl > 0 {
+		entry.expires = rc.now().Add(rc.ttl)
+	}
+	if element, ok := rc.entries[celExpr]; ok {
+		element.Value = entry
+		rc.order.MoveToFront(element)
+		return
+	}
+	rc.entries[celExpr] = rc.order.PushFront(entry)
+	if rc.order.Len() > rc.size {
+		rc.remove(rc.order.Back())
+		rc.stats.Evictions++
+	}
+}
+
+// remove drops a cached result.
+func (rc *resultCache) remove(element *list.Element) {
+	rc.order.Remove(element)
+	delete(rc.entries, element.Value.(*cacheEntry).expr)
+}
+
+// convertCached compiles and converts celExpr, through the cache when
+// enabled. Results depending on the context of the call, e.g. through
+// subqueries, are not cached.
+func (c *Converter) convertCached(ctx context.Context, celExpr string) (*ConvertResult, error) {
+	if c.cache != nil {
+		if result, ok := c.cache.get(celExpr); ok {
+			if c.stats != nil {
+				c.stats.Record(c.columnUsages(result.expr))
+			}
+			return result, nil
+		}
+	}
+
+	_, checkedExpr, err := c.compile(ctx, celExpr)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.convertChecked(ctx, checkedExpr.Expr(), checkedExpr.cost)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil && !result.contextual {
+		c.cache.add(celExpr, result)
+	}
+	return result, nil
+}
+
+// CacheStats returns the activity of the conversion cache, zero when
+// Config.CacheSize is not set.
+func (c *Converter) CacheStats() CacheStats {
+	c = c.current()
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	stats := c.cache.stats
+	stats.Entries = c.cache.order.Len()
+	return stats
+}
+
+// WithoutCache bypasses the conversion cache for a call, e.g. to measure
+// the conversion or when the caller knows the expression is unique.
+func WithoutCache() ConvertOption {
+	return func(options *convertOptions) {
+		options.noCache = true
+	}
+}
+
+// clone returns a copy of the result whose slices can be modified without
+// affecting r.
+func (r *ConvertResult) clone() *ConvertResult {
+	clone := *r
+	clone.Args = slices.Clone(r.Args)
+	clone.Warnings = slices.Clone(r.Warnings)
+	clone.Joins = slices.Clone(r.Joins)
+	clone.Fields = slices.Clone(r.Fields)
+	clone.Columns = slices.Clone(r.Columns)
+	clone.Operators = slices.Clone(r.Operators)
+	clone.Deprecations = slices.Clone(r.Deprecations)
+	return &clone
+}
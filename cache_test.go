@@ -0,0 +1,165 @@
+package cel2squirrel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func newTestCacheConverter(t *testing.T, config Config) *Converter {
+	t.Helper()
+
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+		"age":    {Type: cel.IntType},
+	}
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Cache(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    Config
+		exprs     []string
+		opts      []ConvertOption
+		wantStats CacheStats
+	}{
+		{
+			name:      "disabled",
+			exprs:     []string{`age > 1`, `age > 1`},
+			wantStats: CacheStats{},
+		},
+		{
+			name:      "repeated filter",
+			config:    Config{CacheSize: 2},
+			exprs:     []string{`age > 1`, `age > 1`, `age > 1`},
+			wantStats: CacheStats{Hits: 2, Misses: 1, Entries: 1},
+		},
+		{
+			name:      "least recently used evicted",
+			config:    Config{CacheSize: 2},
+			exprs:     []string{`age > 1`, `age > 2`, `age > 1`, `age > 3`, `age > 1`, `age > 2`},
+			wantStats: CacheStats{Hits: 2, Misses: 4, Evictions: 2, Entries: 2},
+		},
+		{
+			name:      "errors not cached",
+			config:    Config{CacheSize: 2},
+			exprs:     []string{`age > "x"`, `age > "x"`},
+			wantStats: CacheStats{Misses: 2},
+		},
+		{
+			name:      "bypassed",
+			config:    Config{CacheSize: 2},
+			exprs:     []string{`age > 1`, `age > 1`},
+			opts:      []ConvertOption{WithoutCache()},
+			wantStats: CacheStats{},
+		},
+		{
+			name:      "bypassed with an overlay",
+			config:    Config{CacheSize: 2},
+			exprs:     []string{`age > 1`, `age > 1`},
+			opts:      []ConvertOption{WithMappingOverlay(map[string]string{"age": "rollup_age"})},
+			wantStats: CacheStats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := newTestCacheConverter(t, tt.config)
+			for _, celExpr := range tt.exprs {
+				converter.Convert(celExpr, tt.opts...)
+			}
+			if got := converter.CacheStats(); got != tt.wantStats {
+				t.Errorf("CacheStats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestConverter_CacheTTL(t *testing.T) {
+	converter := newTestCacheConverter(t, Config{CacheSize: 2, CacheTTL: time.Minute})
+	now := time.Now()
+	converter.cache.now = func() time.Time { return now }
+
+	converter.Convert(`age > 1`)
+	now = now.Add(30 * time.Second)
+	converter.Convert(`age > 1`)
+	now = now.Add(time.Minute)
+	converter.Convert(`age > 1`)
+
+	want := CacheStats{Hits: 1, Misses: 2, Expirations: 1, Entries: 1}
+	if got := converter.CacheStats(); got != want {
+		t.Errorf("CacheStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConverter_CacheIsolation(t *testing.T) {
+	converter := newTestCacheConverter(t, Config{
+		CacheSize: 2,
+		MandatoryConditionsFunc: func(ctx context.Context) ([]squirrel.Sqlizer, error) {
+			return []squirrel.Sqlizer{squirrel.Eq{"tenant_id": ctx.Value(tenantKey{})}}, nil
+		},
+	})
+
+	for _, tenant := range []string{"t1", "t2"} {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		result, err := converter.ConvertContext(ctx, `status == "open"`)
+		if err != nil {
+			t.Fatalf("ConvertContext() error = %v", err)
+		}
+		sql, args, err := result.Where.ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+		if want := "(status = ? AND tenant_id = ?)"; sql != want {
+			t.Errorf("SQL = %q, want %q", sql, want)
+		}
+		if len(args) != 2 || args[1] != tenant {
+			t.Errorf("args = %v, want the tenant %s", args, tenant)
+		}
+
+		// Callers modifying a result do not affect the cached one
+		result.Fields[0] = "modified"
+		result.Warnings = append(result.Warnings, Diagnostic{Message: "modified"})
+	}
+
+	result, err := converter.ConvertContext(context.WithValue(context.Background(), tenantKey{}, "t3"), `status == "open"`)
+	if err != nil {
+		t.Fatalf("ConvertContext() error = %v", err)
+	}
+	if result.Fields[0] != "status" || len(result.Warnings) != 0 {
+		t.Errorf("cached result = %+v, modified by a caller", result)
+	}
+	if stats := converter.CacheStats(); stats.Hits != 2 {
+		t.Errorf("CacheStats() = %+v, want 2 hits", stats)
+	}
+}
+
+func TestConverter_CacheSubquery(t *testing.T) {
+	converter := newTestSubqueryConverter(t, Config{CacheSize: 2})
+
+	for _, user := range []string{"u1", "u2"} {
+		ctx := context.WithValue(context.Background(), userIDKey{}, user)
+		result, err := converter.ConvertContext(ctx, `orgId in allowedOrgs()`)
+		if err != nil {
+			t.Fatalf("ConvertContext() error = %v", err)
+		}
+		_, args, err := result.Where.ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+		if len(args) != 1 || args[0] != user {
+			t.Errorf("args = %v, want the user %s", args, user)
+		}
+	}
+	if stats := converter.CacheStats(); stats.Entries != 0 {
+		t.Errorf("CacheStats() = %+v, want no cached result", stats)
+	}
+}
@@ -0,0 +1,115 @@
+package cel2squirrel
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"maps"
+	"slices"
+
+	celast "github.com/google/cel-go/common/ast"
+	"google.golang.org/protobuf/proto"
+)
+
+// cacheKeyVersion is bumped whenever the cache key derivation changes, so
+// that keys computed by different releases never collide.
+const cacheKeyVersion = "cel2squirrel/cachekey/v1"
+
+// CacheKey returns a key identifying the rows selected by the result, for
+// use in application-level query result caches. It is the hex-encoded
+// SHA-256 digest of the normalized expression, the converter's schema
+// fingerprint, the dialect and the SQL with its bound values, so that
+// results of equivalent expressions share a key while any change of field
+// mapping or value yields a different one.
+//
+// Expressions differing only by whitespace normalize to the same key.
+func (r *ConvertResult) CacheKey() (string, error) {
+	if r.Where == nil {
+		return "", fmt.Errorf("result has no WHERE clause")
+	}
+
+	sql, args, err := r.Where.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to render SQL: %w", err)
+	}
+
+	expr, err := normalizedExpr(r.expr)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	writeKeyPart(h, cacheKeyVersion)
+	writeKeyPart(h, r.schema)
+	writeKeyPart(h, string(expr))
+	writeKeyPart(h, sql)
+	for _, arg := range args {
+		writeKeyPart(h, fmt.Sprintf("%T:%v", arg, arg))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalizedExpr returns a deterministic encoding of a checked expression.
+// Expression ids only depend on the token structure, so formatting does not
+// affect the encoding, which is that of the protobuf form of the expression.
+func normalizedExpr(expr celast.Expr) ([]byte, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	pb, err := celast.ExprToProto(expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode expression: %w", err)
+	}
+	encoded, err := proto.MarshalOptions{Deterministic: true}.Marshal(pb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode expression: %w", err)
+	}
+	return encoded, nil
+}
+
+// writeKeyPart writes a length-prefixed part, so that the boundaries between
+// parts cannot be shifted to produce the same digest.
+func writeKeyPart(h hash.Hash, part string) {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(part)))
+	h.Write(length[:])
+	h.Write([]byte(part))
+}
+
+// schemaFingerprint digests the configuration determining the rows selected
+// by a filter: the dialect, the field declarations, the collections and the
+// function templates.
+func schemaFingerprint(config Config, columnMappings map[string]string, collections map[string]*collection) string {
+	h := sha256.New()
+	writeKeyPart(h, string(config.Dialect))
+
+	for _, name := range slices.Sorted(maps.Keys(config.FieldDeclarations)) {
+		mapping := config.FieldDeclarations[name]
+		typeName := ""
+		if mapping.Type != nil {
+			typeName = mapping.Type.String()
+		}
+		writeKeyPart(h, name)
+		writeKeyPart(h, typeName)
+		writeKeyPart(h, columnMappings[name])
+		if mapping.Join != nil {
+			writeKeyPart(h, mapping.Join.Clause())
+		}
+		writeKeyPart(h, fmt.Sprintf("%s|%t|%s|%t",
+			mapping.Granularity, mapping.TruncateToGranularity, mapping.Kind, mapping.CaseInsensitive))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(collections)) {
+		writeKeyPart(h, name)
+		writeKeyPart(h, collections[name].subquery())
+		writeKeyPart(h, collections[name].converter.schema)
+	}
+
+	for _, fn := range config.Functions {
+		writeKeyPart(h, fn.Name)
+		writeKeyPart(h, fn.SQL)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
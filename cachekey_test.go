@@ -0,0 +1,96 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConvertResult_CacheKey(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"name": {Type: cel.StringType, Column: "user_name"},
+		"age":  {Type: cel.IntType},
+	}
+	newConverter := func(config Config) *Converter {
+		t.Helper()
+		if config.FieldDeclarations == nil {
+			config.FieldDeclarations = fields
+		}
+		converter, err := NewConverter(config)
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+		return converter
+	}
+	cacheKey := func(converter *Converter, celExpr string) string {
+		t.Helper()
+		result, err := converter.Convert(celExpr)
+		if err != nil {
+			t.Fatalf("Convert(%q) error = %v", celExpr, err)
+		}
+		key, err := result.CacheKey()
+		if err != nil {
+			t.Fatalf("CacheKey() error = %v", err)
+		}
+		return key
+	}
+
+	converter := newConverter(Config{})
+	base := cacheKey(converter, `name == "bob" && age > 18`)
+
+	if len(base) != 64 {
+		t.Errorf("CacheKey() = %q, want a hex-encoded SHA-256 digest", base)
+	}
+	if key := cacheKey(converter, "name==\"bob\"  &&\n\tage>18"); key != base {
+		t.Error("formatting changed the cache key")
+	}
+	if key := cacheKey(newConverter(Config{}), `name == "bob" && age > 18`); key != base {
+		t.Error("an identically configured converter produced another cache key")
+	}
+
+	different := map[string]string{
+		"value":    cacheKey(converter, `name == "alice" && age > 18`),
+		"operator": cacheKey(converter, `name == "bob" && age >= 18`),
+		"operand":  cacheKey(converter, `age > 18 && name == "bob"`),
+		"dialect":  cacheKey(newConverter(Config{Dialect: DialectPostgreSQL}), `name == "bob" && age > 18`),
+		"column": cacheKey(newConverter(Config{FieldDeclarations: map[string]ColumnMapping{
+			"name": {Type: cel.StringType, Column: "login"},
+			"age":  {Type: cel.IntType},
+		}}), `name == "bob" && age > 18`),
+	}
+	seen := map[string]string{base: "base"}
+	for change, key := range different {
+		if previous, ok := seen[key]; ok {
+			t.Errorf("changing the %s produced the same cache key as %s", change, previous)
+		}
+		seen[key] = change
+	}
+}
+
+func TestConvertResult_CacheKeyHybrid(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name": {Type: cel.StringType},
+			"age":  {Type: cel.IntType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	keys := make(map[string]bool)
+	for _, celExpr := range []string{`age > 18 && name.matches("^a")`, `age > 18 && name.matches("^b")`} {
+		result, err := converter.ConvertHybrid(celExpr)
+		if err != nil {
+			t.Fatalf("ConvertHybrid() error = %v", err)
+		}
+		key, err := result.CacheKey()
+		if err != nil {
+			t.Fatalf("CacheKey() error = %v", err)
+		}
+		keys[key] = true
+	}
+	if len(keys) != 2 {
+		t.Error("residual filters differing only in memory share a cache key")
+	}
+}
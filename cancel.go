@@ -0,0 +1,23 @@
+package cel2squirrel
+
+import (
+	"context"
+)
+
+// checkContext fails a conversion whose context is canceled or past its
+// deadline, so that pathological filters do not outlive their request.
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return newConversionError("filter conversion canceled", CodeCanceled, err)
+	}
+	return nil
+}
+
+// interrupted checks the context of the current call while walking the
+// expression tree.
+func (c *Converter) interrupted() error {
+	if c.conv == nil {
+		return nil
+	}
+	return checkContext(c.conv.ctx)
+}
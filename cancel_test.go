@@ -0,0 +1,114 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ConvertContextCanceled(t *testing.T) {
+	walked, cancelWalk := context.WithCancel(context.Background())
+	defer cancelWalk()
+
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			// Cancels the conversion while the tree is being walked
+			"region": {Type: cel.StringType, Transform: func(value any) (any, error) {
+				cancelWalk()
+				return value, nil
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		celExpr string
+		wantErr error
+	}{
+		{
+			name:    "live context",
+			ctx:     context.Background(),
+			celExpr: `status == "open"`,
+		},
+		{
+			name:    "canceled before compilation",
+			ctx:     canceled,
+			celExpr: `status == "open"`,
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "deadline exceeded",
+			ctx:     expired,
+			celExpr: `status == "open"`,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "canceled during the walk",
+			ctx:     walked,
+			celExpr: `region == "eu" || status == "open"`,
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.ConvertContext(tt.ctx, tt.celExpr)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("ConvertContext() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrCanceled) {
+				t.Errorf("ConvertContext() error = %v, want %v", err, ErrCanceled)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ConvertContext() error = %v, want it to wrap %v", err, tt.wantErr)
+			}
+			if got := errorCode(err); got != string(CodeCanceled) {
+				t.Errorf("error code = %q, want %q", got, CodeCanceled)
+			}
+		})
+	}
+
+	if err := converter.ValidateContext(canceled, `status == "open"`); !errors.Is(err, ErrCanceled) {
+		t.Errorf("ValidateContext() error = %v, want %v", err, ErrCanceled)
+	}
+}
+
+func TestConverter_ConvertWithAuthContextCanceled(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"salary": {Type: cel.IntType},
+		},
+		PublicFields: []string{"status"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = converter.ConvertWithAuthContext(canceled, `status == "open"`, []string{"user"})
+	if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("ConvertWithAuthContext() error = %v, want %v", err, ErrCanceled)
+	}
+	if _, err := converter.ConvertWithAuthContext(context.Background(), `status == "open"`, []string{"user"}); err != nil {
+		t.Errorf("ConvertWithAuthContext() error = %v, want nil", err)
+	}
+}
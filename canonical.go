@@ -0,0 +1,278 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+)
+
+// mirroredComparisons maps comparison operators to the operator comparing
+// their swapped operands.
+var mirroredComparisons = map[string]string{
+	operators.Equals:        operators.Equals,
+	operators.NotEquals:     operators.NotEquals,
+	operators.Less:          operators.Greater,
+	operators.LessEquals:    operators.GreaterEquals,
+	operators.Greater:       operators.Less,
+	operators.GreaterEquals: operators.LessEquals,
+}
+
+// canonicalize rewrites an expression into a canonical form, so that
+// semantically identical filters produce identical SQL: the operands of
+// && and || chains are sorted and deduplicated, literals compared to an
+// expression are moved to the right (`18 <= age` becomes `age >= 18`),
+// comparisons with true are dropped (`a != false` becomes `a`) and those
+// with false spelled `a == false`, which unlike `!a` does not match NULL
+// columns, the literals of `in` lists are sorted and deduplicated, and double
+// negations are collapsed. Macro bodies are left as written. The input tree
+// is left untouched.
+func canonicalize(expr celast.Expr) celast.Expr {
+	switch expr.Kind() {
+	case celast.CallKind:
+		return canonicalCallExpr(expr)
+	case celast.ListKind:
+		list := expr.AsList()
+		elems, changed := canonicalizeAll(list.Elements())
+		if !changed {
+			return expr
+		}
+		return exprFactory.NewList(expr.ID(), elems, list.OptionalIndices())
+	}
+	return expr
+}
+
+// canonicalizeAll canonicalizes exprs, reporting whether any changed.
+func canonicalizeAll(exprs []celast.Expr) ([]celast.Expr, bool) {
+	changed := false
+	result := make([]celast.Expr, len(exprs))
+	for i, expr := range exprs {
+		result[i] = canonicalize(expr)
+		changed = changed || result[i] != expr
+	}
+	return result, changed
+}
+
+// canonicalCallExpr canonicalizes a call and its arguments.
+func canonicalCallExpr(expr celast.Expr) celast.Expr {
+	call := expr.AsCall()
+	args, changed := canonicalizeAll(call.Args())
+	if call.IsMemberFunction() {
+		target := canonicalize(call.Target())
+		if !changed && target == call.Target() {
+			return expr
+		}
+		return exprFactory.NewMemberCall(expr.ID(), call.FunctionName(), target, args...)
+	}
+
+	function := call.FunctionName()
+	switch {
+	case function == operators.LogicalNot && len(args) == 1:
+		return negateExpr(expr.ID(), args[0])
+
+	case (function == operators.LogicalAnd || function == operators.LogicalOr) && len(args) == 2:
+		return canonicalChain(expr.ID(), function, args)
+
+	case function == operators.In && len(args) == 2:
+		if list, ok := sortedLiteralList(args[1]); ok {
+			args[1], changed = list, true
+		}
+
+	case mirroredComparisons[function] != "" && len(args) == 2:
+		left, right := args[0], args[1]
+		if left.Kind() == celast.LiteralKind && right.Kind() != celast.LiteralKind {
+			function, left, right = mirroredComparisons[function], right, left
+			args, changed = []celast.Expr{left, right}, true
+		}
+		value, ok := boolConstant(right)
+		if !ok || left.Kind() == celast.LiteralKind {
+			break
+		}
+		// `a == false` is kept rather than negated: `NOT (a IS TRUE)` and
+		// `a IS NOT TRUE` also match the rows where a is NULL
+		switch {
+		case function == operators.Equals && value, function == operators.NotEquals && !value:
+			return left
+		case function == operators.NotEquals:
+			return newCall(expr.ID(), operators.Equals, left, newBoolConstant(right.ID(), false))
+		}
+	}
+
+	if !changed {
+		return expr
+	}
+	return newCall(expr.ID(), function, args...)
+}
+
+// negateExpr returns the negation of a canonical expression, collapsing double
+// negations and negated comparisons with false: `!(a == false)` becomes `a`,
+// which does not match NULL columns either.
+func negateExpr(id int64, expr celast.Expr) celast.Expr {
+	if call := expr.AsCall(); expr.Kind() == celast.CallKind {
+		args := call.Args()
+		if call.FunctionName() == operators.LogicalNot && len(args) == 1 {
+			return args[0]
+		}
+		if call.FunctionName() == operators.Equals && len(args) == 2 {
+			if value, ok := boolConstant(args[1]); ok && !value {
+				return args[0]
+			}
+		}
+	}
+	return newCall(id, operators.LogicalNot, expr)
+}
+
+// canonicalChain rebuilds a chain of && or || over canonical operands,
+// sorted and deduplicated, as a left-deep tree reusing the chain IDs.
+func canonicalChain(id int64, function string, args []celast.Expr) celast.Expr {
+	ids := []int64{id}
+	var operands []celast.Expr
+	var flatten func(celast.Expr)
+	flatten = func(expr celast.Expr) {
+		if call := expr.AsCall(); expr.Kind() == celast.CallKind && call.FunctionName() == function &&
+			len(call.Args()) == 2 {
+			ids = append(ids, expr.ID())
+			flatten(call.Args()[0])
+			flatten(call.Args()[1])
+			return
+		}
+		operands = append(operands, expr)
+	}
+	for _, arg := range args {
+		flatten(arg)
+	}
+
+	// The outermost call keeps the ID of the chain
+	operands = sortedUnique(operands)
+	chain := operands[0]
+	for i, operand := range operands[1:] {
+		chain = newCall(ids[len(operands)-2-i], function, chain, operand)
+	}
+	return chain
+}
+
+// sortedLiteralList returns a list of literals sorted and deduplicated, or
+// false when expr is not such a list or is already canonical.
+func sortedLiteralList(expr celast.Expr) (celast.Expr, bool) {
+	list := expr.AsList()
+	if expr.Kind() != celast.ListKind || len(list.OptionalIndices()) > 0 {
+		return expr, false
+	}
+	for _, elem := range list.Elements() {
+		if elem.Kind() != celast.LiteralKind {
+			return expr, false
+		}
+	}
+	elems := sortedUnique(list.Elements())
+	if slices.Equal(elems, list.Elements()) {
+		return expr, false
+	}
+	return exprFactory.NewList(expr.ID(), elems, nil), true
+}
+
+// sortedUnique returns exprs ordered by their sort key, without the
+// repeated ones.
+func sortedUnique(exprs []celast.Expr) []celast.Expr {
+	type keyed struct {
+		expr celast.Expr
+		key  string
+	}
+	sorted := make([]keyed, len(exprs))
+	for i, expr := range exprs {
+		var b strings.Builder
+		writeSortKey(&b, expr)
+		sorted[i] = keyed{expr: expr, key: b.String()}
+	}
+	slices.SortStableFunc(sorted, func(a, b keyed) int {
+		return strings.Compare(a.key, b.key)
+	})
+	sorted = slices.CompactFunc(sorted, func(a, b keyed) bool {
+		return a.key == b.key
+	})
+
+	result := make([]celast.Expr, len(sorted))
+	for i, k := range sorted {
+		result[i] = k.expr
+	}
+	return result
+}
+
+// writeSortKey renders an expression, literal values included, to order the
+// operands of canonical expressions. Calls are rendered with their first
+// operand ahead, so that predicates on the same field sort together. Equal
+// keys denote identical expressions.
+func writeSortKey(b *strings.Builder, expr celast.Expr) {
+	switch expr.Kind() {
+	case celast.CallKind:
+		call := expr.AsCall()
+		args := call.Args()
+		b.WriteByte('(')
+		switch {
+		case call.IsMemberFunction():
+			writeSortKey(b, call.Target())
+			b.WriteString(" ." + call.FunctionName() + "()")
+		case len(args) > 0:
+			writeSortKey(b, args[0])
+			args = args[1:]
+			b.WriteString(" " + call.FunctionName())
+		default:
+			b.WriteString(call.FunctionName())
+		}
+		for _, arg := range args {
+			b.WriteByte(' ')
+			writeSortKey(b, arg)
+		}
+		b.WriteByte(')')
+	case celast.IdentKind:
+		b.WriteString(expr.AsIdent())
+	case celast.LiteralKind:
+		if expr.AsLiteral() == types.NullValue {
+			b.WriteString("null")
+			return
+		}
+		fmt.Fprintf(b, "%T:%q", expr.AsLiteral(), fmt.Sprint(expr.AsLiteral().Value()))
+	case celast.SelectKind:
+		sel := expr.AsSelect()
+		if sel.IsTestOnly() {
+			b.WriteString("has")
+		}
+		b.WriteByte('(')
+		writeSortKey(b, sel.Operand())
+		b.WriteString(" ." + sel.FieldName() + ")")
+	case celast.ListKind:
+		b.WriteByte('[')
+		for _, elem := range expr.AsList().Elements() {
+			writeSortKey(b, elem)
+			b.WriteByte(' ')
+		}
+		b.WriteByte(']')
+	case celast.MapKind:
+		b.WriteByte('{')
+		for _, entry := range expr.AsMap().Entries() {
+			writeSortKey(b, entry.AsMapEntry().Key())
+			b.WriteByte(':')
+			writeSortKey(b, entry.AsMapEntry().Value())
+			b.WriteByte(' ')
+		}
+		b.WriteByte('}')
+	case celast.StructKind:
+		b.WriteString(expr.AsStruct().TypeName() + "{")
+		for _, field := range expr.AsStruct().Fields() {
+			b.WriteString(field.AsStructField().Name() + ":")
+			writeSortKey(b, field.AsStructField().Value())
+			b.WriteByte(' ')
+		}
+		b.WriteByte('}')
+	case celast.ComprehensionKind:
+		comp := expr.AsComprehension()
+		b.WriteString("(comprehension " + comp.IterVar() + " " + comp.IterVar2() + " " + comp.AccuVar())
+		for _, e := range []celast.Expr{comp.IterRange(), comp.AccuInit(), comp.LoopCondition(), comp.LoopStep(), comp.Result()} {
+			b.WriteByte(' ')
+			writeSortKey(b, e)
+		}
+		b.WriteByte(')')
+	}
+}
@@ -0,0 +1,277 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newCanonicalConverter(t *testing.T, config Config) *Converter {
+	t.Helper()
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"is_draft": {Type: cel.BoolType, Column: "is_draft"},
+		"age":      {Type: cel.IntType, Column: "age"},
+		"status":   {Type: cel.StringType, Column: "status"},
+		"name":     {Type: cel.StringType, Column: "name"},
+	}
+	config.Canonicalize = true
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_Canonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{name: "sorted and", celExpr: `status == "a" && age > 3`, wantSQL: "(age > ? AND status = ?)", wantArgs: []interface{}{int64(3), "a"}},
+		{name: "sorted or", celExpr: `status == "a" || age > 3`, wantSQL: "(age > ? OR status = ?)", wantArgs: []interface{}{int64(3), "a"}},
+		{name: "left-deep chain", celExpr: `name == "x" && (status == "a" && age > 3)`, wantSQL: "((age > ? AND name = ?) AND status = ?)", wantArgs: []interface{}{int64(3), "x", "a"}},
+		{name: "duplicate operand", celExpr: `age > 3 && age > 3`, wantSQL: "age > ?", wantArgs: []interface{}{int64(3)}},
+		{name: "literal on the left", celExpr: `18 <= age`, wantSQL: "age >= ?", wantArgs: []interface{}{int64(18)}},
+		{name: "literal equality on the left", celExpr: `"a" == status`, wantSQL: "status = ?", wantArgs: []interface{}{"a"}},
+		{name: "equals true", celExpr: `is_draft == true`, wantSQL: "is_draft = ?", wantArgs: []interface{}{true}},
+		{name: "not equals false", celExpr: `is_draft != false`, wantSQL: "is_draft = ?", wantArgs: []interface{}{true}},
+		{name: "equals false", celExpr: `is_draft == false`, wantSQL: "is_draft = ?", wantArgs: []interface{}{false}},
+		{name: "not equals true", celExpr: `is_draft != true`, wantSQL: "is_draft = ?", wantArgs: []interface{}{false}},
+		{name: "false on the left", celExpr: `false == is_draft`, wantSQL: "is_draft = ?", wantArgs: []interface{}{false}},
+		{name: "double negation", celExpr: `!!(age > 3)`, wantSQL: "age > ?", wantArgs: []interface{}{int64(3)}},
+		{name: "negated equals false", celExpr: `!(is_draft == false)`, wantSQL: "is_draft = ?", wantArgs: []interface{}{true}},
+		{name: "in list", celExpr: `status in ["b", "a", "b"]`, wantSQL: "status IN (?,?)", wantArgs: []interface{}{"a", "b"}},
+		{name: "nested in or", celExpr: `!!is_draft || 3 < age`, wantSQL: "(age > ? OR is_draft = ?)", wantArgs: []interface{}{int64(3), true}},
+	}
+
+	converter := newCanonicalConverter(t, Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_CanonicalizeIdenticalSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		exprs  []string
+	}{
+		{
+			name:  "commuted operands",
+			exprs: []string{`status == "a" && age > 3 && name == "x"`, `name == "x" && (age > 3 && status == "a")`, `3 < age && "a" == status && name == "x"`},
+		},
+		{
+			name:  "negations",
+			exprs: []string{`is_draft`, `!!is_draft`, `is_draft == true`, `!(is_draft == false)`, `is_draft && is_draft`},
+		},
+		{
+			name:   "between across the chain",
+			config: Config{UseBetween: true},
+			exprs:  []string{`age >= 18 && status == "a" && age <= 30`, `status == "a" && 30 >= age && 18 <= age`},
+		},
+		{
+			name:   "equality chain",
+			config: Config{CollapseOrToIn: true},
+			exprs:  []string{`status == "a" || age > 3 || status == "b"`, `"b" == status || status == "a" || 3 < age`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := newCanonicalConverter(t, tt.config)
+			var want string
+			for i, celExpr := range tt.exprs {
+				result, err := converter.Convert(celExpr)
+				if err != nil {
+					t.Fatalf("Convert(%s) error = %v", celExpr, err)
+				}
+				sql, _, err := result.Where.ToSql()
+				if err != nil {
+					t.Fatalf("ToSql() error = %v", err)
+				}
+				if i == 0 {
+					want = sql
+				} else if sql != want {
+					t.Errorf("Convert(%s) = %v, want %v as for %s", celExpr, sql, want, tt.exprs[0])
+				}
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_CanonicalizeNullRows(t *testing.T) {
+	configs := map[string]Config{
+		"default":       {},
+		"is true":       {BooleanStyle: BooleanIsTrue},
+		"push down not": {PushDownNot: true},
+		"both":          {BooleanStyle: BooleanIsTrue, PushDownNot: true},
+	}
+	exprs := []string{
+		`is_draft == false`,
+		`is_draft != true`,
+		`is_draft != false`,
+		`!(is_draft == false)`,
+		`!(is_draft != true)`,
+		`!is_draft`,
+	}
+
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			plain, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{"is_draft": {Type: cel.BoolType}},
+				BooleanStyle:      config.BooleanStyle,
+				PushDownNot:       config.PushDownNot,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+			canonical := newCanonicalConverter(t, config)
+
+			// A row where is_draft is NULL is selected by the canonical SQL
+			// only if it is by the SQL of the filter as written
+			for _, celExpr := range exprs {
+				want := matchesNullRow(t, plain, celExpr)
+				if got := matchesNullRow(t, canonical, celExpr); got != want {
+					t.Errorf("%s: canonical SQL selects NULL rows = %v, want %v", celExpr, got, want)
+				}
+			}
+		})
+	}
+}
+
+// matchesNullRow reports whether the SQL of a filter on is_draft selects a
+// row where the column is NULL, with SQL three-valued logic.
+func matchesNullRow(t *testing.T, converter *Converter, celExpr string) bool {
+	t.Helper()
+	result, err := converter.Convert(celExpr)
+	if err != nil {
+		t.Fatalf("Convert(%s) error = %v", celExpr, err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+
+	// eval returns the value of a predicate, known false when NULL
+	var eval func(sql string) (value, known bool)
+	eval = func(sql string) (bool, bool) {
+		switch {
+		case strings.HasPrefix(sql, "NOT (") && strings.HasSuffix(sql, ")"):
+			value, known := eval(sql[len("NOT (") : len(sql)-1])
+			return !value, known
+		case sql == "is_draft = ?", sql == "is_draft <> ?":
+			return false, false
+		case sql == "is_draft IS TRUE":
+			return false, true
+		case sql == "is_draft IS NOT TRUE":
+			return true, true
+		}
+		t.Fatalf("Convert(%s) = %s, not evaluable", celExpr, sql)
+		return false, false
+	}
+	value, known := eval(sql)
+	return value && known
+}
+
+func TestCanonicalize_InputUntouched(t *testing.T) {
+	converter := newCanonicalConverter(t, Config{})
+	ast, issues := converter.Env().Compile(`status == "a" && 3 < age && !!is_draft`)
+	if issues.Err() != nil {
+		t.Fatalf("failed to compile: %v", issues.Err())
+	}
+	before, err := cel.AstToString(ast)
+	if err != nil {
+		t.Fatalf("AstToString() error = %v", err)
+	}
+
+	for range 2 {
+		if _, err := converter.ConvertAst(ast); err != nil {
+			t.Fatalf("ConvertAst() error = %v", err)
+		}
+	}
+
+	after, err := cel.AstToString(ast)
+	if err != nil {
+		t.Fatalf("AstToString() error = %v", err)
+	}
+	if after != before {
+		t.Errorf("AST rewritten to %s, want %s", after, before)
+	}
+}
+
+func TestConverter_Convert_CanonicalizeDisabled(t *testing.T) {
+	converter, err := NewConverter(Config{FieldDeclarations: map[string]ColumnMapping{
+		"age":    {Type: cel.IntType, Column: "age"},
+		"status": {Type: cel.StringType, Column: "status"},
+	}})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`status == "a" && age > 3`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "(status = ? AND age > ?)"; sql != want {
+		t.Errorf("ToSql() = %v, SQL should be unchanged without Canonicalize", sql)
+	}
+}
+
+func TestConverter_Convert_CanonicalizeAllowedOps(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"age": {Type: cel.IntType, AllowedOps: []string{">"}},
+		},
+		Canonicalize: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCode string
+	}{
+		{name: "allowed", celExpr: `age > 3`},
+		{name: "allowed mirrored", celExpr: `3 < age`},
+		{name: "denied", celExpr: `age < 3`, wantCode: "OPERATOR_NOT_ALLOWED"},
+		{name: "denied mirrored", celExpr: `3 > age`, wantCode: "OPERATOR_NOT_ALLOWED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Errorf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if got := errorCode(converter.Validate(tt.celExpr)); got != tt.wantCode {
+				t.Errorf("Validate() error code = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package cel2squirrel
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/google/cel-go/common/types"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Capabilities describes the filter surface of a converter: the fields a
+// client may filter on with their operators, and the limits enforced. It is
+// meant to be served to clients and SDKs discovering filters at runtime.
+type Capabilities struct {
+	Fields      []FieldCapability      `json:"fields"`
+	Collections []CollectionCapability `json:"collections,omitempty"`
+	Aggregates  []FieldCapability      `json:"aggregates,omitempty"`
+	FlagGroups  map[string][]string    `json:"flagGroups,omitempty"`
+	Limits      Limits                 `json:"limits"`
+}
+
+// FieldCapability describes a filterable field.
+type FieldCapability struct {
+	// Name is the CEL field name.
+	Name string `json:"name"`
+	// Type is the CEL type of the field, e.g. "string" or "list(int)".
+	Type string `json:"type"`
+	// Operators lists the operators and functions applicable to the field,
+	// e.g. "==", "in" or "startsWith".
+	Operators []string `json:"operators"`
+	// Access is AccessPublic or AccessRestricted. Only set by
+	// Converter.Schema.
+	Access Access `json:"access,omitempty"`
+	// Roles lists the roles allowed to filter on a restricted field.
+	Roles []string `json:"roles,omitempty"`
+	// Deprecated fields should no longer be filtered on, in favor of
+	// ReplacedBy if set.
+	Deprecated bool   `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+// CollectionCapability describes a collection filtered with exists() and
+// all().
+type CollectionCapability struct {
+	Name   string            `json:"name"`
+	Fields []FieldCapability `json:"fields"`
+	// Access and Roles are those of the collection, as for fields.
+	Access Access   `json:"access,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+}
+
+// Access tells who may filter on a field.
+type Access string
+
+const (
+	// AccessPublic fields may be filtered on by any user.
+	AccessPublic Access = "public"
+	// AccessRestricted fields may only be filtered on by users with one of
+	// the roles listed in Config.FieldACL.
+	AccessRestricted Access = "restricted"
+)
+
+// Limits are the limits enforced on filter expressions. Zero values are
+// unlimited.
+type Limits struct {
+	MaxExpressionLength  int    `json:"maxExpressionLength"`
+	MaxExpressionDepth   int    `json:"maxExpressionDepth"`
+	MaxInClauseSize      int    `json:"maxInClauseSize"`
+	MaxConversionBytes   int    `json:"maxConversionBytes"`
+	MaxLikePatternLength int    `json:"maxLikePatternLength"`
+	MaxLikeWildcards     int    `json:"maxLikeWildcards"`
+	MaxPredicates        int    `json:"maxPredicates"`
+	MaxExpressionCost    uint64 `json:"maxExpressionCost"`
+}
+
+// Capabilities returns the filter surface of the converter. When
+// field-level authorization is configured, only the fields and collections
+// authorized for userRoles are listed.
+func (c *Converter) Capabilities(userRoles ...string) Capabilities {
+	c = c.current()
+	return c.capabilities(func(name string) bool {
+		return !c.authorization() || c.isFieldAuthorized(name, userRoles)
+	})
+}
+
+// Schema describes the complete filter surface of the converter, e.g. for
+// API documentation and filter builders: every field a user may filter on,
+// whatever their roles, annotated with its access, public or restricted to
+// the roles listed. Fields no role may filter on are left out. It encodes
+// to JSON like Capabilities.
+func (c *Converter) Schema() Capabilities {
+	c = c.current()
+	schema := c.capabilities(func(name string) bool {
+		return !c.authorization() || c.publicFields[name] || len(c.fieldACL[name]) > 0
+	})
+	for i := range schema.Fields {
+		schema.Fields[i].Access, schema.Fields[i].Roles = c.fieldAccess(schema.Fields[i].Name)
+	}
+	for i := range schema.Collections {
+		schema.Collections[i].Access, schema.Collections[i].Roles = c.fieldAccess(schema.Collections[i].Name)
+	}
+	for i := range schema.Aggregates {
+		schema.Aggregates[i].Access = AccessPublic
+	}
+	return schema
+}
+
+// authorization reports whether field-level authorization is configured.
+func (c *Converter) authorization() bool {
+	return len(c.publicFields) > 0 || len(c.fieldACL) > 0
+}
+
+// fieldAccess returns the access of a field and the roles allowed to filter
+// on it when restricted.
+func (c *Converter) fieldAccess(name string) (Access, []string) {
+	if !c.authorization() || c.publicFields[name] {
+		return AccessPublic, nil
+	}
+	return AccessRestricted, slices.Sorted(slices.Values(c.fieldACL[name]))
+}
+
+// capabilities describes the filter surface of the fields and collections
+// accepted by authorized.
+func (c *Converter) capabilities(authorized func(string) bool) Capabilities {
+	capabilities := Capabilities{
+		Fields: c.fieldCapabilities(authorized),
+		Limits: Limits{
+			MaxExpressionLength:  c.maxExpressionLength,
+			MaxExpressionDepth:   c.maxExpressionDepth,
+			MaxInClauseSize:      c.maxInClauseSize,
+			MaxConversionBytes:   c.maxConversionBytes,
+			MaxLikePatternLength: c.maxLikeLength,
+			MaxLikeWildcards:     c.maxLikeWildcards,
+			MaxPredicates:        c.maxPredicates,
+			MaxExpressionCost:    c.maxExpressionCost,
+		},
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.collections)) {
+		if authorized(name) {
+			capabilities.Collections = append(capabilities.Collections, CollectionCapability{
+				Name:   name,
+				Fields: c.collections[name].converter.fieldCapabilities(nil),
+			})
+		}
+	}
+	if c.having != nil {
+		capabilities.Aggregates = c.having.fieldCapabilities(nil)
+	}
+	for _, flag := range slices.Sorted(maps.Keys(c.flags)) {
+		if !authorized(flag) {
+			continue
+		}
+		if capabilities.FlagGroups == nil {
+			capabilities.FlagGroups = make(map[string][]string)
+		}
+		group := c.flags[flag]
+		capabilities.FlagGroups[group] = append(capabilities.FlagGroups[group], flag)
+	}
+	return capabilities
+}
+
+// fieldCapabilities describes the declared fields accepted by authorized,
+// or all of them when it is nil.
+func (c *Converter) fieldCapabilities(authorized func(string) bool) []FieldCapability {
+	fields := []FieldCapability{}
+	for _, name := range slices.Sorted(maps.Keys(c.fieldDeclarations)) {
+		mapping := c.fieldDeclarations[name]
+		if mapping.Type == nil || (authorized != nil && !authorized(name)) {
+			continue
+		}
+		fields = append(fields, FieldCapability{
+			Name:       name,
+			Type:       mapping.Type.String(),
+			Operators:  c.fieldOperators(mapping),
+			Deprecated: mapping.Deprecated,
+			ReplacedBy: mapping.ReplacedBy,
+		})
+	}
+	return fields
+}
+
+// fieldOperators returns the operators and functions the converter
+// translates for a field.
+func (c *Converter) fieldOperators(mapping ColumnMapping) []string {
+	comparisons := []string{"==", "!=", "<", "<=", ">", ">="}
+
+	var operators []string
+	switch mapping.Type.Kind() {
+	case types.BoolKind:
+		operators = []string{"==", "!=", "!"}
+	case types.IntKind, types.UintKind, types.DoubleKind:
+		operators = append(comparisons, "in", "+", "-", "*", "/")
+	case types.StringKind:
+		operators = append(comparisons, "in", "contains", "startsWith", "endsWith", "equalsIgnoreCase")
+		if c.stringExtensions {
+			operators = append(operators, slices.Sorted(maps.Keys(stringExtFunctions))...)
+		}
+	case types.TimestampKind:
+		operators = comparisons
+	case types.ListKind:
+		operators = []string{"==", "in", "size", "containsAll", "containsAny"}
+	case types.MapKind:
+		operators = []string{"[?]", ".?", "orValue"}
+	case types.OpaqueKind:
+		if mapping.Type.TypeName() == GeoPointType.TypeName() {
+			operators = []string{"near"}
+		}
+	}
+
+	// Templated functions declared on the field's type
+	for _, name := range slices.Sorted(maps.Keys(c.functions)) {
+		if receiver := c.functions[name].receiver; receiver != nil && receiver.IsExactType(mapping.Type) {
+			operators = append(operators, name)
+		}
+	}
+	if len(mapping.AllowedOps) > 0 {
+		operators = slices.DeleteFunc(operators, func(op string) bool {
+			return !slices.Contains(mapping.AllowedOps, op)
+		})
+	}
+	return operators
+}
+
+// Struct returns the capabilities as a protobuf Struct, e.g. to be served
+// by a gRPC method returning google.protobuf.Struct.
+func (c Capabilities) Struct() (*structpb.Struct, error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err := s.UnmarshalJSON(encoded); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CapabilitiesHandler returns an HTTP handler serving the capabilities of
+// the converter as JSON on GET and HEAD requests. userRoles, when not nil,
+// returns the roles of the requesting user, whose unauthorized fields are
+// left out.
+func CapabilitiesHandler(c *Converter, userRoles func(*http.Request) []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var roles []string
+		if userRoles != nil {
+			roles = userRoles(r)
+		}
+		encoded, err := json.Marshal(c.Capabilities(roles...))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write(encoded)
+	})
+}
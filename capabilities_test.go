@@ -0,0 +1,180 @@
+package cel2squirrel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newTestCapabilitiesConverter(t *testing.T) *Converter {
+	t.Helper()
+
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":       {Type: cel.StringType},
+			"age":        {Type: cel.IntType},
+			"is_draft":   {Type: cel.BoolType},
+			"is_deleted": {Type: cel.BoolType},
+			"tags":       {Type: cel.ListType(cel.StringType)},
+			"salary":     {Type: cel.IntType},
+		},
+		PublicFields:    []string{"name", "age", "is_draft", "is_deleted", "tags"},
+		FieldACL:        map[string][]string{"salary": {"hr"}},
+		FlagGroups:      map[string][]string{"state": {"is_draft", "is_deleted"}},
+		AggregateFields: map[string]Aggregate{"count": {Function: AggregateCount}},
+		Functions: []FunctionTemplate{{
+			Name:         "similarTo",
+			ReceiverType: cel.StringType,
+			ArgTypes:     []*cel.Type{cel.StringType},
+			SQL:          "similarity({col}, {arg0}) > 0.5",
+		}},
+		MaxInClauseSize: 50,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Capabilities(t *testing.T) {
+	converter := newTestCapabilitiesConverter(t)
+
+	capabilities := converter.Capabilities()
+	var names []string
+	for _, field := range capabilities.Fields {
+		names = append(names, field.Name)
+	}
+	if want := []string{"age", "is_deleted", "is_draft", "name", "tags"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Fields = %v, want %v", names, want)
+	}
+
+	name := capabilities.Fields[3]
+	wantOperators := []string{"==", "!=", "<", "<=", ">", ">=", "in", "contains", "startsWith", "endsWith", "equalsIgnoreCase", "similarTo"}
+	if name.Type != "string" || !reflect.DeepEqual(name.Operators, wantOperators) {
+		t.Errorf("name = %+v, want string with operators %v", name, wantOperators)
+	}
+	if want := map[string][]string{"state": {"is_deleted", "is_draft"}}; !reflect.DeepEqual(capabilities.FlagGroups, want) {
+		t.Errorf("FlagGroups = %v, want %v", capabilities.FlagGroups, want)
+	}
+	if len(capabilities.Aggregates) != 1 || capabilities.Aggregates[0].Name != "count" || capabilities.Aggregates[0].Type != "int" {
+		t.Errorf("Aggregates = %+v, want count of type int", capabilities.Aggregates)
+	}
+	if capabilities.Limits.MaxInClauseSize != 50 || capabilities.Limits.MaxExpressionLength != 10000 {
+		t.Errorf("Limits = %+v", capabilities.Limits)
+	}
+
+	if fields := converter.Capabilities("hr").Fields; len(fields) != 6 {
+		t.Errorf("Capabilities(hr) lists %d fields, want 6", len(fields))
+	}
+}
+
+func TestConverter_Schema(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":     {Type: cel.StringType},
+			"salary":   {Type: cel.IntType},
+			"internal": {Type: cel.StringType},
+		},
+		Collections: map[string]Collection{
+			"reviews": {
+				Table:  "reviews",
+				On:     "reviews.user_id = users.id",
+				Fields: map[string]ColumnMapping{"score": {Type: cel.IntType}},
+			},
+		},
+		PublicFields: []string{"name"},
+		FieldACL:     map[string][]string{"salary": {"payroll", "hr"}, "reviews": {"manager"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	schema := converter.Schema()
+	wantFields := []FieldCapability{
+		{
+			Name:      "name",
+			Type:      "string",
+			Operators: []string{"==", "!=", "<", "<=", ">", ">=", "in", "contains", "startsWith", "endsWith", "equalsIgnoreCase"},
+			Access:    AccessPublic,
+		},
+		{
+			Name:      "salary",
+			Type:      "int",
+			Operators: []string{"==", "!=", "<", "<=", ">", ">=", "in", "+", "-", "*", "/"},
+			Access:    AccessRestricted,
+			Roles:     []string{"hr", "payroll"},
+		},
+	}
+	if !reflect.DeepEqual(schema.Fields, wantFields) {
+		t.Errorf("Fields = %+v, want %+v", schema.Fields, wantFields)
+	}
+	if len(schema.Collections) != 1 || schema.Collections[0].Access != AccessRestricted ||
+		!reflect.DeepEqual(schema.Collections[0].Roles, []string{"manager"}) {
+		t.Errorf("Collections = %+v, want reviews restricted to manager", schema.Collections)
+	}
+
+	encoded, err := json.Marshal(schema.Fields[1])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"name":"salary","type":"int","operators":["==","!=","\u003c","\u003c=","\u003e","\u003e=","in","+","-","*","/"],"access":"restricted","roles":["hr","payroll"]}`
+	if string(encoded) != want {
+		t.Errorf("json.Marshal() = %s, want %s", encoded, want)
+	}
+
+	// Capabilities remain a per-user view without access annotations
+	if fields := converter.Capabilities().Fields; len(fields) != 1 || fields[0].Access != "" {
+		t.Errorf("Capabilities().Fields = %+v, want name only", fields)
+	}
+
+	// Without authorization, every field is public
+	open := newTestCollectionConverter(t, Config{})
+	for _, field := range open.Schema().Fields {
+		if field.Access != AccessPublic || field.Roles != nil {
+			t.Errorf("field %s access = %q %v, want public", field.Name, field.Access, field.Roles)
+		}
+	}
+}
+
+func TestCapabilities_Struct(t *testing.T) {
+	s, err := newTestCapabilitiesConverter(t).Capabilities().Struct()
+	if err != nil {
+		t.Fatalf("Struct() error = %v", err)
+	}
+	limits := s.Fields["limits"].GetStructValue()
+	if got := limits.GetFields()["maxInClauseSize"].GetNumberValue(); got != 50 {
+		t.Errorf("limits.maxInClauseSize = %v, want 50", got)
+	}
+}
+
+func TestCapabilitiesHandler(t *testing.T) {
+	handler := CapabilitiesHandler(newTestCapabilitiesConverter(t), func(r *http.Request) []string {
+		return r.Header.Values("X-Roles")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/filters/capabilities", nil)
+	request.Header.Set("X-Roles", "hr")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("GET = %d %s, want 200 application/json", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	var capabilities Capabilities
+	if err := json.Unmarshal(recorder.Body.Bytes(), &capabilities); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(capabilities.Fields) != 6 {
+		t.Errorf("served %d fields, want 6", len(capabilities.Fields))
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/filters/capabilities", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", recorder.Code)
+	}
+}
@@ -0,0 +1,197 @@
+// Package cel2squirreltest provides utilities for testing databases and
+// dialects against the semantics of the cel2squirrel package.
+package cel2squirreltest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	"zntr.io/cel2squirrel"
+)
+
+// Table is the name of the table queried by the conformance suite.
+const Table = "conformance"
+
+// Backend is a database targeted by a dialect, under conformance test.
+//
+// The backend owns a table named Table with the following columns:
+//
+//	id         integer, primary key
+//	name       string
+//	age        integer
+//	score      floating point
+//	active     boolean
+//	created_at timestamp
+//	deleted_at timestamp, nullable
+type Backend interface {
+	// Dialect returns the dialect the SQL is generated for.
+	Dialect() cel2squirrel.Dialect
+	// Load replaces the content of the table with the given rows.
+	Load(rows []Row) error
+	// Query executes a SELECT statement returning the id column, with
+	// placeholders in the dialect's format, and returns the selected ids.
+	Query(query string, args []any) ([]int64, error)
+}
+
+// Row is a row of the conformance table.
+type Row struct {
+	ID        int64
+	Name      string
+	Age       int64
+	Score     float64
+	Active    bool
+	CreatedAt time.Time
+	// DeletedAt is nil for NULL.
+	DeletedAt *time.Time
+}
+
+// Case is a conformance test case: a CEL filter and the ids of the rows it
+// selects according to CEL semantics.
+type Case struct {
+	Name   string
+	Filter string
+	Want   []int64
+}
+
+// fields declares the CEL view of the conformance table.
+var fields = map[string]cel2squirrel.ColumnMapping{
+	"id":        {Type: cel.IntType},
+	"name":      {Type: cel.StringType},
+	"age":       {Type: cel.IntType},
+	"score":     {Type: cel.DoubleType},
+	"active":    {Type: cel.BoolType},
+	"createdAt": {Type: cel.TimestampType, Column: "created_at"},
+	"deletedAt": {Type: cel.TimestampType, Column: "deleted_at"},
+}
+
+// variants are the converter configurations each case is checked under.
+var variants = []struct {
+	name   string
+	config cel2squirrel.Config
+}{
+	{name: "default"},
+	{name: "rewrites", config: cel2squirrel.Config{
+		CompatLevel:    cel2squirrel.CompatLatest,
+		UseBetween:     true,
+		CollapseOrToIn: true,
+	}},
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// rows are the fixtures of the conformance table.
+var rows = []Row{
+	{ID: 1, Name: "alice", Age: 30, Score: 4.5, Active: true, CreatedAt: date(2024, time.January, 10)},
+	{ID: 2, Name: "Bob", Age: 17, Score: 3.0, CreatedAt: date(2024, time.March, 5), DeletedAt: ptr(date(2024, time.June, 1))},
+	{ID: 3, Name: "carol_x", Age: 45, Score: 4.9, Active: true, CreatedAt: date(2023, time.December, 31)},
+	{ID: 4, Name: "dave%", Age: 17, Score: 2.5, Active: true, CreatedAt: date(2024, time.February, 29)},
+	{ID: 5, Name: "Eve", Age: 62, Score: 0.0, CreatedAt: date(2024, time.May, 20), DeletedAt: ptr(date(2024, time.May, 21))},
+}
+
+// cases cover every operator and function supported by the converter.
+var cases = []Case{
+	{Name: "equal", Filter: `age == 17`, Want: []int64{2, 4}},
+	{Name: "not equal", Filter: `age != 17`, Want: []int64{1, 3, 5}},
+	{Name: "less", Filter: `age < 30`, Want: []int64{2, 4}},
+	{Name: "less or equal", Filter: `age <= 30`, Want: []int64{1, 2, 4}},
+	{Name: "greater", Filter: `score > 4.5`, Want: []int64{3}},
+	{Name: "greater or equal", Filter: `score >= 4.5`, Want: []int64{1, 3}},
+	{Name: "string equal", Filter: `name == "Bob"`, Want: []int64{2}},
+	{Name: "boolean field", Filter: `active`, Want: []int64{1, 3, 4}},
+	{Name: "negated boolean field", Filter: `!active`, Want: []int64{2, 5}},
+	{Name: "and", Filter: `active && age > 20`, Want: []int64{1, 3}},
+	{Name: "or", Filter: `age < 18 || score > 4.8`, Want: []int64{2, 3, 4}},
+	{Name: "not", Filter: `!(age == 17)`, Want: []int64{1, 3, 5}},
+	{Name: "negated conjunction", Filter: `!(active && age < 40)`, Want: []int64{2, 3, 5}},
+	{Name: "range", Filter: `age >= 17 && age <= 30`, Want: []int64{1, 2, 4}},
+	{Name: "equality chain", Filter: `age == 17 || age == 45`, Want: []int64{2, 3, 4}},
+	{Name: "in", Filter: `age in [17, 62]`, Want: []int64{2, 4, 5}},
+	{Name: "not in", Filter: `!(age in [17, 62])`, Want: []int64{1, 3}},
+	{Name: "contains", Filter: `name.contains("o")`, Want: []int64{2, 3}},
+	{Name: "starts with", Filter: `name.startsWith("ca")`, Want: []int64{3}},
+	{Name: "ends with", Filter: `name.endsWith("e")`, Want: []int64{1, 5}},
+	{Name: "case sensitive match", Filter: `name.startsWith("b")`, Want: []int64{}},
+	{Name: "escaped percent", Filter: `name.contains("%")`, Want: []int64{4}},
+	{Name: "escaped underscore", Filter: `name.contains("_")`, Want: []int64{3}},
+	{Name: "equals ignore case", Filter: `name.equalsIgnoreCase("BOB")`, Want: []int64{2}},
+	{Name: "is null", Filter: `deletedAt == null`, Want: []int64{1, 3, 4}},
+	{Name: "is not null", Filter: `deletedAt != null`, Want: []int64{2, 5}},
+	{Name: "timestamp", Filter: `createdAt >= timestamp("2024-01-01T00:00:00Z")`, Want: []int64{1, 2, 4, 5}},
+	{Name: "arithmetic", Filter: `age + 10 > 50`, Want: []int64{3, 5}},
+	{Name: "double arithmetic", Filter: `score * 2.0 >= 9.0`, Want: []int64{1, 3}},
+	{Name: "constant arithmetic", Filter: `age > 20 + 10`, Want: []int64{3, 5}},
+	{Name: "string conversion", Filter: `string(age) == "17"`, Want: []int64{2, 4}},
+	{Name: "double conversion", Filter: `double(age) > 44.5`, Want: []int64{3, 5}},
+	{Name: "always true", Filter: `true`, Want: []int64{1, 2, 3, 4, 5}},
+	{Name: "always false", Filter: `false`, Want: []int64{}},
+}
+
+// RunDialectConformance checks that the SQL generated for the backend's
+// dialect selects the same rows as CEL would, for every operator and function
+// supported by the converter, under the default and the latest converter
+// configurations.
+func RunDialectConformance(t *testing.T, backend Backend) {
+	t.Helper()
+
+	if err := backend.Load(slices.Clone(rows)); err != nil {
+		t.Fatalf("failed to load conformance rows: %v", err)
+	}
+
+	dialect := backend.Dialect()
+	for _, variant := range variants {
+		t.Run(variant.name, func(t *testing.T) {
+			config := variant.config
+			config.FieldDeclarations = fields
+			config.Dialect = dialect
+
+			converter, err := cel2squirrel.NewConverter(config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			for _, tc := range cases {
+				t.Run(tc.Name, func(t *testing.T) {
+					query, args, err := selectQuery(converter, dialect, tc.Filter)
+					if err != nil {
+						t.Fatalf("%s: %v", tc.Filter, err)
+					}
+
+					got, err := backend.Query(query, args)
+					if err != nil {
+						t.Fatalf("%s: query %q failed: %v", tc.Filter, query, err)
+					}
+					slices.Sort(got)
+					if !slices.Equal(got, tc.Want) {
+						t.Errorf("%s: query %q with args %v selected %v, want %v", tc.Filter, query, args, got, tc.Want)
+					}
+				})
+			}
+		})
+	}
+}
+
+// selectQuery converts a filter and renders the SELECT statement sent to the
+// backend.
+func selectQuery(converter *cel2squirrel.Converter, dialect cel2squirrel.Dialect, filter string) (string, []any, error) {
+	result, err := converter.Convert(filter)
+	if err != nil {
+		return "", nil, fmt.Errorf("conversion failed: %w", err)
+	}
+
+	return squirrel.Select("id").
+		From(Table).
+		Where(result.Where).
+		OrderBy("id").
+		PlaceholderFormat(dialect.PlaceholderFormat()).
+		ToSql()
+}
@@ -0,0 +1,152 @@
+package cel2squirreltest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"zntr.io/cel2squirrel"
+)
+
+// oracleBackend answers the suite's queries with the rows selected by the CEL
+// evaluation of the filters they were generated from.
+type oracleBackend struct {
+	dialect cel2squirrel.Dialect
+	results map[string][]int64
+	loaded  []Row
+}
+
+func newOracleBackend(t *testing.T, dialect cel2squirrel.Dialect) *oracleBackend {
+	t.Helper()
+
+	backend := &oracleBackend{dialect: dialect, results: make(map[string][]int64)}
+	for _, variant := range variants {
+		config := variant.config
+		config.FieldDeclarations = fields
+		config.Dialect = dialect
+
+		converter, err := cel2squirrel.NewConverter(config)
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+
+		for _, tc := range cases {
+			query, args, err := selectQuery(converter, dialect, tc.Filter)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.Filter, err)
+			}
+			backend.results[fmt.Sprint(query, args)] = evalCEL(t, tc.Filter)
+		}
+	}
+	return backend
+}
+
+func (b *oracleBackend) Dialect() cel2squirrel.Dialect {
+	return b.dialect
+}
+
+func (b *oracleBackend) Load(rows []Row) error {
+	b.loaded = rows
+	return nil
+}
+
+func (b *oracleBackend) Query(query string, args []any) ([]int64, error) {
+	ids, ok := b.results[fmt.Sprint(query, args)]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query")
+	}
+	return slices.Clone(ids), nil
+}
+
+// evalCEL returns the ids of the fixture rows matching the filter.
+func evalCEL(t *testing.T, filter string) []int64 {
+	t.Helper()
+
+	options := []cel.EnvOption{
+		// equalsIgnoreCase is a cel2squirrel extension
+		cel.Function("equalsIgnoreCase",
+			cel.MemberOverload("string_equals_ignore_case_string",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return types.Bool(strings.EqualFold(string(lhs.(types.String)), string(rhs.(types.String))))
+				}),
+			),
+		),
+	}
+	for name, mapping := range fields {
+		options = append(options, cel.Variable(name, mapping.Type))
+	}
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		t.Fatalf("failed to create CEL environment: %v", err)
+	}
+
+	ast, issues := env.Compile(filter)
+	if issues != nil && issues.Err() != nil {
+		t.Fatalf("%s: %v", filter, issues.Err())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		t.Fatalf("%s: %v", filter, err)
+	}
+
+	ids := []int64{}
+	for _, row := range rows {
+		vars := map[string]any{
+			"id":        row.ID,
+			"name":      row.Name,
+			"age":       row.Age,
+			"score":     row.Score,
+			"active":    row.Active,
+			"createdAt": row.CreatedAt,
+			"deletedAt": nil,
+		}
+		if row.DeletedAt != nil {
+			vars["deletedAt"] = *row.DeletedAt
+		}
+
+		out, _, err := prg.Eval(vars)
+		if err != nil {
+			t.Fatalf("%s: evaluation failed: %v", filter, err)
+		}
+		if out.Value() == true {
+			ids = append(ids, row.ID)
+		}
+	}
+	return ids
+}
+
+func TestCases(t *testing.T) {
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := evalCEL(t, tc.Filter)
+			if !slices.Equal(got, tc.Want) {
+				t.Errorf("%s selects %v, want %v", tc.Filter, got, tc.Want)
+			}
+		})
+	}
+}
+
+func TestRunDialectConformance(t *testing.T) {
+	dialects := []cel2squirrel.Dialect{
+		cel2squirrel.DialectDefault,
+		cel2squirrel.DialectPostgreSQL,
+		cel2squirrel.DialectMySQL,
+		cel2squirrel.DialectSQLite,
+	}
+
+	for _, dialect := range dialects {
+		t.Run(fmt.Sprintf("dialect=%q", dialect), func(t *testing.T) {
+			backend := newOracleBackend(t, dialect)
+			RunDialectConformance(t, backend)
+
+			if len(backend.loaded) != len(rows) {
+				t.Errorf("loaded %d rows, want %d", len(backend.loaded), len(rows))
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cel2squirrel
+
+import (
+	"github.com/Masterminds/squirrel"
+)
+
+// spliceOperands returns the operands of a binary AND/OR, inlining operands
+// that are themselves connectives of the same kind T. It also returns the
+// index at which the right-hand operands start.
+func spliceOperands[T ~[]squirrel.Sqlizer](left, right squirrel.Sqlizer) ([]squirrel.Sqlizer, int) {
+	operands := inlineOperand[T](nil, left)
+	split := len(operands)
+	return inlineOperand[T](operands, right), split
+}
+
+// inlineOperand appends operand, or its own operands when it is of kind T.
+func inlineOperand[T ~[]squirrel.Sqlizer](operands []squirrel.Sqlizer, operand squirrel.Sqlizer) []squirrel.Sqlizer {
+	if nested, ok := operand.(T); ok {
+		return append(operands, nested...)
+	}
+	return append(operands, operand)
+}
+
+// mergeAcross tries to merge every right-hand operand (from index split) into
+// a left-hand one using merge. Operands of the same side were already merged
+// when that side was converted, so only cross pairs are considered.
+func mergeAcross(operands []squirrel.Sqlizer, split int, merge func(a, b squirrel.Sqlizer) (squirrel.Sqlizer, bool)) []squirrel.Sqlizer {
+	merged := operands[:split:split]
+	for _, right := range operands[split:] {
+		combined := false
+		for i := 0; i < split; i++ {
+			if result, ok := merge(merged[i], right); ok {
+				merged[i] = result
+				combined = true
+				break
+			}
+		}
+		if !combined {
+			merged = append(merged, right)
+		}
+	}
+	return merged
+}
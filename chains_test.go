@@ -0,0 +1,85 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_FlattenLogicalChains(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"a":      {Type: cel.BoolType, Column: "a"},
+		"b":      {Type: cel.BoolType, Column: "b"},
+		"c":      {Type: cel.BoolType, Column: "c"},
+		"d":      {Type: cel.BoolType, Column: "d"},
+		"age":    {Type: cel.IntType, Column: "age"},
+		"status": {Type: cel.StringType, Column: "status"},
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		celExpr string
+		wantSQL string
+	}{
+		{
+			name:    "AND chain",
+			celExpr: `a && b && c`,
+			wantSQL: "(a = ? AND b = ? AND c = ?)",
+		},
+		{
+			name:    "OR chain",
+			celExpr: `a || b || c || d`,
+			wantSQL: "(a = ? OR b = ? OR c = ? OR d = ?)",
+		},
+		{
+			name:    "mixed connectives keep grouping",
+			celExpr: `(a && b) || (c && d)`,
+			wantSQL: "((a = ? AND b = ?) OR (c = ? AND d = ?))",
+		},
+		{
+			name:    "nested chains",
+			celExpr: `a && (b || c || d) && age > 1`,
+			wantSQL: "(a = ? AND (b = ? OR c = ? OR d = ?) AND age > ?)",
+		},
+		{
+			name:    "BETWEEN across chain",
+			config:  Config{UseBetween: true},
+			celExpr: `age >= 18 && a && age <= 30`,
+			wantSQL: "(age BETWEEN ? AND ? AND a = ?)",
+		},
+		{
+			name:    "IN across chain",
+			config:  Config{CollapseOrToIn: true},
+			celExpr: `status == "x" || a || status == "y" || status == "z"`,
+			wantSQL: "(status IN (?,?,?) OR a = ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.FieldDeclarations = fields
+			config.FlattenLogicalChains = true
+
+			converter, err := NewConverter(config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+		})
+	}
+}
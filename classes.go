@@ -0,0 +1,100 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// ExpressionClass classifies a filter by the kind of query it produces, from
+// the cheapest to the most expensive, so that operators can enforce quotas
+// per class, e.g. allow unlimited point lookups but throttle text searches.
+type ExpressionClass string
+
+const (
+	// ClassPointLookup filters require the equality of a key field, or
+	// never match.
+	ClassPointLookup ExpressionClass = "point_lookup"
+	// ClassRangeScan filters require an equality, range or prefix match
+	// that an index can serve.
+	ClassRangeScan ExpressionClass = "range_scan"
+	// ClassTextSearch filters match text patterns, e.g. with contains(),
+	// which indexes generally cannot serve.
+	ClassTextSearch ExpressionClass = "text_search"
+	// ClassFullScan filters have no predicate an index can serve, e.g.
+	// disjunctions, inequalities or computed columns, and risk scanning the
+	// whole table.
+	ClassFullScan ExpressionClass = "full_scan"
+)
+
+// QuotaFunc decides whether a filter of the given class may be run, e.g. by
+// consulting a rate limiter keyed by class and caller. Returning an error
+// rejects the filter with QUOTA_EXCEEDED.
+type QuotaFunc func(ctx context.Context, class ExpressionClass) error
+
+// textFunctions are the CEL functions matching text patterns.
+var textFunctions = []string{"contains", "endsWith", "equalsIgnoreCase"}
+
+// classify returns the class of a converted expression.
+func (c *Converter) classify(expr celast.Expr, alwaysFalse bool) ExpressionClass {
+	if alwaysFalse {
+		return ClassPointLookup
+	}
+
+	usages := c.indexableUsages(expr)
+	for _, usage := range usages {
+		if usage.Operator == OperatorEquality && c.keyColumns[usage.Column] {
+			return ClassPointLookup
+		}
+	}
+
+	text := false
+	walkExpr(expr, func(e celast.Expr) {
+		call := e.AsCall()
+		if !call.IsMemberFunction() {
+			return
+		}
+		if tmpl, ok := c.functions[call.FunctionName()]; ok {
+			text = text || tmpl.matchesLike()
+		}
+		text = text || slices.Contains(textFunctions, call.FunctionName())
+	})
+	if text {
+		return ClassTextSearch
+	}
+
+	for _, usage := range usages {
+		if usage.Operator != OperatorPattern {
+			return ClassRangeScan
+		}
+	}
+	return ClassFullScan
+}
+
+// finalize ANDs the mandatory conditions, static and derived from ctx, onto
+// a successful conversion of celExpr, reports the partial indexes it does not
+// cover, submits it to the configured quota and records it in the corpus.
+func (c *Converter) finalize(ctx context.Context, celExpr string, result *ConvertResult, err error) (*ConvertResult, error) {
+	if err != nil {
+		return result, err
+	}
+	if err := c.applyMandatoryConditions(ctx, result); err != nil {
+		return nil, err
+	}
+	c.warnUncoveredIndexes(result)
+	if c.quota != nil {
+		if err := c.quota(ctx, result.Class); err != nil {
+			return nil, newConversionError(
+				"filter quota exceeded",
+				CodeQuotaExceeded,
+				fmt.Errorf("quota for %s filters: %w", result.Class, err),
+			)
+		}
+	}
+	if c.corpus != nil {
+		c.record(celExpr)
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_Class(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"id":      {Type: cel.StringType},
+			"status":  {Type: cel.StringType},
+			"name":    {Type: cel.StringType},
+			"age":     {Type: cel.IntType},
+			"created": {Type: cel.TimestampType, Column: "created_at"},
+		},
+		KeyFields: []string{"id"},
+		Functions: []FunctionTemplate{{
+			Name:         "like",
+			ReceiverType: cel.StringType,
+			ArgTypes:     []*cel.Type{cel.StringType},
+			SQL:          "{col} LIKE {arg0}",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		celExpr string
+		want    ExpressionClass
+	}{
+		{celExpr: `id == "p1"`, want: ClassPointLookup},
+		{celExpr: `id in ["p1", "p2"] && name.contains("x")`, want: ClassPointLookup},
+		{celExpr: `false && age > 1`, want: ClassPointLookup},
+		{celExpr: `status == "active"`, want: ClassRangeScan},
+		{celExpr: `age > 18 && status != "x"`, want: ClassRangeScan},
+		{celExpr: `name.startsWith("a")`, want: ClassRangeScan},
+		{celExpr: `status == "active" && name.contains("x")`, want: ClassTextSearch},
+		{celExpr: `name.endsWith("x") || age > 3`, want: ClassTextSearch},
+		{celExpr: `name.like("a%b")`, want: ClassTextSearch},
+		{celExpr: `id == "p1" || status == "a"`, want: ClassFullScan},
+		{celExpr: `status != "archived"`, want: ClassFullScan},
+		{celExpr: `age + 1 > 18`, want: ClassFullScan},
+		{celExpr: `true`, want: ClassFullScan},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.celExpr, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Class != tt.want {
+				t.Errorf("Class = %v, want %v", result.Class, tt.want)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_Quota(t *testing.T) {
+	var classes []ExpressionClass
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tenant": {Type: cel.StringType},
+			"name":   {Type: cel.StringType},
+		},
+		PublicFields: []string{"name"},
+		Quota: func(_ context.Context, class ExpressionClass) error {
+			classes = append(classes, class)
+			if class == ClassTextSearch {
+				return errors.New("text search budget exhausted")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	if _, err := converter.Convert(`name == "x"`); err != nil {
+		t.Errorf("Convert() error = %v", err)
+	}
+
+	_, err = converter.Convert(`name.contains("x")`)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.ErrorCode != "QUOTA_EXCEEDED" {
+		t.Errorf("Convert() error = %v, want QUOTA_EXCEEDED", err)
+	}
+
+	// Scope predicates are classified with the filter, and not on their own
+	scopes := ScopeStack{{Name: "tenant", Predicate: `tenant == "t1"`}}
+	if _, err := converter.ConvertWithScopes(context.Background(), `name.contains("x")`, nil, scopes); err == nil {
+		t.Error("ConvertWithScopes() should exceed the quota")
+	}
+	if _, err := converter.ConvertHybrid(`name.contains("x")`); err == nil {
+		t.Error("ConvertHybrid() should exceed the quota")
+	}
+
+	want := []ExpressionClass{ClassRangeScan, ClassTextSearch, ClassTextSearch, ClassTextSearch}
+	if len(classes) != len(want) {
+		t.Fatalf("quota consulted for %v, want %v", classes, want)
+	}
+	for i := range want {
+		if classes[i] != want[i] {
+			t.Errorf("quota consulted for %v, want %v", classes, want)
+		}
+	}
+}
+
+func TestNewConverter_UndeclaredKeyField(t *testing.T) {
+	if _, err := NewConverter(Config{KeyFields: []string{"id"}}); err == nil {
+		t.Error("NewConverter() should reject an undeclared key field")
+	}
+}
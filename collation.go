@@ -0,0 +1,38 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// collationName matches the collation names accepted in ColumnMapping:
+// letters, digits, underscores, and for quoted PostgreSQL collations such as
+// "und-x-icu", dashes and dots.
+var (
+	collationName     = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+	bareCollationName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// collate applies a collation to the column of a string field:
+// col COLLATE "und-x-icu" on PostgreSQL and in ANSI SQL, where collation
+// names are identifiers, and col COLLATE utf8mb4_0900_ai_ci on MySQL, SQLite
+// and SQL Server, whose collation names are bare words.
+func (d Dialect) collate(column, collation string) (string, error) {
+	if !collationName.MatchString(collation) {
+		return "", fmt.Errorf("invalid collation %q", collation)
+	}
+
+	switch d {
+	case DialectMySQL, DialectSQLite, DialectSQLServer:
+		if !bareCollationName.MatchString(collation) {
+			return "", fmt.Errorf("invalid collation %q for dialect %q", collation, d)
+		}
+		return column + " COLLATE " + collation, nil
+	default:
+		quoted, err := d.QuoteIdentifier(collation)
+		if err != nil {
+			return "", err
+		}
+		return column + " COLLATE " + quoted, nil
+	}
+}
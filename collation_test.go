@@ -0,0 +1,98 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_Collation(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		collation string
+		celExpr   string
+		wantSQL   string
+		wantArgs  []any
+	}{
+		{
+			name:      "postgres ICU",
+			dialect:   DialectPostgreSQL,
+			collation: "und-x-icu",
+			celExpr:   `name == "Ångström"`,
+			wantSQL:   `users.name COLLATE "und-x-icu" = ?`,
+			wantArgs:  []any{"Ångström"},
+		},
+		{
+			name:      "mysql accent insensitive",
+			dialect:   DialectMySQL,
+			collation: "utf8mb4_0900_ai_ci",
+			celExpr:   `name in ["a", "b"] || name < "m"`,
+			wantSQL:   "(users.name COLLATE utf8mb4_0900_ai_ci IN (?,?) OR users.name COLLATE utf8mb4_0900_ai_ci < ?)",
+			wantArgs:  []any{"a", "b", "m"},
+		},
+		{
+			name:      "sqlite",
+			dialect:   DialectSQLite,
+			collation: "NOCASE",
+			celExpr:   `name.startsWith("jo")`,
+			wantSQL:   `users.name COLLATE NOCASE LIKE ? ESCAPE '\'`,
+			wantArgs:  []any{"jo%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{
+					"name": {Type: cel.StringType, Collation: tt.collation},
+				},
+				TableAlias: "users",
+				Dialect:    tt.dialect,
+				AuditSQL:   true,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNewConverter_InvalidCollation(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		mapping ColumnMapping
+	}{
+		{name: "non-string field", mapping: ColumnMapping{Type: cel.IntType, Collation: "C"}},
+		{name: "quote", mapping: ColumnMapping{Type: cel.StringType, Collation: `C" = 1 --`}},
+		{name: "space", mapping: ColumnMapping{Type: cel.StringType, Collation: "C x"}},
+		{name: "dash on mysql", dialect: DialectMySQL, mapping: ColumnMapping{Type: cel.StringType, Collation: "und-x-icu"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{"name": tt.mapping},
+				Dialect:           tt.dialect,
+			})
+			if err == nil {
+				t.Error("NewConverter() should reject the collation")
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// Collection declares a one-to-many relation, e.g. the comments of a
+// prompt, filtered with exists() and all() macros translated to EXISTS
+// subqueries: `comments.exists(c, c.flagged)` converts to
+// EXISTS (SELECT 1 FROM comments WHERE comments.prompt_id = prompts.id AND flagged = ?).
+type Collection struct {
+	// Table is the child table, optionally schema-qualified.
+	Table string
+	// Alias is the name the child table is referred to by in the subquery.
+	// When set, child columns are qualified with it. Optional.
+	Alias string
+	// On correlates child rows with the filtered row, e.g.
+	// "comments.prompt_id = prompts.id". It is trusted configuration subject
+	// to the same restrictions as ColumnMapping.Expr.
+	On string
+	// Fields declares the fields of a child row, read through the iteration
+	// variable of the macro, e.g. c.flagged.
+	Fields map[string]ColumnMapping
+}
+
+// collection is a configured Collection.
+type collection struct {
+	join JoinSpec
+	// converter converts the predicates over child rows.
+	converter *Converter
+}
+
+// subquery returns the trusted SQL of the subqueries over the collection's
+// rows, for auditing.
+func (c *collection) subquery() string {
+	columns := slices.Sorted(maps.Values(c.converter.columnMappings))
+	return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s AND %s)", c.join.from(), c.join.On, strings.Join(columns, " "))
+}
+
+// collectionTypeName returns the CEL type name of the rows of a collection.
+func collectionTypeName(name string) string {
+	return "cel2squirrel.collections." + name
+}
+
+// newCollections validates the collections and creates the converters of
+// their predicates, which share the parent's options.
+func newCollections(config Config) (map[string]*collection, error) {
+	collections := make(map[string]*collection, len(config.Collections))
+	for name, declared := range config.Collections {
+		if _, clash := config.FieldDeclarations[name]; clash {
+			return nil, fmt.Errorf("collection %s conflicts with a field of the same name", name)
+		}
+		join := JoinSpec{Table: declared.Table, Alias: declared.Alias, On: strings.TrimSpace(declared.On)}
+		if err := validateJoin(name, join); err != nil {
+			return nil, err
+		}
+		for field, mapping := range declared.Fields {
+			if mapping.Type == nil || mapping.Join != nil {
+				return nil, fmt.Errorf("collection %s: field %s requires a type and cannot declare a join", name, field)
+			}
+		}
+
+		childConfig := config
+		childConfig.FieldDeclarations = declared.Fields
+		childConfig.TableAlias = declared.Alias
+		childConfig.Collections = nil
+		childConfig.FlagGroups = nil
+		childConfig.Fallbacks = nil
+		childConfig.PublicFields = nil
+		childConfig.FieldACL = nil
+		childConfig.AuditSQL = false
+		childConfig.Stats = nil
+		childConfig.KeyFields = nil
+		childConfig.Quota = nil
+		childConfig.Corpus = nil
+		childConfig.CacheSize = 0
+		childConfig.SoftDeleteField = ""
+		childConfig.MandatoryConditions = nil
+		childConfig.MandatoryConditionsFunc = nil
+		converter, err := NewConverter(childConfig)
+		if err != nil {
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+		collections[name] = &collection{join: join, converter: converter}
+	}
+	return collections, nil
+}
+
+// collectionDeclarations declares each collection as a list of rows whose
+// fields are known to the type checker.
+func collectionDeclarations(collections map[string]Collection) ([]cel.EnvOption, error) {
+	if len(collections) == 0 {
+		return nil, nil
+	}
+
+	registry, err := types.NewRegistry()
+	if err != nil {
+		return nil, err
+	}
+	provider := &collectionTypes{Registry: registry, fields: make(map[string]map[string]*cel.Type)}
+	opts := []cel.EnvOption{cel.CustomTypeProvider(provider), cel.CustomTypeAdapter(provider.Registry)}
+	for name, declared := range collections {
+		typeName := collectionTypeName(name)
+		fields := make(map[string]*cel.Type, len(declared.Fields))
+		for field, mapping := range declared.Fields {
+			fields[field] = mapping.Type
+		}
+		provider.fields[typeName] = fields
+		opts = append(opts, cel.Variable(name, cel.ListType(cel.ObjectType(typeName))))
+	}
+	return opts, nil
+}
+
+// collectionTypes extends a type registry with the row types of the
+// collections. Rows are type-checked as objects, but may be provided as
+// maps when evaluating residual filters.
+type collectionTypes struct {
+	*types.Registry
+	fields map[string]map[string]*cel.Type
+}
+
+// FindStructType implements types.Provider.
+func (p *collectionTypes) FindStructType(structType string) (*types.Type, bool) {
+	if _, ok := p.fields[structType]; ok {
+		return types.NewTypeTypeWithParam(types.NewObjectType(structType)), true
+	}
+	return p.Registry.FindStructType(structType)
+}
+
+// FindStructFieldNames implements types.Provider.
+func (p *collectionTypes) FindStructFieldNames(structType string) ([]string, bool) {
+	if fields, ok := p.fields[structType]; ok {
+		return slices.Sorted(maps.Keys(fields)), true
+	}
+	return p.Registry.FindStructFieldNames(structType)
+}
+
+// FindStructFieldType implements types.Provider.
+func (p *collectionTypes) FindStructFieldType(structType, fieldName string) (*types.FieldType, bool) {
+	if fields, ok := p.fields[structType]; ok {
+		fieldType, ok := fields[fieldName]
+		if !ok {
+			return nil, false
+		}
+		return &types.FieldType{Type: fieldType}, true
+	}
+	return p.Registry.FindStructFieldType(structType, fieldName)
+}
+
+// existsExpr is an EXISTS subquery over the rows of a collection. all
+// renders NOT EXISTS over the rows not matching the predicate.
+type existsExpr struct {
+	join JoinSpec
+	pred squirrel.Sqlizer
+	all  bool
+}
+
+// ToSql implements squirrel.Sqlizer.
+func (e *existsExpr) ToSql() (string, []interface{}, error) {
+	sql, args, err := e.pred.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	if e.all {
+		return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s AND NOT (%s))", e.join.from(), e.join.On, sql), args, nil
+	}
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s AND %s)", e.join.from(), e.join.On, sql), args, nil
+}
+
+// convertComprehension converts the exists() and all() macros over a
+// collection to EXISTS subqueries.
+func (c *Converter) convertComprehension(comp celast.ComprehensionExpr) (squirrel.Sqlizer, error) {
+	name := comp.IterRange().AsIdent()
+	coll, ok := c.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("comprehensions are only supported over collections")
+	}
+
+	pred, all, ok := quantifierPredicate(comp)
+	if !ok {
+		return nil, fmt.Errorf("collection %s can only be filtered with exists() and all()", name)
+	}
+	pred, err := rowPredicate(pred, comp.IterVar(), coll.converter.fieldDeclarations)
+	if err != nil {
+		return nil, fmt.Errorf("collection %s: %w", name, err)
+	}
+
+	child := coll.converter.scoped(c.context())
+	sqlizer, err := child.convertExpr(pred)
+	if err != nil {
+		return nil, err
+	}
+
+	// Account for the child conversion in the parent call
+	complexity := child.conv.complexity
+	if err := c.charge(complexity.Nodes, complexity.Values, complexity.ApproxBytes); err != nil {
+		return nil, err
+	}
+	if c.conv != nil {
+		c.conv.warnings = append(c.conv.warnings, child.conv.warnings...)
+		c.conv.trusted = append(c.conv.trusted, child.conv.trusted...)
+		c.conv.contextual = c.conv.contextual || child.conv.contextual
+		c.conv.complexity.LikePatterns += complexity.LikePatterns
+		c.conv.complexity.MaxLikePatternLength = max(c.conv.complexity.MaxLikePatternLength, complexity.MaxLikePatternLength)
+		c.conv.complexity.MaxLikeWildcards = max(c.conv.complexity.MaxLikeWildcards, complexity.MaxLikeWildcards)
+	}
+
+	return &existsExpr{join: coll.join, pred: sqlizer, all: all}, nil
+}
+
+// quantifierPredicate returns the predicate of an exists() or all() macro
+// expansion, and whether it is all().
+func quantifierPredicate(comp celast.ComprehensionExpr) (celast.Expr, bool, bool) {
+	init, ok := boolConstant(comp.AccuInit())
+	if !ok || comp.Result().AsIdent() != comp.AccuVar() {
+		return nil, false, false
+	}
+
+	step := comp.LoopStep().AsCall()
+	if len(step.Args()) != 2 || step.Args()[0].AsIdent() != comp.AccuVar() {
+		return nil, false, false
+	}
+	switch {
+	case !init && step.FunctionName() == "_||_":
+		return step.Args()[1], false, true
+	case init && step.FunctionName() == "_&&_":
+		return step.Args()[1], true, true
+	}
+	return nil, false, false
+}
+
+// rowPredicate rewrites the fields of the iteration variable read by a
+// predicate, e.g. c.flagged, into identifiers of the child converter. The
+// predicate may not read anything else.
+func rowPredicate(pred celast.Expr, iterVar string, fields map[string]ColumnMapping) (celast.Expr, error) {
+	pred = exprFactory.CopyExpr(pred)
+
+	var err error
+	var rewrite func(celast.Expr)
+	rewrite = func(e celast.Expr) {
+		if err != nil {
+			return
+		}
+		switch e.Kind() {
+		case celast.SelectKind:
+			sel := e.AsSelect()
+			if _, ok := fields[sel.FieldName()]; ok && sel.Operand().AsIdent() == iterVar && !sel.IsTestOnly() {
+				e.SetKindCase(exprFactory.NewIdent(e.ID(), sel.FieldName()))
+				return
+			}
+			rewrite(sel.Operand())
+		case celast.IdentKind:
+			// Fields of the row are selected from the iteration variable
+			err = fmt.Errorf("predicates may only read fields of the collection, got %s", e.AsIdent())
+		case celast.CallKind:
+			call := e.AsCall()
+			if call.IsMemberFunction() {
+				rewrite(call.Target())
+			}
+			for _, arg := range call.Args() {
+				rewrite(arg)
+			}
+		case celast.ListKind:
+			for _, elem := range e.AsList().Elements() {
+				rewrite(elem)
+			}
+		case celast.ComprehensionKind:
+			err = fmt.Errorf("nested comprehensions are not supported")
+		}
+	}
+	rewrite(pred)
+	if err != nil {
+		return nil, err
+	}
+	return pred, nil
+}
@@ -0,0 +1,194 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newTestCollectionConverter(t *testing.T, config Config) *Converter {
+	t.Helper()
+
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"title":  {Type: cel.StringType},
+		"status": {Type: cel.StringType},
+	}
+	config.Collections = map[string]Collection{
+		"comments": {
+			Table: "comments",
+			On:    "comments.prompt_id = prompts.id",
+			Fields: map[string]ColumnMapping{
+				"flagged": {Type: cel.BoolType},
+				"body":    {Type: cel.StringType, Column: "content"},
+				"status":  {Type: cel.StringType},
+			},
+		},
+		"tags": {
+			Table: "prompt_tags",
+			Alias: "t",
+			On:    "t.prompt_id = prompts.id",
+			Fields: map[string]ColumnMapping{
+				"name": {Type: cel.StringType},
+			},
+		},
+	}
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_Collections(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{AuditSQL: true})
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "exists",
+			celExpr:  `comments.exists(c, c.flagged)`,
+			wantSQL:  "EXISTS (SELECT 1 FROM comments WHERE comments.prompt_id = prompts.id AND flagged = ?)",
+			wantArgs: []any{true},
+		},
+		{
+			name:     "exists with parent predicate",
+			celExpr:  `status == "open" && comments.exists(c, c.body.contains("spam") && c.status != "deleted")`,
+			wantSQL:  "(status = ? AND EXISTS (SELECT 1 FROM comments WHERE comments.prompt_id = prompts.id AND (content LIKE ? AND status <> ?)))",
+			wantArgs: []any{"open", "%spam%", "deleted"},
+		},
+		{
+			name:     "all",
+			celExpr:  `tags.all(t, t.name.startsWith("public-"))`,
+			wantSQL:  "NOT EXISTS (SELECT 1 FROM prompt_tags t WHERE t.prompt_id = prompts.id AND NOT (t.name LIKE ?))",
+			wantArgs: []any{"public-%"},
+		},
+		{
+			name:     "negated",
+			celExpr:  `!tags.exists(t, t.name == "draft")`,
+			wantSQL:  "NOT (EXISTS (SELECT 1 FROM prompt_tags t WHERE t.prompt_id = prompts.id AND t.name = ?))",
+			wantArgs: []any{"draft"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_CollectionErrors(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{})
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCode string
+	}{
+		{
+			name:     "unknown field",
+			celExpr:  `comments.exists(c, c.author == "bob")`,
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "parent field in predicate",
+			celExpr:  `comments.exists(c, c.status == status)`,
+			wantCode: "UNSUPPORTED_OPERATION",
+		},
+		{
+			name:     "exists_one",
+			celExpr:  `comments.exists_one(c, c.flagged)`,
+			wantCode: "UNSUPPORTED_OPERATION",
+		},
+		{
+			name:     "size",
+			celExpr:  `size(comments) > 2`,
+			wantCode: "UNSUPPORTED_OPERATION",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if err == nil {
+				t.Fatal("Convert() expected error")
+			}
+			if code := string(err.(*ConversionError).ErrorCode); code != tt.wantCode {
+				t.Errorf("ErrorCode = %s, want %s (%v)", code, tt.wantCode, err.(*ConversionError).InternalError)
+			}
+		})
+	}
+}
+
+func TestConverter_ConvertWithAuth_Collections(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{PublicFields: []string{"comments"}})
+
+	if _, err := converter.ConvertWithAuth(`comments.exists(c, c.flagged)`, nil); err != nil {
+		t.Errorf("ConvertWithAuth() error = %v", err)
+	}
+	if _, err := converter.ConvertWithAuth(`tags.exists(t, t.name == "a")`, nil); err == nil {
+		t.Error("ConvertWithAuth() expected error for a restricted collection")
+	}
+}
+
+func TestConverter_ConvertHybrid_Collections(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{})
+
+	result, err := converter.ConvertHybrid(`comments.exists(c, c.flagged) && title.matches("^a")`)
+	if err != nil {
+		t.Fatalf("ConvertHybrid() error = %v", err)
+	}
+	matched, err := result.Residual.Eval(map[string]any{
+		"title":    "abc",
+		"comments": []map[string]any{{"flagged": true}},
+	})
+	if err != nil || !matched {
+		t.Errorf("Residual.Eval() = %v, %v, want true", matched, err)
+	}
+}
+
+func TestConverter_ConvertWithAuth_CollectionVariableShadowing(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{
+		PublicFields: []string{"title", "comments"},
+		FieldACL:     map[string][]string{"status": {"admin"}},
+	})
+
+	// The macro variable is only in scope within the macro: status is still
+	// the restricted field outside of it.
+	celExpr := `comments.exists(status, status.flagged) && status == "open"`
+	if _, err := converter.ConvertWithAuth(celExpr, []string{"user"}); !errors.Is(err, ErrUnauthorizedField) {
+		t.Errorf("ConvertWithAuth() error = %v, want %v", err, ErrUnauthorizedField)
+	}
+	if _, err := converter.ConvertWithAuth(celExpr, []string{"admin"}); err != nil {
+		t.Errorf("ConvertWithAuth() error = %v", err)
+	}
+	if _, err := converter.ConvertWithAuth(`comments.exists(status, status.flagged)`, []string{"user"}); err != nil {
+		t.Errorf("ConvertWithAuth() error = %v", err)
+	}
+
+	scopes := ScopeStack{}.Push(Scope{Name: "tenant", HiddenFields: []string{"status"}})
+	if _, err := converter.ConvertWithScopes(context.Background(), celExpr, []string{"admin"}, scopes); !errors.Is(err, ErrUnauthorizedField) {
+		t.Errorf("ConvertWithScopes() error = %v, want %v", err, ErrUnauthorizedField)
+	}
+}
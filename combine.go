@@ -0,0 +1,143 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+)
+
+// LogicalOp combines the filter expressions of ConvertAll.
+type LogicalOp string
+
+const (
+	// LogicalAnd matches the rows matched by every expression.
+	LogicalAnd LogicalOp = "AND"
+	// LogicalOr matches the rows matched by any expression.
+	LogicalOr LogicalOp = "OR"
+)
+
+// ConvertAll converts independent filter expressions, e.g. a user filter, a
+// saved view and an admin constraint, and combines them with op, without
+// concatenating CEL strings. Each expression is checked on its own, and the
+// combination against the length, depth, predicate, cost and memory limits
+// as if the expressions had been written together.
+func (c *Converter) ConvertAll(exprs []string, op LogicalOp, opts ...ConvertOption) (*ConvertResult, error) {
+	return c.ConvertAllContext(context.Background(), exprs, op, opts...)
+}
+
+// ConvertAllContext is like ConvertAll, passing ctx to the SecurityLogger.
+func (c *Converter) ConvertAllContext(ctx context.Context, exprs []string, op LogicalOp, opts ...ConvertOption) (*ConvertResult, error) {
+	c = c.current()
+	return c.convertAll(ctx, exprs, op, nil, opts)
+}
+
+// ConvertAllWithAuth is like ConvertAll, authorizing the fields referenced
+// by every expression as ConvertWithAuth does.
+func (c *Converter) ConvertAllWithAuth(exprs []string, op LogicalOp, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	return c.ConvertAllWithAuthContext(context.Background(), exprs, op, userRoles, opts...)
+}
+
+// ConvertAllWithAuthContext is like ConvertAllWithAuth, passing ctx to the
+// SecurityLogger.
+func (c *Converter) ConvertAllWithAuthContext(ctx context.Context, exprs []string, op LogicalOp, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	c = c.current()
+	if !c.authorization() {
+		return c.ConvertAllContext(ctx, exprs, op, opts...)
+	}
+	if userRoles == nil {
+		userRoles = []string{}
+	}
+	return c.convertAll(ctx, exprs, op, userRoles, opts)
+}
+
+// convertAll checks, combines and converts filter expressions, authorizing
+// their fields unless userRoles is nil.
+func (c *Converter) convertAll(ctx context.Context, exprs []string, op LogicalOp, userRoles []string, opts []ConvertOption) (result *ConvertResult, err error) {
+	var function, symbol string
+	switch op {
+	case LogicalAnd:
+		function, symbol = operators.LogicalAnd, " && "
+	case LogicalOr:
+		function, symbol = operators.LogicalOr, " || "
+	default:
+		return nil, fmt.Errorf("unknown logical operator %q", op)
+	}
+	if len(exprs) == 0 {
+		return nil, fmt.Errorf("no filter expressions to combine")
+	}
+
+	c, err = c.withOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The expressions as written together, for the limits and the logs
+	parts := make([]string, len(exprs))
+	for i, celExpr := range exprs {
+		parts[i] = "(" + celExpr + ")"
+	}
+	celExpr := strings.Join(parts, symbol)
+	defer c.logAttempt(ctx, celExpr, time.Now(), &err)
+
+	checked := make([]celast.Expr, len(exprs))
+	var cost uint64
+	for i, part := range exprs {
+		_, checkedExpr, err := c.compile(ctx, part)
+		if err == nil && userRoles != nil {
+			err = c.authorize(ctx, part, checkedExpr.Expr(), userRoles, nil)
+		}
+		if err != nil {
+			return c.maskOutput(celExpr, nil, expressionError(i, err))
+		}
+		checked[i] = checkedExpr.Expr()
+		cost = addCost(cost, checkedExpr.cost)
+	}
+
+	combined := combineExprs(function, checked)
+	if err := c.checkLength(celExpr); err != nil {
+		return c.maskOutput(celExpr, nil, err)
+	}
+	if err := c.checkDepth(c.calculateExpressionDepth(combined)); err != nil {
+		return c.maskOutput(celExpr, nil, err)
+	}
+	if err := c.checkPredicates(countPredicates(combined)); err != nil {
+		return c.maskOutput(celExpr, nil, err)
+	}
+	if err := c.checkCost(cost); err != nil {
+		return c.maskOutput(celExpr, nil, err)
+	}
+
+	result, err = c.convertChecked(ctx, combined, cost)
+	result, err = c.finalize(ctx, celExpr, result, err)
+	return c.maskOutput(celExpr, result, err)
+}
+
+// combineExprs combines expressions with a logical operator into a
+// balanced tree, as the CEL parser does for chains.
+func combineExprs(function string, exprs []celast.Expr) celast.Expr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	mid := len(exprs) / 2
+	return newCall(0, function, combineExprs(function, exprs[:mid]), combineExprs(function, exprs[mid:]))
+}
+
+// expressionError identifies the expression a conversion error comes from in
+// its internal error.
+func expressionError(index int, err error) error {
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		return fmt.Errorf("expression %d: %w", index, err)
+	}
+	return &ConversionError{
+		PublicMessage: convErr.PublicMessage,
+		ErrorCode:     convErr.ErrorCode,
+		InternalError: fmt.Errorf("expression %d: %w", index, convErr.InternalError),
+		Location:      convErr.Location,
+	}
+}
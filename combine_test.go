@@ -0,0 +1,186 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ConvertAll(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status":    {Type: cel.StringType},
+			"age":       {Type: cel.IntType},
+			"tenant_id": {Type: cel.StringType},
+		},
+		MaxExpressionLength: 60,
+		MaxExpressionDepth:  4,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		exprs    []string
+		op       LogicalOp
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode string
+	}{
+		{
+			name:     "and",
+			exprs:    []string{`status == "active"`, `age > 18`, `tenant_id == "t1"`},
+			op:       LogicalAnd,
+			wantSQL:  "(status = ? AND (age > ? AND tenant_id = ?))",
+			wantArgs: []interface{}{"active", int64(18), "t1"},
+		},
+		{
+			name:     "or",
+			exprs:    []string{`status == "active"`, `age > 18 && age < 65`},
+			op:       LogicalOr,
+			wantSQL:  "(status = ? OR (age > ? AND age < ?))",
+			wantArgs: []interface{}{"active", int64(18), int64(65)},
+		},
+		{
+			name:     "single",
+			exprs:    []string{`age > 18`},
+			op:       LogicalAnd,
+			wantSQL:  "age > ?",
+			wantArgs: []interface{}{int64(18)},
+		},
+		{
+			name:     "invalid expression",
+			exprs:    []string{`age > 18`, `status ==`},
+			op:       LogicalAnd,
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "total length",
+			exprs:    []string{`status == "active"`, `status == "pending"`, `status == "closed"`},
+			op:       LogicalOr,
+			wantCode: "LIMIT_LENGTH",
+		},
+		{
+			name:     "total depth",
+			exprs:    []string{`age > 1 && age < 9`, `age > 2 && age < 8`, `age != 5`},
+			op:       LogicalAnd,
+			wantCode: "LIMIT_DEPTH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertAll(tt.exprs, tt.op)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ConvertAll() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ConvertAll() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ConvertAll() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_ConvertAll_Errors(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{"age": {Type: cel.IntType}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	_, err = converter.ConvertAll([]string{`age > 1`, `unknown == 1`}, LogicalAnd)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || !strings.HasPrefix(convErr.InternalError.Error(), "expression 1: ") {
+		t.Errorf("ConvertAll() error = %v, want the failing expression identified", err)
+	}
+	if _, err := converter.ConvertAll(nil, LogicalAnd); err == nil {
+		t.Error("ConvertAll(nil) error = nil, want error")
+	}
+	if _, err := converter.ConvertAll([]string{`age > 1`}, LogicalOp("XOR")); err == nil {
+		t.Error("ConvertAll(XOR) error = nil, want error")
+	}
+}
+
+func TestConverter_ConvertAllWithAuth(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+			"salary": {Type: cel.IntType},
+		},
+		PublicFields: []string{"status", "age"},
+		FieldACL:     map[string][]string{"salary": {"hr"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		exprs    []string
+		roles    []string
+		wantSQL  string
+		wantCode string
+	}{
+		{
+			name:    "public fields",
+			exprs:   []string{`status == "active"`, `age > 18`},
+			wantSQL: "(status = ? AND age > ?)",
+		},
+		{
+			name:     "restricted field in first expression",
+			exprs:    []string{`salary > 1000`, `age > 18`},
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:     "restricted field in last expression",
+			exprs:    []string{`status == "active"`, `age > 18`, `salary > 1000`},
+			roles:    []string{"user"},
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:    "authorized role",
+			exprs:   []string{`status == "active"`, `salary > 1000`},
+			roles:   []string{"hr"},
+			wantSQL: "(status = ? AND salary > ?)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertAllWithAuth(tt.exprs, LogicalAnd, tt.roles)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ConvertAllWithAuth() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ConvertAllWithAuth() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	if _, err := converter.ConvertAll([]string{`salary > 1000`}, LogicalAnd); err != nil {
+		t.Errorf("ConvertAll() error = %v, want no authorization", err)
+	}
+}
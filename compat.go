@@ -0,0 +1,48 @@
+package cel2squirrel
+
+import "fmt"
+
+// CompatLevel pins the SQL produced by the converter across library upgrades.
+// Behavior changes that alter the generated SQL are introduced behind a new
+// level, so existing users keep their golden SQL until they opt in.
+type CompatLevel int
+
+const (
+	// CompatV1 produces the original output. It is the default.
+	CompatV1 CompatLevel = iota
+	// CompatV2 additionally folds boolean constants (FoldConstants), pushes
+	// negations down (PushDownNot) and flattens AND/OR chains
+	// (FlattenLogicalChains).
+	CompatV2
+	// CompatV3 additionally appends an explicit ESCAPE clause to LIKE
+	// patterns (ExplicitLikeEscape).
+	CompatV3
+	// CompatV4 additionally compares standalone boolean fields with the
+	// TRUE literal (BooleanLiteral) unless another BooleanStyle is set.
+	CompatV4
+
+	// CompatLatest is the most recent compatibility level.
+	CompatLatest = CompatV4
+)
+
+// applyCompatLevel enables the behaviors implied by the configured
+// compatibility level. Behaviors enabled individually are always honored.
+func (config Config) applyCompatLevel() (Config, error) {
+	if config.CompatLevel < CompatV1 || config.CompatLevel > CompatLatest {
+		return config, fmt.Errorf("unsupported compatibility level %d", config.CompatLevel)
+	}
+
+	if config.CompatLevel >= CompatV2 {
+		config.FoldConstants = true
+		config.PushDownNot = true
+		config.FlattenLogicalChains = true
+	}
+	if config.CompatLevel >= CompatV3 {
+		config.ExplicitLikeEscape = true
+	}
+	if config.CompatLevel >= CompatV4 && config.BooleanStyle == BooleanBound {
+		config.BooleanStyle = BooleanLiteral
+	}
+
+	return config, nil
+}
@@ -0,0 +1,65 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_CompatLevel(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"a":     {Type: cel.BoolType, Column: "a"},
+		"b":     {Type: cel.BoolType, Column: "b"},
+		"age":   {Type: cel.IntType, Column: "age"},
+		"label": {Type: cel.StringType, Column: "label"},
+	}
+
+	tests := []struct {
+		name    string
+		level   CompatLevel
+		celExpr string
+		wantSQL string
+	}{
+		{name: "v1 chain", level: CompatV1, celExpr: `a && b && age > 1`, wantSQL: "((a = ? AND b = ?) AND age > ?)"},
+		{name: "v1 negation", level: CompatV1, celExpr: `!(age == 1)`, wantSQL: "NOT (age = ?)"},
+		{name: "v1 constant", level: CompatV1, celExpr: `true && a`, wantSQL: "(TRUE AND a = ?)"},
+		{name: "v2 chain", level: CompatV2, celExpr: `a && b && age > 1`, wantSQL: "(a = ? AND b = ? AND age > ?)"},
+		{name: "v2 negation", level: CompatV2, celExpr: `!(age == 1)`, wantSQL: "age <> ?"},
+		{name: "v2 constant", level: CompatV2, celExpr: `true && a`, wantSQL: "a = ?"},
+		{name: "v2 like", level: CompatV2, celExpr: `label.contains("x")`, wantSQL: "label LIKE ?"},
+		{name: "v3 like", level: CompatV3, celExpr: `label.contains("x")`, wantSQL: `label LIKE ? ESCAPE '\'`},
+		{name: "v3 boolean", level: CompatV3, celExpr: `a && !b`, wantSQL: "(a = ? AND b <> ?)"},
+		{name: "v4 boolean", level: CompatV4, celExpr: `a && !b`, wantSQL: "(a = TRUE AND b <> TRUE)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, CompatLevel: tt.level})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+		})
+	}
+}
+
+func TestNewConverter_UnsupportedCompatLevel(t *testing.T) {
+	for _, level := range []CompatLevel{-1, CompatLatest + 1} {
+		if _, err := NewConverter(Config{CompatLevel: level}); err == nil {
+			t.Errorf("NewConverter() should reject compatibility level %d", level)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cel2squirrel
+
+import (
+	"fmt"
+)
+
+// Approximate memory costs used for per-call accounting. They model the
+// Squirrel predicates and bound values materialized during conversion rather
+// than exact heap usage, which is enough to attribute and cap resource usage.
+const (
+	approxNodeBytes  = 64
+	approxValueBytes = 16
+)
+
+// Complexity reports the cost of a conversion.
+type Complexity struct {
+	// Depth is the nesting depth of the expression.
+	Depth int
+	// Predicates is the number of predicates combined by the logical
+	// operators of the expression. The expression as written is checked
+	// against Config.MaxPredicates.
+	Predicates int
+	// Nodes is the number of expression nodes materialized as SQL.
+	Nodes int
+	// Values is the number of literal values bound as query arguments.
+	Values int
+	// ApproxBytes is an approximation of the memory materialized for the
+	// conversion, checked against Config.MaxConversionBytes.
+	ApproxBytes int
+	// LikePatterns is the number of LIKE patterns bound as arguments.
+	LikePatterns int
+	// MaxLikePatternLength is the length in bytes of the longest LIKE
+	// pattern, checked against Config.MaxLikePatternLength.
+	MaxLikePatternLength int
+	// MaxLikeWildcards is the largest number of interior % wildcards of a
+	// LIKE pattern, checked against Config.MaxLikeWildcards.
+	MaxLikeWildcards int
+	// Cost is the worst-case evaluation cost of the expression estimated by
+	// cel-go, checked against Config.MaxExpressionCost. It is only estimated
+	// when MaxExpressionCost is set, and 0 otherwise.
+	Cost uint64
+}
+
+// charge accounts for nodes and bytes materialized by the current call and
+// fails once the configured budget is exceeded. It is a no-op outside of a
+// scoped conversion.
+func (c *Converter) charge(nodes, values, bytes int) error {
+	if c.conv == nil {
+		return nil
+	}
+
+	c.conv.complexity.Nodes += nodes
+	c.conv.complexity.Values += values
+	c.conv.complexity.ApproxBytes += bytes
+
+	if c.maxConversionBytes > 0 && c.conv.complexity.ApproxBytes > c.maxConversionBytes {
+		return newConversionError(
+			"filter expression exceeds memory budget",
+			CodeLimitMemory,
+			fmt.Errorf("conversion exceeds memory budget of %d bytes", c.maxConversionBytes),
+		)
+	}
+	return nil
+}
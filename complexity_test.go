@@ -0,0 +1,118 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_Complexity(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status": {Type: cel.StringType, Column: "status"},
+		"age":    {Type: cel.IntType, Column: "age"},
+	}
+
+	converter, err := NewConverter(Config{FieldDeclarations: fields})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`status == "active" && age in [1, 2, 3]`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	got := result.Complexity
+	if got.Nodes != 3 {
+		t.Errorf("Nodes = %d, want 3", got.Nodes)
+	}
+	if got.Values != 4 {
+		t.Errorf("Values = %d, want 4", got.Values)
+	}
+	if got.Depth != 4 {
+		t.Errorf("Depth = %d, want 4", got.Depth)
+	}
+	if want := 3*approxNodeBytes + 4*approxValueBytes + len("active"); got.ApproxBytes != want {
+		t.Errorf("ApproxBytes = %d, want %d", got.ApproxBytes, want)
+	}
+
+	// Accounting is per call
+	again, err := converter.Convert(`status == "active" && age in [1, 2, 3]`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if again.Complexity != got {
+		t.Errorf("Complexity should not accumulate across calls: %+v vs %+v", again.Complexity, got)
+	}
+}
+
+func TestConverter_Convert_MaxConversionBytes(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "status"},
+		},
+		MaxConversionBytes: 256,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	if _, err := converter.Convert(`status == "active"`); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	_, err = converter.Convert(`status == "` + strings.Repeat("x", 300) + `"`)
+	if err == nil || !strings.Contains(err.Error(), "memory budget") {
+		t.Errorf("expected memory budget error, got %v", err)
+	}
+}
+
+func TestConverter_Convert_MaxPredicates(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "status"},
+			"age":    {Type: cel.IntType, Column: "age"},
+		},
+		PublicFields:  []string{"status", "age"},
+		MaxPredicates: 3,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	// Hundreds of ORed equalities stay shallow, the parser balancing chains
+	wide := strings.Repeat(`status == "x" || `, 500) + `age > 1`
+
+	tests := []struct {
+		name    string
+		celExpr string
+		wantErr bool
+	}{
+		{name: "single", celExpr: `status == "a"`},
+		{name: "at the limit", celExpr: `status == "a" || age > 1 || !(age < 3)`},
+		{name: "in list counts once", celExpr: `status in ["a", "b", "c", "d"] && age > 1`},
+		{name: "over the limit", celExpr: `status == "a" || status == "b" || age > 1 || age < 3`, wantErr: true},
+		{name: "wide", celExpr: wide, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if got := errors.Is(err, ErrLimitPredicates); got != tt.wantErr {
+				t.Errorf("Convert() error = %v, want LIMIT_PREDICATES %v", err, tt.wantErr)
+			}
+			_, err = converter.ConvertWithAuth(tt.celExpr, nil)
+			if got := errors.Is(err, ErrLimitPredicates); got != tt.wantErr {
+				t.Errorf("ConvertWithAuth() error = %v, want LIMIT_PREDICATES %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The combination of expressions within the limit may exceed it
+	_, err = converter.ConvertAll([]string{`status == "a" || age > 1`, `age < 3 || age > 9`}, LogicalAnd)
+	if !errors.Is(err, ErrLimitPredicates) {
+		t.Errorf("ConvertAll() error = %v, want %v", err, ErrLimitPredicates)
+	}
+}
@@ -0,0 +1,217 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+)
+
+var (
+	// declaredFieldName matches the names of declared fields: CEL identifiers.
+	declaredFieldName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// plainColumnName matches column names, possibly qualified with a table.
+	plainColumnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+// ConfigBuilder builds the field declarations and authorization settings of
+// a Config with a fluent API, e.g.
+//
+//	config, err := cel2squirrel.NewConfigBuilder().
+//		String("status").
+//		Int("age", "age_years").
+//		Double("salary").
+//		Public("status", "age").
+//		ACL("salary", "admin").
+//		Build()
+//
+// Each call is validated as it is made: the first invalid call, such as a
+// field declared twice or an ACL on an undeclared field, makes the following
+// calls no-ops and is returned by Build, prefixed with the call.
+type ConfigBuilder struct {
+	config Config
+	public map[string]bool
+	err    error
+}
+
+// NewConfigBuilder creates a builder starting from DefaultConfig.
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{
+		config: DefaultConfig(),
+		public: make(map[string]bool),
+	}
+}
+
+// String declares a string field, read from column if given, otherwise from
+// the column named after the field.
+func (b *ConfigBuilder) String(name string, column ...string) *ConfigBuilder {
+	return b.declare("String", name, cel.StringType, column)
+}
+
+// Int declares an int field, read from column if given, otherwise from the
+// column named after the field.
+func (b *ConfigBuilder) Int(name string, column ...string) *ConfigBuilder {
+	return b.declare("Int", name, cel.IntType, column)
+}
+
+// Uint declares a uint field, read from column if given, otherwise from the
+// column named after the field.
+func (b *ConfigBuilder) Uint(name string, column ...string) *ConfigBuilder {
+	return b.declare("Uint", name, cel.UintType, column)
+}
+
+// Double declares a double field, read from column if given, otherwise from
+// the column named after the field.
+func (b *ConfigBuilder) Double(name string, column ...string) *ConfigBuilder {
+	return b.declare("Double", name, cel.DoubleType, column)
+}
+
+// Bool declares a bool field, read from column if given, otherwise from the
+// column named after the field.
+func (b *ConfigBuilder) Bool(name string, column ...string) *ConfigBuilder {
+	return b.declare("Bool", name, cel.BoolType, column)
+}
+
+// Timestamp declares a timestamp field, read from column if given, otherwise
+// from the column named after the field.
+func (b *ConfigBuilder) Timestamp(name string, column ...string) *ConfigBuilder {
+	return b.declare("Timestamp", name, cel.TimestampType, column)
+}
+
+// Duration declares a duration field, read from column if given, otherwise
+// from the column named after the field.
+func (b *ConfigBuilder) Duration(name string, column ...string) *ConfigBuilder {
+	return b.declare("Duration", name, cel.DurationType, column)
+}
+
+// Field declares a field with a complete mapping, for the options the typed
+// methods do not cover, e.g. list fields, SQL expressions or joins.
+func (b *ConfigBuilder) Field(name string, mapping ColumnMapping) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if mapping.Type == nil {
+		b.err = fmt.Errorf("Field(%q): field %s has no type", name, name)
+		return b
+	}
+	if err := b.checkName(name); err != nil {
+		b.err = fmt.Errorf("Field(%q): %w", name, err)
+		return b
+	}
+	b.config.FieldDeclarations[name] = mapping
+	return b
+}
+
+// Public makes fields filterable by any user. Declaring public fields
+// enables authorization: the other fields require a role listed by ACL.
+func (b *ConfigBuilder) Public(fields ...string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	for _, field := range fields {
+		if err := b.checkDeclared(field); err != nil {
+			b.err = fmt.Errorf("Public(%q): %w", field, err)
+			return b
+		}
+		if !b.public[field] {
+			b.public[field] = true
+			b.config.PublicFields = append(b.config.PublicFields, field)
+		}
+	}
+	return b
+}
+
+// ACL allows users with any of roles to filter by field.
+func (b *ConfigBuilder) ACL(field string, roles ...string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	if err := b.checkDeclared(field); err != nil {
+		b.err = fmt.Errorf("ACL(%q): %w", field, err)
+		return b
+	}
+	if len(roles) == 0 || slices.Contains(roles, "") {
+		b.err = fmt.Errorf("ACL(%q): roles must be non-empty", field)
+		return b
+	}
+	if b.config.FieldACL == nil {
+		b.config.FieldACL = make(map[string][]string)
+	}
+	for _, role := range roles {
+		if !slices.Contains(b.config.FieldACL[field], role) {
+			b.config.FieldACL[field] = append(b.config.FieldACL[field], role)
+		}
+	}
+	return b
+}
+
+// Build returns the configuration, or the error of the first invalid call.
+// Other settings, such as the dialect, can be set on the returned Config.
+func (b *ConfigBuilder) Build() (Config, error) {
+	if b.err != nil {
+		return Config{}, b.err
+	}
+	if len(b.config.FieldACL) > 0 && len(b.config.PublicFields) == 0 {
+		// Authorization is disabled without public fields, and the ACL ignored
+		return Config{}, fmt.Errorf("ACL requires at least one field declared with Public")
+	}
+
+	config := b.config
+	config.FieldDeclarations = maps.Clone(b.config.FieldDeclarations)
+	config.PublicFields = slices.Clone(b.config.PublicFields)
+	if b.config.FieldACL != nil {
+		config.FieldACL = make(map[string][]string, len(b.config.FieldACL))
+		for field, roles := range b.config.FieldACL {
+			config.FieldACL[field] = slices.Clone(roles)
+		}
+	}
+	return config, nil
+}
+
+// declare declares a field of type t, on behalf of method.
+func (b *ConfigBuilder) declare(method, name string, t *cel.Type, column []string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+	call := fmt.Sprintf("%s(%q)", method, name)
+	if err := b.checkName(name); err != nil {
+		b.err = fmt.Errorf("%s: %w", call, err)
+		return b
+	}
+	mapping := ColumnMapping{Type: t}
+	switch len(column) {
+	case 0:
+	case 1:
+		if !plainColumnName.MatchString(column[0]) {
+			b.err = fmt.Errorf("%s: invalid column name %q", call, column[0])
+			return b
+		}
+		mapping.Column = column[0]
+	default:
+		b.err = fmt.Errorf("%s: expected at most one column, got %d", call, len(column))
+		return b
+	}
+	b.config.FieldDeclarations[name] = mapping
+	return b
+}
+
+// checkName checks that a field name is valid and not yet declared.
+func (b *ConfigBuilder) checkName(name string) error {
+	if !declaredFieldName.MatchString(name) {
+		return fmt.Errorf("invalid field name %q", name)
+	}
+	if _, ok := b.config.FieldDeclarations[name]; ok {
+		return fmt.Errorf("field %s is already declared", name)
+	}
+	return nil
+}
+
+// checkDeclared checks that a field was declared.
+func (b *ConfigBuilder) checkDeclared(field string) error {
+	if _, ok := b.config.FieldDeclarations[field]; !ok {
+		return fmt.Errorf("field %s is not declared", field)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConfigBuilder_Build(t *testing.T) {
+	config, err := NewConfigBuilder().
+		String("status").
+		Int("age", "age_years").
+		Double("salary", "hr.salary").
+		Timestamp("created_at").
+		Field("tags", ColumnMapping{Type: cel.ListType(cel.StringType), Kind: KindArray}).
+		Public("status", "age", "status").
+		ACL("salary", "admin", "hr").
+		ACL("salary", "admin").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	wantFields := map[string]ColumnMapping{
+		"status":     {Type: cel.StringType},
+		"age":        {Type: cel.IntType, Column: "age_years"},
+		"salary":     {Type: cel.DoubleType, Column: "hr.salary"},
+		"created_at": {Type: cel.TimestampType},
+		"tags":       {Type: cel.ListType(cel.StringType), Kind: KindArray},
+	}
+	if !reflect.DeepEqual(config.FieldDeclarations, wantFields) {
+		t.Errorf("FieldDeclarations = %v, want %v", config.FieldDeclarations, wantFields)
+	}
+	if want := []string{"status", "age"}; !reflect.DeepEqual(config.PublicFields, want) {
+		t.Errorf("PublicFields = %v, want %v", config.PublicFields, want)
+	}
+	if want := map[string][]string{"salary": {"admin", "hr"}}; !reflect.DeepEqual(config.FieldACL, want) {
+		t.Errorf("FieldACL = %v, want %v", config.FieldACL, want)
+	}
+	if config.MaxExpressionLength != DefaultConfig().MaxExpressionLength {
+		t.Errorf("MaxExpressionLength = %d, want the default", config.MaxExpressionLength)
+	}
+
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	result, err := converter.ConvertWithAuth(`age >= 18 && salary > 1000.0`, []string{"hr"})
+	if err != nil {
+		t.Fatalf("ConvertWithAuth() error = %v", err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "(age_years >= ? AND hr.salary > ?)"; sql != want {
+		t.Errorf("SQL = %q, want %q", sql, want)
+	}
+	if _, err := converter.ConvertWithAuth(`salary > 1000.0`, []string{"viewer"}); err == nil {
+		t.Error("ConvertWithAuth() without role error = nil, want error")
+	}
+}
+
+func TestConfigBuilder_Build_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(b *ConfigBuilder) *ConfigBuilder
+		wantErr string
+	}{
+		{
+			name: "duplicate field",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.String("status").Int("status")
+			},
+			wantErr: `Int("status"): field status is already declared`,
+		},
+		{
+			name: "invalid field name",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.String("first-name")
+			},
+			wantErr: `String("first-name"): invalid field name "first-name"`,
+		},
+		{
+			name: "invalid column",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.String("status", "status; DROP TABLE users")
+			},
+			wantErr: `String("status"): invalid column name "status; DROP TABLE users"`,
+		},
+		{
+			name: "several columns",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.Bool("active", "active", "enabled")
+			},
+			wantErr: `Bool("active"): expected at most one column, got 2`,
+		},
+		{
+			name: "untyped field",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.Field("tags", ColumnMapping{Column: "tags"})
+			},
+			wantErr: `Field("tags"): field tags has no type`,
+		},
+		{
+			name: "public undeclared field",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.String("status").Public("status", "age")
+			},
+			wantErr: `Public("age"): field age is not declared`,
+		},
+		{
+			name: "acl undeclared field",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.ACL("salary", "admin")
+			},
+			wantErr: `ACL("salary"): field salary is not declared`,
+		},
+		{
+			name: "acl without roles",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.Double("salary").Public("salary").ACL("salary")
+			},
+			wantErr: `ACL("salary"): roles must be non-empty`,
+		},
+		{
+			name: "acl without public fields",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.Double("salary").ACL("salary", "admin")
+			},
+			wantErr: "ACL requires at least one field declared with Public",
+		},
+		{
+			name: "first error kept",
+			build: func(b *ConfigBuilder) *ConfigBuilder {
+				return b.String("a").String("a").Int("b").Public("c")
+			},
+			wantErr: `String("a"): field a is already declared`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build(NewConfigBuilder()).Build()
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Build() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigBuilder_Build_Copies(t *testing.T) {
+	builder := NewConfigBuilder().String("status").Public("status")
+	first, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := builder.Int("age").Public("age").Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if len(first.FieldDeclarations) != 1 || len(first.PublicFields) != 1 {
+		t.Errorf("first Config changed by later calls: %v, %v", first.FieldDeclarations, first.PublicFields)
+	}
+}
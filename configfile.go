@@ -0,0 +1,509 @@
+package cel2squirrel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// configFile is the declarative form of a Config, as stored in JSON and
+// YAML definition files.
+type configFile struct {
+	Fields       map[string]fieldFile      `json:"fields,omitempty"`
+	Collections  map[string]collectionFile `json:"collections,omitempty"`
+	TableAlias   string                    `json:"table_alias,omitempty"`
+	Dialect      Dialect                   `json:"dialect,omitempty"`
+	Limits       limitsFile                `json:"limits,omitzero"`
+	Cache        cacheFile                 `json:"cache,omitzero"`
+	PublicFields []string                  `json:"public_fields,omitempty"`
+	FieldACL     map[string][]string       `json:"acl,omitempty"`
+	KeyFields    []string                  `json:"key_fields,omitempty"`
+	SoftDelete   string                    `json:"soft_delete_field,omitempty"`
+	FlagGroups   map[string][]string       `json:"flag_groups,omitempty"`
+	CompatLevel  CompatLevel               `json:"compat_level,omitempty"`
+	BooleanStyle BooleanStyle              `json:"boolean_style,omitempty"`
+	EmptyFilter  EmptyFilterMode           `json:"empty_filter,omitempty"`
+	ErrorDetail  ErrorDetail               `json:"error_detail,omitempty"`
+	Messages     map[ErrorCode]string      `json:"error_messages,omitempty"`
+
+	UseBetween           bool `json:"use_between,omitempty"`
+	CollapseOrToIn       bool `json:"collapse_or_to_in,omitempty"`
+	FoldConstants        bool `json:"fold_constants,omitempty"`
+	Canonicalize         bool `json:"canonicalize,omitempty"`
+	PushDownNot          bool `json:"push_down_not,omitempty"`
+	FlattenLogicalChains bool `json:"flatten_logical_chains,omitempty"`
+	CaseInsensitiveLike  bool `json:"case_insensitive_like,omitempty"`
+	ExplicitLikeEscape   bool `json:"explicit_like_escape,omitempty"`
+	AuditSQL             bool `json:"audit_sql,omitempty"`
+	StringExtensions     bool `json:"string_extensions,omitempty"`
+	TimestampStrings     bool `json:"timestamp_strings,omitempty"`
+}
+
+type limitsFile struct {
+	MaxExpressionLength  int    `json:"max_expression_length,omitempty"`
+	MaxExpressionDepth   int    `json:"max_expression_depth,omitempty"`
+	MaxInClauseSize      int    `json:"max_in_clause_size,omitempty"`
+	MaxConversionBytes   int    `json:"max_conversion_bytes,omitempty"`
+	MaxLikePatternLength int    `json:"max_like_pattern_length,omitempty"`
+	MaxLikeWildcards     int    `json:"max_like_wildcards,omitempty"`
+	MaxPredicates        int    `json:"max_predicates,omitempty"`
+	MaxExpressionCost    uint64 `json:"max_expression_cost,omitempty"`
+	InValuesThreshold    int    `json:"in_values_threshold,omitempty"`
+}
+
+type cacheFile struct {
+	Size int    `json:"size,omitempty"`
+	TTL  string `json:"ttl,omitempty"`
+}
+
+type fieldFile struct {
+	// Type is the CEL type, e.g. "string" or "list(int)".
+	Type                  string            `json:"type"`
+	Column                string            `json:"column,omitempty"`
+	Expr                  string            `json:"expr,omitempty"`
+	Table                 string            `json:"table,omitempty"`
+	JSONPath              string            `json:"json_path,omitempty"`
+	Kind                  ColumnKind        `json:"kind,omitempty"`
+	Collation             string            `json:"collation,omitempty"`
+	CaseInsensitive       bool              `json:"case_insensitive,omitempty"`
+	Masked                bool              `json:"masked,omitempty"`
+	Granularity           string            `json:"granularity,omitempty"`
+	TruncateToGranularity bool              `json:"truncate_to_granularity,omitempty"`
+	Join                  *joinFile         `json:"join,omitempty"`
+	PartialIndex          *partialIndexFile `json:"partial_index,omitempty"`
+	Aliases               []string          `json:"aliases,omitempty"`
+	DeprecatedAliases     bool              `json:"deprecated_aliases,omitempty"`
+	Deprecated            bool              `json:"deprecated,omitempty"`
+	ReplacedBy            string            `json:"replaced_by,omitempty"`
+	AllowedOps            []string          `json:"allowed_ops,omitempty"`
+}
+
+type joinFile struct {
+	Table string `json:"table"`
+	Alias string `json:"alias,omitempty"`
+	On    string `json:"on"`
+}
+
+type partialIndexFile struct {
+	Condition   string `json:"condition"`
+	Description string `json:"description,omitempty"`
+}
+
+type collectionFile struct {
+	Table  string               `json:"table"`
+	Alias  string               `json:"alias,omitempty"`
+	On     string               `json:"on"`
+	Fields map[string]fieldFile `json:"fields"`
+}
+
+// MarshalJSON encodes the declarative settings of the configuration, to be
+// stored in a definition file, e.g.
+//
+//	{
+//	  "fields": {
+//	    "status": {"type": "string"},
+//	    "tags": {"type": "list(string)", "column": "tag_names", "kind": "array"}
+//	  },
+//	  "dialect": "postgres",
+//	  "limits": {"max_expression_length": 2000},
+//	  "public_fields": ["status"],
+//	  "acl": {"tags": ["admin"]}
+//	}
+//
+// Keys are the snake_case names of the Config and ColumnMapping fields, with
+// limits grouped under "limits", CacheSize and CacheTTL under "cache" as
+// "size" and "ttl", and FieldACL as "acl". Field types use the
+// CEL type syntax: bool, int, uint, double, string, bytes, timestamp,
+// duration, dyn, list(T) and map(K, V). Settings holding Go code or state,
+// namely PlaceholderFormat, Functions, Fallbacks, AggregateFields,
+// Subqueries, Quota, Stats, Corpus, SecurityLogger and the mandatory
+// conditions, are not encoded and must be set in code.
+func (config Config) MarshalJSON() ([]byte, error) {
+	file := configFile{
+		TableAlias:   config.TableAlias,
+		Dialect:      config.Dialect,
+		PublicFields: config.PublicFields,
+		FieldACL:     config.FieldACL,
+		KeyFields:    config.KeyFields,
+		SoftDelete:   config.SoftDeleteField,
+		FlagGroups:   config.FlagGroups,
+		CompatLevel:  config.CompatLevel,
+		BooleanStyle: config.BooleanStyle,
+		EmptyFilter:  config.EmptyFilter,
+		ErrorDetail:  config.ErrorDetail,
+		Messages:     config.ErrorMessages,
+		Limits: limitsFile{
+			MaxExpressionLength:  config.MaxExpressionLength,
+			MaxExpressionDepth:   config.MaxExpressionDepth,
+			MaxInClauseSize:      config.MaxInClauseSize,
+			MaxConversionBytes:   config.MaxConversionBytes,
+			MaxLikePatternLength: config.MaxLikePatternLength,
+			MaxLikeWildcards:     config.MaxLikeWildcards,
+			MaxPredicates:        config.MaxPredicates,
+			MaxExpressionCost:    config.MaxExpressionCost,
+			InValuesThreshold:    config.InValuesThreshold,
+		},
+		Cache:                cacheFile{Size: config.CacheSize},
+		UseBetween:           config.UseBetween,
+		CollapseOrToIn:       config.CollapseOrToIn,
+		FoldConstants:        config.FoldConstants,
+		Canonicalize:         config.Canonicalize,
+		PushDownNot:          config.PushDownNot,
+		FlattenLogicalChains: config.FlattenLogicalChains,
+		CaseInsensitiveLike:  config.CaseInsensitiveLike,
+		ExplicitLikeEscape:   config.ExplicitLikeEscape,
+		AuditSQL:             config.AuditSQL,
+		StringExtensions:     config.StringExtensions,
+		TimestampStrings:     config.TimestampStrings,
+	}
+
+	if config.CacheTTL != 0 {
+		file.Cache.TTL = config.CacheTTL.String()
+	}
+
+	var err error
+	if file.Fields, err = marshalFields(config.FieldDeclarations); err != nil {
+		return nil, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Collections)) {
+		coll := config.Collections[name]
+		fields, err := marshalFields(coll.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+		if file.Collections == nil {
+			file.Collections = make(map[string]collectionFile)
+		}
+		file.Collections[name] = collectionFile{Table: coll.Table, Alias: coll.Alias, On: coll.On, Fields: fields}
+	}
+	return json.Marshal(file)
+}
+
+// UnmarshalJSON decodes a definition file encoded by MarshalJSON. The
+// declarative settings of the configuration are replaced, and the others,
+// such as Quota, are left as they are, so that they can be set before
+// decoding. Unknown keys are rejected.
+func (config *Config) UnmarshalJSON(data []byte) error {
+	var file configFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fields, err := unmarshalFields(file.Fields)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	var cacheTTL time.Duration
+	if file.Cache.TTL != "" {
+		if cacheTTL, err = time.ParseDuration(file.Cache.TTL); err != nil {
+			return fmt.Errorf("invalid configuration: cache ttl: %w", err)
+		}
+	}
+	var collections map[string]Collection
+	for _, name := range slices.Sorted(maps.Keys(file.Collections)) {
+		coll := file.Collections[name]
+		collFields, err := unmarshalFields(coll.Fields)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: collection %s: %w", name, err)
+		}
+		if collections == nil {
+			collections = make(map[string]Collection)
+		}
+		collections[name] = Collection{Table: coll.Table, Alias: coll.Alias, On: coll.On, Fields: collFields}
+	}
+
+	config.FieldDeclarations = fields
+	config.Collections = collections
+	config.TableAlias = file.TableAlias
+	config.Dialect = file.Dialect
+	config.PublicFields = file.PublicFields
+	config.FieldACL = file.FieldACL
+	config.KeyFields = file.KeyFields
+	config.SoftDeleteField = file.SoftDelete
+	config.FlagGroups = file.FlagGroups
+	config.CompatLevel = file.CompatLevel
+	config.BooleanStyle = file.BooleanStyle
+	config.EmptyFilter = file.EmptyFilter
+	config.ErrorDetail = file.ErrorDetail
+	config.ErrorMessages = file.Messages
+	config.MaxExpressionLength = file.Limits.MaxExpressionLength
+	config.MaxExpressionDepth = file.Limits.MaxExpressionDepth
+	config.MaxInClauseSize = file.Limits.MaxInClauseSize
+	config.MaxConversionBytes = file.Limits.MaxConversionBytes
+	config.MaxLikePatternLength = file.Limits.MaxLikePatternLength
+	config.MaxLikeWildcards = file.Limits.MaxLikeWildcards
+	config.MaxPredicates = file.Limits.MaxPredicates
+	config.MaxExpressionCost = file.Limits.MaxExpressionCost
+	config.InValuesThreshold = file.Limits.InValuesThreshold
+	config.CacheSize = file.Cache.Size
+	config.CacheTTL = cacheTTL
+	config.UseBetween = file.UseBetween
+	config.CollapseOrToIn = file.CollapseOrToIn
+	config.FoldConstants = file.FoldConstants
+	config.Canonicalize = file.Canonicalize
+	config.PushDownNot = file.PushDownNot
+	config.FlattenLogicalChains = file.FlattenLogicalChains
+	config.CaseInsensitiveLike = file.CaseInsensitiveLike
+	config.ExplicitLikeEscape = file.ExplicitLikeEscape
+	config.AuditSQL = file.AuditSQL
+	config.StringExtensions = file.StringExtensions
+	config.TimestampStrings = file.TimestampStrings
+	return nil
+}
+
+// MarshalYAML encodes the configuration like MarshalJSON, for YAML
+// libraries such as gopkg.in/yaml.v3 and github.com/goccy/go-yaml.
+func (config Config) MarshalYAML() (any, error) {
+	data, err := config.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var document map[string]any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// UnmarshalYAML decodes the configuration like UnmarshalJSON, for YAML
+// libraries such as gopkg.in/yaml.v3 and github.com/goccy/go-yaml.
+// sigs.k8s.io/yaml converts YAML to JSON and uses UnmarshalJSON instead.
+func (config *Config) UnmarshalYAML(unmarshal func(any) error) error {
+	var document any
+	if err := unmarshal(&document); err != nil {
+		return err
+	}
+	document, err := jsonCompatible(document)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return config.UnmarshalJSON(data)
+}
+
+// jsonCompatible converts the maps with interface keys some YAML libraries
+// decode mappings to into maps with string keys.
+func jsonCompatible(value any) (any, error) {
+	switch value := value.(type) {
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for key, elem := range value {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("non-string key %v", key)
+			}
+			var err error
+			if converted[name], err = jsonCompatible(elem); err != nil {
+				return nil, err
+			}
+		}
+		return converted, nil
+	case map[string]any:
+		for key, elem := range value {
+			var err error
+			if value[key], err = jsonCompatible(elem); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	case []any:
+		for i, elem := range value {
+			var err error
+			if value[i], err = jsonCompatible(elem); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+// marshalFields encodes field declarations.
+func marshalFields(fields map[string]ColumnMapping) (map[string]fieldFile, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	encoded := make(map[string]fieldFile, len(fields))
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		mapping := fields[name]
+		typeName, err := formatCELType(mapping.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		field := fieldFile{
+			Type:                  typeName,
+			Column:                mapping.Column,
+			Expr:                  mapping.Expr,
+			Table:                 mapping.Table,
+			JSONPath:              mapping.JSONPath,
+			Kind:                  mapping.Kind,
+			Collation:             mapping.Collation,
+			CaseInsensitive:       mapping.CaseInsensitive,
+			Masked:                mapping.Masked,
+			TruncateToGranularity: mapping.TruncateToGranularity,
+			Aliases:               mapping.Aliases,
+			DeprecatedAliases:     mapping.DeprecatedAliases,
+			Deprecated:            mapping.Deprecated,
+			ReplacedBy:            mapping.ReplacedBy,
+			AllowedOps:            mapping.AllowedOps,
+		}
+		if mapping.Granularity != 0 {
+			field.Granularity = mapping.Granularity.String()
+		}
+		if mapping.Join != nil {
+			field.Join = &joinFile{Table: mapping.Join.Table, Alias: mapping.Join.Alias, On: mapping.Join.On}
+		}
+		if mapping.PartialIndex != nil {
+			field.PartialIndex = &partialIndexFile{
+				Condition:   mapping.PartialIndex.Condition,
+				Description: mapping.PartialIndex.Description,
+			}
+		}
+		encoded[name] = field
+	}
+	return encoded, nil
+}
+
+// unmarshalFields decodes field declarations.
+func unmarshalFields(fields map[string]fieldFile) (map[string]ColumnMapping, error) {
+	decoded := make(map[string]ColumnMapping, len(fields))
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		field := fields[name]
+		t, err := parseCELType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		mapping := ColumnMapping{
+			Type:                  t,
+			Column:                field.Column,
+			Expr:                  field.Expr,
+			Table:                 field.Table,
+			JSONPath:              field.JSONPath,
+			Kind:                  field.Kind,
+			Collation:             field.Collation,
+			CaseInsensitive:       field.CaseInsensitive,
+			Masked:                field.Masked,
+			TruncateToGranularity: field.TruncateToGranularity,
+			Aliases:               field.Aliases,
+			DeprecatedAliases:     field.DeprecatedAliases,
+			Deprecated:            field.Deprecated,
+			ReplacedBy:            field.ReplacedBy,
+			AllowedOps:            field.AllowedOps,
+		}
+		if field.Granularity != "" {
+			if mapping.Granularity, err = time.ParseDuration(field.Granularity); err != nil {
+				return nil, fmt.Errorf("field %s: invalid granularity: %w", name, err)
+			}
+		}
+		if field.Join != nil {
+			mapping.Join = &JoinSpec{Table: field.Join.Table, Alias: field.Join.Alias, On: field.Join.On}
+		}
+		if field.PartialIndex != nil {
+			mapping.PartialIndex = &PartialIndex{
+				Condition:   field.PartialIndex.Condition,
+				Description: field.PartialIndex.Description,
+			}
+		}
+		decoded[name] = mapping
+	}
+	return decoded, nil
+}
+
+// celTypeNames are the names of the primitive types of definition files.
+var celTypeNames = map[string]*cel.Type{
+	"bool":      cel.BoolType,
+	"int":       cel.IntType,
+	"uint":      cel.UintType,
+	"double":    cel.DoubleType,
+	"string":    cel.StringType,
+	"bytes":     cel.BytesType,
+	"timestamp": cel.TimestampType,
+	"duration":  cel.DurationType,
+	"dyn":       cel.DynType,
+}
+
+// formatCELType returns the name of a type in definition files.
+func formatCELType(t *cel.Type) (string, error) {
+	if t == nil {
+		return "", fmt.Errorf("missing type")
+	}
+	switch t.Kind() {
+	case types.ListKind:
+		elem, err := formatCELType(t.Parameters()[0])
+		if err != nil {
+			return "", err
+		}
+		return "list(" + elem + ")", nil
+	case types.MapKind:
+		key, err := formatCELType(t.Parameters()[0])
+		if err != nil {
+			return "", err
+		}
+		value, err := formatCELType(t.Parameters()[1])
+		if err != nil {
+			return "", err
+		}
+		return "map(" + key + ", " + value + ")", nil
+	}
+	for name, candidate := range celTypeNames {
+		if t.IsExactType(candidate) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %v", t)
+}
+
+// parseCELType parses the name of a type in definition files.
+func parseCELType(name string) (*cel.Type, error) {
+	name = strings.TrimSpace(name)
+	if t, ok := celTypeNames[name]; ok {
+		return t, nil
+	}
+
+	if params, ok := strings.CutPrefix(name, "list("); ok && strings.HasSuffix(params, ")") {
+		elem, err := parseCELType(strings.TrimSuffix(params, ")"))
+		if err != nil {
+			return nil, err
+		}
+		return cel.ListType(elem), nil
+	}
+	if params, ok := strings.CutPrefix(name, "map("); ok && strings.HasSuffix(params, ")") {
+		params = strings.TrimSuffix(params, ")")
+		// Split on the comma outside of nested parameters
+		depth := 0
+		for i, r := range params {
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			case ',':
+				if depth > 0 {
+					continue
+				}
+				key, err := parseCELType(params[:i])
+				if err != nil {
+					return nil, err
+				}
+				value, err := parseCELType(params[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				return cel.MapType(key, value), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown type %q", name)
+}
@@ -0,0 +1,206 @@
+package cel2squirrel
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConfig_MarshalJSON_RoundTrip(t *testing.T) {
+	config := Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status":     {Type: cel.StringType, Collation: "und-x-icu", AllowedOps: []string{"==", "in"}},
+			"age":        {Type: cel.IntType, Column: "age_years", Aliases: []string{"years"}, DeprecatedAliases: true},
+			"tags":       {Type: cel.ListType(cel.StringType), Kind: KindArray},
+			"labels":     {Type: cel.MapType(cel.StringType, cel.ListType(cel.IntType))},
+			"created_at": {Type: cel.TimestampType, Granularity: 24 * time.Hour, TruncateToGranularity: true},
+			"email":      {Type: cel.StringType, Expr: "LOWER(email)", Masked: true},
+			"author":     {Type: cel.StringType, Column: "name", Join: &JoinSpec{Table: "users", Alias: "u", On: "p.author_id = u.id"}},
+			"deleted":    {Type: cel.BoolType, Expr: "deleted_at IS NOT NULL", Deprecated: true, ReplacedBy: "created_at"},
+			"title": {Type: cel.StringType, PartialIndex: &PartialIndex{
+				Condition: "!deleted",
+			}},
+		},
+		Collections: map[string]Collection{
+			"comments": {
+				Table:  "comments",
+				On:     "comments.prompt_id = p.id",
+				Fields: map[string]ColumnMapping{"flagged": {Type: cel.BoolType}},
+			},
+		},
+		TableAlias:          "p",
+		Dialect:             DialectPostgreSQL,
+		MaxExpressionLength: 2000,
+		MaxInClauseSize:     100,
+		InValuesThreshold:   50,
+		PublicFields:        []string{"status", "age"},
+		FieldACL:            map[string][]string{"email": {"admin"}},
+		KeyFields:           []string{"status"},
+		SoftDeleteField:     "created_at",
+		CompatLevel:         CompatV2,
+		BooleanStyle:        BooleanIsTrue,
+		EmptyFilter:         EmptyFilterMatchAll,
+		ErrorDetail:         ErrorDetailFull,
+		CacheSize:           128,
+		CacheTTL:            time.Minute,
+		ErrorMessages:       map[ErrorCode]string{CodeLimitDepth: "filtre trop complexe"},
+		UseBetween:          true,
+		AuditSQL:            true,
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded Config
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, config) {
+		t.Errorf("round trip = %+v, want %+v", decoded, config)
+	}
+	if _, err := NewConverter(decoded); err != nil {
+		t.Errorf("NewConverter() error = %v", err)
+	}
+}
+
+func TestConfig_UnmarshalJSON(t *testing.T) {
+	quota := func(context.Context, ExpressionClass) error { return nil }
+	config := Config{Quota: quota, MaxLikeWildcards: 3}
+	err := json.Unmarshal([]byte(`{
+		"fields": {
+			"status": {"type": "string"},
+			"scores": {"type": "map(string, double)", "column": "score_map"}
+		},
+		"dialect": "mysql",
+		"limits": {"max_expression_depth": 10},
+		"public_fields": ["status"],
+		"acl": {"scores": ["analyst"]},
+		"collapse_or_to_in": true
+	}`), &config)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	want := map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+		"scores": {Type: cel.MapType(cel.StringType, cel.DoubleType), Column: "score_map"},
+	}
+	if !reflect.DeepEqual(config.FieldDeclarations, want) {
+		t.Errorf("FieldDeclarations = %v, want %v", config.FieldDeclarations, want)
+	}
+	if config.Dialect != DialectMySQL || config.MaxExpressionDepth != 10 || !config.CollapseOrToIn {
+		t.Errorf("settings = %q, %d, %v", config.Dialect, config.MaxExpressionDepth, config.CollapseOrToIn)
+	}
+	if config.MaxLikeWildcards != 0 {
+		t.Errorf("MaxLikeWildcards = %d, want it replaced by the file's", config.MaxLikeWildcards)
+	}
+	if config.Quota == nil {
+		t.Error("Quota was reset, want it kept")
+	}
+	if !reflect.DeepEqual(config.FieldACL, map[string][]string{"scores": {"analyst"}}) {
+		t.Errorf("FieldACL = %v", config.FieldACL)
+	}
+}
+
+func TestConfig_UnmarshalYAML(t *testing.T) {
+	// The document as decoded by YAML libraries mapping to interface keys
+	document := map[any]any{
+		"fields": map[any]any{
+			"status": map[any]any{"type": "string"},
+			"tags":   map[any]any{"type": "list(string)", "kind": "array"},
+		},
+		"dialect":       "postgres",
+		"public_fields": []any{"status"},
+		"limits":        map[any]any{"max_in_clause_size": 20},
+	}
+	unmarshal := func(v any) error {
+		reflect.ValueOf(v).Elem().Set(reflect.ValueOf(document))
+		return nil
+	}
+
+	var config Config
+	if err := config.UnmarshalYAML(unmarshal); err != nil {
+		t.Fatalf("UnmarshalYAML() error = %v", err)
+	}
+	want := map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+		"tags":   {Type: cel.ListType(cel.StringType), Kind: KindArray},
+	}
+	if !reflect.DeepEqual(config.FieldDeclarations, want) {
+		t.Errorf("FieldDeclarations = %v, want %v", config.FieldDeclarations, want)
+	}
+	if config.Dialect != DialectPostgreSQL || config.MaxInClauseSize != 20 {
+		t.Errorf("settings = %q, %d", config.Dialect, config.MaxInClauseSize)
+	}
+
+	encoded, err := config.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+	fields := encoded.(map[string]any)["fields"].(map[string]any)
+	if got := fields["tags"]; !reflect.DeepEqual(got, map[string]any{"type": "list(string)", "kind": "array"}) {
+		t.Errorf("MarshalYAML() tags = %v", got)
+	}
+}
+
+func TestConfig_UnmarshalJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "unknown key",
+			data:    `{"fields": {}, "max_depth": 3}`,
+			wantErr: `unknown field "max_depth"`,
+		},
+		{
+			name:    "unknown type",
+			data:    `{"fields": {"id": {"type": "uuid"}}}`,
+			wantErr: `field id: unknown type "uuid"`,
+		},
+		{
+			name:    "missing type",
+			data:    `{"fields": {"id": {"column": "id"}}}`,
+			wantErr: `field id: unknown type ""`,
+		},
+		{
+			name:    "malformed map type",
+			data:    `{"fields": {"m": {"type": "map(string)"}}}`,
+			wantErr: `field m: unknown type "map(string)"`,
+		},
+		{
+			name:    "invalid granularity",
+			data:    `{"fields": {"day": {"type": "timestamp", "granularity": "1d"}}}`,
+			wantErr: "field day: invalid granularity",
+		},
+		{
+			name:    "collection field",
+			data:    `{"collections": {"c": {"table": "c", "on": "x", "fields": {"f": {"type": "list"}}}}}`,
+			wantErr: `collection c: field f: unknown type "list"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			err := json.Unmarshal([]byte(tt.data), &config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_MarshalJSON_Errors(t *testing.T) {
+	config := Config{FieldDeclarations: map[string]ColumnMapping{"id": {Column: "id"}}}
+	if _, err := json.Marshal(config); err == nil || !strings.Contains(err.Error(), "field id: missing type") {
+		t.Errorf("Marshal() error = %v, want missing type", err)
+	}
+}
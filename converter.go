@@ -3,29 +3,38 @@
 package cel2squirrel
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/cel-go/cel"
-	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
 )
 
 // SecurityLogger is an interface for logging security-relevant events.
 // Implementations should log these events to a security audit log for monitoring.
+// Each method receives the context passed to ConvertContext or
+// ConvertWithAuthContext, from which trace IDs, request IDs or tenant
+// information can be extracted.
 type SecurityLogger interface {
 	// LogConversionAttempt logs an attempt to convert a CEL expression to SQL.
-	LogConversionAttempt(expr string, success bool, err error, duration time.Duration)
+	LogConversionAttempt(ctx context.Context, expr string, success bool, err error, duration time.Duration)
 
 	// LogComplexExpression logs when an expression is unusually complex.
-	LogComplexExpression(expr string, depth int, length int)
+	LogComplexExpression(ctx context.Context, expr string, depth int, length int)
 
 	// LogUnauthorizedField logs when a user attempts to access a restricted field.
-	LogUnauthorizedField(expr string, field string, userRoles []string)
+	LogUnauthorizedField(ctx context.Context, expr string, field string, userRoles []string)
 
 	// LogUnsupportedOperation logs when an unsupported CEL function is used.
-	LogUnsupportedOperation(expr string, operation string)
+	LogUnsupportedOperation(ctx context.Context, expr string, operation string)
 }
 
 // Converter converts CEL expressions to Squirrel SQL builder objects.
@@ -36,9 +45,79 @@ type Converter struct {
 	maxExpressionLength int
 	maxExpressionDepth  int
 	maxInClauseSize     int
+	inValuesThreshold   int
 	publicFields        map[string]bool
 	fieldACL            map[string][]string
 	securityLogger      SecurityLogger
+	dialect             Dialect
+	useBetween          bool
+	collapseOrToIn      bool
+	foldConstants       bool
+	canonicalize        bool
+	functions           map[string]*sqlTemplate
+	booleanStyle        BooleanStyle
+	emptyFilter         EmptyFilterMode
+	errorDetail         ErrorDetail
+	errorMessages       map[ErrorCode]string
+	timestampStrings    bool
+	keyColumns          map[string]bool
+	partialIndexes      map[string]*partialIndex
+	placeholderFormat   squirrel.PlaceholderFormat
+	corpus              *Corpus
+	quota               QuotaFunc
+	subqueries          map[string]SubqueryFunction
+	pushDownNot         bool
+	maxConversionBytes  int
+	maxExpressionCost   uint64
+	maxPredicates       int
+	maxLikeLength       int
+	maxLikeWildcards    int
+	flattenChains       bool
+	stats               *FilterStats
+	cache               *resultCache
+	caseInsensitiveLike bool
+	explicitLikeEscape  bool
+	auditor             *sqlAuditor
+	maskedFields        map[string]bool
+	schema              string
+	stringExtensions    bool
+	fallbacks           map[string]Fallback
+	tableAlias          string
+	fieldJoins          map[string]JoinSpec
+	collections         map[string]*collection
+	having              *Converter
+	flags               flagGroups
+	softDeleteField     string
+	mandatory           []squirrel.Sqlizer
+	mandatoryFunc       func(ctx context.Context) ([]squirrel.Sqlizer, error)
+	aliases             map[string]string
+	// config is the configuration the converter was created from.
+	config Config
+	// registry holds the fields registered at runtime.
+	registry *fieldRegistry
+
+	// conv holds per-call state. It is only set on the scoped copies created
+	// for a single conversion, never on the shared converter.
+	conv *conversion
+}
+
+// conversion carries the state accumulated during a single conversion.
+type conversion struct {
+	ctx        context.Context
+	warnings   []Diagnostic
+	complexity Complexity
+	// negations counts the NOT operators enclosing the current predicate.
+	negations int
+	// approximated is set when a fallback widened a predicate.
+	approximated bool
+	// joins lists the joins required by the fields read so far.
+	joins []JoinSpec
+	// trusted lists the SQL of the subqueries rendered so far, trusted by
+	// the auditor.
+	trusted []string
+	// contextual is set when the SQL depends on the context of the call,
+	// e.g. through subqueries, so that the result is not cached.
+	contextual bool
 }
 
 // Config contains configuration for the CEL to SQL converter.
@@ -64,6 +143,46 @@ type Config struct {
 	// Default: 1000. Set to 0 to apply default.
 	MaxInClauseSize int
 
+	// MaxConversionBytes caps the approximate memory materialized by a single
+	// conversion, as reported in ConvertResult.Complexity.ApproxBytes.
+	// Default: 0 (unlimited).
+	MaxConversionBytes int
+
+	// MaxLikePatternLength rejects LIKE patterns longer than this many
+	// bytes, as reported in ConvertResult.Complexity.MaxLikePatternLength.
+	// Default: 0 (unlimited).
+	MaxLikePatternLength int
+
+	// MaxLikeWildcards rejects LIKE patterns with more interior % wildcards,
+	// whose worst-case matching cost grows with each of them. Patterns built
+	// from CEL string functions have none; raw patterns passed to
+	// FunctionTemplate LIKE arguments may. Default: 0 (unlimited).
+	MaxLikeWildcards int
+
+	// MaxPredicates rejects filters combining more predicates, i.e.
+	// comparisons, LIKE patterns, IN lists and other leaves of their
+	// logical operators, as reported in ConvertResult.Complexity.Predicates.
+	// It catches wide but shallow filters, such as thousands of ORed
+	// equalities, which the depth and length limits let through.
+	// Default: 0 (unlimited).
+	MaxPredicates int
+
+	// MaxExpressionCost rejects filters whose worst-case evaluation cost, as
+	// estimated by cel-go and reported in ConvertResult.Complexity.Cost,
+	// exceeds the budget. String, bytes, list and map fields are assumed to
+	// hold up to 1024 elements. Estimating the cost allocates, so it is only
+	// done when set. Default: 0 (unlimited).
+	MaxExpressionCost uint64
+
+	// InValuesThreshold materializes IN lists with more values than the
+	// threshold as a VALUES table, e.g.
+	// status IN (SELECT v.x FROM (VALUES (?),(?),...) AS v(x)): a semi-join
+	// planners hash rather than comparing each value in turn, which escapes
+	// the limits some databases put on IN lists, such as Oracle's 1000
+	// expressions. Each value is still bound as a parameter.
+	// Default: 0 (disabled).
+	InValuesThreshold int
+
 	// Authorization settings for field-level access control
 	// PublicFields is a list of field names that any user can filter by.
 	// If empty, authorization checks are disabled.
@@ -72,6 +191,195 @@ type Config struct {
 	// FieldACL maps field names to lists of roles that can access them.
 	// Only checked if PublicFields is not empty.
 	FieldACL map[string][]string
+
+	// SecurityLogger receives the security events of conversions: every
+	// conversion attempt with its outcome and duration, complex
+	// expressions, unauthorized fields and unsupported operations.
+	// Default: none.
+	SecurityLogger SecurityLogger
+
+	// Dialect selects the SQL flavour used for dialect-specific constructs
+	// such as CAST type names. Default: DialectDefault (ANSI SQL).
+	Dialect Dialect
+
+	// PlaceholderFormat is the placeholder format of the SQL rendered by
+	// ConvertResult.ToSql, e.g. squirrel.Dollar for $1, $2. Default: the
+	// dialect's, see Dialect.PlaceholderFormat. ConvertResult.Where always
+	// renders ? placeholders, replaced by the query builder it is added to.
+	PlaceholderFormat squirrel.PlaceholderFormat
+
+	// UseBetween rewrites inclusive range checks on the same column, such as
+	// `age >= 18 && age <= 30`, into `age BETWEEN ? AND ?`. Default: false.
+	UseBetween bool
+
+	// CollapseOrToIn rewrites OR-chains of equalities on the same column, such
+	// as `status == "a" || status == "b"`, into `status IN (?,?)`. Chains
+	// that would exceed MaxInClauseSize are left untouched. Default: false.
+	CollapseOrToIn bool
+
+	// FoldConstants simplifies boolean literals out of the generated SQL:
+	// `true && x` becomes `x`, `false || x` becomes `x` and `false && x`
+	// becomes FALSE. ConvertResult.AlwaysTrue and AlwaysFalse are reported
+	// regardless of this setting. Default: false.
+	FoldConstants bool
+
+	// Canonicalize rewrites filters into a canonical form before conversion,
+	// so that semantically identical filters produce identical SQL text and
+	// share prepared statements and plans: the operands of && and || are
+	// sorted and deduplicated, literals are moved to the right of
+	// comparisons, comparisons with boolean literals are dropped, the
+	// literals of in lists are sorted and deduplicated, and double negations
+	// are collapsed. Default: false.
+	Canonicalize bool
+
+	// Functions declares custom CEL functions translated through SQL
+	// templates. See FunctionTemplate.
+	Functions []FunctionTemplate
+
+	// PushDownNot pushes negations into the predicates they apply to instead
+	// of wrapping them in NOT (...): `!(a == 1)` becomes `a <> ?`,
+	// `!(x && y)` becomes `(!x OR !y)` and `!label.contains("x")` becomes
+	// `label NOT LIKE ?`, which is friendlier to indexes. Default: false.
+	PushDownNot bool
+
+	// FlattenLogicalChains collapses associative chains such as `a && b && c`
+	// into a single AND (or OR) with all operands, producing
+	// `(a = ? AND b = ? AND c = ?)` instead of nested parentheses.
+	// Default: false.
+	FlattenLogicalChains bool
+
+	// BooleanStyle selects how standalone boolean fields are tested: with a
+	// bound true, IS TRUE, the TRUE literal or the bare column.
+	// Default: BooleanBound (is_draft = ?).
+	BooleanStyle BooleanStyle
+
+	// EmptyFilter selects how empty or whitespace-only expressions are
+	// converted: rejected as invalid, matching every row, or rejected with
+	// ErrEmptyFilter. Default: EmptyFilterInvalid.
+	EmptyFilter EmptyFilterMode
+
+	// ErrorDetail selects whether conversion error messages include the
+	// internal error, e.g. compiler diagnostics, for development
+	// environments. Default: ErrorDetailSanitized.
+	ErrorDetail ErrorDetail
+
+	// ErrorMessages replaces the public messages of conversion errors by
+	// error code, e.g. to translate or re-word them. Codes without an entry
+	// keep the default message.
+	ErrorMessages map[ErrorCode]string
+
+	// CompatLevel enables the output behaviors introduced up to the given
+	// level, on top of those enabled individually. Default: CompatV1.
+	CompatLevel CompatLevel
+
+	// CaseInsensitiveLike makes contains(), startsWith() and endsWith()
+	// case-insensitive: ILIKE on PostgreSQL, LOWER(col) LIKE LOWER(?)
+	// elsewhere. Default: false.
+	CaseInsensitiveLike bool
+
+	// ExplicitLikeEscape appends ESCAPE '\' to LIKE matches against escaped
+	// literal patterns, so that the escaping of wildcards is honored on
+	// databases whose default escape character is not the backslash. It is
+	// always applied on SQLite, which has none. Default: false.
+	ExplicitLikeEscape bool
+
+	// AuditSQL re-verifies the SQL of every conversion: outside of
+	// placeholders it may only contain keywords, operators and the tokens of
+	// mapped columns and function templates. Violations fail the conversion
+	// with AUDIT_VIOLATION. It acts as a tripwire against identifiers leaking
+	// from user input, at the cost of rendering the SQL once more.
+	// Default: false.
+	AuditSQL bool
+
+	// StringExtensions enables the cel-go strings extension library. Its
+	// trim(), lowerAscii(), upperAscii(), replace() with constant arguments
+	// and indexOf() are translated to SQL; the other functions of the
+	// library are left to residual filters. Default: false.
+	StringExtensions bool
+
+	// TimestampStrings accepts RFC 3339 string literals compared with
+	// timestamp fields, e.g. `created_at >= "2024-01-01T00:00:00Z"`, as if
+	// wrapped in timestamp(). Invalid strings are rejected with
+	// INVALID_TIMESTAMP. Default: false (timestamp() is required).
+	TimestampStrings bool
+
+	// Fallbacks configures, per CEL function name, how predicates using a
+	// function without SQL translation are handled instead of being
+	// rejected, e.g. {"matches": FallbackLiteralContains}. See Fallback.
+	Fallbacks map[string]Fallback
+
+	// TableAlias qualifies the columns of all fields without a Table of
+	// their own, so that predicates read p.status = ? instead of
+	// status = ?, avoiding ambiguities in queries with JOINs. Fields mapped
+	// to an Expr are not qualified. Default: "" (bare columns).
+	TableAlias string
+
+	// Collections declares one-to-many relations, filtered with the
+	// exists() and all() macros translated to EXISTS subqueries, e.g.
+	// `comments.exists(c, c.flagged)`. See Collection. Field-level
+	// authorization applies to the collection name.
+	Collections map[string]Collection
+
+	// AggregateFields declares the aggregate pseudo-fields filtered by
+	// ConvertHaving, e.g. "count": {Function: AggregateCount} and
+	// "sum_amount": {Function: AggregateSum, Field: "amount"}.
+	AggregateFields map[string]Aggregate
+
+	// FlagGroups declares groups of boolean fields, e.g.
+	// "state": {"is_draft", "is_archived", "is_deleted"}, enabling the
+	// anyOf() macro: `anyOf(is_draft, is_archived)` is true when any of the
+	// flags is set and translates to (is_draft = TRUE OR is_archived = TRUE).
+	// The flags of a single call must belong to the same group.
+	FlagGroups map[string][]string
+
+	// Subqueries declares functions standing for the values selected by a
+	// SQL subquery, e.g. `orgId in allowedOrgs()`. See SubqueryFunction.
+	Subqueries []SubqueryFunction
+
+	// KeyFields lists the fields identifying rows, such as "id", whose
+	// equality makes a filter a ClassPointLookup. See ConvertResult.Class.
+	KeyFields []string
+
+	// Quota, when set, is consulted with the class of every converted
+	// filter, which it may reject with QUOTA_EXCEEDED, e.g. to throttle
+	// text searches. Scope predicates are classified with the filter.
+	Quota QuotaFunc
+
+	// Stats, when set, records the columns and operators used by every
+	// successful conversion, e.g. to derive index suggestions.
+	Stats *FilterStats
+
+	// CacheSize enables an LRU cache of the results of Convert, keyed by
+	// expression, holding up to CacheSize results so that repeated filters
+	// skip compilation and conversion. Mandatory conditions, Quota, Stats
+	// and Corpus still apply to cached results. Default: 0 (no cache).
+	CacheSize int
+
+	// CacheTTL expires cached results after the given duration. Default: 0
+	// (results are only evicted to make room).
+	CacheTTL time.Duration
+
+	// Corpus, when set, records the normalized and redacted shape of every
+	// filter converted successfully, to be exported as a regression corpus.
+	// See Corpus.
+	Corpus *Corpus
+
+	// SoftDeleteField names a field marking deleted rows, e.g. "deleted_at".
+	// Conversions of expressions not referencing it only match the rows
+	// where it is NULL, like ORMs do; expressions referencing it, e.g.
+	// `deleted_at != null`, choose themselves. It must be declared on the
+	// filtered table. Default: "" (no soft deletion).
+	SoftDeleteField string
+
+	// MandatoryConditions are trusted predicates ANDed onto the WHERE clause
+	// of every conversion, e.g. squirrel.Eq{"deleted_at": nil}, so that row
+	// scoping cannot be forgotten at call sites.
+	MandatoryConditions []squirrel.Sqlizer
+
+	// MandatoryConditionsFunc, when set, returns additional mandatory
+	// conditions from the context of each call, e.g. the tenant of the
+	// request. Its errors fail the conversion with SCOPE_UNAVAILABLE.
+	MandatoryConditionsFunc func(ctx context.Context) ([]squirrel.Sqlizer, error)
 }
 
 // ColumnMapping is a mapping of a CEL field name to a SQL column name.
@@ -80,6 +388,81 @@ type ColumnMapping struct {
 	Type *cel.Type
 	// Column is the name of the SQL column.
 	Column string
+	// Granularity is the finest precision supported when filtering a timestamp
+	// field, e.g. 24*time.Hour for day-level rollup tables. Literals with a
+	// finer precision are rejected. Zero allows any precision.
+	Granularity time.Duration
+	// TruncateToGranularity truncates finer-grained timestamp literals to the
+	// field's Granularity, reporting a warning, instead of rejecting them.
+	TruncateToGranularity bool
+	// Kind describes how a list field is stored. Default: KindJSON.
+	Kind ColumnKind
+	// Collation is the collation the string field is compared with, e.g.
+	// "und-x-icu" for PostgreSQL ICU collations, rendered as
+	// col COLLATE "und-x-icu" = ?, or utf8mb4_0900_ai_ci for case- and
+	// accent-insensitive matching on MySQL. Names are quoted on PostgreSQL
+	// and in ANSI SQL, and must be bare words on other dialects.
+	Collation string
+	// CaseInsensitive makes contains(), startsWith() and endsWith() on the
+	// field case-insensitive, as Config.CaseInsensitiveLike does for all
+	// fields.
+	CaseInsensitive bool
+	// Masked fields may be filtered on, but their values are never echoed:
+	// the literals compared with them are replaced with a truncated SHA-256
+	// digest in error details, warnings, SecurityLogger events and residual
+	// filter sources.
+	Masked bool
+	// Expr is a SQL expression computing the field, used in place of a
+	// column, e.g. "LOWER(email)" or "first_name || ' ' || last_name". It
+	// is trusted configuration, but must not contain placeholders,
+	// comments, statement separators or subqueries. Expressions other than
+	// a single identifier, call or parenthesized group are wrapped in
+	// parentheses. Mutually exclusive with Column, Table and JSONPath.
+	Expr string
+	// Table qualifies the column with a table name or alias, e.g. "p" for
+	// p.status, overriding Config.TableAlias. Columns containing a dot are
+	// considered qualified already.
+	Table string
+	// JSONPath addresses a value nested in the JSON document stored in
+	// Column, as dot-separated keys, e.g. "address.city". Int, uint and
+	// double fields are cast to a numeric SQL type. Requires a dialect.
+	JSONPath string
+	// Join declares that the field lives on another table, e.g. an
+	// author.name field read from users joined on
+	// prompts.author_id = users.id. The column is qualified with the join's
+	// Alias or Table, which Table must match if set, and
+	// ConvertResult.Joins lists the joins required by a filter.
+	Join *JoinSpec
+	// PartialIndex declares that the column is only indexed for the rows
+	// matching a condition. Filters on the field falling outside of it are
+	// reported in ConvertResult.Warnings. See PartialIndex.
+	PartialIndex *PartialIndex
+	// Aliases are alternative names of the field, e.g. the names it had
+	// before an API rename, sharing its column and authorization.
+	Aliases []string
+	// DeprecatedAliases marks the field's Aliases Deprecated, replaced by
+	// the field.
+	DeprecatedAliases bool
+	// Deprecated fields may still be filtered on, but are reported in
+	// ConvertResult.Deprecations and Warnings, e.g. to track their use
+	// before removing them.
+	Deprecated bool
+	// ReplacedBy names the field to filter on instead of a Deprecated one.
+	ReplacedBy string
+	// AllowedOps restricts the operators and functions applicable to the
+	// field, by their CEL syntax as in ConvertResult.Operators, e.g.
+	// []string{"==", "in"} for an identifier or []string{"contains"} for a
+	// description. Filters applying others are rejected. Fields compared
+	// on the right are checked against the mirrored comparison: `3 < age`
+	// applies > to age. Empty allows all.
+	AllowedOps []string
+	// Transform normalizes the values the field is compared with, with ==,
+	// !=, <, <=, >, >= or in, before they are bound, e.g. to lowercase
+	// emails or map external IDs to internal ones. Values are type-checked
+	// before being transformed, and null is never transformed. Errors reject
+	// the filter with INVALID_VALUE. Transforms are not serialized by
+	// Config.MarshalJSON.
+	Transform func(value any) (any, error)
 }
 
 // DefaultConfig returns a Config with secure default values.
@@ -94,6 +477,19 @@ func DefaultConfig() Config {
 
 // NewConverter creates a new CEL to SQL converter with the given configuration.
 func NewConverter(config Config) (*Converter, error) {
+	declared := config
+	config, err := config.applyCompatLevel()
+	if err != nil {
+		return nil, err
+	}
+	config, aliases, err := expandAliases(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReplacements(config.FieldDeclarations); err != nil {
+		return nil, fmt.Errorf("invalid field declaration: %w", err)
+	}
+
 	// Apply secure defaults for zero values
 	if config.MaxExpressionLength == 0 {
 		config.MaxExpressionLength = 10000
@@ -115,45 +511,318 @@ func NewConverter(config Config) (*Converter, error) {
 			if mapping.Type != nil {
 				opts = append(opts, cel.Variable(name, mapping.Type))
 			}
+			table, err := fieldTable(name, mapping, config.TableAlias)
+			if err != nil {
+				return nil, fmt.Errorf("invalid field declaration: %w", err)
+			}
+
 			// Store column mapping (use column name if specified, otherwise use field name)
-			if mapping.Column != "" {
-				columnMappings[name] = mapping.Column
+			if mapping.Expr != "" {
+				column, err := sqlExprColumn(name, mapping)
+				if err != nil {
+					return nil, fmt.Errorf("invalid field declaration: %w", err)
+				}
+				columnMappings[name] = column
+			} else if mapping.JSONPath != "" {
+				if mapping.Column != "" {
+					mapping.Column = qualifyColumn(table, mapping.Column)
+				} else {
+					mapping.Column = qualifyColumn(table, name)
+				}
+				column, err := jsonPathColumn(name, mapping, config.Dialect)
+				if err != nil {
+					return nil, fmt.Errorf("invalid field declaration: %w", err)
+				}
+				columnMappings[name] = column
+			} else if mapping.Column != "" {
+				columnMappings[name] = qualifyColumn(table, mapping.Column)
 			} else {
-				columnMappings[name] = name
+				columnMappings[name] = qualifyColumn(table, name)
+			}
+
+			if mapping.Collation != "" {
+				if mapping.Type == nil || !mapping.Type.IsExactType(cel.StringType) {
+					return nil, fmt.Errorf("invalid field declaration: field %s: collations only apply to string fields", name)
+				}
+				column, err := config.Dialect.collate(columnMappings[name], mapping.Collation)
+				if err != nil {
+					return nil, fmt.Errorf("invalid field declaration: field %s: %w", name, err)
+				}
+				columnMappings[name] = column
 			}
 		}
 	}
 
+	opts = append(opts, cel.OptionalTypes())
+	opts = append(opts, filterFunctions()...)
+	opts = append(opts, geoFunctions()...)
+	collectionOpts, err := collectionDeclarations(config.Collections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare collections: %w", err)
+	}
+	opts = append(opts, collectionOpts...)
+	if config.StringExtensions {
+		opts = append(opts, ext.Strings())
+	}
+
+	// Add the anyOf() macro over flag groups
+	var flags flagGroups
+	if len(config.FlagGroups) > 0 {
+		flags, err = newFlagGroups(config.FlagGroups, config.FieldDeclarations)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flag groups: %w", err)
+		}
+		opts = append(opts, flags.declarations()...)
+	}
+
+	fieldJoins, err := newFieldJoins(config.FieldDeclarations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field declaration: %w", err)
+	}
+
+	collections, err := newCollections(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection: %w", err)
+	}
+
+	var having *Converter
+	if len(config.AggregateFields) > 0 {
+		if having, err = newHavingConverter(config, columnMappings); err != nil {
+			return nil, fmt.Errorf("invalid aggregate declaration: %w", err)
+		}
+	}
+
+	if err := config.BooleanStyle.validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := config.EmptyFilter.validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := config.ErrorDetail.validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := validateErrorMessages(config.ErrorMessages); err != nil {
+		return nil, fmt.Errorf("invalid error messages: %w", err)
+	}
+
+	if err := validateFallbacks(config.Fallbacks); err != nil {
+		return nil, fmt.Errorf("invalid fallbacks: %w", err)
+	}
+
+	// Add templated custom functions
+	functions := make(map[string]*sqlTemplate, len(config.Functions))
+	for _, fn := range config.Functions {
+		tmpl, err := parseSQLTemplate(fn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid function template: %w", err)
+		}
+		if _, exists := functions[fn.Name]; exists {
+			return nil, fmt.Errorf("invalid function template: duplicate function %s", fn.Name)
+		}
+		functions[fn.Name] = tmpl
+		opts = append(opts, fn.declaration())
+	}
+
+	subqueries, subqueryOpts, err := newSubqueries(config.Subqueries, config.FieldDeclarations)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, subqueryOpts...)
+
 	env, err := cel.NewEnv(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
 	}
 
+	maskedFields := make(map[string]bool)
+	for name, mapping := range config.FieldDeclarations {
+		if mapping.Masked {
+			maskedFields[name] = true
+		}
+	}
+
+	keyColumns := make(map[string]bool, len(config.KeyFields))
+	for _, field := range config.KeyFields {
+		column, ok := columnMappings[field]
+		if !ok {
+			return nil, fmt.Errorf("invalid key field: field %s is not declared", field)
+		}
+		keyColumns[column] = true
+	}
+
 	// Build public fields map for O(1) lookup
 	publicFields := make(map[string]bool)
 	for _, field := range config.PublicFields {
 		publicFields[field] = true
 	}
 
-	return &Converter{
+	var auditor *sqlAuditor
+	if config.AuditSQL {
+		var subqueries []string
+		for _, coll := range collections {
+			subqueries = append(subqueries, coll.subquery())
+		}
+		if config.InValuesThreshold > 0 {
+			subqueries = append(subqueries, config.Dialect.valuesFragment())
+		}
+		auditor = newSQLAuditor(columnMappings, functions, subqueries...)
+	}
+
+	placeholderFormat := config.PlaceholderFormat
+	if placeholderFormat == nil {
+		placeholderFormat = config.Dialect.PlaceholderFormat()
+	}
+
+	if field := config.SoftDeleteField; field != "" {
+		mapping, ok := config.FieldDeclarations[field]
+		if !ok {
+			return nil, fmt.Errorf("soft delete field %s is not declared", field)
+		}
+		if mapping.Join != nil {
+			return nil, fmt.Errorf("soft delete field %s must be a field of the filtered table", field)
+		}
+	}
+	for i, condition := range config.MandatoryConditions {
+		if condition == nil {
+			return nil, fmt.Errorf("mandatory condition %d is nil", i)
+		}
+	}
+
+	converter := &Converter{
 		env:                 env,
 		columnMappings:      columnMappings,
 		fieldDeclarations:   config.FieldDeclarations,
 		maxExpressionLength: config.MaxExpressionLength,
 		maxExpressionDepth:  config.MaxExpressionDepth,
 		maxInClauseSize:     config.MaxInClauseSize,
+		inValuesThreshold:   config.InValuesThreshold,
 		publicFields:        publicFields,
 		fieldACL:            config.FieldACL,
-	}, nil
+		dialect:             config.Dialect,
+		useBetween:          config.UseBetween,
+		collapseOrToIn:      config.CollapseOrToIn,
+		foldConstants:       config.FoldConstants,
+		canonicalize:        config.Canonicalize,
+		functions:           functions,
+		booleanStyle:        config.BooleanStyle,
+		emptyFilter:         config.EmptyFilter,
+		errorDetail:         config.ErrorDetail,
+		errorMessages:       config.ErrorMessages,
+		timestampStrings:    config.TimestampStrings,
+		placeholderFormat:   placeholderFormat,
+		keyColumns:          keyColumns,
+		quota:               config.Quota,
+		softDeleteField:     config.SoftDeleteField,
+		mandatory:           slices.Clone(config.MandatoryConditions),
+		mandatoryFunc:       config.MandatoryConditionsFunc,
+		securityLogger:      config.SecurityLogger,
+		config:              declared,
+		aliases:             aliases,
+		registry:            &fieldRegistry{},
+		subqueries:          subqueries,
+		pushDownNot:         config.PushDownNot,
+		maxConversionBytes:  config.MaxConversionBytes,
+		maxExpressionCost:   config.MaxExpressionCost,
+		maxPredicates:       config.MaxPredicates,
+		maxLikeLength:       config.MaxLikePatternLength,
+		maxLikeWildcards:    config.MaxLikeWildcards,
+		flattenChains:       config.FlattenLogicalChains,
+		stats:               config.Stats,
+		cache:               newResultCache(config.CacheSize, config.CacheTTL),
+		corpus:              config.Corpus,
+		caseInsensitiveLike: config.CaseInsensitiveLike,
+		explicitLikeEscape:  config.ExplicitLikeEscape,
+		auditor:             auditor,
+		maskedFields:        maskedFields,
+		schema:              schemaFingerprint(config, columnMappings, collections),
+		stringExtensions:    config.StringExtensions,
+		fallbacks:           config.Fallbacks,
+		tableAlias:          config.TableAlias,
+		fieldJoins:          fieldJoins,
+		collections:         collections,
+		having:              having,
+		flags:               flags,
+	}
+	if err := converter.initPartialIndexes(config.FieldDeclarations); err != nil {
+		return nil, fmt.Errorf("invalid partial index: %w", err)
+	}
+	return converter, nil
 }
 
 // ConvertResult contains the result of converting a CEL expression to SQL.
 type ConvertResult struct {
-	// Where is the Squirrel Sqlizer that can be used in WHERE clauses
-	Where squirrel.Sqlizer
+	// Where is the Sqlizer that can be used in WHERE clauses
+	Where Sqlizer
 
 	// Args contains any arguments that need to be bound to the query
 	Args []interface{}
+
+	// Warnings lists the non-fatal issues found during conversion, such as
+	// timestamp literals truncated to a field's granularity or LIKE patterns
+	// unable to use an index.
+	Warnings []Diagnostic
+
+	// AlwaysTrue reports that the filter matches every row, e.g. `true || x`.
+	AlwaysTrue bool
+
+	// AlwaysFalse reports that the filter can never match, e.g. `false && x`,
+	// so callers may skip the query entirely.
+	AlwaysFalse bool
+
+	// Complexity reports the cost of the conversion.
+	Complexity Complexity
+
+	// Joins lists the joins required by the relation fields the filter
+	// reads, in order of first use. See ApplyJoins.
+	Joins []JoinSpec
+
+	// Class classifies the filter by the kind of query it produces, e.g.
+	// to enforce quotas per class. See Config.Quota.
+	Class ExpressionClass
+
+	// Fields lists the declared fields and collections the filter
+	// references, sorted.
+	Fields []string
+
+	// Columns lists the SQL columns, or expressions of computed fields, of
+	// the referenced fields, sorted.
+	Columns []string
+
+	// Operators lists the operators and functions the filter uses by their
+	// CEL syntax, sorted, e.g. ["&&", "==", "startsWith"].
+	Operators []string
+
+	// Deprecations lists the Deprecated fields the filter references,
+	// sorted by field.
+	Deprecations []Deprecation
+
+	// expr is the converted expression and schema the fingerprint of the
+	// converter's configuration, both used by CacheKey.
+	expr   celast.Expr
+	schema string
+	// placeholder is the placeholder format of ToSql.
+	placeholder squirrel.PlaceholderFormat
+	// contextual reports that the SQL depends on the context of the call.
+	contextual bool
+}
+
+// ToSql renders the WHERE clause with the placeholder format of the
+// converter, e.g. status = $1 with squirrel.Dollar, for callers using the
+// filter without a query builder. Add Where, not the result, to query
+// builders, which replace placeholders themselves.
+func (r *ConvertResult) ToSql() (string, []interface{}, error) {
+	if r.Where == nil {
+		return "", nil, fmt.Errorf("result has no WHERE clause")
+	}
+	sql, args, err := r.Where.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	if r.placeholder == nil {
+		return sql, args, nil
+	}
+	sql, err = r.placeholder.ReplacePlaceholders(sql)
+	return sql, args, err
 }
 
 // ConversionError represents an error that occurred during CEL to SQL conversion.
@@ -163,8 +832,11 @@ type ConversionError struct {
 	PublicMessage string
 	// InternalError contains the detailed error for internal logging.
 	InternalError error
-	// ErrorCode is a machine-readable error code.
-	ErrorCode string
+	// ErrorCode is a machine-readable error code, e.g. CodeInvalidSyntax.
+	ErrorCode ErrorCode
+	// Location locates the cause of the error in the expression, when
+	// known, e.g. for the syntax and type errors of INVALID_SYNTAX.
+	Location *SourceLocation
 }
 
 // Error implements the error interface, returning the public message.
@@ -178,7 +850,7 @@ func (e *ConversionError) Unwrap() error {
 }
 
 // newConversionError creates a ConversionError with a sanitized public message.
-func newConversionError(publicMsg, errorCode string, internalErr error) error {
+func newConversionError(publicMsg string, errorCode ErrorCode, internalErr error) error {
 	return &ConversionError{
 		PublicMessage: publicMsg,
 		ErrorCode:     errorCode,
@@ -186,212 +858,350 @@ func newConversionError(publicMsg, errorCode string, internalErr error) error {
 	}
 }
 
+// asConversionError returns the ConversionError wrapped by err. Other errors
+// are internal details of an unsupported expression shape, such as a
+// comparison between two literals, and are sanitized accordingly.
+func asConversionError(err error) error {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return convErr
+	}
+	return newConversionError("unsupported filter operation", CodeUnsupportedOperation, err)
+}
+
+// checkLength enforces the maximum expression length.
+func (c *Converter) checkLength(celExpr string) error {
+	if len(celExpr) <= c.maxExpressionLength {
+		return nil
+	}
+	return newConversionError(
+		fmt.Sprintf("filter expression exceeds maximum length of %d characters", c.maxExpressionLength),
+		CodeLimitLength,
+		fmt.Errorf("expression exceeds maximum length of %d characters (got %d)", c.maxExpressionLength, len(celExpr)),
+	)
+}
+
+// checkDepth enforces the maximum expression depth.
+func (c *Converter) checkDepth(depth int) error {
+	if depth <= c.maxExpressionDepth {
+		return nil
+	}
+	return newConversionError(
+		fmt.Sprintf("filter expression exceeds maximum depth of %d", c.maxExpressionDepth),
+		CodeLimitDepth,
+		fmt.Errorf("expression exceeds maximum depth of %d (got %d)", c.maxExpressionDepth, depth),
+	)
+}
+
+// checkPredicates enforces the maximum number of predicates of expressions.
+func (c *Converter) checkPredicates(count int) error {
+	if c.maxPredicates == 0 || count <= c.maxPredicates {
+		return nil
+	}
+	return newConversionError(
+		fmt.Sprintf("filter expression exceeds maximum of %d predicates", c.maxPredicates),
+		CodeLimitPredicates,
+		fmt.Errorf("expression has %d predicates, exceeding maximum of %d", count, c.maxPredicates),
+	)
+}
+
+// checkInClauseSize enforces the maximum number of values of IN clauses.
+func (c *Converter) checkInClauseSize(size int) error {
+	if size <= c.maxInClauseSize {
+		return nil
+	}
+	return newConversionError(
+		fmt.Sprintf("IN clause exceeds maximum of %d values", c.maxInClauseSize),
+		CodeLimitInSize,
+		fmt.Errorf("IN clause size %d exceeds maximum of %d", size, c.maxInClauseSize),
+	)
+}
+
 // Convert parses a CEL expression and converts it to a Squirrel SQL builder object.
 // It validates that the expression is boolean and returns a Sqlizer that can be used
 // in WHERE clauses. Column mappings are automatically applied based on the converter's
-// configuration.
-func (c *Converter) Convert(celExpr string) (*ConvertResult, error) {
-	var convErr error
+// configuration, or by options such as WithMappingOverlay.
+func (c *Converter) Convert(celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+	return c.ConvertContext(context.Background(), celExpr, opts...)
+}
+
+// ConvertContext is like Convert, passing ctx to the SecurityLogger. The
+// conversion fails with CANCELED once ctx is canceled or past its deadline.
+func (c *Converter) ConvertContext(ctx context.Context, celExpr string, opts ...ConvertOption) (result *ConvertResult, err error) {
+	c = c.current()
+	defer c.logAttempt(ctx, celExpr, time.Now(), &err)
+
+	c, err = c.withOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err = c.convertCached(ctx, celExpr)
+	result, err = c.finalize(ctx, celExpr, result, err)
+	return c.maskOutput(celExpr, result, err)
+}
+
+// convertChecked converts a validated expression tree of the given
+// estimated cost into a ConvertResult.
+func (c *Converter) convertChecked(ctx context.Context, expr celast.Expr, cost uint64) (*ConvertResult, error) {
+	if err := c.checkAllowedOps(expr); err != nil {
+		return nil, err
+	}
+
+	folded := foldConstants(expr)
+	if c.foldConstants {
+		expr = folded
+	}
+	if c.canonicalize {
+		expr = canonicalize(expr)
+	}
+
+	scoped := c.scoped(ctx)
+	sqlizer, err := scoped.convertExpr(expr)
+	if err != nil {
+		return nil, asConversionError(fmt.Errorf("failed to convert CEL to SQL: %w", err))
+	}
+
+	if c.auditor != nil {
+		if err := c.auditor.audit(sqlizer, scoped.conv.trusted...); err != nil {
+			return nil, asConversionError(err)
+		}
+	}
+
+	result := &ConvertResult{
+		Where:       sqlizer,
+		Args:        []interface{}{},
+		Warnings:    scoped.conv.warnings,
+		Complexity:  scoped.conv.complexity,
+		Joins:       scoped.conv.joins,
+		expr:        expr,
+		schema:      c.schema,
+		placeholder: c.placeholderFormat,
+		contextual:  scoped.conv.contextual,
+	}
+	result.Complexity.Depth = c.calculateExpressionDepth(expr)
+	result.Complexity.Cost = cost
+	c.describe(expr, result)
+	if value, ok := boolConstant(folded); ok {
+		result.AlwaysTrue = value
+		result.AlwaysFalse = !value
+	}
+	result.Class = c.classify(expr, result.AlwaysFalse)
 
+	if c.stats != nil {
+		c.stats.Record(c.columnUsages(expr))
+	}
+	return result, nil
+}
+
+// compile parses and type-checks a CEL filter expression, enforcing the
+// configured length, depth, predicate and cost limits. It returns both the checked AST
+// and its native representation used for navigation during conversion.
+func (c *Converter) compile(ctx context.Context, celExpr string) (*cel.Ast, *checkedFilter, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, nil, err
+	}
 	// SECURITY: Validate expression length immediately
-	if len(celExpr) > c.maxExpressionLength {
-		convErr = fmt.Errorf("expression exceeds maximum length of %d characters (got %d)",
-			c.maxExpressionLength, len(celExpr))
-		return nil, convErr
+	if err := c.checkLength(celExpr); err != nil {
+		return nil, nil, err
+	}
+	celExpr, err := c.resolveEmpty(celExpr)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Parse the CEL expression
-	compiled, issues := c.env.Compile(celExpr)
+	compiled, issues := c.compileCEL(celExpr)
 	if issues != nil && issues.Err() != nil {
 		// SECURITY: Sanitize error - don't expose field names or internal details
-		convErr = newConversionError(
-			"invalid filter expression syntax",
-			"INVALID_SYNTAX",
-			fmt.Errorf("CEL compilation failed: %w", issues.Err()),
-		)
-		return nil, convErr
+		return nil, nil, compileError(celExpr, "CEL compilation", issues)
+	}
+
+	checkedExpr, err := c.checkCompiled(ctx, celExpr, compiled)
+	if err != nil {
+		return nil, nil, err
+	}
+	return compiled, checkedExpr, nil
+}
+
+// checkCompiled validates a type-checked CEL expression, enforcing the
+// configured depth, predicate and cost limits, and returns its native
+// representation.
+func (c *Converter) checkCompiled(ctx context.Context, celExpr string, compiled *cel.Ast) (*checkedFilter, error) {
+	// Compilation may have outlived the context
+	if err := checkContext(ctx); err != nil {
+		return nil, err
 	}
 
 	// Validate that the expression returns a boolean
 	if compiled.OutputType() != cel.BoolType {
 		// SECURITY: Sanitize error - don't expose type system details
-		convErr = newConversionError(
+		return nil, newConversionError(
 			"filter expression must evaluate to boolean",
-			"INVALID_TYPE",
+			CodeInvalidType,
 			fmt.Errorf("expected boolean, got %v", compiled.OutputType()),
 		)
-		return nil, convErr
 	}
 
-	// Convert AST to checked expression to get the protobuf representation
-	// Note: We use protobuf types internally for navigation, but they're not exposed in the public API
-	checkedExpr, err := cel.AstToCheckedExpr(compiled)
-	if err != nil {
-		convErr = fmt.Errorf("failed to convert AST to checked expression: %w", err)
-		return nil, convErr
-	}
+	// Navigate the native AST, without copying it to its protobuf form
+	checkedExpr := compiled.NativeRep()
 
 	// SECURITY: Validate expression complexity (depth)
-	depth := c.calculateExpressionDepth(checkedExpr.GetExpr())
-	if depth > c.maxExpressionDepth {
-		convErr = fmt.Errorf("expression exceeds maximum depth of %d (got %d)",
-			c.maxExpressionDepth, depth)
-		return nil, convErr
+	depth := c.calculateExpressionDepth(checkedExpr.Expr())
+	if err := c.checkDepth(depth); err != nil {
+		return nil, err
+	}
+	if err := c.checkPredicates(countPredicates(checkedExpr.Expr())); err != nil {
+		return nil, err
+	}
+
+	// SECURITY: Validate the estimated evaluation cost
+	cost, err := c.estimateCost(checkedExpr)
+	if err != nil {
+		return nil, err
 	}
 
 	// SECURITY: Log if expression is unusually complex
 	if c.securityLogger != nil && (depth > c.maxExpressionDepth/2 || len(celExpr) > c.maxExpressionLength/2) {
 		c.securityLogger.LogComplexExpression(
-			celExpr,
+			ctx,
+			c.logExpr(celExpr),
 			depth,
 			len(celExpr),
 		)
 	}
 
-	sqlizer, err := c.convertExpr(checkedExpr.GetExpr())
-	if err != nil {
-		convErr = fmt.Errorf("failed to convert CEL to SQL: %w", err)
-		return nil, convErr
-	}
-
-	return &ConvertResult{
-		Where: sqlizer,
-		Args:  []interface{}{},
-	}, nil
+	return &checkedFilter{AST: checkedExpr, cost: cost}, nil
 }
 
 // ConvertWithAuth converts a CEL expression to SQL with field-level authorization.
 // It checks that the user (identified by their roles) is authorized to filter by
 // all fields referenced in the expression. If authorization is not configured
 // (PublicFields is empty), this behaves the same as Convert().
-func (c *Converter) ConvertWithAuth(celExpr string, userRoles []string) (*ConvertResult, error) {
+func (c *Converter) ConvertWithAuth(celExpr string, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	return c.ConvertWithAuthContext(context.Background(), celExpr, userRoles, opts...)
+}
+
+// ConvertWithAuthContext is like ConvertWithAuth, passing ctx to the
+// SecurityLogger.
+func (c *Converter) ConvertWithAuthContext(ctx context.Context, celExpr string, userRoles []string, opts ...ConvertOption) (result *ConvertResult, err error) {
+	c = c.current()
 	// If authorization is not configured, use standard Convert
 	if len(c.publicFields) == 0 && len(c.fieldACL) == 0 {
-		return c.Convert(celExpr)
+		return c.ConvertContext(ctx, celExpr, opts...)
 	}
+	defer c.logAttempt(ctx, celExpr, time.Now(), &err)
 
-	// First validate expression length
-	if len(celExpr) > c.maxExpressionLength {
-		return nil, fmt.Errorf("expression exceeds maximum length of %d characters (got %d)",
-			c.maxExpressionLength, len(celExpr))
+	c, err = c.withOptions(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Parse the CEL expression
-	compiled, issues := c.env.Compile(celExpr)
-	if issues != nil && issues.Err() != nil {
-		return nil, newConversionError(
-			"invalid filter expression syntax",
-			"INVALID_SYNTAX",
-			fmt.Errorf("CEL compilation failed: %w", issues.Err()),
-		)
-	}
+	result, err = c.convertWithAuth(ctx, celExpr, userRoles, nil)
+	result, err = c.finalize(ctx, celExpr, result, err)
+	return c.maskOutput(celExpr, result, err)
+}
 
-	// Validate that the expression returns a boolean
-	if compiled.OutputType() != cel.BoolType {
-		return nil, newConversionError(
-			"filter expression must evaluate to boolean",
-			"INVALID_TYPE",
-			fmt.Errorf("expected boolean, got %v", compiled.OutputType()),
-		)
+// convertWithAuth authorizes the fields referenced by a CEL expression,
+// which must also be visible in every scope, and converts it.
+func (c *Converter) convertWithAuth(ctx context.Context, celExpr string, userRoles []string, scopes ScopeStack) (*ConvertResult, error) {
+	checkedExpr, err := c.checkWithAuth(ctx, celExpr, userRoles, scopes)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert AST to checked expression
-	checkedExpr, err := cel.AstToCheckedExpr(compiled)
+	// Convert to SQL
+	return c.convertChecked(ctx, checkedExpr.Expr(), checkedExpr.cost)
+}
+
+// checkWithAuth compiles a CEL expression, enforcing the limits, and
+// authorizes the fields it references, which must also be visible in every
+// scope.
+func (c *Converter) checkWithAuth(ctx context.Context, celExpr string, userRoles []string, scopes ScopeStack) (*checkedFilter, error) {
+	_, checkedExpr, err := c.compile(ctx, celExpr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert AST to checked expression: %w", err)
+		return nil, err
+	}
+	if err := c.authorize(ctx, celExpr, checkedExpr.Expr(), userRoles, scopes); err != nil {
+		return nil, err
+	}
+	return checkedExpr, nil
+}
+
+// authorize checks that the user may filter by every field referenced by
+// an expression, which must also be visible in every scope.
+func (c *Converter) authorize(ctx context.Context, celExpr string, expr celast.Expr, userRoles []string, scopes ScopeStack) error {
+	authorization := c.authorization()
+	if !authorization && len(scopes) == 0 {
+		return nil
 	}
 
 	// SECURITY: Extract referenced fields and check authorization
-	referencedFields := c.extractReferencedFields(checkedExpr.GetExpr())
+	referencedFields := c.extractReferencedFields(expr)
 	for _, field := range referencedFields {
-		if !c.isFieldAuthorized(field, userRoles) {
+		scope, hidden := scopes.hidingScope(field)
+		if hidden || (authorization && !c.isFieldAuthorized(field, userRoles)) {
 			// SECURITY: Log unauthorized access attempt
 			if c.securityLogger != nil {
 				c.securityLogger.LogUnauthorizedField(
-					celExpr,
+					ctx,
+					c.logExpr(celExpr),
 					field,
 					userRoles,
 				)
 			}
 
 			// SECURITY: Don't reveal which field was unauthorized
-			return nil, newConversionError(
+			internalErr := fmt.Errorf("user with roles %v attempted to filter by restricted field: %s",
+				userRoles, field)
+			if hidden {
+				internalErr = fmt.Errorf("scope %s hides field %s from the filter", scope, field)
+			}
+			return newConversionError(
 				"access denied: insufficient permissions for requested filter",
-				"UNAUTHORIZED_FIELD",
-				fmt.Errorf("user with roles %v attempted to filter by restricted field: %s",
-					userRoles, field),
+				CodeUnauthorizedField,
+				internalErr,
 			)
 		}
 	}
+	return nil
+}
 
-	// Validate expression complexity (depth)
-	depth := c.calculateExpressionDepth(checkedExpr.GetExpr())
-	if depth > c.maxExpressionDepth {
-		return nil, fmt.Errorf("expression exceeds maximum depth of %d (got %d)",
-			c.maxExpressionDepth, depth)
-	}
+// scoped returns a shallow copy of the converter carrying fresh per-call state.
+func (c *Converter) scoped(ctx context.Context) *Converter {
+	scoped := *c
+	scoped.conv = &conversion{ctx: ctx}
+	return &scoped
+}
 
-	// Convert to SQL
-	sqlizer, err := c.convertExpr(checkedExpr.GetExpr())
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert CEL to SQL: %w", err)
+// logAttempt reports the outcome and duration of a conversion started at
+// start to the SecurityLogger.
+func (c *Converter) logAttempt(ctx context.Context, celExpr string, start time.Time, err *error) {
+	if c.securityLogger == nil {
+		return
 	}
+	c.securityLogger.LogConversionAttempt(ctx, c.logExpr(celExpr), *err == nil, *err, time.Since(start))
+}
 
-	return &ConvertResult{
-		Where: sqlizer,
-		Args:  []interface{}{},
-	}, nil
+// context returns the context of the current call.
+func (c *Converter) context() context.Context {
+	if c.conv == nil {
+		return context.Background()
+	}
+	return c.conv.ctx
 }
 
 // extractReferencedFields recursively extracts all field names referenced in an expression.
-func (c *Converter) extractReferencedFields(expr *exprpb.Expr) []string {
-	fields := make(map[string]bool)
-	c.walkExpr(expr, func(e *exprpb.Expr) {
-		if ident := e.GetIdentExpr(); ident != nil {
-			fields[ident.Name] = true
-		}
-		if sel := e.GetSelectExpr(); sel != nil {
-			fields[sel.Field] = true
-		}
-	})
-
-	result := make([]string, 0, len(fields))
-	for field := range fields {
+func (c *Converter) extractReferencedFields(expr celast.Expr) []string {
+	w := walkFields(expr)
+	defer w.release()
+	result := make([]string, 0, len(w.fields))
+	for field := range w.fields {
 		result = append(result, field)
 	}
 	return result
 }
 
-// walkExpr recursively visits all expressions in the tree.
-func (c *Converter) walkExpr(expr *exprpb.Expr, fn func(*exprpb.Expr)) {
-	if expr == nil {
-		return
-	}
-
-	fn(expr)
-
-	switch e := expr.ExprKind.(type) {
-	case *exprpb.Expr_CallExpr:
-		if e.CallExpr.Target != nil {
-			c.walkExpr(e.CallExpr.Target, fn)
-		}
-		for _, arg := range e.CallExpr.Args {
-			c.walkExpr(arg, fn)
-		}
-	case *exprpb.Expr_SelectExpr:
-		c.walkExpr(e.SelectExpr.Operand, fn)
-	case *exprpb.Expr_ListExpr:
-		for _, elem := range e.ListExpr.Elements {
-			c.walkExpr(elem, fn)
-		}
-	case *exprpb.Expr_StructExpr:
-		for _, entry := range e.StructExpr.Entries {
-			c.walkExpr(entry.GetMapKey(), fn)
-			c.walkExpr(entry.Value, fn)
-		}
-	}
-}
-
 // isFieldAuthorized checks if a field can be accessed by the given user roles.
 func (c *Converter) isFieldAuthorized(field string, userRoles []string) bool {
 	// Check if field is public (no authorization required)
@@ -415,57 +1225,52 @@ func (c *Converter) isFieldAuthorized(field string, userRoles []string) bool {
 }
 
 // calculateExpressionDepth recursively calculates the maximum nesting depth of an expression.
-func (c *Converter) calculateExpressionDepth(expr *exprpb.Expr) int {
+func (c *Converter) calculateExpressionDepth(expr celast.Expr) int {
 	if expr == nil {
 		return 0
 	}
 
-	switch e := expr.ExprKind.(type) {
-	case *exprpb.Expr_CallExpr:
+	switch expr.Kind() {
+	case celast.CallKind:
+		call := expr.AsCall()
 		maxArgDepth := 0
 		// Check target (for method calls)
-		if e.CallExpr.Target != nil {
-			targetDepth := c.calculateExpressionDepth(e.CallExpr.Target)
-			if targetDepth > maxArgDepth {
-				maxArgDepth = targetDepth
-			}
+		if call.IsMemberFunction() {
+			maxArgDepth = c.calculateExpressionDepth(call.Target())
 		}
 		// Check all arguments
-		for _, arg := range e.CallExpr.Args {
-			argDepth := c.calculateExpressionDepth(arg)
-			if argDepth > maxArgDepth {
-				maxArgDepth = argDepth
-			}
+		for _, arg := range call.Args() {
+			maxArgDepth = max(maxArgDepth, c.calculateExpressionDepth(arg))
 		}
 		return maxArgDepth + 1
 
-	case *exprpb.Expr_SelectExpr:
-		return c.calculateExpressionDepth(e.SelectExpr.Operand) + 1
+	case celast.SelectKind:
+		return c.calculateExpressionDepth(expr.AsSelect().Operand()) + 1
 
-	case *exprpb.Expr_ListExpr:
+	case celast.ListKind:
 		maxElemDepth := 0
-		for _, elem := range e.ListExpr.Elements {
-			elemDepth := c.calculateExpressionDepth(elem)
-			if elemDepth > maxElemDepth {
-				maxElemDepth = elemDepth
-			}
+		for _, elem := range expr.AsList().Elements() {
+			maxElemDepth = max(maxElemDepth, c.calculateExpressionDepth(elem))
 		}
 		return maxElemDepth + 1
 
-	case *exprpb.Expr_StructExpr:
+	case celast.MapKind:
 		maxEntryDepth := 0
-		for _, entry := range e.StructExpr.Entries {
-			keyDepth := c.calculateExpressionDepth(entry.GetMapKey())
-			valueDepth := c.calculateExpressionDepth(entry.Value)
-			if keyDepth > maxEntryDepth {
-				maxEntryDepth = keyDepth
-			}
-			if valueDepth > maxEntryDepth {
-				maxEntryDepth = valueDepth
-			}
+		for _, entry := range expr.AsMap().Entries() {
+			mapEntry := entry.AsMapEntry()
+			maxEntryDepth = max(maxEntryDepth,
+				c.calculateExpressionDepth(mapEntry.Key()),
+				c.calculateExpressionDepth(mapEntry.Value()))
 		}
 		return maxEntryDepth + 1
 
+	case celast.StructKind:
+		maxFieldDepth := 0
+		for _, field := range expr.AsStruct().Fields() {
+			maxFieldDepth = max(maxFieldDepth, c.calculateExpressionDepth(field.AsStructField().Value()))
+		}
+		return maxFieldDepth + 1
+
 	default:
 		// Leaf nodes (constants, identifiers)
 		return 1
@@ -473,78 +1278,114 @@ func (c *Converter) calculateExpressionDepth(expr *exprpb.Expr) int {
 }
 
 // convertExpr converts a CEL expression to a Squirrel Sqlizer.
-func (c *Converter) convertExpr(expr *exprpb.Expr) (squirrel.Sqlizer, error) {
+func (c *Converter) convertExpr(expr celast.Expr) (squirrel.Sqlizer, error) {
 	if expr == nil {
 		return nil, fmt.Errorf("nil expression")
 	}
 
-	switch expr.ExprKind.(type) {
-	case *exprpb.Expr_CallExpr:
-		callExpr := expr.GetCallExpr()
-		if callExpr == nil {
-			return nil, fmt.Errorf("nil call expression")
+	if err := c.interrupted(); err != nil {
+		return nil, err
+	}
+	if err := c.charge(1, 0, approxNodeBytes); err != nil {
+		return nil, err
+	}
+
+	switch expr.Kind() {
+	case celast.CallKind:
+		sqlizer, err := c.convertCallExpr(expr.AsCall())
+		if err != nil {
+			return c.fallback(expr, err)
 		}
-		return c.convertCallExpr(callExpr)
-	case *exprpb.Expr_IdentExpr:
+		return sqlizer, nil
+	case celast.IdentKind:
 		// Standalone identifier (e.g., "is_published")
-		ident := expr.GetIdentExpr()
-		if ident == nil {
-			return nil, fmt.Errorf("nil identifier expression")
-		}
-		column := c.mapFieldName(ident.Name)
-		return squirrel.Eq{column: true}, nil
-	case *exprpb.Expr_ConstExpr:
+		return c.boolField(expr.AsIdent())
+	case celast.LiteralKind:
 		// Constant value
-		constExpr := expr.GetConstExpr()
-		if constExpr == nil {
-			return nil, fmt.Errorf("nil constant expression")
-		}
-		return c.convertConstExpr(constExpr)
+		return c.convertConstExpr(expr.AsLiteral())
+	case celast.ComprehensionKind:
+		return c.convertComprehension(expr.AsComprehension())
 	default:
-		return nil, fmt.Errorf("unsupported expression type: %T", expr.ExprKind)
+		return nil, fmt.Errorf("unsupported expression type: %s", exprKindName(expr))
+	}
+}
+
+// exprKindNames names the kinds of expression nodes in error messages.
+var exprKindNames = map[celast.ExprKind]string{
+	celast.CallKind:          "call",
+	celast.ComprehensionKind: "comprehension",
+	celast.IdentKind:         "identifier",
+	celast.ListKind:          "list",
+	celast.LiteralKind:       "literal",
+	celast.MapKind:           "map",
+	celast.SelectKind:        "select",
+	celast.StructKind:        "struct",
+}
+
+// exprKindName returns the kind of an expression node, for error messages.
+func exprKindName(expr celast.Expr) string {
+	if name, ok := exprKindNames[expr.Kind()]; ok {
+		return name
 	}
+	return "unspecified"
 }
 
 // convertCallExpr converts a CEL call expression to a Squirrel Sqlizer.
-func (c *Converter) convertCallExpr(call *exprpb.Expr_Call) (squirrel.Sqlizer, error) {
+func (c *Converter) convertCallExpr(call celast.CallExpr) (squirrel.Sqlizer, error) {
 	if call == nil {
 		return nil, fmt.Errorf("nil call expression")
 	}
 
-	function := call.Function
+	function := call.FunctionName()
+	args := call.Args()
 
 	switch function {
 	case "_&&_": // Logical AND
-		return c.convertLogicalAnd(call.Args)
+		return c.convertLogicalAnd(args)
 	case "_||_": // Logical OR
-		return c.convertLogicalOr(call.Args)
+		return c.convertLogicalOr(args)
 	case "!_": // Logical NOT
-		return c.convertLogicalNot(call.Args)
+		return c.convertLogicalNot(args)
 	case "_==_": // Equality
-		return c.convertComparison(call.Args, "=")
+		return c.convertComparison(args, "=")
 	case "_!=_": // Inequality
-		return c.convertComparison(call.Args, "!=")
+		return c.convertComparison(args, "!=")
 	case "_<_": // Less than
-		return c.convertComparison(call.Args, "<")
+		return c.convertComparison(args, "<")
 	case "_<=_": // Less than or equal
-		return c.convertComparison(call.Args, "<=")
+		return c.convertComparison(args, "<=")
 	case "_>_": // Greater than
-		return c.convertComparison(call.Args, ">")
+		return c.convertComparison(args, ">")
 	case "_>=_": // Greater than or equal
-		return c.convertComparison(call.Args, ">=")
+		return c.convertComparison(args, ">=")
 	case "@in": // IN operator
-		return c.convertInOperator(call.Args)
+		return c.convertInOperator(args)
 	case "contains": // String contains
 		return c.convertContains(call)
 	case "startsWith": // String starts with
 		return c.convertStartsWith(call)
 	case "endsWith": // String ends with
 		return c.convertEndsWith(call)
+	case "containsAll": // List field contains every element
+		return c.convertListContains(call, "@>", true)
+	case "containsAny": // List field shares an element
+		return c.convertListContains(call, "&&", false)
+	case "equalsIgnoreCase": // Case-insensitive equality
+		return c.convertEqualsIgnoreCase(call)
+	case anyOfFunction: // Any flag of a group set
+		return c.convertAnyOf(call)
+	case "near": // Location within a radius
+		return c.convertNear(call)
 	default:
+		if tmpl, ok := c.functions[function]; ok && call.IsMemberFunction() {
+			return c.convertTemplateCall(tmpl, call)
+		}
+
 		// SECURITY: Log unsupported operation attempt
 		if c.securityLogger != nil {
 			c.securityLogger.LogUnsupportedOperation(
-				call.String(),
+				c.context(),
+				c.logCall(call),
 				function,
 			)
 		}
@@ -552,14 +1393,14 @@ func (c *Converter) convertCallExpr(call *exprpb.Expr_Call) (squirrel.Sqlizer, e
 		// SECURITY: Sanitize error - don't expose supported function list
 		return nil, newConversionError(
 			"unsupported filter operation",
-			"UNSUPPORTED_OPERATION",
+			CodeUnsupportedOperation,
 			fmt.Errorf("unsupported CEL function: %s", function),
 		)
 	}
 }
 
 // convertLogicalAnd converts CEL AND operator to Squirrel And.
-func (c *Converter) convertLogicalAnd(args []*exprpb.Expr) (squirrel.Sqlizer, error) {
+func (c *Converter) convertLogicalAnd(args []celast.Expr) (squirrel.Sqlizer, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("AND operator requires exactly 2 arguments, got %d", len(args))
 	}
@@ -574,11 +1415,51 @@ func (c *Converter) convertLogicalAnd(args []*exprpb.Expr) (squirrel.Sqlizer, er
 		return nil, err
 	}
 
-	return squirrel.And{left, right}, nil
+	operands, split := []squirrel.Sqlizer{left, right}, 1
+	if c.flattenChains {
+		operands, split = spliceOperands[squirrel.And](left, right)
+	}
+	if c.useBetween {
+		operands = mergeAcross(operands, split, mergeBetween)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return squirrel.And(operands), nil
+}
+
+// mergeBetween combines an inclusive lower and upper bound on the same column
+// into a single BETWEEN predicate. It reports false when the operands are not
+// such a pair.
+func mergeBetween(left, right squirrel.Sqlizer) (squirrel.Sqlizer, bool) {
+	lower, okLower := left.(squirrel.GtOrEq)
+	upper, okUpper := right.(squirrel.LtOrEq)
+	if !okLower || !okUpper {
+		lower, okLower = right.(squirrel.GtOrEq)
+		upper, okUpper = left.(squirrel.LtOrEq)
+		if !okLower || !okUpper {
+			return nil, false
+		}
+	}
+
+	if len(lower) != 1 || len(upper) != 1 {
+		return nil, false
+	}
+
+	for column, low := range lower {
+		high, ok := upper[column]
+		if !ok {
+			return nil, false
+		}
+		return squirrel.Expr(fmt.Sprintf("%s BETWEEN ? AND ?", column), low, high), true
+	}
+
+	return nil, false
 }
 
 // convertLogicalOr converts CEL OR operator to Squirrel Or.
-func (c *Converter) convertLogicalOr(args []*exprpb.Expr) (squirrel.Sqlizer, error) {
+func (c *Converter) convertLogicalOr(args []celast.Expr) (squirrel.Sqlizer, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("OR operator requires exactly 2 arguments, got %d", len(args))
 	}
@@ -593,55 +1474,139 @@ func (c *Converter) convertLogicalOr(args []*exprpb.Expr) (squirrel.Sqlizer, err
 		return nil, err
 	}
 
-	return squirrel.Or{left, right}, nil
+	operands, split := []squirrel.Sqlizer{left, right}, 1
+	if c.flattenChains {
+		operands, split = spliceOperands[squirrel.Or](left, right)
+	}
+	if c.collapseOrToIn {
+		operands = mergeAcross(operands, split, c.mergeEqualities)
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return squirrel.Or(operands), nil
+}
+
+// mergeEqualities combines two equality or IN predicates on the same column
+// into a single IN predicate. NULL checks are never merged, and neither are
+// predicates whose combined size would exceed the IN clause limit.
+func (c *Converter) mergeEqualities(left, right squirrel.Sqlizer) (squirrel.Sqlizer, bool) {
+	leftEq, okLeft := left.(squirrel.Eq)
+	rightEq, okRight := right.(squirrel.Eq)
+	if !okLeft || !okRight || len(leftEq) != 1 || len(rightEq) != 1 {
+		return nil, false
+	}
+
+	for column, leftValue := range leftEq {
+		rightValue, ok := rightEq[column]
+		if !ok || leftValue == nil || rightValue == nil {
+			return nil, false
+		}
+
+		values := slices.Concat(inValues(leftValue), inValues(rightValue))
+		if len(values) > c.maxInClauseSize {
+			return nil, false
+		}
+		return squirrel.Eq{column: values}, true
+	}
+
+	return nil, false
+}
+
+// inValues returns the values matched by an equality or IN predicate.
+func inValues(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
 }
 
 // convertLogicalNot converts CEL NOT operator to SQL NOT.
-func (c *Converter) convertLogicalNot(args []*exprpb.Expr) (squirrel.Sqlizer, error) {
+func (c *Converter) convertLogicalNot(args []celast.Expr) (squirrel.Sqlizer, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("NOT operator requires exactly 1 argument, got %d", len(args))
 	}
 
+	// Fallbacks widen predicates, which a negation would narrow
+	if c.conv != nil {
+		c.conv.negations++
+		defer func() { c.conv.negations-- }()
+	}
+
 	inner, err := c.convertExpr(args[0])
 	if err != nil {
 		return nil, err
 	}
 
+	if c.pushDownNot {
+		return negate(inner), nil
+	}
+
 	// Squirrel doesn't have a direct NOT, so we use NotEq for simple cases
 	// For complex expressions, we wrap in a custom Sqlizer
 	return &notSqlizer{inner: inner}, nil
 }
 
 // convertComparison converts CEL comparison operators to Squirrel comparison.
-func (c *Converter) convertComparison(args []*exprpb.Expr, op string) (squirrel.Sqlizer, error) {
+func (c *Converter) convertComparison(args []celast.Expr, op string) (squirrel.Sqlizer, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("comparison operator requires exactly 2 arguments, got %d", len(args))
 	}
 
-	// Get the field name (left side)
-	field, err := c.getFieldName(args[0])
+	// Get the column (left side)
+	lhs, err := c.getColumnExpr(args[0])
 	if err != nil {
 		return nil, err
 	}
-	column := c.mapFieldName(field)
+	field, column := lhs.field, lhs.sql
 
-	// Get the value (right side)
-	value, err := c.getConstantValue(args[1])
-	if err != nil {
+	// Get the value (right side); emptiness checks compare the list length
+	var value interface{}
+	if isEmptyList(args[1]) {
+		if lhs, err = c.getSizeExpr(args[0]); err != nil {
+			return nil, err
+		}
+		field, column = lhs.field, lhs.sql
+		value = int64(0)
+	} else if value, err = c.getConstantValue(args[1]); err != nil {
 		return nil, err
 	}
 
 	// SECURITY: Validate type compatibility at runtime
 	if value != nil {
-		if err := c.validateTypeCompatibility(field, value); err != nil {
+		var err error
+		if lhs.castTo != "" {
+			err = validateValueType(lhs.castTo, value)
+		} else {
+			err = c.validateTypeCompatibility(field, value)
+		}
+		if err != nil {
 			return nil, newConversionError(
 				"invalid comparison type",
-				"TYPE_MISMATCH",
+				CodeTypeMismatch,
 				fmt.Errorf("type mismatch for field %s: %w", field, err),
 			)
 		}
 	}
 
+	// Enforce the field's timestamp precision
+	if lhs.castTo == "" {
+		if value, err = c.applyGranularity(field, value); err != nil {
+			return nil, err
+		}
+	}
+	if !isEmptyList(args[1]) {
+		if value, err = c.transformValue(field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Computed columns bind their own values
+	if len(lhs.args) > 0 || isValuer(value) {
+		return lhs.compare(op, value)
+	}
+
 	// Handle NULL comparisons
 	if value == nil {
 		switch op {
@@ -680,9 +1645,17 @@ func (c *Converter) validateTypeCompatibility(fieldName string, value interface{
 		return nil
 	}
 
-	fieldType := mapping.Type.String()
+	return validateValueType(mapping.Type.String(), value)
+}
+
+// validateValueType checks if a value is compatible with the named CEL type.
+// Values implementing driver.Valuer are accepted for any type: their SQL
+// representation is up to the driver.
+func validateValueType(fieldType string, value interface{}) error {
+	if isValuer(value) {
+		return nil
+	}
 
-	// Validate based on declared type
 	switch fieldType {
 	case "string":
 		if _, ok := value.(string); !ok {
@@ -704,6 +1677,10 @@ func (c *Converter) validateTypeCompatibility(fieldName string, value interface{
 		if _, ok := value.(uint64); !ok {
 			return fmt.Errorf("expected uint, got %T", value)
 		}
+	case "google.protobuf.Timestamp":
+		if _, ok := value.(time.Time); !ok {
+			return fmt.Errorf("expected timestamp, got %T", value)
+		}
 	// Add more type checks as needed
 	default:
 		// For complex types (lists, maps, etc.), rely on CEL's type checking
@@ -714,17 +1691,26 @@ func (c *Converter) validateTypeCompatibility(fieldName string, value interface{
 }
 
 // convertInOperator converts CEL IN operator to Squirrel Eq with array.
-func (c *Converter) convertInOperator(args []*exprpb.Expr) (squirrel.Sqlizer, error) {
+func (c *Converter) convertInOperator(args []celast.Expr) (squirrel.Sqlizer, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("IN operator requires exactly 2 arguments, got %d", len(args))
 	}
 
-	// Get the field name (left side)
-	field, err := c.getFieldName(args[0])
+	// Membership in the values selected by a subquery
+	if fn, ok := c.subqueryFunction(args[1]); ok {
+		return c.convertSubqueryMembership(args[0], fn)
+	}
+
+	// Membership in a list field
+	if args[1].Kind() != celast.ListKind {
+		return c.convertListMembership(args[0], args[1])
+	}
+
+	// Get the column (left side)
+	lhs, err := c.getColumnExpr(args[0])
 	if err != nil {
 		return nil, err
 	}
-	column := c.mapFieldName(field)
 
 	// Get the list (right side)
 	list, err := c.getListValues(args[1])
@@ -732,42 +1718,70 @@ func (c *Converter) convertInOperator(args []*exprpb.Expr) (squirrel.Sqlizer, er
 		return nil, err
 	}
 
-	return squirrel.Eq{column: list}, nil
+	if len(list) == 0 {
+		c.warnf(DiagnosticEmptyInList, lhs.field, "%s in [] never matches", lhs.sql)
+	}
+
+	// Enforce the field's timestamp precision
+	if lhs.castTo == "" {
+		for i, value := range list {
+			if list[i], err = c.applyGranularity(lhs.field, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, value := range list {
+		if list[i], err = c.transformValue(lhs.field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.inValuesThreshold > 0 && len(list) > c.inValuesThreshold {
+		return c.valuesIn(lhs, list), nil
+	}
+
+	if len(lhs.args) > 0 || slices.ContainsFunc(list, isValuer) {
+		return lhs.in(list), nil
+	}
+
+	return squirrel.Eq{lhs.sql: list}, nil
 }
 
+// likeEscaper escapes SQL LIKE special characters in a single pass: % (any
+// chars), _ (single char), \ (escape char), [ and ] (character class, SQL
+// Server and PostgreSQL with certain collations).
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "[", `\[`, "]", `\]`)
+
 // escapeLikePattern escapes SQL LIKE special characters to prevent injection.
-// Escapes: % (any chars), _ (single char), \ (escape char), [ and ] (character class)
 func escapeLikePattern(s string) string {
-	// Escape backslash first to avoid double-escaping
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	// Escape LIKE wildcards
-	s = strings.ReplaceAll(s, "%", "\\%")
-	s = strings.ReplaceAll(s, "_", "\\_")
-	// Escape character class brackets (SQL Server, PostgreSQL with certain collations)
-	s = strings.ReplaceAll(s, "[", "\\[")
-	s = strings.ReplaceAll(s, "]", "\\]")
-	return s
+	return likeEscaper.Replace(s)
 }
 
 // convertContains converts CEL contains() to SQL LIKE.
-func (c *Converter) convertContains(call *exprpb.Expr_Call) (squirrel.Sqlizer, error) {
+func (c *Converter) convertContains(call celast.CallExpr) (squirrel.Sqlizer, error) {
 	if call == nil {
 		return nil, fmt.Errorf("nil call expression")
 	}
 
-	if len(call.Args) != 1 {
-		return nil, fmt.Errorf("contains() requires exactly 1 argument, got %d", len(call.Args))
+	args := call.Args()
+	if len(args) != 1 {
+		return nil, fmt.Errorf("contains() requires exactly 1 argument, got %d", len(args))
 	}
 
-	// Get the field name (receiver/target)
-	field, err := c.getFieldName(call.Target)
+	// Get the column (receiver/target)
+	lhs, err := c.getColumnExpr(call.Target())
 	if err != nil {
 		return nil, err
 	}
-	column := c.mapFieldName(field)
+
+	// Another field as argument: compare the columns, as its value is
+	// not a pattern
+	if arg, ok := c.getFieldArgument(args[0]); ok {
+		return c.matchOperand("contains", lhs, arg), nil
+	}
 
 	// Get the search string (argument)
-	value, err := c.getConstantValue(call.Args[0])
+	value, err := c.getConstantValue(args[0])
 	if err != nil {
 		return nil, err
 	}
@@ -779,28 +1793,34 @@ func (c *Converter) convertContains(call *exprpb.Expr_Call) (squirrel.Sqlizer, e
 
 	// SECURITY FIX: Escape LIKE special characters to prevent SQL injection
 	escapedValue := escapeLikePattern(strValue)
-	return squirrel.Like{column: fmt.Sprintf("%%%s%%", escapedValue)}, nil
+	return c.like(lhs, "%"+escapedValue+"%")
 }
 
 // convertStartsWith converts CEL startsWith() to SQL LIKE.
-func (c *Converter) convertStartsWith(call *exprpb.Expr_Call) (squirrel.Sqlizer, error) {
+func (c *Converter) convertStartsWith(call celast.CallExpr) (squirrel.Sqlizer, error) {
 	if call == nil {
 		return nil, fmt.Errorf("nil call expression")
 	}
 
-	if len(call.Args) != 1 {
-		return nil, fmt.Errorf("startsWith() requires exactly 1 argument, got %d", len(call.Args))
+	args := call.Args()
+	if len(args) != 1 {
+		return nil, fmt.Errorf("startsWith() requires exactly 1 argument, got %d", len(args))
 	}
 
-	// Get the field name (receiver/target)
-	field, err := c.getFieldName(call.Target)
+	// Get the column (receiver/target)
+	lhs, err := c.getColumnExpr(call.Target())
 	if err != nil {
 		return nil, err
 	}
-	column := c.mapFieldName(field)
+
+	// Another field as argument: compare the columns, as its value is
+	// not a pattern
+	if arg, ok := c.getFieldArgument(args[0]); ok {
+		return c.matchOperand("startsWith", lhs, arg), nil
+	}
 
 	// Get the prefix string (argument)
-	value, err := c.getConstantValue(call.Args[0])
+	value, err := c.getConstantValue(args[0])
 	if err != nil {
 		return nil, err
 	}
@@ -812,28 +1832,34 @@ func (c *Converter) convertStartsWith(call *exprpb.Expr_Call) (squirrel.Sqlizer,
 
 	// SECURITY FIX: Escape LIKE special characters to prevent SQL injection
 	escapedValue := escapeLikePattern(strValue)
-	return squirrel.Like{column: fmt.Sprintf("%s%%", escapedValue)}, nil
+	return c.like(lhs, escapedValue+"%")
 }
 
 // convertEndsWith converts CEL endsWith() to SQL LIKE.
-func (c *Converter) convertEndsWith(call *exprpb.Expr_Call) (squirrel.Sqlizer, error) {
+func (c *Converter) convertEndsWith(call celast.CallExpr) (squirrel.Sqlizer, error) {
 	if call == nil {
 		return nil, fmt.Errorf("nil call expression")
 	}
 
-	if len(call.Args) != 1 {
-		return nil, fmt.Errorf("endsWith() requires exactly 1 argument, got %d", len(call.Args))
+	args := call.Args()
+	if len(args) != 1 {
+		return nil, fmt.Errorf("endsWith() requires exactly 1 argument, got %d", len(args))
 	}
 
-	// Get the field name (receiver/target)
-	field, err := c.getFieldName(call.Target)
+	// Get the column (receiver/target)
+	lhs, err := c.getColumnExpr(call.Target())
 	if err != nil {
 		return nil, err
 	}
-	column := c.mapFieldName(field)
+
+	// Another field as argument: compare the columns, as its value is
+	// not a pattern
+	if arg, ok := c.getFieldArgument(args[0]); ok {
+		return c.matchOperand("endsWith", lhs, arg), nil
+	}
 
 	// Get the suffix string (argument)
-	value, err := c.getConstantValue(call.Args[0])
+	value, err := c.getConstantValue(args[0])
 	if err != nil {
 		return nil, err
 	}
@@ -845,62 +1871,110 @@ func (c *Converter) convertEndsWith(call *exprpb.Expr_Call) (squirrel.Sqlizer, e
 
 	// SECURITY FIX: Escape LIKE special characters to prevent SQL injection
 	escapedValue := escapeLikePattern(strValue)
-	return squirrel.Like{column: fmt.Sprintf("%%%s", escapedValue)}, nil
+	return c.like(lhs, "%"+escapedValue)
 }
 
-// getFieldName extracts a field name from an expression.
-func (c *Converter) getFieldName(expr *exprpb.Expr) (string, error) {
-	if ident := expr.GetIdentExpr(); ident != nil {
-		return ident.Name, nil
+// like renders a LIKE match of lhs against a bound pattern, case-insensitive
+// when configured for the field.
+func (c *Converter) like(lhs operand, pattern string) (squirrel.Sqlizer, error) {
+	if strings.HasPrefix(pattern, "%") {
+		c.warnf(DiagnosticLeadingWildcard, lhs.field,
+			"LIKE pattern on %s starts with a wildcard and may not use an index", lhs.sql)
+	}
+	return c.matchLike(lhs, pattern, c.isCaseInsensitive(lhs.field))
+}
+
+// matchOperand renders the contains, startsWith or endsWith match of lhs
+// against the string of another operand, case-insensitive when configured
+// for the field.
+func (c *Converter) matchOperand(function string, lhs, arg operand) squirrel.Sqlizer {
+	template, _ := c.dialect.substringMatch(function)
+	if c.isCaseInsensitive(lhs.field) {
+		lhs, arg = lhs.apply("LOWER(%s)", "string"), arg.apply("LOWER(%s)", "string")
 	}
+	sql, args := expandOperands(template, lhs, arg)
+	return squirrel.Expr(sql, args...)
+}
+
+// isCaseInsensitive reports whether LIKE matches on the field ignore case.
+func (c *Converter) isCaseInsensitive(field string) bool {
+	return c.caseInsensitiveLike || c.fieldDeclarations[field].CaseInsensitive
+}
 
-	if sel := expr.GetSelectExpr(); sel != nil {
-		return sel.Field, nil
+// getFieldName extracts a field name from an expression.
+func (c *Converter) getFieldName(expr celast.Expr) (string, error) {
+	switch expr.Kind() {
+	case celast.IdentKind:
+		name := expr.AsIdent()
+		if _, ok := c.collections[name]; ok {
+			return "", fmt.Errorf("collection %s can only be filtered with exists() and all()", name)
+		}
+		return name, nil
+	case celast.SelectKind:
+		return expr.AsSelect().FieldName(), nil
 	}
 
-	return "", fmt.Errorf("expression is not a field identifier: %T", expr.ExprKind)
+	return "", fmt.Errorf("expression is not a field identifier: %s", exprKindName(expr))
 }
 
 // getConstantValue extracts a constant value from an expression.
-func (c *Converter) getConstantValue(expr *exprpb.Expr) (interface{}, error) {
-	constExpr := expr.GetConstExpr()
-	if constExpr == nil {
-		return nil, fmt.Errorf("expression is not a constant: %T", expr.ExprKind)
-	}
-
-	switch constExpr.ConstantKind.(type) {
-	case *exprpb.Constant_BoolValue:
-		return constExpr.GetBoolValue(), nil
-	case *exprpb.Constant_Int64Value:
-		return constExpr.GetInt64Value(), nil
-	case *exprpb.Constant_Uint64Value:
-		return constExpr.GetUint64Value(), nil
-	case *exprpb.Constant_DoubleValue:
-		return constExpr.GetDoubleValue(), nil
-	case *exprpb.Constant_StringValue:
-		return constExpr.GetStringValue(), nil
-	case *exprpb.Constant_NullValue:
+func (c *Converter) getConstantValue(expr celast.Expr) (interface{}, error) {
+	if call := expr.AsCall(); call.FunctionName() == "timestamp" {
+		if err := c.charge(0, 1, approxValueBytes); err != nil {
+			return nil, err
+		}
+		return c.getTimestampLiteral(call)
+	}
+
+	if isConstantArithmetic(expr) {
+		if err := c.charge(0, 1, approxValueBytes); err != nil {
+			return nil, err
+		}
+		return c.evalConstant(expr)
+	}
+
+	if expr.Kind() != celast.LiteralKind {
+		return nil, fmt.Errorf("expression is not a constant: %s", exprKindName(expr))
+	}
+
+	literal := expr.AsLiteral()
+	str, _ := literal.(types.String)
+	if err := c.charge(0, 1, approxValueBytes+len(str)); err != nil {
+		return nil, err
+	}
+
+	switch value := literal.(type) {
+	case types.Bool:
+		return bool(value), nil
+	case types.Int:
+		return int64(value), nil
+	case types.Uint:
+		return uint64(value), nil
+	case types.Double:
+		return float64(value), nil
+	case types.String:
+		return string(value), nil
+	case types.Null:
 		return nil, nil
 	default:
-		return nil, fmt.Errorf("unsupported constant type: %T", constExpr.ConstantKind)
+		return nil, fmt.Errorf("unsupported constant type: %s", literal.Type())
 	}
 }
 
 // getListValues extracts list values from an expression.
-func (c *Converter) getListValues(expr *exprpb.Expr) ([]interface{}, error) {
-	list := expr.GetListExpr()
-	if list == nil {
-		return nil, fmt.Errorf("expression is not a list: %T", expr.ExprKind)
+func (c *Converter) getListValues(expr celast.Expr) ([]interface{}, error) {
+	if expr.Kind() != celast.ListKind {
+		return nil, fmt.Errorf("expression is not a list: %s", exprKindName(expr))
 	}
+	elements := expr.AsList().Elements()
 
 	// SECURITY: Limit IN clause size to prevent DoS
-	if len(list.Elements) > c.maxInClauseSize {
-		return nil, fmt.Errorf("IN clause size %d exceeds maximum of %d",
-			len(list.Elements), c.maxInClauseSize)
+	if err := c.checkInClauseSize(len(elements)); err != nil {
+		return nil, err
 	}
 
-	values := make([]interface{}, len(list.Elements))
-	for i, elem := range list.Elements {
+	values := make([]interface{}, len(elements))
+	for i, elem := range elements {
 		val, err := c.getConstantValue(elem)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get list element %d: %w", i, err)
@@ -912,24 +1986,24 @@ func (c *Converter) getListValues(expr *exprpb.Expr) ([]interface{}, error) {
 }
 
 // convertConstExpr converts a constant expression (shouldn't typically appear at top level).
-func (c *Converter) convertConstExpr(constExpr *exprpb.Constant) (squirrel.Sqlizer, error) {
-	if constExpr == nil {
+func (c *Converter) convertConstExpr(literal ref.Val) (squirrel.Sqlizer, error) {
+	if literal == nil {
 		return nil, fmt.Errorf("nil constant expression")
 	}
 
-	switch constExpr.ConstantKind.(type) {
-	case *exprpb.Constant_BoolValue:
-		if constExpr.GetBoolValue() {
-			return squirrel.Expr("TRUE"), nil
-		}
+	switch literal {
+	case types.True:
+		return squirrel.Expr("TRUE"), nil
+	case types.False:
 		return squirrel.Expr("FALSE"), nil
 	default:
-		return nil, fmt.Errorf("unsupported constant type at top level: %T", constExpr.ConstantKind)
+		return nil, fmt.Errorf("unsupported constant type at top level: %s", literal.Type())
 	}
 }
 
 // mapFieldName maps a CEL field name to a SQL column name using the converter's column mappings.
 func (c *Converter) mapFieldName(field string) string {
+	c.requireJoin(field)
 	if c.columnMappings != nil {
 		if mapped, ok := c.columnMappings[field]; ok {
 			return mapped
@@ -954,7 +2028,9 @@ func (n *notSqlizer) ToSql() (string, []interface{}, error) {
 	return fmt.Sprintf("NOT (%s)", sql), args, nil
 }
 
-// QuoteIdentifier quotes a SQL identifier to prevent SQL injection.
+// QuoteIdentifier quotes a SQL identifier with ANSI double quotes to prevent
+// SQL injection. See Dialect.QuoteIdentifier for dialect-specific quoting,
+// which also rejects control characters.
 func QuoteIdentifier(name string) string {
 	// Replace any double quotes with escaped double quotes
 	escaped := strings.ReplaceAll(name, `"`, `""`)
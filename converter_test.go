@@ -1,12 +1,18 @@
 package cel2squirrel
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/google/cel-go/cel"
-	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
 )
 
 // =============================================================================
@@ -767,6 +773,68 @@ func TestConverter_PostgreSQLPlaceholders(t *testing.T) {
 	}
 }
 
+func TestConvertResult_ToSql(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+		"age":    {Type: cel.IntType},
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantSQL string
+	}{
+		{name: "default", config: Config{}, wantSQL: "(status = ? AND age >= ?)"},
+		{name: "dialect", config: Config{Dialect: DialectPostgreSQL}, wantSQL: "(status = $1 AND age >= $2)"},
+		{name: "explicit", config: Config{Dialect: DialectPostgreSQL, PlaceholderFormat: squirrel.AtP}, wantSQL: "(status = @p1 AND age >= @p2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.FieldDeclarations = fields
+			converter, err := NewConverter(tt.config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+			result, err := converter.Convert(`status == "published" && age >= 18`)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, []any{"published", int64(18)}) {
+				t.Errorf("ToSql() args = %v", args)
+			}
+
+			// Where keeps ? placeholders for query builders
+			if sql, _, _ := result.Where.ToSql(); sql != "(status = ? AND age >= ?)" {
+				t.Errorf("Where.ToSql() = %v", sql)
+			}
+		})
+	}
+
+	t.Run("scopes", func(t *testing.T) {
+		converter, err := NewConverter(Config{FieldDeclarations: fields, PlaceholderFormat: squirrel.Dollar})
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+		result, err := converter.ConvertWithScopes(context.Background(), `age >= 18`, nil,
+			ScopeStack{{Name: "published", Predicate: `status == "published"`}})
+		if err != nil {
+			t.Fatalf("ConvertWithScopes() error = %v", err)
+		}
+		if sql, _, _ := result.ToSql(); sql != "(status = $1 AND age >= $2)" {
+			t.Errorf("ToSql() = %v", sql)
+		}
+	})
+}
+
 func TestConverter_MultipleQueryFormats(t *testing.T) {
 	config := Config{
 		FieldDeclarations: map[string]ColumnMapping{
@@ -1021,6 +1089,41 @@ func TestQuoteIdentifier(t *testing.T) {
 	}
 }
 
+func TestDialect_QuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", dialect: DialectDefault, input: "user_id", want: `"user_id"`},
+		{name: "postgres quotes", dialect: DialectPostgreSQL, input: `user"id`, want: `"user""id"`},
+		{name: "mysql", dialect: DialectMySQL, input: "user_id", want: "`user_id`"},
+		{name: "mysql backticks", dialect: DialectMySQL, input: "user`id", want: "`user``id`"},
+		{name: "sqlserver", dialect: DialectSQLServer, input: "user id", want: "[user id]"},
+		{name: "sqlserver brackets", dialect: DialectSQLServer, input: "a[b]c", want: "[a[b]]c]"},
+		{name: "unicode", dialect: DialectSQLite, input: "prénom", want: `"prénom"`},
+		{name: "empty", dialect: DialectDefault, input: "", wantErr: true},
+		{name: "NUL", dialect: DialectPostgreSQL, input: "user\x00id", wantErr: true},
+		{name: "newline", dialect: DialectMySQL, input: "user\nid", wantErr: true},
+		{name: "DEL", dialect: DialectSQLServer, input: "user\x7fid", wantErr: true},
+		{name: "invalid UTF-8", dialect: DialectDefault, input: "user\xffid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dialect.QuoteIdentifier(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QuoteIdentifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QuoteIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNewConverter_EmptyConfig(t *testing.T) {
 	config := Config{}
 	converter, err := NewConverter(config)
@@ -1583,7 +1686,7 @@ func TestConverter_InternalMethods_ErrorPaths(t *testing.T) {
 
 	// Test getFieldName with invalid expression types
 	t.Run("getFieldName with nil expression", func(t *testing.T) {
-		nilExpr := &exprpb.Expr{}
+		nilExpr := exprFactory.NewUnspecifiedExpr(0)
 		_, err := converter.getFieldName(nilExpr)
 		if err == nil {
 			t.Error("getFieldName() with nil expression should return error")
@@ -1592,11 +1695,7 @@ func TestConverter_InternalMethods_ErrorPaths(t *testing.T) {
 
 	// Test getConstantValue with invalid constant types
 	t.Run("getConstantValue with nil constant", func(t *testing.T) {
-		nonConstExpr := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "field"},
-			},
-		}
+		nonConstExpr := exprFactory.NewIdent(0, "field")
 		_, err := converter.getConstantValue(nonConstExpr)
 		if err == nil {
 			t.Error("getConstantValue() with non-constant should return error")
@@ -1605,15 +1704,7 @@ func TestConverter_InternalMethods_ErrorPaths(t *testing.T) {
 
 	// Test getListValues with non-list expression
 	t.Run("getListValues with non-list", func(t *testing.T) {
-		nonListExpr := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_ConstExpr{
-				ConstExpr: &exprpb.Constant{
-					ConstantKind: &exprpb.Constant_StringValue{
-						StringValue: "not a list",
-					},
-				},
-			},
-		}
+		nonListExpr := exprFactory.NewLiteral(0, types.String("not a list"))
 		_, err := converter.getListValues(nonListExpr)
 		if err == nil {
 			t.Error("getListValues() with non-list should return error")
@@ -1622,11 +1713,7 @@ func TestConverter_InternalMethods_ErrorPaths(t *testing.T) {
 
 	// Test convertConstExpr with non-boolean constant
 	t.Run("convertConstExpr with non-boolean", func(t *testing.T) {
-		intConst := &exprpb.Constant{
-			ConstantKind: &exprpb.Constant_Int64Value{
-				Int64Value: 42,
-			},
-		}
+		intConst := types.Int(42)
 		_, err := converter.convertConstExpr(intConst)
 		if err == nil {
 			t.Error("convertConstExpr() with non-boolean should return error")
@@ -1651,7 +1738,7 @@ func TestConverter_LogicalOperators_ArgumentErrors(t *testing.T) {
 
 	// Test convertLogicalAnd with wrong number of arguments
 	t.Run("convertLogicalAnd with 0 args", func(t *testing.T) {
-		_, err := converter.convertLogicalAnd([]*exprpb.Expr{})
+		_, err := converter.convertLogicalAnd([]celast.Expr{})
 		if err == nil {
 			t.Error("convertLogicalAnd() with 0 args should return error")
 		}
@@ -1661,12 +1748,8 @@ func TestConverter_LogicalOperators_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertLogicalAnd with 1 arg", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "a"},
-			},
-		}
-		_, err := converter.convertLogicalAnd([]*exprpb.Expr{arg})
+		arg := exprFactory.NewIdent(0, "a")
+		_, err := converter.convertLogicalAnd([]celast.Expr{arg})
 		if err == nil {
 			t.Error("convertLogicalAnd() with 1 arg should return error")
 		}
@@ -1676,12 +1759,8 @@ func TestConverter_LogicalOperators_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertLogicalAnd with 3 args", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "a"},
-			},
-		}
-		_, err := converter.convertLogicalAnd([]*exprpb.Expr{arg, arg, arg})
+		arg := exprFactory.NewIdent(0, "a")
+		_, err := converter.convertLogicalAnd([]celast.Expr{arg, arg, arg})
 		if err == nil {
 			t.Error("convertLogicalAnd() with 3 args should return error")
 		}
@@ -1692,19 +1771,15 @@ func TestConverter_LogicalOperators_ArgumentErrors(t *testing.T) {
 
 	// Test convertLogicalOr with wrong number of arguments
 	t.Run("convertLogicalOr with 0 args", func(t *testing.T) {
-		_, err := converter.convertLogicalOr([]*exprpb.Expr{})
+		_, err := converter.convertLogicalOr([]celast.Expr{})
 		if err == nil {
 			t.Error("convertLogicalOr() with 0 args should return error")
 		}
 	})
 
 	t.Run("convertLogicalOr with 3 args", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "a"},
-			},
-		}
-		_, err := converter.convertLogicalOr([]*exprpb.Expr{arg, arg, arg})
+		arg := exprFactory.NewIdent(0, "a")
+		_, err := converter.convertLogicalOr([]celast.Expr{arg, arg, arg})
 		if err == nil {
 			t.Error("convertLogicalOr() with 3 args should return error")
 		}
@@ -1712,7 +1787,7 @@ func TestConverter_LogicalOperators_ArgumentErrors(t *testing.T) {
 
 	// Test convertLogicalNot with wrong number of arguments
 	t.Run("convertLogicalNot with 0 args", func(t *testing.T) {
-		_, err := converter.convertLogicalNot([]*exprpb.Expr{})
+		_, err := converter.convertLogicalNot([]celast.Expr{})
 		if err == nil {
 			t.Error("convertLogicalNot() with 0 args should return error")
 		}
@@ -1722,12 +1797,8 @@ func TestConverter_LogicalOperators_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertLogicalNot with 2 args", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "a"},
-			},
-		}
-		_, err := converter.convertLogicalNot([]*exprpb.Expr{arg, arg})
+		arg := exprFactory.NewIdent(0, "a")
+		_, err := converter.convertLogicalNot([]celast.Expr{arg, arg})
 		if err == nil {
 			t.Error("convertLogicalNot() with 2 args should return error")
 		}
@@ -1750,7 +1821,7 @@ func TestConverter_ComparisonOperator_ArgumentErrors(t *testing.T) {
 	}
 
 	t.Run("convertComparison with 0 args", func(t *testing.T) {
-		_, err := converter.convertComparison([]*exprpb.Expr{}, "=")
+		_, err := converter.convertComparison([]celast.Expr{}, "=")
 		if err == nil {
 			t.Error("convertComparison() with 0 args should return error")
 		}
@@ -1760,12 +1831,8 @@ func TestConverter_ComparisonOperator_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertComparison with 1 arg", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "field"},
-			},
-		}
-		_, err := converter.convertComparison([]*exprpb.Expr{arg}, "=")
+		arg := exprFactory.NewIdent(0, "field")
+		_, err := converter.convertComparison([]celast.Expr{arg}, "=")
 		if err == nil {
 			t.Error("convertComparison() with 1 arg should return error")
 		}
@@ -1786,14 +1853,7 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 
 	// Test contains with wrong number of arguments
 	t.Run("convertContains with 0 args", func(t *testing.T) {
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{},
-		}
+		call := exprFactory.NewMemberCall(0, "contains", exprFactory.NewIdent(0, "label")).AsCall()
 		_, err := converter.convertContains(call)
 		if err == nil {
 			t.Error("convertContains() with 0 args should return error")
@@ -1804,21 +1864,8 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertContains with 2 args", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_ConstExpr{
-				ConstExpr: &exprpb.Constant{
-					ConstantKind: &exprpb.Constant_StringValue{StringValue: "test"},
-				},
-			},
-		}
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{arg, arg},
-		}
+		arg := exprFactory.NewLiteral(0, types.String("test"))
+		call := exprFactory.NewMemberCall(0, "contains", exprFactory.NewIdent(0, "label"), arg, arg).AsCall()
 		_, err := converter.convertContains(call)
 		if err == nil {
 			t.Error("convertContains() with 2 args should return error")
@@ -1827,14 +1874,7 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 
 	// Test startsWith with wrong number of arguments
 	t.Run("convertStartsWith with 0 args", func(t *testing.T) {
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{},
-		}
+		call := exprFactory.NewMemberCall(0, "startsWith", exprFactory.NewIdent(0, "label")).AsCall()
 		_, err := converter.convertStartsWith(call)
 		if err == nil {
 			t.Error("convertStartsWith() with 0 args should return error")
@@ -1843,14 +1883,7 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 
 	// Test endsWith with wrong number of arguments
 	t.Run("convertEndsWith with 0 args", func(t *testing.T) {
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{},
-		}
+		call := exprFactory.NewMemberCall(0, "endsWith", exprFactory.NewIdent(0, "label")).AsCall()
 		_, err := converter.convertEndsWith(call)
 		if err == nil {
 			t.Error("convertEndsWith() with 0 args should return error")
@@ -1859,21 +1892,8 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 
 	// Test with non-string argument
 	t.Run("convertContains with int argument", func(t *testing.T) {
-		intArg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_ConstExpr{
-				ConstExpr: &exprpb.Constant{
-					ConstantKind: &exprpb.Constant_Int64Value{Int64Value: 42},
-				},
-			},
-		}
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{intArg},
-		}
+		intArg := exprFactory.NewLiteral(0, types.Int(42))
+		call := exprFactory.NewMemberCall(0, "contains", exprFactory.NewIdent(0, "label"), intArg).AsCall()
 		_, err := converter.convertContains(call)
 		if err == nil {
 			t.Error("convertContains() with int argument should return error")
@@ -1884,21 +1904,8 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertStartsWith with int argument", func(t *testing.T) {
-		intArg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_ConstExpr{
-				ConstExpr: &exprpb.Constant{
-					ConstantKind: &exprpb.Constant_Int64Value{Int64Value: 42},
-				},
-			},
-		}
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{intArg},
-		}
+		intArg := exprFactory.NewLiteral(0, types.Int(42))
+		call := exprFactory.NewMemberCall(0, "startsWith", exprFactory.NewIdent(0, "label"), intArg).AsCall()
 		_, err := converter.convertStartsWith(call)
 		if err == nil {
 			t.Error("convertStartsWith() with int argument should return error")
@@ -1906,21 +1913,8 @@ func TestConverter_StringOperations_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertEndsWith with int argument", func(t *testing.T) {
-		intArg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_ConstExpr{
-				ConstExpr: &exprpb.Constant{
-					ConstantKind: &exprpb.Constant_Int64Value{Int64Value: 42},
-				},
-			},
-		}
-		call := &exprpb.Expr_Call{
-			Target: &exprpb.Expr{
-				ExprKind: &exprpb.Expr_IdentExpr{
-					IdentExpr: &exprpb.Expr_Ident{Name: "label"},
-				},
-			},
-			Args: []*exprpb.Expr{intArg},
-		}
+		intArg := exprFactory.NewLiteral(0, types.Int(42))
+		call := exprFactory.NewMemberCall(0, "endsWith", exprFactory.NewIdent(0, "label"), intArg).AsCall()
 		_, err := converter.convertEndsWith(call)
 		if err == nil {
 			t.Error("convertEndsWith() with int argument should return error")
@@ -1941,7 +1935,7 @@ func TestConverter_InOperator_ArgumentErrors(t *testing.T) {
 	}
 
 	t.Run("convertInOperator with 0 args", func(t *testing.T) {
-		_, err := converter.convertInOperator([]*exprpb.Expr{})
+		_, err := converter.convertInOperator([]celast.Expr{})
 		if err == nil {
 			t.Error("convertInOperator() with 0 args should return error")
 		}
@@ -1951,12 +1945,8 @@ func TestConverter_InOperator_ArgumentErrors(t *testing.T) {
 	})
 
 	t.Run("convertInOperator with 1 arg", func(t *testing.T) {
-		arg := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "status"},
-			},
-		}
-		_, err := converter.convertInOperator([]*exprpb.Expr{arg})
+		arg := exprFactory.NewIdent(0, "status")
+		_, err := converter.convertInOperator([]celast.Expr{arg})
 		if err == nil {
 			t.Error("convertInOperator() with 1 arg should return error")
 		}
@@ -1977,18 +1967,8 @@ func TestConverter_GetListValues_ErrorInElement(t *testing.T) {
 
 	// Create a list expression with an invalid element (non-constant)
 	t.Run("getListValues with non-constant element", func(t *testing.T) {
-		invalidElement := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_IdentExpr{
-				IdentExpr: &exprpb.Expr_Ident{Name: "status"},
-			},
-		}
-		listExpr := &exprpb.Expr{
-			ExprKind: &exprpb.Expr_ListExpr{
-				ListExpr: &exprpb.Expr_CreateList{
-					Elements: []*exprpb.Expr{invalidElement},
-				},
-			},
-		}
+		invalidElement := exprFactory.NewIdent(0, "status")
+		listExpr := exprFactory.NewList(0, []celast.Expr{invalidElement}, nil)
 		_, err := converter.getListValues(listExpr)
 		if err == nil {
 			t.Error("getListValues() with non-constant element should return error")
@@ -2013,10 +1993,7 @@ func TestConverter_UnsupportedFunction(t *testing.T) {
 
 	// Test convertCallExpr with unsupported function
 	t.Run("convertCallExpr with unsupported function", func(t *testing.T) {
-		call := &exprpb.Expr_Call{
-			Function: "unsupported_function",
-			Args:     []*exprpb.Expr{},
-		}
+		call := exprFactory.NewCall(0, "unsupported_function").AsCall()
 		_, err := converter.convertCallExpr(call)
 		if err == nil {
 			t.Error("convertCallExpr() with unsupported function should return error")
@@ -2486,7 +2463,7 @@ func TestSecurity_FieldAuthorization(t *testing.T) {
 
 			if err != nil {
 				if convErr, ok := err.(*ConversionError); ok {
-					if tt.errCode != "" && convErr.ErrorCode != tt.errCode {
+					if tt.errCode != "" && string(convErr.ErrorCode) != tt.errCode {
 						t.Errorf("Expected error code %q, got %q", tt.errCode, convErr.ErrorCode)
 					}
 
@@ -2581,7 +2558,7 @@ func TestSecurity_TypeValidation(t *testing.T) {
 
 			if err != nil && tt.errCode != "" {
 				if convErr, ok := err.(*ConversionError); ok {
-					if convErr.ErrorCode != tt.errCode {
+					if string(convErr.ErrorCode) != tt.errCode {
 						t.Errorf("Expected error code %q, got %q", tt.errCode, convErr.ErrorCode)
 					}
 				}
@@ -2642,3 +2619,962 @@ func TestSecurity_Integration(t *testing.T) {
 		t.Errorf("Unexpected SQL structure: %s", sql)
 	}
 }
+
+// =============================================================================
+// TYPE CONVERSIONS
+// =============================================================================
+
+func TestConverter_Convert_CastCalls(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"score": {Type: cel.DoubleType, Column: "score"},
+		"id":    {Type: cel.IntType, Column: "user_id"},
+		"code":  {Type: cel.StringType, Column: "code"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{name: "int cast", celExpr: `int(score) > 3`, wantSQL: "CAST(CASE WHEN score < 0 THEN CEILING(score) ELSE FLOOR(score) END AS BIGINT) > ?", wantArgs: []any{int64(3)}},
+		{name: "int cast of int", celExpr: `int(id) > 3`, wantSQL: "CAST(user_id AS BIGINT) > ?", wantArgs: []any{int64(3)}},
+		{name: "int cast of arithmetic", celExpr: `int(score * 2.0) > 3`, wantSQL: "CAST(CASE WHEN (score * ?) < 0 THEN CEILING((score * ?)) ELSE FLOOR((score * ?)) END AS BIGINT) > ?", wantArgs: []any{2.0, 2.0, 2.0, int64(3)}},
+		{name: "string cast", celExpr: `string(id) == "42"`, wantSQL: "CAST(user_id AS VARCHAR) = ?", wantArgs: []any{"42"}},
+		{name: "double cast", celExpr: `double(id) <= 1.5`, wantSQL: "CAST(user_id AS DOUBLE PRECISION) <= ?", wantArgs: []any{1.5}},
+		{name: "nested cast", celExpr: `int(string(score)) == 1`, wantSQL: "CAST(CAST(score AS VARCHAR) AS BIGINT) = ?", wantArgs: []any{int64(1)}},
+		{name: "cast in IN", celExpr: `int(code) in [1, 2]`, wantSQL: "CAST(code AS BIGINT) IN (?,?)", wantArgs: []any{int64(1), int64(2)}},
+		{name: "cast with startsWith", celExpr: `string(id).startsWith("4")`, wantSQL: "CAST(user_id AS VARCHAR) LIKE ?", wantArgs: []any{"4%"}},
+		{name: "postgres string cast", dialect: DialectPostgreSQL, celExpr: `string(id) == "42"`, wantSQL: "CAST(user_id AS TEXT) = ?", wantArgs: []any{"42"}},
+		{name: "mysql int cast", dialect: DialectMySQL, celExpr: `int(score) > 3`, wantSQL: "CAST(TRUNCATE(score, 0) AS SIGNED) > ?", wantArgs: []any{int64(3)}},
+		{name: "postgres int cast", dialect: DialectPostgreSQL, celExpr: `int(score) > 3`, wantSQL: "CAST(TRUNC(score) AS BIGINT) > ?", wantArgs: []any{int64(3)}},
+		{name: "sqlite int cast", dialect: DialectSQLite, celExpr: `int(score) > 3`, wantSQL: "CAST(score AS INTEGER) > ?", wantArgs: []any{int64(3)}},
+		{name: "sqlite double cast", dialect: DialectSQLite, celExpr: `double(id) < 2.0`, wantSQL: "CAST(user_id AS REAL) < ?", wantArgs: []any{2.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("expected %d args, got %d", len(tt.wantArgs), len(args))
+			}
+
+			for i, arg := range args {
+				if arg != tt.wantArgs[i] {
+					t.Errorf("arg %d = %v (type %T), want %v (type %T)", i, arg, arg, tt.wantArgs[i], tt.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_UnsupportedConversion(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"score":     {Type: cel.DoubleType, Column: "score"},
+			"createdAt": {Type: cel.TimestampType, Column: "created_at"},
+			"ttl":       {Type: cel.DurationType, Column: "ttl"},
+			"active":    {Type: cel.BoolType, Column: "active"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCode string
+	}{
+		{name: "no CAST mapping", celExpr: `uint(score) > 3u`},
+		{name: "int of timestamp", celExpr: `int(createdAt) > 100`, wantCode: "UNSUPPORTED_OPERATION"},
+		{name: "string of timestamp", celExpr: `string(createdAt) == "x"`, wantCode: "UNSUPPORTED_OPERATION"},
+		{name: "string of duration", celExpr: `string(ttl) == "60s"`, wantCode: "UNSUPPORTED_OPERATION"},
+		{name: "string of bool", celExpr: `string(active) == "true"`, wantCode: "UNSUPPORTED_OPERATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if err == nil {
+				t.Fatal("Convert() should reject conversions without a SQL CAST mapping")
+			}
+			if tt.wantCode != "" && errorCode(err) != tt.wantCode {
+				t.Errorf("Convert() error = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// STRING OPERATIONS WITH FIELD ARGUMENTS
+// =============================================================================
+
+func TestConverter_Convert_StringOperationsFieldArgument(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"fullName":  {Type: cel.StringType, Column: "full_name"},
+		"firstName": {Type: cel.StringType, Column: "first_name"},
+		"id":        {Type: cel.IntType, Column: "id"},
+	}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		celExpr string
+		wantSQL string
+	}{
+		{name: "contains", celExpr: `fullName.contains(firstName)`, wantSQL: "POSITION(first_name IN full_name) > 0"},
+		{name: "startsWith", celExpr: `fullName.startsWith(firstName)`, wantSQL: "POSITION(first_name IN full_name) = 1"},
+		{name: "endsWith", celExpr: `fullName.endsWith(firstName)`, wantSQL: "SUBSTRING(full_name FROM CHAR_LENGTH(full_name) - CHAR_LENGTH(first_name) + 1) = first_name"},
+		{name: "cast argument", celExpr: `fullName.startsWith(string(id))`, wantSQL: "POSITION(CAST(id AS VARCHAR) IN full_name) = 1"},
+		{name: "postgres", dialect: DialectPostgreSQL, celExpr: `fullName.endsWith(firstName)`, wantSQL: "RIGHT(full_name, LENGTH(first_name)) = first_name"},
+		{name: "mysql", dialect: DialectMySQL, celExpr: `fullName.contains(firstName)`, wantSQL: "LOCATE(first_name, full_name) > 0"},
+		{name: "sqlserver", dialect: DialectSQLServer, celExpr: `fullName.startsWith(firstName)`, wantSQL: "IIF(DATALENGTH(first_name) = 0, 1, CHARINDEX(first_name, full_name)) = 1"},
+		{name: "sqlite", dialect: DialectSQLite, celExpr: `fullName.contains(firstName)`, wantSQL: "INSTR(full_name, first_name) > 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != 0 {
+				t.Errorf("expected no args, got %v", args)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_StringOperationsFieldArgumentValues(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+	rows := []struct {
+		name, other *string
+	}{
+		{name: ptr("50% off"), other: ptr("%")},
+		{name: ptr("half"), other: ptr("%")},
+		{name: ptr("a%"), other: ptr("a%")},
+		{name: ptr("a_c"), other: ptr("_")},
+		{name: ptr("abc"), other: ptr("_")},
+		{name: ptr("abc"), other: ptr("a_")},
+		{name: ptr(`a\b`), other: ptr(`\`)},
+		{name: ptr("abc"), other: ptr("")},
+		{name: ptr("abc"), other: ptr("bc")},
+		{name: ptr("abc"), other: ptr("xabc")},
+		{name: ptr("abc"), other: nil},
+		{name: nil, other: ptr("a")},
+	}
+	functions := map[string]func(s, substr string) bool{
+		"contains":   strings.Contains,
+		"startsWith": strings.HasPrefix,
+		"endsWith":   strings.HasSuffix,
+	}
+
+	for _, dialect := range []Dialect{DialectDefault, DialectPostgreSQL, DialectMySQL, DialectSQLite, DialectSQLServer} {
+		converter, err := NewConverter(Config{
+			FieldDeclarations: map[string]ColumnMapping{
+				"name":  {Type: cel.StringType},
+				"other": {Type: cel.StringType},
+			},
+			Dialect: dialect,
+		})
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+
+		for function, match := range functions {
+			result, err := converter.Convert(fmt.Sprintf("name.%s(other)", function))
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			// Values are matched literally, and NULL rows are never selected
+			for _, row := range rows {
+				want := row.name != nil && row.other != nil && match(*row.name, *row.other)
+				got := evalSQL(t, sql, map[string]*string{"name": row.name, "other": row.other}) == true
+				if got != want {
+					t.Errorf("%s: %s selects (%v, %v) = %v, want %v", dialect, sql, deref(row.name), deref(row.other), got, want)
+				}
+			}
+		}
+	}
+}
+
+func deref(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// evalSQL evaluates the string functions and comparisons of generated SQL
+// over a row of string columns, with NULL as nil.
+func evalSQL(t *testing.T, sql string, row map[string]*string) any {
+	t.Helper()
+	tokens, err := tokenizeSQL(sql)
+	if err != nil {
+		t.Fatalf("tokenizeSQL(%s) error = %v", sql, err)
+	}
+	e := &sqlEvaluator{t: t, sql: sql, tokens: tokens, row: row}
+	value := e.comparison()
+	if e.pos != len(tokens) {
+		t.Fatalf("%s: unexpected token %q", sql, tokens[e.pos].text)
+	}
+	return value
+}
+
+// sqlEvaluator is a recursive descent evaluator of generated SQL.
+type sqlEvaluator struct {
+	t      *testing.T
+	sql    string
+	tokens []sqlToken
+	pos    int
+	row    map[string]*string
+}
+
+func (e *sqlEvaluator) peek() string {
+	if e.pos >= len(e.tokens) {
+		return ""
+	}
+	return e.tokens[e.pos].text
+}
+
+func (e *sqlEvaluator) expect(text string) {
+	if !strings.EqualFold(e.peek(), text) {
+		e.t.Fatalf("%s: got %q, want %q", e.sql, e.peek(), text)
+	}
+	e.pos++
+}
+
+func (e *sqlEvaluator) comparison() any {
+	left := e.sum()
+	op := e.peek()
+	if op != "=" && op != ">" {
+		return left
+	}
+	e.pos++
+	right := e.sum()
+	if left == nil || right == nil {
+		return nil
+	}
+	if op == ">" {
+		return left.(int64) > right.(int64)
+	}
+	return left == right
+}
+
+func (e *sqlEvaluator) sum() any {
+	value := e.primary()
+	for op := e.peek(); op == "+" || op == "-"; op = e.peek() {
+		e.pos++
+		right := e.primary()
+		switch {
+		case value == nil || right == nil:
+			value = nil
+		case op == "+":
+			if s, ok := value.(string); ok {
+				value = s + right.(string)
+			} else {
+				value = value.(int64) + right.(int64)
+			}
+		default:
+			value = value.(int64) - right.(int64)
+		}
+	}
+	return value
+}
+
+func (e *sqlEvaluator) primary() any {
+	token := e.tokens[e.pos]
+	e.pos++
+	switch token.kind {
+	case tokenNumber:
+		var n int64
+		fmt.Sscan(token.text, &n)
+		return n
+	case tokenLiteral:
+		return token.text
+	case tokenIdentifier:
+		if e.peek() == "(" {
+			return e.call(strings.ToUpper(token.text))
+		}
+		if value := e.row[token.text]; value != nil {
+			return *value
+		}
+		return nil
+	}
+	if token.text == "(" {
+		value := e.comparison()
+		e.expect(")")
+		return value
+	}
+	e.t.Fatalf("%s: unexpected token %q", e.sql, token.text)
+	return nil
+}
+
+func (e *sqlEvaluator) call(function string) any {
+	e.expect("(")
+	var args []any
+	switch function {
+	case "POSITION":
+		args = append(args, e.comparison())
+		e.expect("IN")
+		args = append(args, e.comparison())
+	case "SUBSTRING":
+		args = append(args, e.comparison())
+		e.expect("FROM")
+		args = append(args, e.comparison())
+	default:
+		for args = append(args, e.comparison()); e.peek() == ","; args = append(args, e.comparison()) {
+			e.pos++
+		}
+	}
+	e.expect(")")
+
+	if function == "IIF" {
+		if args[0] == true {
+			return args[1]
+		}
+		return args[2]
+	}
+	if slices.Contains(args, nil) {
+		return nil
+	}
+	position := func(s, substr string) any {
+		if i := strings.Index(s, substr); i >= 0 {
+			return int64(len([]rune(s[:i])) + 1)
+		}
+		return int64(0)
+	}
+	runes := func(i int) []rune { return []rune(args[i].(string)) }
+	switch function {
+	case "STRPOS", "INSTR":
+		return position(args[0].(string), args[1].(string))
+	case "LOCATE", "POSITION":
+		return position(args[1].(string), args[0].(string))
+	case "CHARINDEX":
+		if args[0] == "" {
+			return int64(0)
+		}
+		return position(args[1].(string), args[0].(string))
+	case "DATALENGTH":
+		return int64(len(args[0].(string)))
+	case "LENGTH", "CHAR_LENGTH":
+		return int64(len(runes(0)))
+	case "LEN":
+		return int64(len([]rune(strings.TrimRight(args[0].(string), " "))))
+	case "LOWER":
+		return strings.ToLower(args[0].(string))
+	case "RIGHT":
+		s, n := runes(0), int(args[1].(int64))
+		return string(s[len(s)-min(max(n, 0), len(s)):])
+	case "SUBSTR":
+		// SQLite counts negative starts from the end
+		s, start := runes(0), int(args[1].(int64))
+		switch {
+		case start < 0:
+			return string(s[max(len(s)+start, 0):])
+		case start == 0:
+			return string(s)
+		}
+		return string(s[min(start-1, len(s)):])
+	case "SUBSTRING":
+		s, start := runes(0), int(args[1].(int64))
+		return string(s[min(max(start-1, 0), len(s)):])
+	}
+	e.t.Fatalf("%s: unknown function %s", e.sql, function)
+	return nil
+}
+
+// =============================================================================
+// BETWEEN OPTIMIZATION
+// =============================================================================
+
+func TestConverter_Convert_Between(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"age":    {Type: cel.IntType, Column: "user_age"},
+		"score":  {Type: cel.DoubleType, Column: "score"},
+		"status": {Type: cel.StringType, Column: "status"},
+	}
+
+	tests := []struct {
+		name       string
+		useBetween bool
+		celExpr    string
+		wantSQL    string
+		wantArgs   []any
+	}{
+		{
+			name:       "lower then upper",
+			useBetween: true,
+			celExpr:    `age >= 18 && age <= 30`,
+			wantSQL:    "user_age BETWEEN ? AND ?",
+			wantArgs:   []any{int64(18), int64(30)},
+		},
+		{
+			name:       "upper then lower",
+			useBetween: true,
+			celExpr:    `age <= 30 && age >= 18`,
+			wantSQL:    "user_age BETWEEN ? AND ?",
+			wantArgs:   []any{int64(18), int64(30)},
+		},
+		{
+			name:       "nested in OR",
+			useBetween: true,
+			celExpr:    `(age >= 18 && age <= 30) || status == "vip"`,
+			wantSQL:    "(user_age BETWEEN ? AND ? OR status = ?)",
+			wantArgs:   []any{int64(18), int64(30), "vip"},
+		},
+		{
+			name:       "different columns",
+			useBetween: true,
+			celExpr:    `age >= 18 && score <= 3.0`,
+			wantSQL:    "(user_age >= ? AND score <= ?)",
+			wantArgs:   []any{int64(18), 3.0},
+		},
+		{
+			name:       "exclusive bounds",
+			useBetween: true,
+			celExpr:    `age > 18 && age < 30`,
+			wantSQL:    "(user_age > ? AND user_age < ?)",
+			wantArgs:   []any{int64(18), int64(30)},
+		},
+		{
+			name:     "disabled by default",
+			celExpr:  `age >= 18 && age <= 30`,
+			wantSQL:  "(user_age >= ? AND user_age <= ?)",
+			wantArgs: []any{int64(18), int64(30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, UseBetween: tt.useBetween})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("expected %d args, got %d", len(tt.wantArgs), len(args))
+			}
+
+			for i, arg := range args {
+				if arg != tt.wantArgs[i] {
+					t.Errorf("arg %d = %v, want %v", i, arg, tt.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+// =============================================================================
+// OR-CHAIN COLLAPSING
+// =============================================================================
+
+func TestConverter_Convert_CollapseOrToIn(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status":    {Type: cel.StringType, Column: "status"},
+		"age":       {Type: cel.IntType, Column: "age"},
+		"deletedAt": {Type: cel.TimestampType, Column: "deleted_at"},
+	}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		maxIn    int
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "equality chain",
+			celExpr:  `status == "a" || status == "b" || status == "c"`,
+			wantSQL:  "status IN (?,?,?)",
+			wantArgs: []any{"a", "b", "c"},
+		},
+		{
+			name:     "chain with IN operand",
+			celExpr:  `status in ["a", "b"] || status == "c"`,
+			wantSQL:  "status IN (?,?,?)",
+			wantArgs: []any{"a", "b", "c"},
+		},
+		{
+			name:     "different columns kept",
+			celExpr:  `status == "a" || age == 3`,
+			wantSQL:  "(status = ? OR age = ?)",
+			wantArgs: []any{"a", int64(3)},
+		},
+		{
+			name:     "partial chain",
+			celExpr:  `status == "a" || status == "b" || age == 3`,
+			wantSQL:  "(status IN (?,?) OR age = ?)",
+			wantArgs: []any{"a", "b", int64(3)},
+		},
+		{
+			name:     "null checks kept",
+			celExpr:  `deletedAt == null || deletedAt == null`,
+			wantSQL:  "(deleted_at IS NULL OR deleted_at IS NULL)",
+			wantArgs: []any{},
+		},
+		{
+			name:     "respects IN clause limit",
+			maxIn:    2,
+			celExpr:  `status == "a" || status == "b" || status == "c"`,
+			wantSQL:  "(status IN (?,?) OR status = ?)",
+			wantArgs: []any{"a", "b", "c"},
+		},
+		{
+			name:     "disabled",
+			disabled: true,
+			celExpr:  `status == "a" || status == "b"`,
+			wantSQL:  "(status = ? OR status = ?)",
+			wantArgs: []any{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{
+				FieldDeclarations: fields,
+				CollapseOrToIn:    !tt.disabled,
+				MaxInClauseSize:   tt.maxIn,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("expected %d args, got %d", len(tt.wantArgs), len(args))
+			}
+
+			for i, arg := range args {
+				if arg != tt.wantArgs[i] {
+					t.Errorf("arg %d = %v, want %v", i, arg, tt.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+// =============================================================================
+// CONTEXT PROPAGATION
+// =============================================================================
+
+type contextKey struct{}
+
+// recordingLogger records the context value of every security event.
+type recordingLogger struct {
+	events []string
+}
+
+func (l *recordingLogger) record(ctx context.Context, event string) {
+	requestID, _ := ctx.Value(contextKey{}).(string)
+	l.events = append(l.events, event+":"+requestID)
+}
+
+func (l *recordingLogger) LogConversionAttempt(ctx context.Context, _ string, _ bool, _ error, _ time.Duration) {
+	l.record(ctx, "attempt")
+}
+
+func (l *recordingLogger) LogComplexExpression(ctx context.Context, _ string, _ int, _ int) {
+	l.record(ctx, "complex")
+}
+
+func (l *recordingLogger) LogUnauthorizedField(ctx context.Context, _ string, _ string, _ []string) {
+	l.record(ctx, "unauthorized")
+}
+
+func (l *recordingLogger) LogUnsupportedOperation(ctx context.Context, _ string, _ string) {
+	l.record(ctx, "unsupported")
+}
+
+func TestConverter_ContextPropagation(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":   {Type: cel.StringType, Column: "name"},
+			"secret": {Type: cel.StringType, Column: "secret"},
+		},
+		MaxExpressionLength: 40,
+		PublicFields:        []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	logger := &recordingLogger{}
+	converter.securityLogger = logger
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "req-1")
+
+	if _, err := converter.ConvertContext(ctx, `name.matches("^a")`); err == nil {
+		t.Error("expected unsupported operation error")
+	}
+	if _, err := converter.ConvertWithAuthContext(ctx, `secret == "x"`, []string{"user"}); err == nil {
+		t.Error("expected unauthorized field error")
+	}
+	if _, err := converter.ConvertContext(ctx, `name == "a" || name == "abcdefghijk"`); err != nil {
+		t.Errorf("ConvertContext() error = %v", err)
+	}
+	if _, err := converter.ConvertWithAuthContext(ctx, `name == "a" || name == "abcdefghijk"`, []string{"user"}); err != nil {
+		t.Errorf("ConvertWithAuthContext() error = %v", err)
+	}
+	if _, err := converter.ConvertHybridContext(ctx, `name.matches("^a")`); err != nil {
+		t.Errorf("ConvertHybridContext() error = %v", err)
+	}
+	if _, err := converter.Convert(`name.matches("^a")`); err == nil {
+		t.Error("expected unsupported operation error")
+	}
+
+	want := []string{
+		"unsupported:req-1", "attempt:req-1",
+		"unauthorized:req-1", "attempt:req-1",
+		"complex:req-1", "attempt:req-1",
+		"complex:req-1", "attempt:req-1",
+		"unsupported:req-1", "attempt:req-1",
+		"unsupported:", "attempt:",
+	}
+	if !slices.Equal(logger.events, want) {
+		t.Errorf("events = %v, want %v", logger.events, want)
+	}
+}
+
+// attemptLogger records the conversion attempts.
+type attemptLogger struct {
+	recordingLogger
+	attempts []string
+}
+
+func (l *attemptLogger) LogConversionAttempt(_ context.Context, expr string, success bool, err error, duration time.Duration) {
+	if duration <= 0 || success != (err == nil) {
+		l.attempts = append(l.attempts, "invalid attempt")
+		return
+	}
+	l.attempts = append(l.attempts, fmt.Sprintf("%s:%t", expr, success))
+}
+
+func TestConverter_SecurityLoggerConfig(t *testing.T) {
+	logger := &attemptLogger{}
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name": {Type: cel.StringType},
+		},
+		SecurityLogger: logger,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	ast, iss := converter.Env().Compile(`name == "c"`)
+	if iss.Err() != nil {
+		t.Fatalf("Compile() error = %v", iss.Err())
+	}
+
+	ctx := context.Background()
+	converter.Convert(`name == "a"`)
+	converter.ConvertWithAuth(`name.matches("^a")`, nil)
+	converter.ConvertAst(ast)
+	converter.ConvertAll([]string{`name == "a"`, `name == "b"`}, LogicalOr)
+	converter.ConvertWithScopes(ctx, `name == "d"`, nil, nil)
+	converter.ConvertHybrid(`name == "e"`)
+
+	want := []string{
+		`name == "a":true`,
+		`name.matches("^a"):false`,
+		`name == "c":true`,
+		`(name == "a") || (name == "b"):true`,
+		`name == "d":true`,
+		`name == "e":true`,
+	}
+	if !slices.Equal(logger.attempts, want) {
+		t.Errorf("attempts = %v, want %v", logger.attempts, want)
+	}
+}
+
+// =============================================================================
+// CASE-INSENSITIVE STRING OPERATIONS
+// =============================================================================
+
+func TestConverter_Convert_CaseInsensitiveLike(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "postgres contains",
+			config:   Config{CaseInsensitiveLike: true, Dialect: DialectPostgreSQL},
+			celExpr:  `name.contains("Bob")`,
+			wantSQL:  "name ILIKE ?",
+			wantArgs: []any{"%Bob%"},
+		},
+		{
+			name:     "default startsWith",
+			config:   Config{CaseInsensitiveLike: true},
+			celExpr:  `name.startsWith("Bo")`,
+			wantSQL:  "LOWER(name) LIKE LOWER(?)",
+			wantArgs: []any{"Bo%"},
+		},
+		{
+			name:     "per-field option",
+			config:   Config{Dialect: DialectMySQL},
+			celExpr:  `title.endsWith("x_y") && name.endsWith("z")`,
+			wantSQL:  "(LOWER(title) LIKE LOWER(?) AND name LIKE ?)",
+			wantArgs: []any{"%x\\_y", "%z"},
+		},
+		{
+			name:     "field argument",
+			config:   Config{CaseInsensitiveLike: true, Dialect: DialectPostgreSQL},
+			celExpr:  `title.contains(name)`,
+			wantSQL:  "STRPOS(LOWER(title), LOWER(name)) > 0",
+			wantArgs: nil,
+		},
+		{
+			name:     "pushed down negation",
+			config:   Config{CaseInsensitiveLike: true, Dialect: DialectPostgreSQL, PushDownNot: true},
+			celExpr:  `!name.contains("bob")`,
+			wantSQL:  "name NOT ILIKE ?",
+			wantArgs: []any{"%bob%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.FieldDeclarations = map[string]ColumnMapping{
+				"name":  {Type: cel.StringType, Column: "name"},
+				"title": {Type: cel.StringType, Column: "title", CaseInsensitive: true},
+			}
+			converter, err := NewConverter(config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// ERROR CODES
+// =============================================================================
+
+func TestConverter_ErrorCodes(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "status"},
+			"a":      {Type: cel.BoolType, Column: "a"},
+		},
+		MaxExpressionLength: 60,
+		MaxExpressionDepth:  3,
+		MaxInClauseSize:     2,
+		MaxConversionBytes:  100,
+		PublicFields:        []string{"status", "a"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCode string
+	}{
+		{name: "length", celExpr: `status == "` + strings.Repeat("x", 60) + `"`, wantCode: "LIMIT_LENGTH"},
+		{name: "depth", celExpr: `((a && a) && a) && a`, wantCode: "LIMIT_DEPTH"},
+		{name: "in size", celExpr: `status in ["a", "b", "c"]`, wantCode: "LIMIT_IN_SIZE"},
+		{name: "memory", celExpr: `status == "` + strings.Repeat("x", 40) + `"`, wantCode: "LIMIT_MEMORY"},
+		{name: "syntax", celExpr: `status ==`, wantCode: "INVALID_SYNTAX"},
+		{name: "unsupported shape", celExpr: `"a" == status`, wantCode: "UNSUPPORTED_OPERATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for method, convert := range map[string]func(string) (*ConvertResult, error){
+				"Convert": func(celExpr string) (*ConvertResult, error) {
+					return converter.Convert(celExpr)
+				},
+				"ConvertWithAuth": func(celExpr string) (*ConvertResult, error) {
+					return converter.ConvertWithAuth(celExpr, nil)
+				},
+			} {
+				_, err := convert(tt.celExpr)
+				convErr, ok := err.(*ConversionError)
+				if !ok {
+					t.Fatalf("%s() error = %v, want *ConversionError", method, err)
+				}
+				if string(convErr.ErrorCode) != tt.wantCode {
+					t.Errorf("%s() code = %q, want %q (%v)", method, convErr.ErrorCode, tt.wantCode, convErr.InternalError)
+				}
+			}
+		})
+	}
+}
+
+// =============================================================================
+// ALLOCATION BUDGET
+// =============================================================================
+
+// budgetExpressions are the filters of the conversion benchmarks, with the
+// allocations allowed to convert their precompiled AST.
+var budgetExpressions = []struct {
+	name      string
+	celExpr   string
+	maxAllocs float64
+}{
+	{name: "comparison", celExpr: `status == "active"`, maxAllocs: 24},
+	{name: "conjunction", celExpr: `status in ["a", "b", "c"] && age >= 18 && name.contains("bob")`, maxAllocs: 50},
+}
+
+func newBudgetConverter(tb testing.TB) *Converter {
+	tb.Helper()
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+			"name":   {Type: cel.StringType},
+		},
+	})
+	if err != nil {
+		tb.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConvertAst_AllocationBudget(t *testing.T) {
+	converter := newBudgetConverter(t)
+
+	for _, tt := range budgetExpressions {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := converter.Env().Compile(tt.celExpr)
+			if issues.Err() != nil {
+				t.Fatalf("failed to compile: %v", issues.Err())
+			}
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := converter.ConvertAst(ast); err != nil {
+					t.Fatalf("ConvertAst() error = %v", err)
+				}
+			})
+			if allocs > tt.maxAllocs {
+				t.Errorf("ConvertAst() allocs = %v, budget %v", allocs, tt.maxAllocs)
+			}
+		})
+	}
+}
+
+// BenchmarkConvert measures conversions from source, dominated by the
+// parsing and type-checking of cel-go.
+func BenchmarkConvert(b *testing.B) {
+	converter := newBudgetConverter(b)
+
+	for _, tt := range budgetExpressions {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := converter.Convert(tt.celExpr); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkConvertAst measures conversions of precompiled ASTs, the share
+// of the conversion owned by this package.
+func BenchmarkConvertAst(b *testing.B) {
+	converter := newBudgetConverter(b)
+
+	for _, tt := range budgetExpressions {
+		ast, issues := converter.Env().Compile(tt.celExpr)
+		if issues.Err() != nil {
+			b.Fatalf("failed to compile: %v", issues.Err())
+		}
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := converter.ConvertAst(ast); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
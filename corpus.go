@@ -0,0 +1,234 @@
+package cel2squirrel
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// CorpusEntry is a filter expression recorded by a Corpus: normalized and
+// with its literal values redacted, along with the SQL it converts to.
+type CorpusEntry struct {
+	// Expr is the normalized, redacted CEL expression.
+	Expr string `json:"expr"`
+	// SQL is the SQL the expression converted to when recorded.
+	SQL string `json:"sql"`
+	// Count is the number of recorded filters normalizing to Expr.
+	Count int `json:"count"`
+}
+
+// Corpus records the distinct shapes of the filters converted in
+// production, to be exported as a fuzz and regression corpus and replayed
+// against new configurations or library versions with ReplayCorpus. It is
+// safe for concurrent use; attach it to converters with Config.Corpus.
+//
+// Expressions are normalized by formatting, and every literal value is
+// replaced by a placeholder of the same type, e.g. "x" or 1, except map keys
+// and timestamp and duration strings, so that the corpus holds no user data.
+type Corpus struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*CorpusEntry
+}
+
+// NewCorpus creates an empty corpus recording at most maxEntries distinct
+// expressions, or any number when zero. Once full, only the counts of the
+// recorded expressions are updated.
+func NewCorpus(maxEntries int) *Corpus {
+	return &Corpus{maxEntries: maxEntries, entries: make(map[string]*CorpusEntry)}
+}
+
+// record adds a converted filter to the corpus.
+func (c *Converter) record(celExpr string) {
+	expr, err := c.normalizeExpr(celExpr)
+	if err != nil {
+		return
+	}
+
+	corpus := c.corpus
+	corpus.mu.Lock()
+	if entry, ok := corpus.entries[expr]; ok {
+		entry.Count++
+		corpus.mu.Unlock()
+		return
+	}
+	full := corpus.maxEntries > 0 && len(corpus.entries) >= corpus.maxEntries
+	corpus.mu.Unlock()
+	if full {
+		return
+	}
+
+	// Redaction may turn the filter into one the converter rejects
+	sql, err := c.corpusSQL(expr)
+	if err != nil {
+		return
+	}
+
+	corpus.mu.Lock()
+	defer corpus.mu.Unlock()
+	if entry, ok := corpus.entries[expr]; ok {
+		entry.Count++
+		return
+	}
+	if corpus.maxEntries == 0 || len(corpus.entries) < corpus.maxEntries {
+		corpus.entries[expr] = &CorpusEntry{Expr: expr, SQL: sql, Count: 1}
+	}
+}
+
+// corpusSQL converts a corpus expression, without recording it.
+func (c *Converter) corpusSQL(celExpr string) (string, error) {
+	_, checkedExpr, err := c.compile(c.context(), celExpr)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.convertChecked(c.context(), checkedExpr.Expr(), checkedExpr.cost)
+	if err != nil {
+		return "", err
+	}
+	sql, _, err := result.Where.ToSql()
+	return sql, err
+}
+
+// Entries returns the recorded expressions, most frequent first.
+func (c *Corpus) Entries() []CorpusEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]CorpusEntry, 0, len(c.entries))
+	for _, expr := range slices.Sorted(maps.Keys(c.entries)) {
+		entries = append(entries, *c.entries[expr])
+	}
+	slices.SortStableFunc(entries, func(a, b CorpusEntry) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	return entries
+}
+
+// WriteTo writes the recorded expressions to w as JSON lines, one
+// CorpusEntry per line, most frequent first.
+func (c *Corpus) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, entry := range c.Entries() {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(append(line, '\n'))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// ReadCorpus reads corpus entries written by Corpus.WriteTo. Blank lines
+// are skipped.
+func ReadCorpus(r io.Reader) ([]CorpusEntry, error) {
+	var entries []CorpusEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry CorpusEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("corpus line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// CorpusMismatch is a corpus entry whose conversion changed on replay.
+type CorpusMismatch struct {
+	Entry CorpusEntry
+	// SQL is the SQL the expression converts to now, empty on error.
+	SQL string
+	// Err is the conversion error, if the expression is now rejected.
+	Err error
+}
+
+// ReplayCorpus converts the corpus entries and returns those that are now
+// rejected, or whose SQL changed, e.g. to check a new configuration or
+// library version against the filters observed in production.
+func (c *Converter) ReplayCorpus(entries []CorpusEntry) []CorpusMismatch {
+	c = c.current()
+	var mismatches []CorpusMismatch
+	for _, entry := range entries {
+		sql, err := c.corpusSQL(entry.Expr)
+		if err != nil || sql != entry.SQL {
+			mismatches = append(mismatches, CorpusMismatch{Entry: entry, SQL: sql, Err: err})
+		}
+	}
+	return mismatches
+}
+
+// corpusEnv parses expressions without expanding macros, so that they can
+// be formatted back as written.
+var corpusEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(cel.ClearMacros(), cel.OptionalTypes())
+})
+
+// normalizeExpr formats a CEL expression with its literal values redacted.
+func (c *Converter) normalizeExpr(celExpr string) (string, error) {
+	env, err := corpusEnv()
+	if err != nil {
+		return "", err
+	}
+	parsed, issues := env.Parse(celExpr)
+	if issues != nil && issues.Err() != nil {
+		return "", issues.Err()
+	}
+	c.redactLiterals(parsed.NativeRep().Expr())
+	return cel.AstToString(parsed)
+}
+
+// redactLiterals replaces the literal values of a parsed expression with
+// placeholders of the same type, keeping map keys and the arguments of
+// timestamp() and duration(), which must remain valid. The expression is
+// rewritten in place.
+func (c *Converter) redactLiterals(expr celast.Expr) {
+	kept := make(map[int64]bool)
+	walkExpr(expr, func(e celast.Expr) {
+		if args := e.AsCall().Args(); len(args) > 0 {
+			switch e.AsCall().FunctionName() {
+			case "timestamp", "duration":
+				kept[args[0].ID()] = true
+			case "_[_]", "_[?_]", "_?._":
+				kept[args[len(args)-1].ID()] = true
+			}
+		}
+
+		if e.Kind() != celast.LiteralKind || kept[e.ID()] {
+			return
+		}
+		var placeholder ref.Val
+		switch e.AsLiteral().(type) {
+		case types.String:
+			placeholder = types.String("x")
+		case types.Bytes:
+			placeholder = types.Bytes("x")
+		case types.Int:
+			placeholder = types.Int(1)
+		case types.Uint:
+			placeholder = types.Uint(1)
+		case types.Double:
+			placeholder = types.Double(1)
+		default:
+			return
+		}
+		e.SetKindCase(exprFactory.NewLiteral(e.ID(), placeholder))
+	})
+}
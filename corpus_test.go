@@ -0,0 +1,181 @@
+package cel2squirrel
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newCorpusConverter(t *testing.T, corpus *Corpus, fields map[string]ColumnMapping) *Converter {
+	t.Helper()
+	converter, err := NewConverter(Config{
+		FieldDeclarations: fields,
+		TableAlias:        "users",
+		Dialect:           DialectPostgreSQL,
+		Corpus:            corpus,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+var corpusFields = map[string]ColumnMapping{
+	"name":       {Type: cel.StringType},
+	"age":        {Type: cel.IntType},
+	"score":      {Type: cel.DoubleType},
+	"created_at": {Type: cel.TimestampType},
+	"labels":     {Type: cel.MapType(cel.StringType, cel.StringType)},
+}
+
+func TestCorpus_Record(t *testing.T) {
+	tests := []struct {
+		name     string
+		celExprs []string
+		want     []CorpusEntry
+	}{
+		{
+			name:     "literals redacted",
+			celExprs: []string{`name == "alice" && age > 30`, `name == "bob" && age > 18`},
+			want: []CorpusEntry{
+				{Expr: `name == "x" && age > 1`, SQL: "(users.name = ? AND users.age > ?)", Count: 2},
+			},
+		},
+		{
+			name:     "formatting normalized",
+			celExprs: []string{`score>=2.5`, `score >= 0.1`},
+			want: []CorpusEntry{
+				{Expr: `score >= 1.0`, SQL: "users.score >= ?", Count: 2},
+			},
+		},
+		{
+			name:     "timestamps kept",
+			celExprs: []string{`created_at > timestamp("2024-01-01T00:00:00Z")`},
+			want: []CorpusEntry{
+				{Expr: `created_at > timestamp("2024-01-01T00:00:00Z")`, SQL: "users.created_at > ?", Count: 1},
+			},
+		},
+		{
+			name:     "map keys kept",
+			celExprs: []string{`labels[?"team"].orValue("core") == "infra"`},
+			want: []CorpusEntry{
+				{Expr: `labels[?"team"].orValue("x") == "x"`, SQL: "COALESCE(users.labels->>'team', ?) = ?", Count: 1},
+			},
+		},
+		{
+			name:     "most frequent first",
+			celExprs: []string{`age == 1`, `name == "a"`, `name == "b"`},
+			want: []CorpusEntry{
+				{Expr: `name == "x"`, SQL: "users.name = ?", Count: 2},
+				{Expr: `age == 1`, SQL: "users.age = ?", Count: 1},
+			},
+		},
+		{
+			name:     "rejected filters not recorded",
+			celExprs: []string{`unknown == 1`},
+			want:     []CorpusEntry{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			corpus := NewCorpus(0)
+			converter := newCorpusConverter(t, corpus, corpusFields)
+			for _, celExpr := range tt.celExprs {
+				_, _ = converter.Convert(celExpr)
+			}
+
+			if got := corpus.Entries(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Entries() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorpus_Record_Macros(t *testing.T) {
+	corpus := NewCorpus(0)
+	converter := newTestCollectionConverter(t, Config{Corpus: corpus})
+	if _, err := converter.Convert(`comments.exists(c, c.body.contains("spam"))`); err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	entries := corpus.Entries()
+	if len(entries) != 1 || entries[0].Expr != `comments.exists(c, c.body.contains("x"))` {
+		t.Errorf("Entries() = %#v, want the exists() macro as written", entries)
+	}
+}
+
+func TestCorpus_MaxEntries(t *testing.T) {
+	corpus := NewCorpus(1)
+	converter := newCorpusConverter(t, corpus, corpusFields)
+	for _, celExpr := range []string{`age == 1`, `name == "a"`, `age == 2`} {
+		if _, err := converter.Convert(celExpr); err != nil {
+			t.Fatalf("Convert(%q) error = %v", celExpr, err)
+		}
+	}
+
+	want := []CorpusEntry{{Expr: `age == 1`, SQL: "users.age = ?", Count: 2}}
+	if got := corpus.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %#v, want %#v", got, want)
+	}
+}
+
+func TestCorpus_Replay(t *testing.T) {
+	corpus := NewCorpus(0)
+	recorder := newCorpusConverter(t, corpus, corpusFields)
+	for _, celExpr := range []string{`name == "alice"`, `age > 30`, `score < 1.5`} {
+		if _, err := recorder.Convert(celExpr); err != nil {
+			t.Fatalf("Convert(%q) error = %v", celExpr, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := corpus.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	entries, err := ReadCorpus(&buf)
+	if err != nil {
+		t.Fatalf("ReadCorpus() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries, corpus.Entries()) {
+		t.Fatalf("ReadCorpus() = %#v, want %#v", entries, corpus.Entries())
+	}
+
+	if mismatches := recorder.ReplayCorpus(entries); len(mismatches) != 0 {
+		t.Errorf("ReplayCorpus() on the recording converter = %#v, want none", mismatches)
+	}
+
+	// age moves to another column and score is no longer declared
+	changed := newCorpusConverter(t, nil, map[string]ColumnMapping{
+		"name": {Type: cel.StringType},
+		"age":  {Type: cel.IntType, Column: "age_years"},
+	})
+	mismatches := changed.ReplayCorpus(entries)
+	if len(mismatches) != 2 {
+		t.Fatalf("ReplayCorpus() = %#v, want 2 mismatches", mismatches)
+	}
+	for _, mismatch := range mismatches {
+		switch mismatch.Entry.Expr {
+		case `age > 1`:
+			if mismatch.Err != nil || mismatch.SQL != "users.age_years > ?" {
+				t.Errorf("age mismatch = %#v, want SQL users.age_years > ?", mismatch)
+			}
+		case `score < 1.0`:
+			if mismatch.Err == nil {
+				t.Errorf("score mismatch = %#v, want an error", mismatch)
+			}
+		default:
+			t.Errorf("unexpected mismatch %#v", mismatch)
+		}
+	}
+}
+
+func TestReadCorpus_Invalid(t *testing.T) {
+	_, err := ReadCorpus(bytes.NewBufferString("{\"expr\":\"a == 1\"}\n\nnot json\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "corpus line 3:") {
+		t.Errorf("ReadCorpus() error = %v, want an error on line 3", err)
+	}
+}
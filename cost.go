@@ -0,0 +1,70 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/google/cel-go/checker"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// checkedFilter is a checked filter expression with its estimated cost.
+type checkedFilter struct {
+	*celast.AST
+	cost uint64
+}
+
+// costFieldSize is the size assumed for string, bytes, list and map fields
+// when estimating the cost of an expression, as their values are unknown.
+const costFieldSize = 1024
+
+// costEstimator sizes the fields of an expression for the cel-go cost
+// estimator, leaving the cost of calls to its defaults.
+type costEstimator struct{}
+
+// EstimateSize implements checker.CostEstimator.
+func (costEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	if element.Path() == nil {
+		return nil
+	}
+	return &checker.SizeEstimate{Min: 0, Max: costFieldSize}
+}
+
+// EstimateCallCost implements checker.CostEstimator.
+func (costEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
+// estimateCost returns the worst-case evaluation cost of a checked
+// expression estimated by cel-go, enforcing the configured budget. Without
+// budget, the cost is not estimated and reported as 0.
+func (c *Converter) estimateCost(checked *celast.AST) (uint64, error) {
+	if c.maxExpressionCost == 0 {
+		return 0, nil
+	}
+	estimate, err := checker.Cost(checked, costEstimator{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate expression cost: %w", err)
+	}
+	return estimate.Max, c.checkCost(estimate.Max)
+}
+
+// checkCost enforces the maximum estimated cost of expressions.
+func (c *Converter) checkCost(cost uint64) error {
+	if c.maxExpressionCost == 0 || cost <= c.maxExpressionCost {
+		return nil
+	}
+	return newConversionError(
+		"filter expression exceeds maximum cost",
+		CodeLimitCost,
+		fmt.Errorf("expression cost %d exceeds maximum of %d", cost, c.maxExpressionCost),
+	)
+}
+
+// addCost adds two costs, saturating on overflow as cel-go does.
+func addCost(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
@@ -0,0 +1,122 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newCostConverter(t *testing.T, maxCost uint64) *Converter {
+	t.Helper()
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+			"name":   {Type: cel.StringType},
+		},
+		PublicFields:      []string{"status", "age", "name"},
+		MaxExpressionCost: maxCost,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_MaxExpressionCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCost uint64
+		wantErr  bool
+	}{
+		{name: "comparison", celExpr: `status == "active"`, wantCost: 2},
+		{name: "conjunction", celExpr: `age > 3 && age < 10`, wantCost: 4},
+		{name: "in list", celExpr: `status in ["a", "b"]`, wantCost: 13},
+		{name: "string scan over budget", celExpr: `name.contains("bob")`, wantErr: true},
+	}
+
+	converter := newCostConverter(t, 50)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for method, convert := range map[string]func(string) (*ConvertResult, error){
+				"Convert": func(celExpr string) (*ConvertResult, error) {
+					return converter.Convert(celExpr)
+				},
+				"ConvertWithAuth": func(celExpr string) (*ConvertResult, error) {
+					return converter.ConvertWithAuth(celExpr, nil)
+				},
+			} {
+				result, err := convert(tt.celExpr)
+				if tt.wantErr {
+					if !errors.Is(err, ErrLimitCost) {
+						t.Errorf("%s() error = %v, want %v", method, err, ErrLimitCost)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s() error = %v", method, err)
+				}
+				if result.Complexity.Cost != tt.wantCost {
+					t.Errorf("%s() Complexity.Cost = %d, want %d", method, result.Complexity.Cost, tt.wantCost)
+				}
+			}
+
+			err := converter.Validate(tt.celExpr)
+			if got := errors.Is(err, ErrLimitCost); got != tt.wantErr {
+				t.Errorf("Validate() error = %v, want LIMIT_COST %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_CostNotEstimatedWithoutBudget(t *testing.T) {
+	converter := newCostConverter(t, 0)
+	result, err := converter.Convert(`name.contains("bob")`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Complexity.Cost != 0 {
+		t.Errorf("Complexity.Cost = %d, want 0 without MaxExpressionCost", result.Complexity.Cost)
+	}
+}
+
+func TestConverter_ConvertAll_MaxExpressionCost(t *testing.T) {
+	converter := newCostConverter(t, 5)
+
+	result, err := converter.ConvertAll([]string{`status == "a"`, `age > 3`}, LogicalAnd)
+	if err != nil {
+		t.Fatalf("ConvertAll() error = %v", err)
+	}
+	if result.Complexity.Cost != 4 {
+		t.Errorf("Complexity.Cost = %d, want 4", result.Complexity.Cost)
+	}
+
+	// Each expression is within the budget, not their combination
+	_, err = converter.ConvertAll([]string{`status == "a"`, `age > 3`, `age < 10`}, LogicalAnd)
+	if !errors.Is(err, ErrLimitCost) {
+		t.Errorf("ConvertAll() error = %v, want %v", err, ErrLimitCost)
+	}
+}
+
+func TestAddCost(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b uint64
+		want uint64
+	}{
+		{name: "zero", a: 0, b: 0, want: 0},
+		{name: "sum", a: 2, b: 3, want: 5},
+		{name: "saturated", a: math.MaxUint64 - 1, b: 2, want: math.MaxUint64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addCost(tt.a, tt.b); got != tt.want {
+				t.Errorf("addCost() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
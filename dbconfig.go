@@ -0,0 +1,192 @@
+package cel2squirrel
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+)
+
+// DBOptions customizes ConfigFromDB.
+type DBOptions struct {
+	// Dialect selects the introspection query. Default: the dialect
+	// detected from the database handle, see DetectDialect.
+	Dialect Dialect
+	// Schema is the schema, or MySQL database, holding the table. Default:
+	// the current one.
+	Schema string
+	// Include lists the columns to declare. Default: every column of a
+	// supported type.
+	Include []string
+	// Exclude lists columns not to declare, e.g. password hashes.
+	Exclude []string
+}
+
+// dbColumnTypes maps the SQL types reported by information_schema, and the
+// declared types of SQLite, lowercased and without parameters, to CEL types.
+var dbColumnTypes = map[string]*cel.Type{
+	// Strings
+	"char": cel.StringType, "character": cel.StringType, "varchar": cel.StringType,
+	"character varying": cel.StringType, "nchar": cel.StringType, "nvarchar": cel.StringType,
+	"text": cel.StringType, "tinytext": cel.StringType, "mediumtext": cel.StringType,
+	"longtext": cel.StringType, "ntext": cel.StringType, "citext": cel.StringType,
+	"clob": cel.StringType, "uuid": cel.StringType, "uniqueidentifier": cel.StringType,
+	"enum": cel.StringType, "bpchar": cel.StringType,
+	// Integers
+	"tinyint": cel.IntType, "smallint": cel.IntType, "mediumint": cel.IntType,
+	"int": cel.IntType, "integer": cel.IntType, "bigint": cel.IntType,
+	"int2": cel.IntType, "int4": cel.IntType, "int8": cel.IntType,
+	"serial": cel.IntType, "bigserial": cel.IntType,
+	// Floating-point and exact numerics
+	"real": cel.DoubleType, "float": cel.DoubleType, "double": cel.DoubleType,
+	"double precision": cel.DoubleType, "float4": cel.DoubleType, "float8": cel.DoubleType,
+	"numeric": cel.DoubleType, "decimal": cel.DoubleType,
+	// Booleans
+	"boolean": cel.BoolType, "bool": cel.BoolType, "bit": cel.BoolType,
+	// Timestamps
+	"date": cel.TimestampType, "datetime": cel.TimestampType, "datetime2": cel.TimestampType,
+	"smalldatetime": cel.TimestampType, "datetimeoffset": cel.TimestampType,
+	"timestamp": cel.TimestampType, "timestamptz": cel.TimestampType,
+	"timestamp without time zone": cel.TimestampType, "timestamp with time zone": cel.TimestampType,
+	// Durations
+	"interval": cel.DurationType,
+}
+
+// ConfigFromDB derives a Config from the columns of a database table, as
+// reported by information_schema, or pragma_table_info on SQLite, so that
+// small services need not maintain field declarations by hand:
+//
+//	config, err := cel2squirrel.ConfigFromDB(ctx, db, "users", cel2squirrel.DBOptions{
+//		Exclude: []string{"password_hash"},
+//	})
+//
+// Each column is declared as a field of the same name, with the CEL type of
+// its SQL type: strings, integers, floating-point and exact numerics as
+// doubles, booleans, dates and timestamps, intervals as durations, and
+// PostgreSQL arrays of these as native arrays. Columns of other types, such
+// as JSON or binary columns, and columns whose names are not valid CEL
+// identifiers, are not declared; listing them in Include is an error.
+//
+// The Config starts from DefaultConfig, with the Dialect used.
+func ConfigFromDB(ctx context.Context, db *sql.DB, table string, opts DBOptions) (Config, error) {
+	if db == nil {
+		return Config{}, fmt.Errorf("nil database handle")
+	}
+	dialect := opts.Dialect
+	if dialect == DialectDefault {
+		dialect, _ = DetectDialect(db)
+	}
+
+	query, args := dialect.columnsQuery(table, opts.Schema)
+	query, err := dialect.PlaceholderFormat().ReplacePlaceholders(query)
+	if err != nil {
+		return Config{}, err
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to list columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	builder := NewConfigBuilder()
+	builder.config.Dialect = dialect
+	found := make(map[string]bool)
+	for rows.Next() {
+		var column, dataType, elemType string
+		if err := rows.Scan(&column, &dataType, &elemType); err != nil {
+			return Config{}, fmt.Errorf("failed to list columns of %s: %w", table, err)
+		}
+		found[column] = true
+		if slices.Contains(opts.Exclude, column) ||
+			(len(opts.Include) > 0 && !slices.Contains(opts.Include, column)) {
+			continue
+		}
+
+		mapping, ok := dbColumnMapping(dataType, elemType)
+		switch {
+		case !ok && slices.Contains(opts.Include, column):
+			return Config{}, fmt.Errorf("column %s of %s has unsupported type %s", column, table, dataType)
+		case !declaredFieldName.MatchString(column) && slices.Contains(opts.Include, column):
+			return Config{}, fmt.Errorf("column %s of %s is not a valid CEL identifier", column, table)
+		case !ok || !declaredFieldName.MatchString(column):
+			continue
+		}
+		builder.config.FieldDeclarations[column] = mapping
+	}
+	if err := rows.Err(); err != nil {
+		return Config{}, fmt.Errorf("failed to list columns of %s: %w", table, err)
+	}
+
+	if len(found) == 0 {
+		return Config{}, fmt.Errorf("table %s not found", table)
+	}
+	for _, column := range slices.Concat(opts.Include, opts.Exclude) {
+		if !found[column] {
+			return Config{}, fmt.Errorf("column %s is not a column of %s", column, table)
+		}
+	}
+	return builder.Build()
+}
+
+// columnsQuery returns the query listing the name, data type and array
+// element type of the columns of a table, with ? placeholders.
+func (d Dialect) columnsQuery(table, schema string) (string, []any) {
+	switch d {
+	case DialectSQLite:
+		if schema != "" {
+			return "SELECT name, type, '' FROM pragma_table_info(?, ?) ORDER BY cid", []any{table, schema}
+		}
+		return "SELECT name, type, '' FROM pragma_table_info(?) ORDER BY cid", []any{table}
+	case DialectPostgreSQL:
+		// Arrays are reported as ARRAY, with the element type prefixed with
+		// an underscore in udt_name, e.g. _text
+		return informationSchemaQuery("CASE WHEN data_type = 'ARRAY' THEN SUBSTRING(udt_name FROM 2) ELSE '' END",
+			"current_schema()", table, schema)
+	case DialectMySQL:
+		return informationSchemaQuery("''", "DATABASE()", table, schema)
+	case DialectSQLServer:
+		return informationSchemaQuery("''", "SCHEMA_NAME()", table, schema)
+	default:
+		return informationSchemaQuery("''", "", table, schema)
+	}
+}
+
+// informationSchemaQuery returns an information_schema query listing the
+// columns of a table, in currentSchema unless schema is set.
+func informationSchemaQuery(elemType, currentSchema, table, schema string) (string, []any) {
+	query := "SELECT column_name, data_type, " + elemType +
+		" FROM information_schema.columns WHERE table_name = ?"
+	args := []any{table}
+	switch {
+	case schema != "":
+		query += " AND table_schema = ?"
+		args = append(args, schema)
+	case currentSchema != "":
+		query += " AND table_schema = " + currentSchema
+	}
+	return query + " ORDER BY ordinal_position", args
+}
+
+// dbColumnMapping returns the mapping of a column of a SQL type, if
+// supported.
+func dbColumnMapping(dataType, elemType string) (ColumnMapping, bool) {
+	if strings.EqualFold(dataType, "ARRAY") {
+		elem, ok := dbColumnType(elemType)
+		if !ok {
+			return ColumnMapping{}, false
+		}
+		return ColumnMapping{Type: cel.ListType(elem), Kind: KindArray}, true
+	}
+	t, ok := dbColumnType(dataType)
+	return ColumnMapping{Type: t}, ok
+}
+
+// dbColumnType returns the CEL type of a SQL type such as "varchar(255)".
+func dbColumnType(dataType string) (*cel.Type, bool) {
+	name, _, _ := strings.Cut(strings.ToLower(dataType), "(")
+	t, ok := dbColumnTypes[strings.TrimSpace(name)]
+	return t, ok
+}
@@ -0,0 +1,245 @@
+package cel2squirrel
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+// schemaDriver serves canned column listings, recording the queries made.
+type schemaDriver struct {
+	mu      sync.Mutex
+	columns [][3]string
+	queries []string
+	args    [][]driver.NamedValue
+}
+
+func (d *schemaDriver) Open(string) (driver.Conn, error) {
+	return schemaConn{d}, nil
+}
+
+type schemaConn struct{ d *schemaDriver }
+
+func (schemaConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (schemaConn) Close() error { return nil }
+
+func (schemaConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c schemaConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.queries = append(c.d.queries, query)
+	c.d.args = append(c.d.args, args)
+	return &schemaRows{columns: c.d.columns}, nil
+}
+
+type schemaRows struct {
+	columns [][3]string
+	next    int
+}
+
+func (*schemaRows) Columns() []string { return []string{"column_name", "data_type", "elem_type"} }
+
+func (*schemaRows) Close() error { return nil }
+
+func (r *schemaRows) Next(dest []driver.Value) error {
+	if r.next == len(r.columns) {
+		return io.EOF
+	}
+	for i, value := range r.columns[r.next] {
+		dest[i] = value
+	}
+	r.next++
+	return nil
+}
+
+var schemaDriverCount int
+
+// openSchemaDB opens a database handle listing columns.
+func openSchemaDB(t *testing.T, columns [][3]string) (*sql.DB, *schemaDriver) {
+	t.Helper()
+	d := &schemaDriver{columns: columns}
+	schemaDriverCount++
+	name := "cel2squirrel-schema-" + strconv.Itoa(schemaDriverCount)
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+var usersColumns = [][3]string{
+	{"id", "uuid", ""},
+	{"email", "character varying", ""},
+	{"age", "integer", ""},
+	{"balance", "numeric", ""},
+	{"active", "boolean", ""},
+	{"created_at", "timestamp with time zone", ""},
+	{"session_length", "interval", ""},
+	{"tags", "ARRAY", "text"},
+	{"scores", "ARRAY", "jsonb"},
+	{"settings", "jsonb", ""},
+	{"password_hash", "text", ""},
+	{"Display Name", "text", ""},
+}
+
+func TestConfigFromDB(t *testing.T) {
+	tests := []struct {
+		name      string
+		columns   [][3]string
+		opts      DBOptions
+		want      map[string]ColumnMapping
+		wantQuery string
+		wantArgs  []any
+	}{
+		{
+			name:    "postgres",
+			columns: usersColumns,
+			opts:    DBOptions{Dialect: DialectPostgreSQL, Exclude: []string{"password_hash"}},
+			want: map[string]ColumnMapping{
+				"id":             {Type: cel.StringType},
+				"email":          {Type: cel.StringType},
+				"age":            {Type: cel.IntType},
+				"balance":        {Type: cel.DoubleType},
+				"active":         {Type: cel.BoolType},
+				"created_at":     {Type: cel.TimestampType},
+				"session_length": {Type: cel.DurationType},
+				"tags":           {Type: cel.ListType(cel.StringType), Kind: KindArray},
+			},
+			wantQuery: "SELECT column_name, data_type, CASE WHEN data_type = 'ARRAY' THEN SUBSTRING(udt_name FROM 2) ELSE '' END" +
+				" FROM information_schema.columns WHERE table_name = $1 AND table_schema = current_schema()" +
+				" ORDER BY ordinal_position",
+			wantArgs: []any{"users"},
+		},
+		{
+			name:    "include",
+			columns: usersColumns,
+			opts:    DBOptions{Dialect: DialectMySQL, Schema: "app", Include: []string{"email", "age"}},
+			want: map[string]ColumnMapping{
+				"email": {Type: cel.StringType},
+				"age":   {Type: cel.IntType},
+			},
+			wantQuery: "SELECT column_name, data_type, '' FROM information_schema.columns" +
+				" WHERE table_name = ? AND table_schema = ? ORDER BY ordinal_position",
+			wantArgs: []any{"users", "app"},
+		},
+		{
+			name: "sqlite declared types",
+			columns: [][3]string{
+				{"name", "VARCHAR(255)", ""},
+				{"rank", "INTEGER", ""},
+				{"avatar", "BLOB", ""},
+			},
+			opts: DBOptions{Dialect: DialectSQLite},
+			want: map[string]ColumnMapping{
+				"name": {Type: cel.StringType},
+				"rank": {Type: cel.IntType},
+			},
+			wantQuery: "SELECT name, type, '' FROM pragma_table_info(?) ORDER BY cid",
+			wantArgs:  []any{"users"},
+		},
+		{
+			name:    "sql server",
+			columns: [][3]string{{"title", "nvarchar", ""}, {"flag", "bit", ""}},
+			opts:    DBOptions{Dialect: DialectSQLServer},
+			want: map[string]ColumnMapping{
+				"title": {Type: cel.StringType},
+				"flag":  {Type: cel.BoolType},
+			},
+			wantQuery: "SELECT column_name, data_type, '' FROM information_schema.columns" +
+				" WHERE table_name = @p1 AND table_schema = SCHEMA_NAME() ORDER BY ordinal_position",
+			wantArgs: []any{"users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, d := openSchemaDB(t, tt.columns)
+			config, err := ConfigFromDB(context.Background(), db, "users", tt.opts)
+			if err != nil {
+				t.Fatalf("ConfigFromDB() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(config.FieldDeclarations, tt.want) {
+				t.Errorf("FieldDeclarations = %v, want %v", config.FieldDeclarations, tt.want)
+			}
+			if config.Dialect != tt.opts.Dialect {
+				t.Errorf("Dialect = %q, want %q", config.Dialect, tt.opts.Dialect)
+			}
+			if len(d.queries) != 1 || d.queries[0] != tt.wantQuery {
+				t.Errorf("queries = %q, want %q", d.queries, tt.wantQuery)
+			}
+			var args []any
+			for _, arg := range d.args[0] {
+				args = append(args, arg.Value)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+			if _, err := NewConverter(config); err != nil {
+				t.Errorf("NewConverter() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigFromDB_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns [][3]string
+		opts    DBOptions
+		wantErr string
+	}{
+		{
+			name:    "table not found",
+			wantErr: "table users not found",
+		},
+		{
+			name:    "unknown excluded column",
+			columns: usersColumns,
+			opts:    DBOptions{Exclude: []string{"password"}},
+			wantErr: "column password is not a column of users",
+		},
+		{
+			name:    "included column of unsupported type",
+			columns: usersColumns,
+			opts:    DBOptions{Include: []string{"email", "settings"}},
+			wantErr: "column settings of users has unsupported type jsonb",
+		},
+		{
+			name:    "included column with invalid name",
+			columns: usersColumns,
+			opts:    DBOptions{Include: []string{"Display Name"}},
+			wantErr: "column Display Name of users is not a valid CEL identifier",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := openSchemaDB(t, tt.columns)
+			_, err := ConfigFromDB(context.Background(), db, "users", tt.opts)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ConfigFromDB() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ConfigFromDB(context.Background(), nil, "users", DBOptions{}); err == nil {
+		t.Error("ConfigFromDB(nil) error = nil, want error")
+	}
+}
@@ -0,0 +1,122 @@
+package cel2squirrel
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// debugSQLPrefix marks SQL rendered by DebugSQL.
+const debugSQLPrefix = "/* DEBUG ONLY - NOT FOR EXECUTION */ "
+
+// DebugSQL renders the filter with its arguments inlined as quoted SQL
+// literals, e.g. /* DEBUG ONLY - NOT FOR EXECUTION */ status = 'ada', for
+// logs and troubleshooting. The result is prefixed with a comment marking it
+// as such: it must never be executed, the arguments must be bound instead.
+// Rendering errors are reported in the returned comment.
+func (r *ConvertResult) DebugSQL() string {
+	if r.Where == nil {
+		return debugSQLPrefix + "/* no WHERE clause */"
+	}
+	query, args, err := r.Where.ToSql()
+	if err != nil {
+		return debugSQLPrefix + debugComment(fmt.Sprintf("failed to render SQL: %v", err))
+	}
+
+	var (
+		out  strings.Builder
+		next int
+	)
+	out.WriteString(debugSQLPrefix)
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(query[i+1:], ch)
+			if end < 0 {
+				out.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			out.WriteString(query[i : i+end+2])
+			i += end + 2
+
+		case ch == '?':
+			if next < len(args) {
+				out.WriteString(debugLiteral(args[next]))
+			} else {
+				out.WriteString("?")
+			}
+			next++
+			i++
+
+		default:
+			out.WriteByte(ch)
+			i++
+		}
+	}
+	if next != len(args) {
+		out.WriteString(" " + debugComment(fmt.Sprintf("%d values for %d placeholders", len(args), next)))
+	}
+	return out.String()
+}
+
+// debugLiteral renders an argument as a SQL literal.
+func debugLiteral(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return debugComment(fmt.Sprintf("invalid value: %v", err))
+		}
+		if _, again := v.(driver.Valuer); !again {
+			return debugLiteral(v)
+		}
+		value = v
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return quoteLiteral(v)
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case time.Time:
+		return quoteLiteral(v.Format(time.RFC3339Nano))
+	case []interface{}:
+		literals := make([]string, len(v))
+		for i, elem := range v {
+			literals[i] = debugLiteral(elem)
+		}
+		return "(" + strings.Join(literals, ", ") + ")"
+	default:
+		return quoteLiteral(fmt.Sprint(v))
+	}
+}
+
+// quoteLiteral quotes a string as a standard SQL literal, doubling single
+// quotes and dropping NUL bytes.
+func quoteLiteral(s string) string {
+	s = strings.ReplaceAll(s, "\x00", "")
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// debugComment renders a message as a SQL comment that cannot be closed
+// early by its content.
+func debugComment(message string) string {
+	return "/* " + strings.ReplaceAll(message, "*/", "* /") + " */"
+}
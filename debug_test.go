@@ -0,0 +1,105 @@
+package cel2squirrel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	"github.com/google/uuid"
+)
+
+func TestConvertResult_DebugSQL(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":       {Type: cel.StringType},
+			"age":        {Type: cel.IntType},
+			"score":      {Type: cel.DoubleType},
+			"active":     {Type: cel.BoolType},
+			"created_at": {Type: cel.TimestampType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		want    string
+	}{
+		{
+			name:    "quoted string",
+			celExpr: `name == "it's"`,
+			want:    debugSQLPrefix + "name = 'it''s'",
+		},
+		{
+			name:    "comment and placeholder in value",
+			celExpr: `name == "?*/ --"`,
+			want:    debugSQLPrefix + "name = '?*/ --'",
+		},
+		{
+			name:    "numbers and booleans",
+			celExpr: `age >= 18 && score < 2.5 && active == true`,
+			want:    debugSQLPrefix + "((age >= 18 AND score < 2.5) AND active = TRUE)",
+		},
+		{
+			name:    "IN list",
+			celExpr: `name in ["a", "b"]`,
+			want:    debugSQLPrefix + "name IN ('a','b')",
+		},
+		{
+			name:    "timestamp",
+			celExpr: `created_at > timestamp("2024-01-01T00:00:00Z")`,
+			want:    debugSQLPrefix + "created_at > '2024-01-01T00:00:00Z'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got := result.DebugSQL(); got != tt.want {
+				t.Errorf("DebugSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebugLiteral(t *testing.T) {
+	id := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "nil", value: nil, want: "NULL"},
+		{name: "uint", value: uint32(7), want: "7"},
+		{name: "bytes", value: []byte{0xca, 0xfe}, want: "X'cafe'"},
+		{name: "NUL byte", value: "a\x00b", want: "'ab'"},
+		{name: "valuer", value: id, want: "'6ba7b810-9dad-11d1-80b4-00c04fd430c8'"},
+		{name: "time", value: time.Date(2024, 1, 1, 12, 0, 0, 5, time.UTC), want: "'2024-01-01T12:00:00.000000005Z'"},
+		{name: "other", value: struct{ A string }{"x'"}, want: "'{x''}'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := debugLiteral(tt.value); got != tt.want {
+				t.Errorf("debugLiteral() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertResult_DebugSQL_Mismatch(t *testing.T) {
+	result := &ConvertResult{Where: squirrel.Expr("a = ? AND b = ?", 1)}
+	want := debugSQLPrefix + "a = 1 AND b = ? /* 1 values for 2 placeholders */"
+	if got := result.DebugSQL(); got != want {
+		t.Errorf("DebugSQL() = %q, want %q", got, want)
+	}
+
+	if got := (&ConvertResult{}).DebugSQL(); got != debugSQLPrefix+"/* no WHERE clause */" {
+		t.Errorf("DebugSQL() = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Deprecation reports a filter referencing a Deprecated field.
+type Deprecation struct {
+	// Field is the deprecated field.
+	Field string
+	// ReplacedBy is the field to filter on instead, if any.
+	ReplacedBy string
+}
+
+// String describes the deprecation, e.g. "field created is deprecated, use
+// create_time".
+func (d Deprecation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "field %s is deprecated", d.Field)
+	if d.ReplacedBy != "" {
+		fmt.Fprintf(&b, ", use %s", d.ReplacedBy)
+	}
+	return b.String()
+}
+
+// reportDeprecations records the deprecated fields among the fields a
+// filter references in its result.
+func (c *Converter) reportDeprecations(fields []string, result *ConvertResult) {
+	for _, name := range fields {
+		mapping, ok := c.fieldDeclarations[name]
+		if !ok || !mapping.Deprecated {
+			continue
+		}
+		deprecation := Deprecation{Field: name, ReplacedBy: mapping.ReplacedBy}
+		result.Deprecations = append(result.Deprecations, deprecation)
+		result.Warnings = append(result.Warnings, Diagnostic{
+			Code:    DiagnosticDeprecatedField,
+			Field:   name,
+			Message: deprecation.String(),
+		})
+	}
+}
+
+// checkReplacements verifies that the fields replacing deprecated ones are
+// declared.
+func checkReplacements(fields map[string]ColumnMapping) error {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		mapping := fields[name]
+		if mapping.ReplacedBy == "" {
+			continue
+		}
+		if !mapping.Deprecated {
+			return fmt.Errorf("field %s: replacement %s given for a field that is not deprecated", name, mapping.ReplacedBy)
+		}
+		if _, ok := fields[mapping.ReplacedBy]; !ok {
+			return fmt.Errorf("field %s: replacement %s is not declared", name, mapping.ReplacedBy)
+		}
+	}
+	return nil
+}
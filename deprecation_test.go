@@ -0,0 +1,104 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Deprecations(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"state":   {Type: cel.StringType, Deprecated: true, ReplacedBy: "status"},
+			"status":  {Type: cel.StringType},
+			"legacy":  {Type: cel.BoolType, Deprecated: true},
+			"created": {Type: cel.TimestampType, Aliases: []string{"ctime"}, DeprecatedAliases: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		celExpr          string
+		wantDeprecations []Deprecation
+		wantWarnings     []string
+	}{
+		{
+			name:    "current field",
+			celExpr: `status == "open"`,
+		},
+		{
+			name:             "replaced field",
+			celExpr:          `state == "open" || status == "open"`,
+			wantDeprecations: []Deprecation{{Field: "state", ReplacedBy: "status"}},
+			wantWarnings:     []string{"field state is deprecated, use status"},
+		},
+		{
+			name:             "several fields",
+			celExpr:          `legacy && state == "open"`,
+			wantDeprecations: []Deprecation{{Field: "legacy"}, {Field: "state", ReplacedBy: "status"}},
+			wantWarnings:     []string{"field legacy is deprecated", "field state is deprecated, use status"},
+		},
+		{
+			name:             "deprecated alias",
+			celExpr:          `ctime > timestamp("2024-01-01T00:00:00Z")`,
+			wantDeprecations: []Deprecation{{Field: "ctime", ReplacedBy: "created"}},
+			wantWarnings:     []string{"field ctime is deprecated, use created"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Deprecations, tt.wantDeprecations) {
+				t.Errorf("Deprecations = %v, want %v", result.Deprecations, tt.wantDeprecations)
+			}
+			if !reflect.DeepEqual(warningMessages(result.Warnings), tt.wantWarnings) {
+				t.Errorf("Warnings = %v, want %v", result.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+
+	capabilities := converter.Capabilities()
+	for _, field := range capabilities.Fields {
+		if field.Name == "state" && (!field.Deprecated || field.ReplacedBy != "status") {
+			t.Errorf("Capabilities() state = %+v, want deprecated in favor of status", field)
+		}
+	}
+}
+
+func TestConverter_Deprecations_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping ColumnMapping
+		wantErr string
+	}{
+		{
+			name:    "undeclared replacement",
+			mapping: ColumnMapping{Type: cel.StringType, Deprecated: true, ReplacedBy: "status"},
+			wantErr: "invalid field declaration: field state: replacement status is not declared",
+		},
+		{
+			name:    "replacement of a current field",
+			mapping: ColumnMapping{Type: cel.StringType, ReplacedBy: "state"},
+			wantErr: "invalid field declaration: field state: replacement state given for a field that is not deprecated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{"state": tt.mapping},
+			})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("NewConverter() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+)
+
+// With returns a new converter declaring the fields of the converter and
+// extraFields, e.g. to specialize a base converter per service for each
+// resource. It shares every other setting, including the limits,
+// authorization, mandatory conditions, Stats and Corpus. When authorization
+// is configured, extra fields absent from PublicFields and FieldACL can only
+// be filtered without it. Extra fields may not redeclare existing ones.
+func (c *Converter) With(extraFields map[string]ColumnMapping) (*Converter, error) {
+	return c.current().withFields(extraFields)
+}
+
+// withFields creates a converter declaring the fields of c and extraFields.
+func (c *Converter) withFields(extraFields map[string]ColumnMapping) (*Converter, error) {
+	config := c.config
+	config.FieldDeclarations = make(map[string]ColumnMapping, len(c.config.FieldDeclarations)+len(extraFields))
+	maps.Copy(config.FieldDeclarations, c.config.FieldDeclarations)
+	for name, mapping := range extraFields {
+		if _, ok := config.FieldDeclarations[name]; ok {
+			return nil, fmt.Errorf("field %s is already declared", name)
+		}
+		config.FieldDeclarations[name] = mapping
+	}
+	return NewConverter(config)
+}
@@ -0,0 +1,99 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_With(t *testing.T) {
+	base, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+		},
+		PublicFields:    []string{"status", "priority"},
+		MaxInClauseSize: 2,
+		TableAlias:      "t",
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	derived, err := base.With(map[string]ColumnMapping{
+		"priority": {Type: cel.IntType, Column: "prio"},
+		"secret":   {Type: cel.StringType},
+	})
+	if err != nil {
+		t.Fatalf("With() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		converter *Converter
+		celExpr   string
+		auth      bool
+		wantSQL   string
+		wantCode  string
+	}{
+		{
+			name:      "extended schema",
+			converter: derived,
+			celExpr:   `status == "open" && priority > 1`,
+			wantSQL:   "(t.status = ? AND t.prio > ?)",
+		},
+		{
+			name:      "base unchanged",
+			converter: base,
+			celExpr:   `priority > 1`,
+			wantCode:  "INVALID_SYNTAX",
+		},
+		{
+			name:      "shared limits",
+			converter: derived,
+			celExpr:   `priority in [1, 2, 3]`,
+			wantCode:  "LIMIT_IN_SIZE",
+		},
+		{
+			name:      "shared authorization",
+			converter: derived,
+			celExpr:   `priority > 1`,
+			auth:      true,
+			wantSQL:   "t.prio > ?",
+		},
+		{
+			name:      "extra field not public",
+			converter: derived,
+			celExpr:   `secret == "x"`,
+			auth:      true,
+			wantCode:  "UNAUTHORIZED_FIELD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convert := tt.converter.Convert
+			if tt.auth {
+				convert = func(celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+					return tt.converter.ConvertWithAuth(celExpr, nil, opts...)
+				}
+			}
+			result, err := convert(tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Convert() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	if _, err := base.With(map[string]ColumnMapping{"status": {Type: cel.IntType}}); err == nil || err.Error() != "field status is already declared" {
+		t.Errorf("With() error = %v, want a redeclaration error", err)
+	}
+}
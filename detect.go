@@ -0,0 +1,96 @@
+package cel2squirrel
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// PlaceholderFormat returns the Squirrel placeholder format expected by the
+// dialect: $1, $2, ... for PostgreSQL, @p1, @p2, ... for SQL Server and ?
+// everywhere else.
+func (d Dialect) PlaceholderFormat() squirrel.PlaceholderFormat {
+	switch d {
+	case DialectPostgreSQL:
+		return squirrel.Dollar
+	case DialectSQLServer:
+		return squirrel.AtP
+	default:
+		return squirrel.Question
+	}
+}
+
+// driverDialects maps well-known database/sql driver names, and fragments of
+// their Go package paths, to dialects.
+var driverDialects = []struct {
+	names   []string
+	pkgPath []string
+	dialect Dialect
+}{
+	{
+		names:   []string{"postgres", "postgresql", "pgx", "pgx/v4", "pgx/v5", "cloudsqlpostgres", "nrpostgres"},
+		pkgPath: []string{"github.com/lib/pq", "github.com/jackc/pgx"},
+		dialect: DialectPostgreSQL,
+	},
+	{
+		names:   []string{"mysql", "nrmysql"},
+		pkgPath: []string{"github.com/go-sql-driver/mysql"},
+		dialect: DialectMySQL,
+	},
+	{
+		names:   []string{"sqlite3", "sqlite", "libsql", "nrsqlite3"},
+		pkgPath: []string{"github.com/mattn/go-sqlite3", "modernc.org/sqlite", "github.com/tursodatabase"},
+		dialect: DialectSQLite,
+	},
+	{
+		names:   []string{"sqlserver", "mssql", "azuresql"},
+		pkgPath: []string{"github.com/microsoft/go-mssqldb", "github.com/denisenkom/go-mssqldb"},
+		dialect: DialectSQLServer,
+	},
+}
+
+// DialectForDriver returns the dialect matching a database/sql driver name as
+// passed to sql.Open. It reports false for unknown drivers.
+func DialectForDriver(driverName string) (Dialect, bool) {
+	driverName = strings.ToLower(driverName)
+	for _, entry := range driverDialects {
+		for _, name := range entry.names {
+			if name == driverName {
+				return entry.dialect, true
+			}
+		}
+	}
+	return DialectDefault, false
+}
+
+// DetectDialect inspects the driver backing a database handle and returns the
+// matching dialect. It reports false when the driver is not recognized, in
+// which case DialectDefault is returned.
+//
+// Example:
+//
+//	dialect, _ := cel2squirrel.DetectDialect(db)
+//	config.Dialect = dialect
+//	query := squirrel.Select("*").From("users").PlaceholderFormat(dialect.PlaceholderFormat())
+func DetectDialect(db *sql.DB) (Dialect, bool) {
+	if db == nil {
+		return DialectDefault, false
+	}
+
+	driverType := reflect.TypeOf(db.Driver())
+	for driverType.Kind() == reflect.Pointer {
+		driverType = driverType.Elem()
+	}
+
+	pkgPath := driverType.PkgPath()
+	for _, entry := range driverDialects {
+		for _, fragment := range entry.pkgPath {
+			if strings.HasPrefix(pkgPath, fragment) {
+				return entry.dialect, true
+			}
+		}
+	}
+	return DialectDefault, false
+}
@@ -0,0 +1,90 @@
+package cel2squirrel
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("stub driver cannot connect")
+}
+
+func init() {
+	sql.Register("cel2squirrel-stub", stubDriver{})
+}
+
+func TestDialectForDriver(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   Dialect
+		found  bool
+	}{
+		{driver: "postgres", want: DialectPostgreSQL, found: true},
+		{driver: "pgx", want: DialectPostgreSQL, found: true},
+		{driver: "MySQL", want: DialectMySQL, found: true},
+		{driver: "sqlite3", want: DialectSQLite, found: true},
+		{driver: "sqlite", want: DialectSQLite, found: true},
+		{driver: "sqlserver", want: DialectSQLServer, found: true},
+		{driver: "oracle", want: DialectDefault, found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			got, found := DialectForDriver(tt.driver)
+			if got != tt.want || found != tt.found {
+				t.Errorf("DialectForDriver(%q) = %q, %v, want %q, %v", tt.driver, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestDetectDialect(t *testing.T) {
+	if got, found := DetectDialect(nil); got != DialectDefault || found {
+		t.Errorf("DetectDialect(nil) = %q, %v", got, found)
+	}
+
+	db, err := sql.Open("cel2squirrel-stub", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	if got, found := DetectDialect(db); got != DialectDefault || found {
+		t.Errorf("DetectDialect(stub) = %q, %v, want unknown driver", got, found)
+	}
+}
+
+func TestDialect_PlaceholderFormat(t *testing.T) {
+	sql, _, err := squirrel.Select("*").From("t").Where(squirrel.Eq{"a": 1}).
+		PlaceholderFormat(DialectPostgreSQL.PlaceholderFormat()).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if sql != "SELECT * FROM t WHERE a = $1" {
+		t.Errorf("postgres placeholders = %q", sql)
+	}
+
+	sql, _, err = squirrel.Select("*").From("t").Where(squirrel.Eq{"a": 1}).
+		PlaceholderFormat(DialectMySQL.PlaceholderFormat()).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if sql != "SELECT * FROM t WHERE a = ?" {
+		t.Errorf("mysql placeholders = %q", sql)
+	}
+
+	sql, _, err = squirrel.Select("*").From("t").Where(squirrel.Eq{"a": 1}).
+		PlaceholderFormat(DialectSQLServer.PlaceholderFormat()).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if sql != "SELECT * FROM t WHERE a = @p1" {
+		t.Errorf("sqlserver placeholders = %q", sql)
+	}
+}
@@ -0,0 +1,55 @@
+package cel2squirrel
+
+import "fmt"
+
+// DiagnosticCode identifies the kind of a Diagnostic.
+type DiagnosticCode string
+
+const (
+	// DiagnosticLeadingWildcard reports a LIKE pattern starting with a
+	// wildcard, as rendered for contains() and endsWith(), which B-tree
+	// indexes cannot serve.
+	DiagnosticLeadingWildcard DiagnosticCode = "LEADING_WILDCARD"
+	// DiagnosticEmptyInList reports `x in []`, which never matches.
+	DiagnosticEmptyInList DiagnosticCode = "EMPTY_IN_LIST"
+	// DiagnosticDeprecatedField reports a Deprecated field, see
+	// ConvertResult.Deprecations.
+	DiagnosticDeprecatedField DiagnosticCode = "DEPRECATED_FIELD"
+	// DiagnosticTimestampTruncated reports a timestamp truncated to the
+	// granularity of its field.
+	DiagnosticTimestampTruncated DiagnosticCode = "TIMESTAMP_TRUNCATED"
+	// DiagnosticApproximated reports a predicate widened or approximated by
+	// a fallback, whose rows must be re-checked in memory.
+	DiagnosticApproximated DiagnosticCode = "APPROXIMATED"
+	// DiagnosticUncoveredIndex reports a filter falling outside the coverage
+	// of a partial index.
+	DiagnosticUncoveredIndex DiagnosticCode = "UNCOVERED_INDEX"
+)
+
+// Diagnostic is a non-fatal issue found while converting a filter, e.g. to
+// log or surface hints to the filter's author.
+type Diagnostic struct {
+	Code DiagnosticCode
+	// Field is the field the diagnostic is about, if any.
+	Field string
+	// Message describes the issue.
+	Message string
+}
+
+// String returns the diagnostic's message.
+func (d Diagnostic) String() string {
+	return d.Message
+}
+
+// newDiagnostic creates a diagnostic with a formatted message.
+func newDiagnostic(code DiagnosticCode, field, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{Code: code, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// warnf records a diagnostic on the current call.
+func (c *Converter) warnf(code DiagnosticCode, field, format string, args ...interface{}) {
+	if c.conv == nil {
+		return
+	}
+	c.conv.warnings = append(c.conv.warnings, newDiagnostic(code, field, format, args...))
+}
@@ -0,0 +1,86 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+// warningMessages returns the messages of diagnostics.
+func warningMessages(diagnostics []Diagnostic) []string {
+	var messages []string
+	for _, diagnostic := range diagnostics {
+		messages = append(messages, diagnostic.Message)
+	}
+	return messages
+}
+
+// withCode returns the diagnostics with a code.
+func withCode(diagnostics []Diagnostic, code DiagnosticCode) []Diagnostic {
+	var matching []Diagnostic
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Code == code {
+			matching = append(matching, diagnostic)
+		}
+	}
+	return matching
+}
+
+func TestConvertResult_Warnings(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":  {Type: cel.StringType},
+			"age":   {Type: cel.IntType},
+			"state": {Type: cel.StringType, Deprecated: true, ReplacedBy: "name"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		want    []Diagnostic
+	}{
+		{
+			name:    "no diagnostics",
+			celExpr: `name.startsWith("a") && age in [1, 2]`,
+		},
+		{
+			name:    "leading wildcard",
+			celExpr: `name.contains("a") || name.endsWith("b")`,
+			want: []Diagnostic{
+				{Code: DiagnosticLeadingWildcard, Field: "name", Message: "LIKE pattern on name starts with a wildcard and may not use an index"},
+				{Code: DiagnosticLeadingWildcard, Field: "name", Message: "LIKE pattern on name starts with a wildcard and may not use an index"},
+			},
+		},
+		{
+			name:    "empty in list",
+			celExpr: `age in []`,
+			want: []Diagnostic{
+				{Code: DiagnosticEmptyInList, Field: "age", Message: "age in [] never matches"},
+			},
+		},
+		{
+			name:    "deprecated field",
+			celExpr: `state == "open"`,
+			want: []Diagnostic{
+				{Code: DiagnosticDeprecatedField, Field: "state", Message: "field state is deprecated, use name"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Warnings, tt.want) {
+				t.Errorf("Warnings = %+v, want %+v", result.Warnings, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dialect identifies the SQL flavour targeted by the generated expressions.
+// The zero value produces portable ANSI SQL.
+type Dialect string
+
+const (
+	// DialectDefault emits ANSI SQL understood by most databases.
+	DialectDefault Dialect = ""
+	// DialectPostgreSQL targets PostgreSQL.
+	DialectPostgreSQL Dialect = "postgres"
+	// DialectMySQL targets MySQL and MariaDB.
+	DialectMySQL Dialect = "mysql"
+	// DialectSQLite targets SQLite.
+	DialectSQLite Dialect = "sqlite"
+	// DialectSQLServer targets Microsoft SQL Server. Besides identifier
+	// quoting, placeholders and casts, it emits ANSI SQL.
+	DialectSQLServer Dialect = "sqlserver"
+)
+
+// QuoteIdentifier quotes a SQL identifier for the dialect: with backticks
+// for MySQL, square brackets for SQL Server and double quotes otherwise,
+// doubling the closing quote character when it appears in the name. Empty
+// names and names containing control characters, NUL bytes or invalid
+// UTF-8 are rejected rather than quoted.
+func (d Dialect) QuoteIdentifier(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty identifier")
+	}
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("identifier %q is not valid UTF-8", name)
+	}
+	if i := strings.IndexFunc(name, unicode.IsControl); i >= 0 {
+		return "", fmt.Errorf("identifier %q contains a control character at offset %d", name, i)
+	}
+
+	switch d {
+	case DialectMySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`", nil
+	case DialectSQLServer:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]", nil
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`, nil
+	}
+}
+
+// castSources lists, by CEL source type, the conversions rendered as a SQL
+// CAST. Timestamps, durations and booleans convert differently in CEL, e.g.
+// int(timestamp) counts epoch seconds, and are not listed.
+var castSources = map[string]map[string]bool{
+	"int":    {"int": true, "double": true, "string": true},
+	"uint":   {"int": true, "double": true, "string": true},
+	"double": {"int": true, "double": true, "string": true},
+	"string": {"int": true, "double": true, "string": true},
+}
+
+// truncate returns the SQL template rounding the number rendered by %[1]s
+// toward zero, as CEL converts doubles to int, where CAST may round.
+func (d Dialect) truncate() string {
+	switch d {
+	case DialectPostgreSQL:
+		return "TRUNC(%[1]s)"
+	case DialectMySQL:
+		return "TRUNCATE(%[1]s, 0)"
+	case DialectSQLite, DialectSQLServer:
+		// CAST truncates
+		return "%[1]s"
+	default:
+		return "CASE WHEN %[1]s < 0 THEN CEILING(%[1]s) ELSE FLOOR(%[1]s) END"
+	}
+}
+
+// castType returns the SQL type name used in CAST expressions for the given
+// CEL conversion function (int, double or string).
+func (d Dialect) castType(function string) (string, bool) {
+	switch d {
+	case DialectMySQL:
+		switch function {
+		case "int":
+			return "SIGNED", true
+		case "double":
+			return "DOUBLE", true
+		case "string":
+			return "CHAR", true
+		}
+	case DialectSQLite:
+		switch function {
+		case "int":
+			return "INTEGER", true
+		case "double":
+			return "REAL", true
+		case "string":
+			return "TEXT", true
+		}
+	case DialectPostgreSQL:
+		switch function {
+		case "int":
+			return "BIGINT", true
+		case "double":
+			return "DOUBLE PRECISION", true
+		case "string":
+			return "TEXT", true
+		}
+	case DialectSQLServer:
+		switch function {
+		case "int":
+			return "BIGINT", true
+		case "double":
+			return "FLOAT", true
+		case "string":
+			return "NVARCHAR(MAX)", true
+		}
+	default:
+		switch function {
+		case "int":
+			return "BIGINT", true
+		case "double":
+			return "DOUBLE PRECISION", true
+		case "string":
+			return "VARCHAR", true
+		}
+	}
+	return "", false
+}
+
+// substringMatch returns the SQL template testing whether the string
+// rendered by %[1]s contains, starts with or ends with (function) the string
+// rendered by %[2]s. The match is literal and NULL when either is NULL.
+func (d Dialect) substringMatch(function string) (string, bool) {
+	var position string
+	switch d {
+	case DialectPostgreSQL:
+		position = "STRPOS(%[1]s, %[2]s)"
+	case DialectMySQL:
+		position = "LOCATE(%[2]s, %[1]s)"
+	case DialectSQLite:
+		position = "INSTR(%[1]s, %[2]s)"
+	case DialectSQLServer:
+		// CHARINDEX does not find the empty string
+		position = "IIF(DATALENGTH(%[2]s) = 0, 1, CHARINDEX(%[2]s, %[1]s))"
+	default:
+		position = "POSITION(%[2]s IN %[1]s)"
+	}
+
+	switch function {
+	case "contains":
+		return position + " > 0", true
+	case "startsWith":
+		return position + " = 1", true
+	case "endsWith":
+		switch d {
+		case DialectPostgreSQL:
+			return "RIGHT(%[1]s, LENGTH(%[2]s)) = %[2]s", true
+		case DialectMySQL:
+			return "RIGHT(%[1]s, CHAR_LENGTH(%[2]s)) = %[2]s", true
+		case DialectSQLite:
+			return "SUBSTR(%[1]s, LENGTH(%[1]s) - LENGTH(%[2]s) + 1) = %[2]s", true
+		case DialectSQLServer:
+			// LEN ignores trailing spaces
+			return "RIGHT(%[1]s, LEN(%[2]s + '.') - 1) = %[2]s", true
+		default:
+			return "SUBSTRING(%[1]s FROM CHAR_LENGTH(%[1]s) - CHAR_LENGTH(%[2]s) + 1) = %[2]s", true
+		}
+	}
+	return "", false
+}
+
+// jsonText returns the SQL extracting the text value of a top-level key from
+// a JSON column. The key must already be validated by isJSONKey.
+func (d Dialect) jsonText(column, key string) (string, bool) {
+	switch d {
+	case DialectPostgreSQL:
+		return fmt.Sprintf("%s->>'%s'", column, key), true
+	case DialectMySQL:
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$.%s'))", column, key), true
+	case DialectSQLite:
+		return fmt.Sprintf("json_extract(%s, '$.%s')", column, key), true
+	default:
+		return "", false
+	}
+}
+
+// jsonPathText returns the SQL extracting the text value at a nested path
+// of a JSON column. The path segments must already be validated by isJSONKey.
+func (d Dialect) jsonPathText(column string, path []string) (string, bool) {
+	switch d {
+	case DialectPostgreSQL:
+		return fmt.Sprintf("%s #>> '{%s}'", column, strings.Join(path, ",")), true
+	case DialectMySQL:
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, '$.%s'))", column, strings.Join(path, ".")), true
+	case DialectSQLite:
+		return fmt.Sprintf("json_extract(%s, '$.%s')", column, strings.Join(path, ".")), true
+	default:
+		return "", false
+	}
+}
+
+// withinDistance returns the SQL template testing whether a location column
+// stored as kind lies within a radius in meters of a point. It binds the
+// point's longitude, latitude and the radius.
+func (d Dialect) withinDistance(column string, kind ColumnKind) (string, bool) {
+	switch {
+	case d == DialectPostgreSQL && kind == KindGeography:
+		return fmt.Sprintf("ST_DWithin(%s, ST_MakePoint(?,?)::geography, ?)", column), true
+	case d == DialectPostgreSQL && kind == KindGeometry:
+		return fmt.Sprintf("ST_DWithin(%s::geography, ST_MakePoint(?,?)::geography, ?)", column), true
+	case d == DialectMySQL && kind == KindGeography:
+		return fmt.Sprintf("ST_Distance_Sphere(%s, POINT(?, ?)) <= ?", column), true
+	default:
+		return "", false
+	}
+}
+
+// indexOf returns the SQL template computing the 0-based position of a
+// bound substring within the string column rendered by %s, -1 when it is
+// absent. needleFirst reports whether the substring placeholder precedes
+// the column.
+func (d Dialect) indexOf() (template string, needleFirst bool) {
+	switch d {
+	case DialectPostgreSQL:
+		return "(STRPOS(%s, ?) - 1)", false
+	case DialectMySQL:
+		return "(LOCATE(?, %s) - 1)", true
+	case DialectSQLite:
+		return "(INSTR(%s, ?) - 1)", false
+	default:
+		return "(POSITION(? IN %s) - 1)", true
+	}
+}
+
+// arrayLength returns the SQL computing the number of elements of a list
+// column stored as kind.
+func (d Dialect) arrayLength(column string, kind ColumnKind) (string, bool) {
+	if kind == KindArray {
+		// Native arrays, where supported: CARDINALITY is standard SQL
+		if d == DialectMySQL || d == DialectSQLite {
+			return "", false
+		}
+		return fmt.Sprintf("cardinality(%s)", column), true
+	}
+
+	switch d {
+	case DialectPostgreSQL:
+		return fmt.Sprintf("jsonb_array_length(%s)", column), true
+	case DialectMySQL:
+		return fmt.Sprintf("JSON_LENGTH(%s)", column), true
+	case DialectSQLite:
+		return fmt.Sprintf("json_array_length(%s)", column), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,48 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EmptyFilterMode selects how empty or whitespace-only expressions, e.g. the
+// absent filter parameter of a list API, are converted.
+type EmptyFilterMode string
+
+const (
+	// EmptyFilterInvalid rejects empty expressions with INVALID_SYNTAX. It is
+	// the default.
+	EmptyFilterInvalid EmptyFilterMode = ""
+	// EmptyFilterMatchAll converts empty expressions as `true`, matching
+	// every row.
+	EmptyFilterMatchAll EmptyFilterMode = "match_all"
+	// EmptyFilterError rejects empty expressions with EMPTY_FILTER, whose
+	// error wraps ErrEmptyFilter.
+	EmptyFilterError EmptyFilterMode = "error"
+)
+
+// ErrEmptyFilter is wrapped by the errors of empty expressions with
+// EmptyFilterError, to be detected with errors.Is.
+var ErrEmptyFilter = errors.New("empty filter expression")
+
+// validate checks that the mode is known.
+func (m EmptyFilterMode) validate() error {
+	switch m {
+	case EmptyFilterInvalid, EmptyFilterMatchAll, EmptyFilterError:
+		return nil
+	}
+	return fmt.Errorf("unknown empty filter mode %q", m)
+}
+
+// resolveEmpty returns the expression to compile in place of an empty
+// expression, per the empty filter mode.
+func (c *Converter) resolveEmpty(celExpr string) (string, error) {
+	if c.emptyFilter == EmptyFilterInvalid || strings.TrimSpace(celExpr) != "" {
+		return celExpr, nil
+	}
+	if c.emptyFilter == EmptyFilterMatchAll {
+		return "true", nil
+	}
+	return "", newConversionError("filter expression is empty", CodeEmptyFilter, ErrEmptyFilter)
+}
@@ -0,0 +1,67 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_EmptyFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     EmptyFilterMode
+		celExpr  string
+		wantSQL  string
+		wantCode string
+	}{
+		{name: "invalid by default", celExpr: "", wantCode: "INVALID_SYNTAX"},
+		{name: "match all", mode: EmptyFilterMatchAll, celExpr: "", wantSQL: "TRUE"},
+		{name: "match all whitespace", mode: EmptyFilterMatchAll, celExpr: " \n\t", wantSQL: "TRUE"},
+		{name: "error", mode: EmptyFilterError, celExpr: "  ", wantCode: "EMPTY_FILTER"},
+		{name: "non-empty expression", mode: EmptyFilterError, celExpr: `status == "a"`, wantSQL: "status = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{"status": {Type: cel.StringType}},
+				PublicFields:      []string{"status"},
+				EmptyFilter:       tt.mode,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			_, authErr := converter.ConvertWithAuth(tt.celExpr, nil)
+			validateErr := converter.Validate(tt.celExpr)
+			for name, err := range map[string]error{"Convert": err, "ConvertWithAuth": authErr, "Validate": validateErr} {
+				if got := errorCode(err); got != tt.wantCode {
+					t.Errorf("%s() error = %v, want %q", name, err, tt.wantCode)
+				}
+				if tt.wantCode == "EMPTY_FILTER" && !errors.Is(err, ErrEmptyFilter) {
+					t.Errorf("%s() error = %v, want ErrEmptyFilter", name, err)
+				}
+			}
+			if tt.wantCode != "" {
+				return
+			}
+
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Convert() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if tt.mode == EmptyFilterMatchAll && !result.AlwaysTrue {
+				t.Error("AlwaysTrue = false, want true for an empty filter")
+			}
+		})
+	}
+
+	if _, err := NewConverter(Config{EmptyFilter: "ignore"}); err == nil {
+		t.Error("NewConverter() with an unknown empty filter mode error = nil, want error")
+	}
+}
@@ -0,0 +1,91 @@
+package cel2squirrel
+
+// ErrorCode is the machine-readable code of a ConversionError.
+type ErrorCode string
+
+const (
+	// CodeInvalidSyntax: the expression does not parse or type-check.
+	CodeInvalidSyntax ErrorCode = "INVALID_SYNTAX"
+	// CodeInvalidType: the expression is not boolean.
+	CodeInvalidType ErrorCode = "INVALID_TYPE"
+	// CodeTypeMismatch: a literal does not match the field's type.
+	CodeTypeMismatch ErrorCode = "TYPE_MISMATCH"
+	// CodeUnauthorizedField: ConvertWithAuth denied a field.
+	CodeUnauthorizedField ErrorCode = "UNAUTHORIZED_FIELD"
+	// CodeUnsupportedOperation: the expression has no SQL translation.
+	CodeUnsupportedOperation ErrorCode = "UNSUPPORTED_OPERATION"
+	// CodeUnsupportedPrecision: a timestamp is finer than the field's
+	// granularity.
+	CodeUnsupportedPrecision ErrorCode = "UNSUPPORTED_PRECISION"
+	// CodeInvalidTimestamp: a timestamp literal is malformed.
+	CodeInvalidTimestamp ErrorCode = "INVALID_TIMESTAMP"
+	// CodeInvalidScope: a scope predicate of ConvertWithScopes failed to
+	// convert.
+	CodeInvalidScope ErrorCode = "INVALID_SCOPE"
+	// CodeInvalidCoordinates: a near() point or radius is out of range.
+	CodeInvalidCoordinates ErrorCode = "INVALID_COORDINATES"
+	// CodeInvalidValue: a field's Transform rejected a value.
+	CodeInvalidValue ErrorCode = "INVALID_VALUE"
+	// CodeOperatorNotAllowed: an operator outside of a field's AllowedOps.
+	CodeOperatorNotAllowed ErrorCode = "OPERATOR_NOT_ALLOWED"
+	// CodeLimitLength: MaxExpressionLength exceeded.
+	CodeLimitLength ErrorCode = "LIMIT_LENGTH"
+	// CodeLimitDepth: MaxExpressionDepth exceeded.
+	CodeLimitDepth ErrorCode = "LIMIT_DEPTH"
+	// CodeLimitInSize: MaxInClauseSize exceeded.
+	CodeLimitInSize ErrorCode = "LIMIT_IN_SIZE"
+	// CodeLimitMemory: MaxConversionBytes exceeded.
+	CodeLimitMemory ErrorCode = "LIMIT_MEMORY"
+	// CodeLimitLikePattern: MaxLikePatternLength or MaxLikeWildcards
+	// exceeded.
+	CodeLimitLikePattern ErrorCode = "LIMIT_LIKE_PATTERN"
+	// CodeLimitPredicates: MaxPredicates exceeded.
+	CodeLimitPredicates ErrorCode = "LIMIT_PREDICATES"
+	// CodeLimitCost: MaxExpressionCost exceeded.
+	CodeLimitCost ErrorCode = "LIMIT_COST"
+	// CodeAuditViolation: generated SQL rejected by AuditSQL.
+	CodeAuditViolation ErrorCode = "AUDIT_VIOLATION"
+	// CodeQuotaExceeded: filter rejected by Config.Quota.
+	CodeQuotaExceeded ErrorCode = "QUOTA_EXCEEDED"
+	// CodeEmptyFilter: empty expression with EmptyFilterError.
+	CodeEmptyFilter ErrorCode = "EMPTY_FILTER"
+	// CodeScopeUnavailable: the mandatory conditions could not be built.
+	CodeScopeUnavailable ErrorCode = "SCOPE_UNAVAILABLE"
+	// CodeCanceled: the context of the conversion was canceled or its
+	// deadline exceeded.
+	CodeCanceled ErrorCode = "CANCELED"
+)
+
+// Sentinel errors matching the conversion errors of a code with errors.Is,
+// e.g. errors.Is(err, ErrUnauthorizedField). ErrEmptyFilter matches
+// CodeEmptyFilter.
+var (
+	ErrInvalidSyntax        error = &ConversionError{ErrorCode: CodeInvalidSyntax, PublicMessage: "invalid filter expression syntax"}
+	ErrInvalidType          error = &ConversionError{ErrorCode: CodeInvalidType, PublicMessage: "filter expression must evaluate to boolean"}
+	ErrTypeMismatch         error = &ConversionError{ErrorCode: CodeTypeMismatch, PublicMessage: "invalid comparison type"}
+	ErrUnauthorizedField    error = &ConversionError{ErrorCode: CodeUnauthorizedField, PublicMessage: "access denied: insufficient permissions for requested filter"}
+	ErrUnsupportedOperation error = &ConversionError{ErrorCode: CodeUnsupportedOperation, PublicMessage: "unsupported filter operation"}
+	ErrUnsupportedPrecision error = &ConversionError{ErrorCode: CodeUnsupportedPrecision, PublicMessage: "timestamp precision not supported for this field"}
+	ErrInvalidTimestamp     error = &ConversionError{ErrorCode: CodeInvalidTimestamp, PublicMessage: "invalid timestamp value"}
+	ErrInvalidScope         error = &ConversionError{ErrorCode: CodeInvalidScope, PublicMessage: "invalid filter scope"}
+	ErrInvalidCoordinates   error = &ConversionError{ErrorCode: CodeInvalidCoordinates, PublicMessage: "invalid geographic coordinates"}
+	ErrInvalidValue         error = &ConversionError{ErrorCode: CodeInvalidValue, PublicMessage: "invalid filter value"}
+	ErrOperatorNotAllowed   error = &ConversionError{ErrorCode: CodeOperatorNotAllowed, PublicMessage: "operator not allowed in filter"}
+	ErrLimitLength          error = &ConversionError{ErrorCode: CodeLimitLength, PublicMessage: "filter expression exceeds maximum length"}
+	ErrLimitDepth           error = &ConversionError{ErrorCode: CodeLimitDepth, PublicMessage: "filter expression exceeds maximum depth"}
+	ErrLimitInSize          error = &ConversionError{ErrorCode: CodeLimitInSize, PublicMessage: "IN clause exceeds maximum number of values"}
+	ErrLimitMemory          error = &ConversionError{ErrorCode: CodeLimitMemory, PublicMessage: "filter expression exceeds memory budget"}
+	ErrLimitLikePattern     error = &ConversionError{ErrorCode: CodeLimitLikePattern, PublicMessage: "LIKE pattern is too complex"}
+	ErrLimitPredicates      error = &ConversionError{ErrorCode: CodeLimitPredicates, PublicMessage: "filter expression exceeds maximum number of predicates"}
+	ErrLimitCost            error = &ConversionError{ErrorCode: CodeLimitCost, PublicMessage: "filter expression exceeds maximum cost"}
+	ErrAuditViolation       error = &ConversionError{ErrorCode: CodeAuditViolation, PublicMessage: "filter expression failed SQL audit"}
+	ErrQuotaExceeded        error = &ConversionError{ErrorCode: CodeQuotaExceeded, PublicMessage: "filter quota exceeded"}
+	ErrScopeUnavailable     error = &ConversionError{ErrorCode: CodeScopeUnavailable, PublicMessage: "filter scope unavailable"}
+	ErrCanceled             error = &ConversionError{ErrorCode: CodeCanceled, PublicMessage: "filter conversion canceled"}
+)
+
+// Is reports whether target is the sentinel error of the error's code.
+func (e *ConversionError) Is(target error) bool {
+	sentinel, ok := target.(*ConversionError)
+	return ok && sentinel.InternalError == nil && sentinel.ErrorCode == e.ErrorCode
+}
@@ -0,0 +1,58 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConversionError_Is(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"secret": {Type: cel.StringType},
+		},
+		PublicFields:    []string{"status"},
+		MaxInClauseSize: 1,
+		EmptyFilter:     EmptyFilterError,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		want    error
+		wantNot error
+	}{
+		{name: "syntax", celExpr: `status ==`, want: ErrInvalidSyntax, wantNot: ErrInvalidType},
+		{name: "type", celExpr: `status`, want: ErrInvalidType, wantNot: ErrInvalidSyntax},
+		{name: "authorization", celExpr: `secret == "x"`, want: ErrUnauthorizedField, wantNot: ErrUnsupportedOperation},
+		{name: "limit", celExpr: `status in ["a", "b"]`, want: ErrLimitInSize, wantNot: ErrLimitDepth},
+		{name: "empty", celExpr: ``, want: ErrEmptyFilter, wantNot: ErrInvalidSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.ConvertWithAuth(tt.celExpr, nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.want)
+			}
+			if errors.Is(err, tt.wantNot) {
+				t.Errorf("errors.Is(%v, %v) = true, want false", err, tt.wantNot)
+			}
+			if wrapped := fmt.Errorf("list prompts: %w", err); !errors.Is(wrapped, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", wrapped, tt.want)
+			}
+		})
+	}
+
+	var convErr *ConversionError
+	_, err = converter.Convert(`status ==`)
+	if !errors.As(err, &convErr) || convErr.ErrorCode != CodeInvalidSyntax {
+		t.Errorf("Convert() error = %v, want code %s", err, CodeInvalidSyntax)
+	}
+}
@@ -0,0 +1,54 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorDetail selects how much of a conversion error is exposed by its
+// message.
+type ErrorDetail string
+
+const (
+	// ErrorDetailSanitized only exposes the public message, e.g. "invalid
+	// filter expression syntax". It is the default, for production.
+	ErrorDetailSanitized ErrorDetail = ""
+	// ErrorDetailFull appends the internal error to the public message,
+	// e.g. the CEL compiler diagnostics naming an undeclared field, for
+	// development and testing environments. Masked values stay masked.
+	ErrorDetailFull ErrorDetail = "full"
+)
+
+// validate checks that the detail level is known.
+func (d ErrorDetail) validate() error {
+	switch d {
+	case ErrorDetailSanitized, ErrorDetailFull:
+		return nil
+	}
+	return fmt.Errorf("unknown error detail %q", d)
+}
+
+// detailError exposes the internal error of a conversion error in its
+// message with ErrorDetailFull.
+func (c *Converter) detailError(err error) error {
+	if c.errorDetail != ErrorDetailFull || err == nil {
+		return err
+	}
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.InternalError == nil {
+		return err
+	}
+	message := convErr.PublicMessage
+	if location := convErr.Location; location != nil {
+		message += fmt.Sprintf(" at line %d, column %d", location.Line, location.Column)
+		if location.Token != "" {
+			message += fmt.Sprintf(" near %q", location.Token)
+		}
+	}
+	return &ConversionError{
+		PublicMessage: fmt.Sprintf("%s: %v", message, convErr.InternalError),
+		ErrorCode:     convErr.ErrorCode,
+		InternalError: convErr.InternalError,
+		Location:      convErr.Location,
+	}
+}
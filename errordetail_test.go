@@ -0,0 +1,82 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ErrorDetail(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+		"ssn":    {Type: cel.StringType, Masked: true},
+	}
+	sanitized, err := NewConverter(Config{FieldDeclarations: fields})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	full, err := NewConverter(Config{FieldDeclarations: fields, ErrorDetail: ErrorDetailFull})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		celExpr      string
+		wantContains string
+		wantAbsent   string
+		wantSentinel error
+	}{
+		{
+			name:         "undeclared field",
+			celExpr:      `statu == "open"`,
+			wantContains: "undeclared reference to 'statu'",
+			wantSentinel: ErrInvalidSyntax,
+		},
+		{
+			name:         "type mismatch",
+			celExpr:      `status == 1`,
+			wantContains: "no matching overload",
+			wantSentinel: ErrInvalidSyntax,
+		},
+		{
+			name:         "masked value",
+			celExpr:      `ssn.matches("123-45-6789")`,
+			wantAbsent:   "123-45-6789",
+			wantSentinel: ErrUnsupportedOperation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sanitizedErr := sanitized.Convert(tt.celExpr)
+			_, fullErr := full.Convert(tt.celExpr)
+			if sanitizedErr == nil || fullErr == nil {
+				t.Fatalf("Convert() errors = %v, %v, want errors", sanitizedErr, fullErr)
+			}
+
+			var convErr *ConversionError
+			if !errors.As(sanitizedErr, &convErr) || sanitizedErr.Error() != convErr.PublicMessage {
+				t.Errorf("sanitized error = %q, want the public message", sanitizedErr)
+			}
+			if !strings.HasPrefix(fullErr.Error(), sanitizedErr.Error()) || fullErr.Error() == sanitizedErr.Error() {
+				t.Errorf("full error = %q, want the public message followed by details", fullErr)
+			}
+			if tt.wantContains != "" && !strings.Contains(fullErr.Error(), tt.wantContains) {
+				t.Errorf("full error = %q, want it to contain %q", fullErr, tt.wantContains)
+			}
+			if tt.wantAbsent != "" && strings.Contains(fullErr.Error(), tt.wantAbsent) {
+				t.Errorf("full error = %q, want it not to contain %q", fullErr, tt.wantAbsent)
+			}
+			if !errors.Is(fullErr, tt.wantSentinel) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", fullErr, tt.wantSentinel)
+			}
+		})
+	}
+
+	if _, err := NewConverter(Config{ErrorDetail: "verbose"}); err == nil {
+		t.Error("NewConverter() with an unknown error detail succeeded, want an error")
+	}
+}
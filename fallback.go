@@ -0,0 +1,191 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// Fallback selects how predicates using a function without SQL translation
+// are handled. Fallbacks widen the filter: the SQL may select rows the CEL
+// expression does not match, which must be re-checked in memory, as
+// ConvertHybrid does. They are therefore never applied under a negation.
+type Fallback int
+
+const (
+	// FallbackReject rejects the filter. It is the default.
+	FallbackReject Fallback = iota
+	// FallbackResidual replaces the predicate using the function with TRUE
+	// in the SQL and reports a warning. ConvertHybrid evaluates the
+	// predicate in memory.
+	FallbackResidual
+	// FallbackLiteralContains approximates matches() by a contains() of the
+	// longest literal the pattern requires, e.g. `name.matches("^ab+c")`
+	// by `name LIKE '%ab%'`, and reports a warning. ConvertHybrid re-checks
+	// the pattern in memory. Only valid for matches().
+	FallbackLiteralContains
+)
+
+// validateFallbacks checks that every configured fallback applies to its
+// function.
+func validateFallbacks(fallbacks map[string]Fallback) error {
+	for function, fallback := range fallbacks {
+		switch fallback {
+		case FallbackReject, FallbackResidual:
+		case FallbackLiteralContains:
+			if function != "matches" {
+				return fmt.Errorf("function %s: FallbackLiteralContains only applies to matches()", function)
+			}
+		default:
+			return fmt.Errorf("function %s: unknown fallback %d", function, fallback)
+		}
+	}
+	return nil
+}
+
+// fallback handles the untranslatable predicate expr according to the
+// fallbacks configured for the functions it uses. It returns err unchanged
+// when no fallback applies.
+func (c *Converter) fallback(expr celast.Expr, err error) (squirrel.Sqlizer, error) {
+	if len(c.fallbacks) == 0 || c.conv == nil || c.conv.negations > 0 || !isUntranslatable(err) {
+		return nil, err
+	}
+
+	call := expr.AsCall()
+	switch call.FunctionName() {
+	case "_&&_", "_||_", "!_":
+		// Operands are handled individually
+		return nil, err
+	case "matches":
+		if c.fallbacks["matches"] == FallbackLiteralContains {
+			if sqlizer, ok := c.matchesLiteralContains(call); ok {
+				c.conv.approximated = true
+				return sqlizer, nil
+			}
+		}
+	}
+
+	var function string
+	walkExpr(expr, func(e celast.Expr) {
+		if name := e.AsCall().FunctionName(); name != "" && function == "" && c.fallbacks[name] != FallbackReject {
+			function = name
+		}
+	})
+	if function == "" {
+		return nil, err
+	}
+
+	c.warnf(DiagnosticApproximated, "", "predicate using %s() has no SQL translation and was widened to TRUE; it must be evaluated in memory", function)
+	c.conv.approximated = true
+	return squirrel.Expr("TRUE"), nil
+}
+
+// matchesLiteralContains approximates a matches() call by a LIKE match of
+// the longest literal required by the pattern.
+func (c *Converter) matchesLiteralContains(call celast.CallExpr) (squirrel.Sqlizer, bool) {
+	var target celast.Expr
+	args := call.Args()
+	if call.IsMemberFunction() {
+		target = call.Target()
+	} else if len(args) == 2 {
+		target, args = args[0], args[1:]
+	}
+	if target == nil || len(args) != 1 {
+		return nil, false
+	}
+
+	lhs, err := c.getColumnExpr(target)
+	if err != nil {
+		return nil, false
+	}
+	value, err := c.getConstantValue(args[0])
+	if err != nil {
+		return nil, false
+	}
+	pattern, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, false
+	}
+	literal, foldCase := requiredLiteral(re.Simplify())
+	if literal == "" {
+		return nil, false
+	}
+
+	sqlizer, err := c.matchLike(lhs, "%"+escapeLikePattern(literal)+"%", foldCase || c.isCaseInsensitive(lhs.field))
+	if err != nil {
+		return nil, false
+	}
+	c.warnf(DiagnosticApproximated, lhs.field, "matches() on %s approximated by contains(%q); rows must be re-checked in memory",
+		lhs.sql, c.displayValue(lhs.field, literal))
+	return sqlizer, true
+}
+
+// requiredLiteral returns the longest literal that every match of re
+// contains, and whether it is matched case-insensitively.
+func requiredLiteral(re *syntax.Regexp) (string, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return strings.ToLower(string(re.Rune)), true
+		}
+		return string(re.Rune), false
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiteral(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiteral(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var (
+			best     string
+			bestFold bool
+			run      []rune
+			runFold  bool
+		)
+		keep := func(literal string, foldCase bool) {
+			if len(literal) > len(best) {
+				best, bestFold = literal, foldCase
+			}
+		}
+		for _, sub := range re.Sub {
+			// A repeated literal ends the run, and its last repetition
+			// starts the next one: ab+c contains both ab and bc
+			repeated := sub.Op == syntax.OpPlus || (sub.Op == syntax.OpRepeat && sub.Min >= 1)
+			literal := sub
+			if repeated {
+				literal = sub.Sub[0]
+			}
+			if literal.Op != syntax.OpLiteral {
+				keep(requiredLiteral(sub))
+				keep(string(run), runFold)
+				run = nil
+				continue
+			}
+
+			foldCase := literal.Flags&syntax.FoldCase != 0
+			if len(run) > 0 && foldCase != runFold {
+				keep(string(run), runFold)
+				run = nil
+			}
+			run, runFold = append(run, literal.Rune...), foldCase
+			if repeated {
+				keep(string(run), runFold)
+				run = append([]rune(nil), literal.Rune...)
+			}
+		}
+		keep(string(run), runFold)
+		if bestFold {
+			best = strings.ToLower(best)
+		}
+		return best, bestFold
+	}
+	return "", false
+}
@@ -0,0 +1,188 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newTestFallbackConverter(t *testing.T, fallbacks map[string]Fallback) *Converter {
+	t.Helper()
+
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name": {Type: cel.StringType},
+			"age":  {Type: cel.IntType},
+		},
+		StringExtensions: true,
+		Fallbacks:        fallbacks,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_Fallbacks(t *testing.T) {
+	converter := newTestFallbackConverter(t, map[string]Fallback{
+		"matches": FallbackLiteralContains,
+		"reverse": FallbackResidual,
+	})
+
+	tests := []struct {
+		name         string
+		celExpr      string
+		wantSQL      string
+		wantArgs     []any
+		wantWarnings int
+	}{
+		{
+			name:         "matches literal",
+			celExpr:      `name.matches("^ab+c$")`,
+			wantSQL:      "name LIKE ?",
+			wantArgs:     []any{"%ab%"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "matches case-insensitive literal",
+			celExpr:      `age > 1 && name.matches("(?i)hello.*world")`,
+			wantSQL:      "(age > ? AND LOWER(name) LIKE LOWER(?))",
+			wantArgs:     []any{int64(1), "%hello%"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "matches without literal",
+			celExpr:      `name.matches("[a-z]+")`,
+			wantSQL:      "TRUE",
+			wantWarnings: 1,
+		},
+		{
+			name:         "residual in disjunction",
+			celExpr:      `age > 1 || name.reverse() == "bob"`,
+			wantSQL:      "(age > ? OR TRUE)",
+			wantArgs:     []any{int64(1)},
+			wantWarnings: 1,
+		},
+		{
+			name:     "translatable",
+			celExpr:  `name.contains("x")`,
+			wantSQL:  "name LIKE ?",
+			wantArgs: []any{"%x%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+			if len(withCode(result.Warnings, DiagnosticApproximated)) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want %d", result.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_FallbacksRejected(t *testing.T) {
+	converter := newTestFallbackConverter(t, map[string]Fallback{
+		"matches": FallbackLiteralContains,
+	})
+
+	for _, celExpr := range []string{
+		`!name.matches("abc")`,
+		`!(age > 1 && name.matches("abc"))`,
+		`name.reverse() == "bob"`,
+	} {
+		t.Run(celExpr, func(t *testing.T) {
+			_, err := converter.Convert(celExpr)
+			if err == nil || err.(*ConversionError).ErrorCode != "UNSUPPORTED_OPERATION" {
+				t.Errorf("Convert() error = %v, want UNSUPPORTED_OPERATION", err)
+			}
+		})
+	}
+}
+
+func TestConverter_ConvertHybrid_Fallbacks(t *testing.T) {
+	converter := newTestFallbackConverter(t, map[string]Fallback{
+		"matches": FallbackLiteralContains,
+	})
+
+	result, err := converter.ConvertHybrid(`age > 1 && name.matches("^abc[0-9]")`)
+	if err != nil {
+		t.Fatalf("ConvertHybrid() error = %v", err)
+	}
+
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "(age > ? AND name LIKE ?)"; sql != want {
+		t.Errorf("SQL = %q, want %q", sql, want)
+	}
+	if result.Residual == nil {
+		t.Fatal("Residual = nil, want the exact matches() check")
+	}
+	if got, want := result.Residual.String(), `name.matches("^abc[0-9]")`; got != want {
+		t.Errorf("Residual = %q, want %q", got, want)
+	}
+
+	for name, want := range map[string]bool{"abc1": true, "xabc1": false} {
+		matched, err := result.Residual.Eval(map[string]any{"name": name, "age": int64(2)})
+		if err != nil {
+			t.Fatalf("Eval() error = %v", err)
+		}
+		if matched != want {
+			t.Errorf("Eval(%q) = %v, want %v", name, matched, want)
+		}
+	}
+}
+
+func TestNewConverter_InvalidFallbacks(t *testing.T) {
+	for name, fallbacks := range map[string]map[string]Fallback{
+		"literal contains": {"reverse": FallbackLiteralContains},
+		"unknown":          {"matches": Fallback(42)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewConverter(Config{Fallbacks: fallbacks}); err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	tests := map[string]string{
+		"abc":             "abc",
+		"^ab+c$":          "ab",
+		"x(hello)+y":      "hello",
+		"foo|bar":         "",
+		"a?bcd":           "bcd",
+		"[0-9]{2}-order":  "-order",
+		"(?:status)=done": "status=done",
+		"ab+cd":           "bcd",
+	}
+
+	for pattern, want := range tests {
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			t.Fatalf("syntax.Parse(%q) error = %v", pattern, err)
+		}
+		if got, _ := requiredLiteral(re.Simplify()); got != want {
+			t.Errorf("requiredLiteral(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package cel2squirrel
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Fields returns an iterator over the declared filterable fields, ordered by
+// CEL field name. The yielded ColumnMapping always carries the resolved SQL
+// column and table, even when the declaration left Column empty or relied on
+// Config.TableAlias, except for computed fields declared with Expr.
+func (c *Converter) Fields() iter.Seq2[string, ColumnMapping] {
+	c = c.current()
+	return func(yield func(string, ColumnMapping) bool) {
+		for _, name := range slices.Sorted(maps.Keys(c.fieldDeclarations)) {
+			if !yield(name, c.resolvedMapping(name)) {
+				return
+			}
+		}
+	}
+}
+
+// Field returns the mapping declared for the given CEL field name.
+func (c *Converter) Field(name string) (ColumnMapping, bool) {
+	c = c.current()
+	if _, ok := c.fieldDeclarations[name]; !ok {
+		return ColumnMapping{}, false
+	}
+	return c.resolvedMapping(name), true
+}
+
+// FieldsByColumn returns an iterator over the CEL fields mapped to the given
+// SQL column, ordered by field name.
+func (c *Converter) FieldsByColumn(column string) iter.Seq2[string, ColumnMapping] {
+	return func(yield func(string, ColumnMapping) bool) {
+		for name, mapping := range c.Fields() {
+			if mapping.Column != column {
+				continue
+			}
+			if !yield(name, mapping) {
+				return
+			}
+		}
+	}
+}
+
+// resolvedMapping returns the declaration of a field with its column resolved.
+func (c *Converter) resolvedMapping(name string) ColumnMapping {
+	mapping := c.fieldDeclarations[name]
+	if mapping.Expr != "" {
+		// Computed fields have no column
+		return mapping
+	}
+	if mapping.Column == "" {
+		mapping.Column = name
+	}
+	if mapping.Table == "" && !strings.Contains(mapping.Column, ".") {
+		mapping.Table = c.tableAlias
+		if join, ok := c.fieldJoins[name]; ok {
+			mapping.Table = join.name()
+		}
+	}
+	return mapping
+}
@@ -0,0 +1,68 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Fields(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status":  {Type: cel.StringType},
+			"ownerId": {Type: cel.StringType, Column: "owner_id"},
+			"owner":   {Type: cel.StringType, Column: "owner_id"},
+			"age":     {Type: cel.IntType, Column: "user_age"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	t.Run("ordered enumeration", func(t *testing.T) {
+		var names, columns []string
+		for name, mapping := range converter.Fields() {
+			names = append(names, name)
+			columns = append(columns, mapping.Column)
+		}
+
+		wantNames := []string{"age", "owner", "ownerId", "status"}
+		wantColumns := []string{"user_age", "owner_id", "owner_id", "status"}
+		for i := range wantNames {
+			if i >= len(names) || names[i] != wantNames[i] || columns[i] != wantColumns[i] {
+				t.Fatalf("Fields() = %v/%v, want %v/%v", names, columns, wantNames, wantColumns)
+			}
+		}
+	})
+
+	t.Run("early termination", func(t *testing.T) {
+		count := 0
+		for range converter.Fields() {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("expected iteration to stop after 1 field, got %d", count)
+		}
+	})
+
+	t.Run("lookup by name", func(t *testing.T) {
+		mapping, ok := converter.Field("age")
+		if !ok || mapping.Column != "user_age" || mapping.Type != cel.IntType {
+			t.Errorf("Field(age) = %+v, %v", mapping, ok)
+		}
+		if _, ok := converter.Field("unknown"); ok {
+			t.Error("Field(unknown) should not be found")
+		}
+	})
+
+	t.Run("lookup by column", func(t *testing.T) {
+		var names []string
+		for name := range converter.FieldsByColumn("owner_id") {
+			names = append(names, name)
+		}
+		if len(names) != 2 || names[0] != "owner" || names[1] != "ownerId" {
+			t.Errorf("FieldsByColumn(owner_id) = %v", names)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package cel2squirrel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+)
+
+// fingerprintVersion is bumped whenever the canonical form changes, so that
+// fingerprints computed by different releases never collide.
+const fingerprintVersion = "cel2squirrel/fingerprint/v1"
+
+// fingerprintEnv parses expressions for Fingerprint, which needs no
+// declarations.
+var fingerprintEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(cel.OptionalTypes())
+})
+
+// Fingerprint returns the fingerprint of a filter expression without
+// converting it, as ConvertResult.Fingerprint does. The expression is only
+// parsed, so its fields need not be declared.
+func Fingerprint(celExpr string) (string, error) {
+	env, err := fingerprintEnv()
+	if err != nil {
+		return "", err
+	}
+	ast, issues := env.Parse(celExpr)
+	if issues != nil && issues.Err() != nil {
+		return "", compileError(celExpr, "CEL parsing", issues)
+	}
+	return fingerprint(ast.NativeRep().Expr()), nil
+}
+
+// Fingerprint returns a hash of the shape of the converted expression, e.g.
+// to cache query plans or deduplicate stored filters. Literal values are
+// abstracted and the operands of &&, ||, == and != sorted, so that
+// `a == 1 && b == "x"` and `"y" == b && a == 2` share a fingerprint.
+// Comprehension variables are renamed, and formatting is ignored. Converters
+// folding constants or wrapping timestamp strings fingerprint the rewritten
+// expression.
+func (r *ConvertResult) Fingerprint() string {
+	if r.expr == nil {
+		return ""
+	}
+	return fingerprint(r.expr)
+}
+
+// fingerprint hashes the canonical form of an expression.
+func fingerprint(expr celast.Expr) string {
+	h := sha256.New()
+	writeKeyPart(h, fingerprintVersion)
+	writeKeyPart(h, canonicalExpr(expr, nil))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalExpr renders an expression in canonical form, renaming the
+// comprehension variables in vars.
+func canonicalExpr(expr celast.Expr, vars map[string]string) string {
+	switch expr.Kind() {
+	case celast.LiteralKind:
+		if expr.AsLiteral() == types.NullValue {
+			return "null"
+		}
+		return "?"
+	case celast.IdentKind:
+		if name, ok := vars[expr.AsIdent()]; ok {
+			return name
+		}
+		return expr.AsIdent()
+	case celast.SelectKind:
+		sel := expr.AsSelect()
+		operand := canonicalExpr(sel.Operand(), vars)
+		if sel.IsTestOnly() {
+			return "has(" + operand + "." + sel.FieldName() + ")"
+		}
+		return operand + "." + sel.FieldName()
+	case celast.CallKind:
+		return canonicalCall(expr.AsCall(), vars)
+	case celast.ListKind:
+		elements := expr.AsList().Elements()
+		elems := make([]string, len(elements))
+		literals := true
+		for i, elem := range elements {
+			elems[i] = canonicalExpr(elem, vars)
+			literals = literals && elems[i] == "?"
+		}
+		if literals && len(elems) > 0 {
+			return "[?]"
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	case celast.MapKind:
+		entries := make([]string, expr.AsMap().Size())
+		for i, entry := range expr.AsMap().Entries() {
+			mapEntry := entry.AsMapEntry()
+			entries[i] = canonicalExpr(mapEntry.Key(), vars) + ":" + canonicalExpr(mapEntry.Value(), vars)
+		}
+		return "{" + strings.Join(entries, ",") + "}"
+	case celast.StructKind:
+		fields := expr.AsStruct().Fields()
+		entries := make([]string, len(fields))
+		for i, field := range fields {
+			entries[i] = field.AsStructField().Name() + ":" + canonicalExpr(field.AsStructField().Value(), vars)
+		}
+		return expr.AsStruct().TypeName() + "{" + strings.Join(entries, ",") + "}"
+	case celast.ComprehensionKind:
+		comp := expr.AsComprehension()
+		iterRange := canonicalExpr(comp.IterRange(), vars)
+		accuInit := canonicalExpr(comp.AccuInit(), vars)
+
+		scoped := make(map[string]string, len(vars)+3)
+		for name, renamed := range vars {
+			scoped[name] = renamed
+		}
+		level := strconv.Itoa(len(vars))
+		scoped[comp.IterVar()] = "@it" + level
+		if comp.HasIterVar2() {
+			scoped[comp.IterVar2()] = "@iv" + level
+		}
+		scoped[comp.AccuVar()] = "@ac" + level
+		return "comprehension(" + strings.Join([]string{
+			iterRange,
+			accuInit,
+			canonicalExpr(comp.LoopCondition(), scoped),
+			canonicalExpr(comp.LoopStep(), scoped),
+			canonicalExpr(comp.Result(), scoped),
+		}, ";") + ")"
+	}
+	return ""
+}
+
+// canonicalCall renders a call in canonical form, flattening chains of &&
+// and || and sorting the operands of commutative operators.
+func canonicalCall(call celast.CallExpr, vars map[string]string) string {
+	var args []string
+	function := call.FunctionName()
+	switch function {
+	case operators.LogicalAnd, operators.LogicalOr:
+		var flatten func(celast.Expr)
+		flatten = func(arg celast.Expr) {
+			if arg.Kind() == celast.CallKind && arg.AsCall().FunctionName() == function {
+				for _, innerArg := range arg.AsCall().Args() {
+					flatten(innerArg)
+				}
+				return
+			}
+			args = append(args, canonicalExpr(arg, vars))
+		}
+		for _, arg := range call.Args() {
+			flatten(arg)
+		}
+		slices.Sort(args)
+	default:
+		if call.IsMemberFunction() {
+			args = append(args, "."+canonicalExpr(call.Target(), vars))
+		}
+		for _, arg := range call.Args() {
+			args = append(args, canonicalExpr(arg, vars))
+		}
+		if function == operators.Equals || function == operators.NotEquals {
+			slices.Sort(args)
+		}
+	}
+	return function + "(" + strings.Join(args, ",") + ")"
+}
@@ -0,0 +1,130 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		wantSame bool
+	}{
+		{
+			name:     "literals abstracted",
+			a:        `status == "active" && age > 18`,
+			b:        `status == "closed" && age > 65`,
+			wantSame: true,
+		},
+		{
+			name:     "commutative operands",
+			a:        `status == "active" && (age > 18 || vip)`,
+			b:        `(vip || age > 21) && "closed" == status`,
+			wantSame: true,
+		},
+		{
+			name:     "associative chains",
+			a:        `(a == 1 && b == 2) && c == 3`,
+			b:        `a == 1 && (c == 3 && b == 2)`,
+			wantSame: true,
+		},
+		{
+			name:     "formatting",
+			a:        `status=="active"`,
+			b:        "status ==\n  \"active\"",
+			wantSame: true,
+		},
+		{
+			name:     "list literals",
+			a:        `status in ["a", "b"]`,
+			b:        `status in ["c", "d", "e"]`,
+			wantSame: true,
+		},
+		{
+			name:     "comprehension variables",
+			a:        `comments.exists(c, c.flagged)`,
+			b:        `comments.exists(x, x.flagged)`,
+			wantSame: true,
+		},
+		{
+			name: "different fields",
+			a:    `status == "active"`,
+			b:    `state == "active"`,
+		},
+		{
+			name: "different operators",
+			a:    `age > 18`,
+			b:    `age >= 18`,
+		},
+		{
+			name: "ordered operands",
+			a:    `age > 18`,
+			b:    `18 > age`,
+		},
+		{
+			name: "null is not abstracted",
+			a:    `manager == null`,
+			b:    `manager == "bob"`,
+		},
+		{
+			name: "different logical operators",
+			a:    `a && b`,
+			b:    `a || b`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Fingerprint(tt.a)
+			if err != nil {
+				t.Fatalf("Fingerprint(%q) error = %v", tt.a, err)
+			}
+			b, err := Fingerprint(tt.b)
+			if err != nil {
+				t.Fatalf("Fingerprint(%q) error = %v", tt.b, err)
+			}
+			if (a == b) != tt.wantSame {
+				t.Errorf("Fingerprint(%q) == Fingerprint(%q) is %v, want %v", tt.a, tt.b, a == b, tt.wantSame)
+			}
+		})
+	}
+
+	if _, err := Fingerprint(`status ==`); errorCode(err) != "INVALID_SYNTAX" {
+		t.Errorf("Fingerprint() error = %v, want INVALID_SYNTAX", err)
+	}
+}
+
+func TestConvertResult_Fingerprint(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	for _, celExpr := range []string{
+		`status == "active" && age > 18`,
+		`status in ["a", "b"] || age != 3`,
+	} {
+		result, err := converter.Convert(celExpr)
+		if err != nil {
+			t.Fatalf("Convert(%q) error = %v", celExpr, err)
+		}
+		want, err := Fingerprint(celExpr)
+		if err != nil {
+			t.Fatalf("Fingerprint(%q) error = %v", celExpr, err)
+		}
+		if got := result.Fingerprint(); got != want {
+			t.Errorf("Fingerprint() of %q = %s, want %s as the standalone Fingerprint", celExpr, got, want)
+		}
+	}
+
+	if got := (&ConvertResult{}).Fingerprint(); got != "" {
+		t.Errorf("Fingerprint() of an empty result = %q, want empty", got)
+	}
+}
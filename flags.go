@@ -0,0 +1,129 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+	"github.com/google/cel-go/parser"
+)
+
+// anyOfFunction is the name of the flag group macro and of the function it
+// expands to.
+const anyOfFunction = "anyOf"
+
+// flagGroups maps each flag to the name of its group.
+type flagGroups map[string]string
+
+// newFlagGroups validates the declared flag groups: every flag must be a
+// boolean field and belong to a single group.
+func newFlagGroups(groups map[string][]string, fields map[string]ColumnMapping) (flagGroups, error) {
+	flags := make(flagGroups)
+
+	// Iterate in a stable order so that errors are deterministic
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if len(groups[name]) == 0 {
+			return nil, fmt.Errorf("flag group %s is empty", name)
+		}
+		for _, flag := range groups[name] {
+			mapping, ok := fields[flag]
+			if !ok || mapping.Type == nil || !mapping.Type.IsExactType(cel.BoolType) {
+				return nil, fmt.Errorf("flag group %s: %s is not a declared bool field", name, flag)
+			}
+			if group, exists := flags[flag]; exists {
+				return nil, fmt.Errorf("flag group %s: %s already belongs to group %s", name, flag, group)
+			}
+			flags[flag] = name
+		}
+	}
+	return flags, nil
+}
+
+// declarations returns the anyOf() macro and the function it expands to.
+func (flags flagGroups) declarations() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Macros(cel.GlobalVarArgMacro(anyOfFunction, flags.expandAnyOf)),
+		cel.Function(anyOfFunction,
+			cel.Overload("any_of_list_bool",
+				[]*cel.Type{cel.ListType(cel.BoolType)}, cel.BoolType,
+				cel.UnaryBinding(anyTrue),
+			),
+		),
+	}
+}
+
+// expandAnyOf checks that the arguments of anyOf() are flags of a single
+// group and rewrites the call to take them as a list.
+func (flags flagGroups) expandAnyOf(eh parser.ExprHelper, _ ast.Expr, args []ast.Expr) (ast.Expr, *common.Error) {
+	if len(args) == 0 {
+		return nil, eh.NewError(0, "anyOf() requires at least one flag")
+	}
+
+	group := ""
+	for _, arg := range args {
+		if arg.Kind() != ast.IdentKind {
+			return nil, eh.NewError(arg.ID(), "anyOf() arguments must be flags")
+		}
+		name := arg.AsIdent()
+		flagGroup, ok := flags[name]
+		if !ok {
+			return nil, eh.NewError(arg.ID(), fmt.Sprintf("%s is not part of a flag group", name))
+		}
+		if group != "" && flagGroup != group {
+			return nil, eh.NewError(arg.ID(), fmt.Sprintf("anyOf() mixes flag groups %s and %s", group, flagGroup))
+		}
+		group = flagGroup
+	}
+
+	return eh.NewCall(anyOfFunction, eh.NewList(args...)), nil
+}
+
+// anyTrue evaluates anyOf() for residual filters.
+func anyTrue(arg ref.Val) ref.Val {
+	list, ok := arg.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(arg)
+	}
+	for it := list.Iterator(); it.HasNext() == types.True; {
+		if it.Next() == types.True {
+			return types.True
+		}
+	}
+	return types.False
+}
+
+// convertAnyOf converts an expanded anyOf() call to a disjunction of its
+// flags: (is_draft = TRUE OR is_archived = TRUE).
+func (c *Converter) convertAnyOf(call celast.CallExpr) (squirrel.Sqlizer, error) {
+	if len(call.Args()) != 1 || call.Args()[0].Kind() != celast.ListKind {
+		return nil, fmt.Errorf("anyOf() requires a list of flags")
+	}
+
+	elements := call.Args()[0].AsList().Elements()
+	predicates := make([]string, 0, len(elements))
+	for _, elem := range elements {
+		if elem.Kind() != celast.IdentKind {
+			return nil, fmt.Errorf("anyOf() arguments must be flags")
+		}
+		if err := c.charge(0, 1, approxValueBytes); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, c.mapFieldName(elem.AsIdent())+" = TRUE")
+	}
+
+	return squirrel.Expr("(" + strings.Join(predicates, " OR ") + ")"), nil
+}
@@ -0,0 +1,158 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newTestFlagConverter(t *testing.T) *Converter {
+	t.Helper()
+
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"is_draft":    {Type: cel.BoolType, Column: "draft"},
+			"is_archived": {Type: cel.BoolType},
+			"is_deleted":  {Type: cel.BoolType},
+			"is_featured": {Type: cel.BoolType},
+			"is_pinned":   {Type: cel.BoolType},
+			"age":         {Type: cel.IntType},
+		},
+		FlagGroups: map[string][]string{
+			"state":     {"is_draft", "is_archived", "is_deleted"},
+			"highlight": {"is_featured", "is_pinned"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_AnyOf(t *testing.T) {
+	converter := newTestFlagConverter(t)
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:    "whole group",
+			celExpr: `anyOf(is_draft, is_archived, is_deleted)`,
+			wantSQL: "(draft = TRUE OR is_archived = TRUE OR is_deleted = TRUE)",
+		},
+		{
+			name:    "single flag",
+			celExpr: `anyOf(is_pinned)`,
+			wantSQL: "(is_pinned = TRUE)",
+		},
+		{
+			name:     "combined",
+			celExpr:  `age > 18 && !anyOf(is_archived, is_deleted)`,
+			wantSQL:  "(age > ? AND NOT ((is_archived = TRUE OR is_deleted = TRUE)))",
+			wantArgs: []any{int64(18)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_AnyOfErrors(t *testing.T) {
+	converter := newTestFlagConverter(t)
+
+	tests := []struct {
+		name    string
+		celExpr string
+	}{
+		{name: "mixed groups", celExpr: `anyOf(is_draft, is_pinned)`},
+		{name: "not a flag", celExpr: `anyOf(is_draft, age > 1)`},
+		{name: "unknown flag", celExpr: `anyOf(is_visible)`},
+		{name: "no flags", celExpr: `anyOf()`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if err == nil {
+				t.Fatal("Convert() expected error")
+			}
+			if code := err.(*ConversionError).ErrorCode; code != "INVALID_SYNTAX" {
+				t.Errorf("ErrorCode = %s, want INVALID_SYNTAX", code)
+			}
+		})
+	}
+}
+
+func TestConverter_ConvertHybrid_AnyOfResidual(t *testing.T) {
+	converter := newTestFlagConverter(t)
+
+	result, err := converter.ConvertHybrid(`age > 18 && (anyOf(is_draft, is_deleted) || string(age).matches("^2"))`)
+	if err != nil {
+		t.Fatalf("ConvertHybrid() error = %v", err)
+	}
+	if result.Residual == nil {
+		t.Fatal("Residual = nil, want the disjunction")
+	}
+
+	vars := map[string]any{"age": int64(30), "is_draft": false, "is_deleted": true}
+	matched, err := result.Residual.Eval(vars)
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if !matched {
+		t.Error("Eval() = false, want true")
+	}
+
+	vars["is_deleted"] = false
+	if matched, _ := result.Residual.Eval(vars); matched {
+		t.Error("Eval() = true, want false")
+	}
+}
+
+func TestNewConverter_InvalidFlagGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups map[string][]string
+	}{
+		{name: "empty group", groups: map[string][]string{"state": {}}},
+		{name: "undeclared flag", groups: map[string][]string{"state": {"is_visible"}}},
+		{name: "non-bool flag", groups: map[string][]string{"state": {"age"}}},
+		{name: "shared flag", groups: map[string][]string{"a": {"is_draft"}, "b": {"is_draft"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{
+					"is_draft": {Type: cel.BoolType},
+					"age":      {Type: cel.IntType},
+				},
+				FlagGroups: tt.groups,
+			})
+			if err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+}
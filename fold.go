@@ -0,0 +1,123 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// exprFactory builds the expressions rewritten by the converter.
+var exprFactory = celast.NewExprFactory()
+
+// foldConstants simplifies boolean operators with constant operands:
+// `true && x` becomes `x`, `false || x` becomes `x`, `false && x` becomes
+// `false` and `!true` becomes `false`. The input tree is left untouched.
+func foldConstants(expr celast.Expr) celast.Expr {
+	if expr.Kind() != celast.CallKind || expr.AsCall().IsMemberFunction() {
+		return expr
+	}
+	call := expr.AsCall()
+	args := call.Args()
+
+	switch call.FunctionName() {
+	case "_&&_", "_||_":
+		if len(args) != 2 {
+			return expr
+		}
+		left, right := foldConstants(args[0]), foldConstants(args[1])
+		absorbing := call.FunctionName() == "_||_" // true absorbs OR, false absorbs AND
+
+		for _, operand := range []celast.Expr{left, right} {
+			if value, ok := boolConstant(operand); ok && value == absorbing {
+				return newBoolConstant(expr.ID(), absorbing)
+			}
+		}
+		if _, ok := boolConstant(left); ok {
+			return right
+		}
+		if _, ok := boolConstant(right); ok {
+			return left
+		}
+		if left == args[0] && right == args[1] {
+			return expr
+		}
+		return newCall(expr.ID(), call.FunctionName(), left, right)
+
+	case "!_":
+		if len(args) != 1 {
+			return expr
+		}
+		inner := foldConstants(args[0])
+		if value, ok := boolConstant(inner); ok {
+			return newBoolConstant(expr.ID(), !value)
+		}
+		if inner == args[0] {
+			return expr
+		}
+		return newCall(expr.ID(), call.FunctionName(), inner)
+	}
+
+	return expr
+}
+
+// boolConstant returns the value of a boolean literal expression.
+func boolConstant(expr celast.Expr) (bool, bool) {
+	value, ok := expr.AsLiteral().(types.Bool)
+	return bool(value), ok
+}
+
+// newBoolConstant builds a boolean literal expression.
+func newBoolConstant(id int64, value bool) celast.Expr {
+	return exprFactory.NewLiteral(id, types.Bool(value))
+}
+
+// newCall builds a global function call expression.
+func newCall(id int64, function string, args ...celast.Expr) celast.Expr {
+	return exprFactory.NewCall(id, function, args...)
+}
+
+// isConstantArithmetic reports whether expr is arithmetic, or a negation,
+// over literals only, such as `18 + 3` or `1024 * 1024`.
+func isConstantArithmetic(expr celast.Expr) bool {
+	if expr.Kind() != celast.CallKind || expr.AsCall().IsMemberFunction() {
+		return false
+	}
+	call := expr.AsCall()
+	if _, ok := arithmeticOperators[call.FunctionName()]; !ok && call.FunctionName() != "-_" {
+		return false
+	}
+	for _, arg := range call.Args() {
+		if arg.Kind() != celast.LiteralKind && !isConstantArithmetic(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// evalConstant evaluates a constant arithmetic expression with the CEL
+// evaluator, so that the result can be bound as a single argument.
+func (c *Converter) evalConstant(expr celast.Expr) (interface{}, error) {
+	prg, err := c.env.PlanProgram(celast.NewAST(expr, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan constant expression: %w", err)
+	}
+
+	out, _, err := prg.Eval(cel.NoVars())
+	if err != nil {
+		// Division by zero, overflow, ...
+		return nil, newConversionError(
+			"invalid constant expression",
+			CodeInvalidSyntax,
+			fmt.Errorf("failed to evaluate constant expression: %w", err),
+		)
+	}
+
+	switch value := out.Value().(type) {
+	case int64, uint64, float64, string:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unsupported constant expression result: %T", value)
+	}
+}
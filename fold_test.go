@@ -0,0 +1,172 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_FoldConstants(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"is_draft": {Type: cel.BoolType, Column: "is_draft"},
+		"age":      {Type: cel.IntType, Column: "age"},
+	}
+
+	tests := []struct {
+		name            string
+		celExpr         string
+		wantSQL         string
+		wantAlwaysTrue  bool
+		wantAlwaysFalse bool
+	}{
+		{name: "true AND field", celExpr: `true && is_draft`, wantSQL: "is_draft = ?"},
+		{name: "field AND true", celExpr: `age > 3 && true`, wantSQL: "age > ?"},
+		{name: "false OR field", celExpr: `false || is_draft`, wantSQL: "is_draft = ?"},
+		{name: "false AND field", celExpr: `false && age > 3`, wantSQL: "FALSE", wantAlwaysFalse: true},
+		{name: "field OR true", celExpr: `age > 3 || true`, wantSQL: "TRUE", wantAlwaysTrue: true},
+		{name: "negated constant", celExpr: `!false`, wantSQL: "TRUE", wantAlwaysTrue: true},
+		{name: "nested", celExpr: `(true && age > 3) || (false && is_draft)`, wantSQL: "age > ?"},
+		{name: "negated folded", celExpr: `!(true && is_draft)`, wantSQL: "NOT (is_draft = ?)"},
+		{name: "nothing to fold", celExpr: `age > 3 && is_draft`, wantSQL: "(age > ? AND is_draft = ?)"},
+	}
+
+	converter, err := NewConverter(Config{FieldDeclarations: fields, FoldConstants: true})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if result.AlwaysTrue != tt.wantAlwaysTrue || result.AlwaysFalse != tt.wantAlwaysFalse {
+				t.Errorf("AlwaysTrue = %v, AlwaysFalse = %v, want %v, %v",
+					result.AlwaysTrue, result.AlwaysFalse, tt.wantAlwaysTrue, tt.wantAlwaysFalse)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_TrivialFlagsWithoutFolding(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"age": {Type: cel.IntType, Column: "age"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`false && age > 3`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+
+	if sql != "(FALSE AND age > ?)" {
+		t.Errorf("ToSql() = %v, SQL should be unchanged without FoldConstants", sql)
+	}
+	if !result.AlwaysFalse || result.AlwaysTrue {
+		t.Errorf("AlwaysFalse should be reported without FoldConstants")
+	}
+}
+
+func TestConverter_Convert_ConstantArithmetic(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"age":   {Type: cel.IntType, Column: "age"},
+			"size":  {Type: cel.IntType, Column: "size"},
+			"ratio": {Type: cel.DoubleType, Column: "ratio"},
+			"name":  {Type: cel.StringType, Column: "name"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{name: "sum", celExpr: `age > 18 + 3`, wantSQL: "age > ?", wantArgs: []any{int64(21)}},
+		{name: "product", celExpr: `size < 1024 * 1024`, wantSQL: "size < ?", wantArgs: []any{int64(1048576)}},
+		{name: "nested", celExpr: `age == (10 - 4) % 4 * -(2)`, wantSQL: "age = ?", wantArgs: []any{int64(-4)}},
+		{name: "double", celExpr: `ratio <= 1.0 / 4.0`, wantSQL: "ratio <= ?", wantArgs: []any{0.25}},
+		{name: "string concatenation", celExpr: `name == "a" + "b"`, wantSQL: "name = ?", wantArgs: []any{"ab"}},
+		{name: "list elements", celExpr: `age in [1 + 1, 2 * 2]`, wantSQL: "age IN (?,?)", wantArgs: []any{int64(2), int64(4)}},
+		{name: "column arithmetic", celExpr: `age * (2 + 3) > 10`, wantSQL: "(age * ?) > ?", wantArgs: []any{int64(5), int64(10)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_ConstantArithmeticErrors(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"age": {Type: cel.IntType, Column: "age"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantCode string
+	}{
+		{name: "division by zero", celExpr: `age > 1 / 0`, wantCode: "INVALID_SYNTAX"},
+		{name: "overflow", celExpr: `age > 9223372036854775807 + 1`, wantCode: "INVALID_SYNTAX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("Convert() error = %v, want ConversionError", err)
+			}
+			if string(convErr.ErrorCode) != tt.wantCode {
+				t.Errorf("Code = %q, want %q", convErr.ErrorCode, tt.wantCode)
+			}
+		})
+	}
+}
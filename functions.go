@@ -0,0 +1,103 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+)
+
+// filterFunctions declares the CEL functions that are not part of the
+// standard library but have a SQL translation.
+func filterFunctions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("equalsIgnoreCase",
+			cel.MemberOverload("string_equals_ignore_case_string",
+				[]*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return types.Bool(strings.EqualFold(string(lhs.(types.String)), string(rhs.(types.String))))
+				}),
+			),
+		),
+		cel.Function("containsAll",
+			cel.MemberOverload("list_contains_all_list",
+				[]*cel.Type{listOfT, listOfT}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return listContains(lhs, rhs, true)
+				}),
+			),
+		),
+		cel.Function("containsAny",
+			cel.MemberOverload("list_contains_any_list",
+				[]*cel.Type{listOfT, listOfT}, cel.BoolType,
+				cel.BinaryBinding(func(lhs, rhs ref.Val) ref.Val {
+					return listContains(lhs, rhs, false)
+				}),
+			),
+		),
+	}
+}
+
+// listOfT is the type of lists of any element type T.
+var listOfT = cel.ListType(cel.TypeParamType("T"))
+
+// listContains evaluates containsAll (all set) and containsAny.
+func listContains(lhs, rhs ref.Val, all bool) ref.Val {
+	list, ok := lhs.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(lhs)
+	}
+	elements, ok := rhs.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(rhs)
+	}
+
+	for it := elements.Iterator(); it.HasNext() == types.True; {
+		if (list.Contains(it.Next()) == types.True) != all {
+			return types.Bool(!all)
+		}
+	}
+	return types.Bool(all)
+}
+
+// convertEqualsIgnoreCase converts equalsIgnoreCase() to a case-insensitive
+// comparison: ILIKE on PostgreSQL, LOWER(column) = LOWER(?) elsewhere.
+func (c *Converter) convertEqualsIgnoreCase(call celast.CallExpr) (squirrel.Sqlizer, error) {
+	if call == nil {
+		return nil, fmt.Errorf("nil call expression")
+	}
+
+	args := call.Args()
+	if len(args) != 1 {
+		return nil, fmt.Errorf("equalsIgnoreCase() requires exactly 1 argument, got %d", len(args))
+	}
+
+	// Get the column (receiver/target)
+	lhs, err := c.getColumnExpr(call.Target())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the comparison string (argument)
+	value, err := c.getConstantValue(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	strValue, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("equalsIgnoreCase() requires string argument, got %T", value)
+	}
+
+	if c.dialect == DialectPostgreSQL {
+		// SECURITY: ILIKE interprets wildcards, escape them to keep exact matching
+		return c.matchLike(lhs, escapeLikePattern(strValue), true)
+	}
+
+	return squirrel.Expr(fmt.Sprintf("LOWER(%s) = LOWER(?)", lhs.sql), append(lhs.bound(), strValue)...), nil
+}
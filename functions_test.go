@@ -0,0 +1,109 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_EqualsIgnoreCase(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"name": {Type: cel.StringType, Column: "user_name"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "default dialect",
+			celExpr:  `name.equalsIgnoreCase("BOB")`,
+			wantSQL:  "LOWER(user_name) = LOWER(?)",
+			wantArgs: []any{"BOB"},
+		},
+		{
+			name:     "postgres uses ILIKE",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `name.equalsIgnoreCase("BOB")`,
+			wantSQL:  "user_name ILIKE ?",
+			wantArgs: []any{"BOB"},
+		},
+		{
+			name:     "postgres escapes wildcards",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `name.equalsIgnoreCase("b%_b")`,
+			wantSQL:  "user_name ILIKE ?",
+			wantArgs: []any{`b\%\_b`},
+		},
+		{
+			name:     "combined with other predicates",
+			celExpr:  `name.equalsIgnoreCase("bob") || name == "alice"`,
+			wantSQL:  "(LOWER(user_name) = LOWER(?) OR user_name = ?)",
+			wantArgs: []any{"bob", "alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("expected %d args, got %d", len(tt.wantArgs), len(args))
+			}
+
+			for i, arg := range args {
+				if arg != tt.wantArgs[i] {
+					t.Errorf("arg %d = %v, want %v", i, arg, tt.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConverter_EqualsIgnoreCase_Errors(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name": {Type: cel.StringType, Column: "name"},
+			"age":  {Type: cel.IntType, Column: "age"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+	}{
+		{name: "non-string receiver", celExpr: `age.equalsIgnoreCase("1")`},
+		{name: "field argument", celExpr: `name.equalsIgnoreCase(name)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := converter.Convert(tt.celExpr); err == nil {
+				t.Errorf("Convert(%q) should fail", tt.celExpr)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+const (
+	// KindGeography stores a location as a PostGIS geography or a MySQL
+	// spatial point with longitude/latitude coordinates.
+	KindGeography ColumnKind = "geography"
+	// KindGeometry stores a location as a PostGIS geometry in SRID 4326. It
+	// is cast to geography so that distances are measured in meters.
+	KindGeometry ColumnKind = "geometry"
+)
+
+// GeoPointType is the CEL type of location fields, which must be declared
+// with KindGeography or KindGeometry. Location fields can only be filtered
+// with near().
+var GeoPointType = cel.OpaqueType("cel2squirrel.GeoPoint")
+
+// geoFunctions declares near(location, lat, lng, meters), true when the
+// location lies within the given radius of a point.
+func geoFunctions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("near",
+			cel.Overload("near_geo_double_double_double",
+				[]*cel.Type{GeoPointType, cel.DoubleType, cel.DoubleType, cel.DoubleType}, cel.BoolType),
+			cel.Overload("near_geo_double_double_int",
+				[]*cel.Type{GeoPointType, cel.DoubleType, cel.DoubleType, cel.IntType}, cel.BoolType),
+		),
+	}
+}
+
+// convertNear converts near(location, lat, lng, meters) to a radius search:
+// ST_DWithin on PostgreSQL and ST_Distance_Sphere on MySQL.
+func (c *Converter) convertNear(call celast.CallExpr) (squirrel.Sqlizer, error) {
+	args := call.Args()
+	if len(args) != 4 || call.IsMemberFunction() {
+		return nil, fmt.Errorf("near() requires exactly 4 arguments, got %d", len(args))
+	}
+
+	field, err := c.getFieldName(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var coordinates [3]float64
+	for i, arg := range args[1:] {
+		value, err := c.getConstantValue(arg)
+		if err != nil {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case float64:
+			coordinates[i] = v
+		case int64:
+			coordinates[i] = float64(v)
+		default:
+			return nil, fmt.Errorf("near() requires numeric arguments, got %T", value)
+		}
+	}
+
+	lat, lng, meters := coordinates[0], coordinates[1], coordinates[2]
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 || meters < 0 {
+		return nil, newConversionError(
+			"invalid geographic coordinates",
+			CodeInvalidCoordinates,
+			fmt.Errorf("near() requires a latitude in [-90, 90], a longitude in [-180, 180] and a non-negative radius, got (%g, %g, %g)", lat, lng, meters),
+		)
+	}
+
+	within, ok := c.dialect.withinDistance(c.mapFieldName(field), c.fieldDeclarations[field].Kind)
+	if !ok {
+		return nil, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("dialect %q does not support radius searches on %s", c.dialect, field),
+		)
+	}
+
+	// Points are built from longitude (x) and latitude (y)
+	return squirrel.Expr(within, lng, lat, meters), nil
+}
@@ -0,0 +1,133 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_Near(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"location": {Type: GeoPointType, Column: "geo", Kind: KindGeography},
+		"position": {Type: GeoPointType, Kind: KindGeometry},
+		"name":     {Type: cel.StringType},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "postgres geography",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `near(location, 48.8566, 2.3522, 500.0)`,
+			wantSQL:  "ST_DWithin(geo, ST_MakePoint(?,?)::geography, ?)",
+			wantArgs: []any{2.3522, 48.8566, 500.0},
+		},
+		{
+			name:     "postgres geometry with integer radius",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `name == "cafe" && near(position, -33.8688, 151.2093, 1000)`,
+			wantSQL:  "(name = ? AND ST_DWithin(position::geography, ST_MakePoint(?,?)::geography, ?))",
+			wantArgs: []any{"cafe", 151.2093, -33.8688, 1000.0},
+		},
+		{
+			name:     "mysql",
+			dialect:  DialectMySQL,
+			celExpr:  `!near(location, 40.7128, -74.006, 250.0)`,
+			wantSQL:  "NOT (ST_Distance_Sphere(geo, POINT(?, ?)) <= ?)",
+			wantArgs: []any{-74.006, 40.7128, 250.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect, AuditSQL: true})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_NearErrors(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"location": {Type: GeoPointType, Kind: KindGeography},
+		"position": {Type: GeoPointType, Kind: KindGeometry},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantCode string
+	}{
+		{
+			name:     "latitude out of range",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `near(location, 91.0, 0.0, 10.0)`,
+			wantCode: "INVALID_COORDINATES",
+		},
+		{
+			name:     "negative radius",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `near(location, 0.0, 0.0, -1)`,
+			wantCode: "INVALID_COORDINATES",
+		},
+		{
+			name:     "sqlite",
+			dialect:  DialectSQLite,
+			celExpr:  `near(location, 0.0, 0.0, 10.0)`,
+			wantCode: "UNSUPPORTED_OPERATION",
+		},
+		{
+			name:     "mysql geometry",
+			dialect:  DialectMySQL,
+			celExpr:  `near(position, 0.0, 0.0, 10.0)`,
+			wantCode: "UNSUPPORTED_OPERATION",
+		},
+		{
+			name:     "comparison",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `near(location, 0.0, 0.0, 10.0) == location`,
+			wantCode: "INVALID_SYNTAX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			_, err = converter.Convert(tt.celExpr)
+			if err == nil {
+				t.Fatal("Convert() expected error")
+			}
+			if code := string(err.(*ConversionError).ErrorCode); code != tt.wantCode {
+				t.Errorf("ErrorCode = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// GroupedConfig configures a GroupedConverter. The embedded Config describes
+// the row-level fields used by the filter expression; its limits, dialect and
+// output options apply to both expressions.
+type GroupedConfig struct {
+	Config
+
+	// Aggregates maps CEL names usable in the having expression to aggregate
+	// SQL expressions.
+	// Example: map[string]ColumnMapping{
+	//   "orderCount": {Type: cel.IntType, Column: "COUNT(*)"},
+	//   "total":      {Type: cel.DoubleType, Column: "SUM(amount)"},
+	// }
+	Aggregates map[string]ColumnMapping
+
+	// GroupBy lists the row-level fields the query is grouped by. They may
+	// also be referenced by the having expression.
+	GroupBy []string
+}
+
+// GroupedConverter converts a row filter and a filter over aggregated values
+// together, producing coordinated WHERE, GROUP BY and HAVING clauses.
+type GroupedConverter struct {
+	where   *Converter
+	having  *Converter
+	groupBy []string
+}
+
+// GroupedResult contains the result of a grouped conversion.
+type GroupedResult struct {
+	// Where filters rows before grouping. Nil when no filter was given.
+	Where Sqlizer
+	// Having filters groups. Nil when no having expression was given.
+	Having Sqlizer
+	// GroupBy lists the SQL columns to group by.
+	GroupBy []string
+	// Warnings lists the non-fatal issues found in either expression.
+	Warnings []Diagnostic
+	// Joins lists the joins required by either expression.
+	Joins []JoinSpec
+}
+
+// NewGroupedConverter creates a converter for filter/having expression pairs.
+func NewGroupedConverter(config GroupedConfig) (*GroupedConverter, error) {
+	where, err := NewConverter(config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	havingConfig := config.Config
+	// Aggregates are SQL expressions: the group by fields carry their table
+	havingConfig.TableAlias = ""
+	havingConfig.AggregateFields = nil
+	havingConfig.KeyFields = nil
+	havingConfig.Quota = nil
+	havingConfig.Corpus = nil
+	havingConfig.SoftDeleteField = ""
+	havingConfig.MandatoryConditions = nil
+	havingConfig.MandatoryConditionsFunc = nil
+	havingConfig.FieldDeclarations = make(map[string]ColumnMapping, len(config.Aggregates)+len(config.GroupBy))
+	maps.Copy(havingConfig.FieldDeclarations, config.Aggregates)
+	if where.having != nil {
+		// Declared aggregate pseudo-fields
+		for name, mapping := range where.having.fieldDeclarations {
+			if _, clash := config.Aggregates[name]; clash {
+				return nil, fmt.Errorf("aggregate field %s conflicts with an aggregate of the same name", name)
+			}
+			havingConfig.FieldDeclarations[name] = mapping
+		}
+	}
+
+	groupBy := make([]string, 0, len(config.GroupBy))
+	for _, field := range config.GroupBy {
+		mapping, ok := where.Field(field)
+		if !ok {
+			return nil, fmt.Errorf("group by field %s is not declared", field)
+		}
+		if _, clash := havingConfig.FieldDeclarations[field]; clash {
+			return nil, fmt.Errorf("group by field %s conflicts with an aggregate of the same name", field)
+		}
+		// Partial index conditions refer to row-level fields
+		mapping.PartialIndex = nil
+		havingConfig.FieldDeclarations[field] = mapping
+		groupBy = append(groupBy, where.mapFieldName(field))
+	}
+
+	having, err := NewConverter(havingConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aggregate declarations: %w", err)
+	}
+
+	return &GroupedConverter{
+		where:   where,
+		having:  having,
+		groupBy: groupBy,
+	}, nil
+}
+
+// Convert converts a row filter and a having expression. Either expression
+// may be empty, in which case the matching clause is omitted.
+func (g *GroupedConverter) Convert(filter, having string) (*GroupedResult, error) {
+	result := &GroupedResult{
+		GroupBy: append([]string(nil), g.groupBy...),
+	}
+
+	if filter != "" {
+		converted, err := g.where.Convert(filter)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		}
+		result.Where = converted.Where
+		result.Warnings = append(result.Warnings, converted.Warnings...)
+		result.addJoins(converted.Joins)
+	}
+
+	if having != "" {
+		converted, err := g.having.Convert(having)
+		if err != nil {
+			return nil, fmt.Errorf("having: %w", err)
+		}
+		result.Having = converted.Where
+		result.Warnings = append(result.Warnings, converted.Warnings...)
+		result.addJoins(converted.Joins)
+	}
+
+	return result, nil
+}
+
+// addJoins adds the joins required by one of the expressions.
+func (r *GroupedResult) addJoins(joins []JoinSpec) {
+	for _, join := range joins {
+		if !slices.Contains(r.Joins, join) {
+			r.Joins = append(r.Joins, join)
+		}
+	}
+}
+
+// ApplyTo adds the joins, WHERE, GROUP BY and HAVING clauses to a select
+// builder, skipping those that are empty. See ApplyGrouped for other
+// builders.
+func (r *GroupedResult) ApplyTo(builder squirrel.SelectBuilder) squirrel.SelectBuilder {
+	return ApplyGrouped(SquirrelBuilder{}, builder, r)
+}
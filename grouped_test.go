@@ -0,0 +1,139 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func newTestGroupedConverter(t *testing.T) *GroupedConverter {
+	t.Helper()
+
+	converter, err := NewGroupedConverter(GroupedConfig{
+		Config: Config{
+			FieldDeclarations: map[string]ColumnMapping{
+				"status":     {Type: cel.StringType, Column: "status"},
+				"customerId": {Type: cel.StringType, Column: "customer_id"},
+			},
+		},
+		Aggregates: map[string]ColumnMapping{
+			"orderCount": {Type: cel.IntType, Column: "COUNT(*)"},
+			"total":      {Type: cel.DoubleType, Column: "SUM(amount)"},
+		},
+		GroupBy: []string{"customerId"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create grouped converter: %v", err)
+	}
+	return converter
+}
+
+func TestGroupedConverter_Convert(t *testing.T) {
+	converter := newTestGroupedConverter(t)
+
+	result, err := converter.Convert(`status == "paid"`, `orderCount > 3 && total >= 100.0`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	sql, args, err := result.ApplyTo(squirrel.Select("customer_id", "COUNT(*)").From("orders")).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+
+	wantSQL := "SELECT customer_id, COUNT(*) FROM orders WHERE status = ? GROUP BY customer_id HAVING (COUNT(*) > ? AND SUM(amount) >= ?)"
+	if sql != wantSQL {
+		t.Errorf("ToSql() = %v, want %v", sql, wantSQL)
+	}
+
+	wantArgs := []any{"paid", int64(3), 100.0}
+	if len(args) != len(wantArgs) {
+		t.Fatalf("args = %v, want %v", args, wantArgs)
+	}
+	for i, arg := range args {
+		if arg != wantArgs[i] {
+			t.Errorf("arg %d = %v, want %v", i, arg, wantArgs[i])
+		}
+	}
+}
+
+func TestGroupedConverter_TableAlias(t *testing.T) {
+	converter, err := NewGroupedConverter(GroupedConfig{
+		Config: Config{
+			TableAlias: "o",
+			FieldDeclarations: map[string]ColumnMapping{
+				"customerId": {Type: cel.StringType, Column: "customer_id"},
+			},
+		},
+		Aggregates: map[string]ColumnMapping{
+			"orderCount": {Type: cel.IntType, Column: "COUNT(*)"},
+		},
+		GroupBy: []string{"customerId"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create grouped converter: %v", err)
+	}
+
+	result, err := converter.Convert(`customerId != ""`, `customerId != "c1" && orderCount > 1`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	sql, _, err := result.ApplyTo(squirrel.Select("*").From("orders o")).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	want := "SELECT * FROM orders o WHERE o.customer_id <> ? GROUP BY o.customer_id HAVING (o.customer_id <> ? AND COUNT(*) > ?)"
+	if sql != want {
+		t.Errorf("ToSql() = %v, want %v", sql, want)
+	}
+}
+
+func TestGroupedConverter_PartialInput(t *testing.T) {
+	converter := newTestGroupedConverter(t)
+
+	t.Run("having only with group by field", func(t *testing.T) {
+		result, err := converter.Convert("", `customerId == "c1" && orderCount > 1`)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if result.Where != nil {
+			t.Error("Where should be nil without a filter")
+		}
+
+		sql, _, err := result.ApplyTo(squirrel.Select("*").From("orders")).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+		if want := "SELECT * FROM orders GROUP BY customer_id HAVING (customer_id = ? AND COUNT(*) > ?)"; sql != want {
+			t.Errorf("ToSql() = %v, want %v", sql, want)
+		}
+	})
+
+	t.Run("filter only", func(t *testing.T) {
+		result, err := converter.Convert(`status == "paid"`, "")
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if result.Having != nil {
+			t.Error("Having should be nil without a having expression")
+		}
+	})
+}
+
+func TestGroupedConverter_Errors(t *testing.T) {
+	converter := newTestGroupedConverter(t)
+
+	if _, err := converter.Convert(`orderCount > 3`, ""); err == nil {
+		t.Error("aggregates must not be usable in the row filter")
+	}
+	if _, err := converter.Convert("", `status == "paid"`); err == nil {
+		t.Error("non-grouped row fields must not be usable in the having expression")
+	}
+
+	_, err := NewGroupedConverter(GroupedConfig{GroupBy: []string{"unknown"}})
+	if err == nil {
+		t.Error("NewGroupedConverter() should reject undeclared group by fields")
+	}
+}
@@ -0,0 +1,73 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// filterField is the request field reported in the BadRequest details.
+const filterField = "filter"
+
+// GRPCStatus converts a conversion error to a gRPC status, for services to
+// return consistent RPC errors. Filter errors map to InvalidArgument,
+// unauthorized fields to PermissionDenied and exceeded limits or quotas to
+// ResourceExhausted, with a BadRequest field violation carrying the error
+// code as reason. Canceled conversions map to Canceled or DeadlineExceeded
+// and other errors to Internal with a generic message. It returns nil for a
+// nil error.
+func GRPCStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		return status.New(codes.Internal, "internal error")
+	}
+
+	code := grpcCode(convErr.ErrorCode)
+	st := status.New(code, convErr.Error())
+	if code == codes.Internal {
+		return st
+	}
+
+	violation := &errdetails.BadRequest_FieldViolation{
+		Field:       filterField,
+		Description: convErr.PublicMessage,
+		Reason:      string(convErr.ErrorCode),
+	}
+	if loc := convErr.Location; loc != nil {
+		violation.Description = fmt.Sprintf("%s at line %d, column %d", convErr.PublicMessage, loc.Line, loc.Column)
+	}
+
+	detailed, detailErr := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{violation},
+	})
+	if detailErr != nil {
+		return st
+	}
+	return detailed
+}
+
+// grpcCode maps an error code to its gRPC code.
+func grpcCode(code ErrorCode) codes.Code {
+	switch code {
+	case CodeUnauthorizedField:
+		return codes.PermissionDenied
+	case CodeLimitLength, CodeLimitDepth, CodeLimitInSize, CodeLimitMemory,
+		CodeLimitLikePattern, CodeLimitPredicates, CodeLimitCost, CodeQuotaExceeded:
+		return codes.ResourceExhausted
+	case CodeScopeUnavailable:
+		return codes.Internal
+	default:
+		return codes.InvalidArgument
+	}
+}
@@ -0,0 +1,140 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGRPCStatus(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"secret": {Type: cel.StringType},
+		},
+		FieldACL:           map[string][]string{"secret": {"admin"}},
+		PublicFields:       []string{"status"},
+		MaxExpressionDepth: 3,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	convert := func(celExpr string) error {
+		_, err := converter.ConvertWithAuth(celExpr, []string{"user"})
+		return err
+	}
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+		wantReason  string
+		wantDesc    string
+	}{
+		{
+			name:        "syntax error",
+			err:         convert(`statu == "open"`),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "invalid filter expression syntax",
+			wantReason:  "INVALID_SYNTAX",
+			wantDesc:    "invalid filter expression syntax at line 1, column 1",
+		},
+		{
+			name:        "unauthorized field",
+			err:         convert(`secret == "x"`),
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "access denied: insufficient permissions for requested filter",
+			wantReason:  "UNAUTHORIZED_FIELD",
+			wantDesc:    "access denied: insufficient permissions for requested filter",
+		},
+		{
+			name:        "limit exceeded",
+			err:         convert(`status == "a" || (status == "b" && (status == "c" || status == "d"))`),
+			wantCode:    codes.ResourceExhausted,
+			wantMessage: "filter expression exceeds maximum depth of 3",
+			wantReason:  "LIMIT_DEPTH",
+			wantDesc:    "filter expression exceeds maximum depth of 3",
+		},
+		{
+			name:        "wrapped conversion error",
+			err:         fmt.Errorf("list orders: %w", ErrQuotaExceeded),
+			wantCode:    codes.ResourceExhausted,
+			wantMessage: "filter quota exceeded",
+			wantReason:  "QUOTA_EXCEEDED",
+			wantDesc:    "filter quota exceeded",
+		},
+		{
+			name:        "scope unavailable",
+			err:         ErrScopeUnavailable,
+			wantCode:    codes.Internal,
+			wantMessage: "filter scope unavailable",
+		},
+		{
+			name:        "context canceled",
+			err:         context.Canceled,
+			wantCode:    codes.Canceled,
+			wantMessage: "context canceled",
+		},
+		{
+			name:        "canceled conversion",
+			err:         newConversionError("filter conversion canceled", CodeCanceled, context.DeadlineExceeded),
+			wantCode:    codes.DeadlineExceeded,
+			wantMessage: "filter conversion canceled",
+		},
+		{
+			name:        "other error",
+			err:         errors.New("connection refused"),
+			wantCode:    codes.Internal,
+			wantMessage: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := GRPCStatus(tt.err)
+			if st.Code() != tt.wantCode {
+				t.Errorf("Code() = %v, want %v", st.Code(), tt.wantCode)
+			}
+			if st.Message() != tt.wantMessage {
+				t.Errorf("Message() = %q, want %q", st.Message(), tt.wantMessage)
+			}
+
+			var violations []*errdetails.BadRequest_FieldViolation
+			for _, detail := range st.Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					violations = append(violations, badRequest.GetFieldViolations()...)
+				}
+			}
+			if tt.wantReason == "" {
+				if len(violations) != 0 {
+					t.Errorf("field violations = %v, want none", violations)
+				}
+				return
+			}
+			if len(violations) != 1 {
+				t.Fatalf("field violations = %v, want 1", violations)
+			}
+			violation := violations[0]
+			if violation.GetField() != "filter" {
+				t.Errorf("Field = %q, want %q", violation.GetField(), "filter")
+			}
+			if violation.GetReason() != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", violation.GetReason(), tt.wantReason)
+			}
+			if violation.GetDescription() != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", violation.GetDescription(), tt.wantDesc)
+			}
+		})
+	}
+
+	if st := GRPCStatus(nil); st != nil {
+		t.Errorf("GRPCStatus(nil) = %v, want nil", st)
+	}
+}
@@ -0,0 +1,251 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// HybridResult contains the result of a partial conversion: the portion of
+// the filter that could be translated to SQL, plus a residual CEL filter that
+// must be applied in memory to the fetched rows.
+type HybridResult struct {
+	ConvertResult
+
+	// Residual is the untranslatable remainder of the filter. It is nil when
+	// the whole expression was converted to SQL.
+	Residual *ResidualFilter
+}
+
+// ResidualFilter is a compiled CEL filter evaluated in memory against rows
+// already narrowed down by the SQL portion of a hybrid conversion.
+type ResidualFilter struct {
+	programs    []cel.Program
+	expressions []string
+}
+
+// Eval evaluates the residual filter against a row. vars maps CEL field names
+// to their values, or is a cel-go interpreter.Activation.
+func (r *ResidualFilter) Eval(vars any) (bool, error) {
+	for i, prg := range r.programs {
+		out, _, err := prg.Eval(vars)
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate residual filter %q: %w", r.expressions[i], err)
+		}
+		matched, ok := out.Value().(bool)
+		if !ok {
+			return false, fmt.Errorf("residual filter %q returned %T, expected bool", r.expressions[i], out.Value())
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// String returns the CEL source of the residual filter.
+func (r *ResidualFilter) String() string {
+	return strings.Join(r.expressions, " && ")
+}
+
+// ConvertHybrid converts the translatable conjuncts of a CEL expression to SQL
+// and compiles the remaining ones into a ResidualFilter instead of failing the
+// whole request. Only top-level && operands are split: a disjunction with an
+// untranslatable branch is evaluated entirely in memory. Errors other than
+// unsupported operations (e.g. type mismatches) still fail the conversion.
+// Conjuncts widened by Config.Fallbacks are converted and re-checked by the
+// residual filter.
+func (c *Converter) ConvertHybrid(celExpr string) (*HybridResult, error) {
+	return c.ConvertHybridContext(context.Background(), celExpr)
+}
+
+// ConvertHybridContext is like ConvertHybrid, passing ctx to the
+// SecurityLogger and MandatoryConditionsFunc. The conversion fails with
+// CANCELED once ctx is canceled or past its deadline.
+func (c *Converter) ConvertHybridContext(ctx context.Context, celExpr string) (*HybridResult, error) {
+	c = c.current()
+	return c.convertHybridLogged(ctx, celExpr, nil)
+}
+
+// ConvertHybridWithAuth is like ConvertHybrid, authorizing the fields
+// referenced as ConvertWithAuth does, including those of the residual filter.
+func (c *Converter) ConvertHybridWithAuth(celExpr string, userRoles []string) (*HybridResult, error) {
+	return c.ConvertHybridWithAuthContext(context.Background(), celExpr, userRoles)
+}
+
+// ConvertHybridWithAuthContext is like ConvertHybridWithAuth, passing ctx to
+// the SecurityLogger and MandatoryConditionsFunc.
+func (c *Converter) ConvertHybridWithAuthContext(ctx context.Context, celExpr string, userRoles []string) (*HybridResult, error) {
+	c = c.current()
+	if !c.authorization() {
+		return c.ConvertHybridContext(ctx, celExpr)
+	}
+	if userRoles == nil {
+		userRoles = []string{}
+	}
+	return c.convertHybridLogged(ctx, celExpr, userRoles)
+}
+
+// convertHybridLogged converts a CEL expression with convertHybrid, then
+// applies the mandatory conditions and logs the attempt.
+func (c *Converter) convertHybridLogged(ctx context.Context, celExpr string, userRoles []string) (_ *HybridResult, err error) {
+	defer c.logAttempt(ctx, celExpr, time.Now(), &err)
+
+	result, err := c.convertHybrid(ctx, celExpr, userRoles)
+	if result != nil {
+		_, err = c.finalize(ctx, celExpr, &result.ConvertResult, err)
+	}
+	var converted *ConvertResult
+	if result != nil {
+		converted = &result.ConvertResult
+	}
+	if _, err = c.maskOutput(celExpr, converted, err); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// convertHybrid splits a CEL expression into its SQL and residual parts,
+// authorizing its fields unless userRoles is nil.
+func (c *Converter) convertHybrid(ctx context.Context, celExpr string, userRoles []string) (*HybridResult, error) {
+	compiled, checkedExpr, err := c.compile(ctx, celExpr)
+	if err == nil && userRoles != nil {
+		err = c.authorize(ctx, celExpr, checkedExpr.Expr(), userRoles, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkAllowedOps(checkedExpr.Expr()); err != nil {
+		return nil, err
+	}
+
+	scoped := c.scoped(ctx)
+	var (
+		where    squirrel.And
+		residual []celast.Expr
+	)
+	expr := checkedExpr.Expr()
+	folded := foldConstants(expr)
+	if c.foldConstants {
+		expr = folded
+	}
+	if c.canonicalize {
+		expr = canonicalize(expr)
+	}
+	for _, conjunct := range splitConjuncts(expr) {
+		scoped.conv.approximated = false
+		joins := len(scoped.conv.joins)
+		sqlizer, err := scoped.convertExpr(conjunct)
+		if err == nil {
+			where = append(where, sqlizer)
+			// Widened by a fallback: re-check the exact predicate in memory
+			if scoped.conv.approximated {
+				residual = append(residual, conjunct)
+			}
+			continue
+		}
+		if !isUntranslatable(err) {
+			return nil, asConversionError(fmt.Errorf("failed to convert CEL to SQL: %w", err))
+		}
+		// Fields of residual conjuncts are read in memory, not joined
+		scoped.conv.joins = scoped.conv.joins[:joins]
+		residual = append(residual, conjunct)
+	}
+
+	result := &HybridResult{
+		ConvertResult: ConvertResult{
+			Args:        []interface{}{},
+			Warnings:    scoped.conv.warnings,
+			Complexity:  scoped.conv.complexity,
+			expr:        expr,
+			schema:      c.schema,
+			placeholder: c.placeholderFormat,
+		},
+	}
+	result.Complexity.Depth = c.calculateExpressionDepth(expr)
+	c.describe(expr, &result.ConvertResult)
+	if value, ok := boolConstant(folded); ok {
+		result.AlwaysTrue = value
+		result.AlwaysFalse = !value
+	}
+	result.Class = c.classify(expr, result.AlwaysFalse)
+
+	switch len(where) {
+	case 0:
+		result.Where = squirrel.Expr("TRUE")
+	case 1:
+		result.Where = where[0]
+	default:
+		result.Where = where
+	}
+
+	if c.auditor != nil {
+		if err := c.auditor.audit(result.Where, scoped.conv.trusted...); err != nil {
+			return nil, asConversionError(err)
+		}
+	}
+
+	if len(residual) > 0 {
+		result.Residual, err = c.newResidualFilter(compiled, residual)
+		if err != nil {
+			return nil, asConversionError(err)
+		}
+	}
+
+	return result, nil
+}
+
+// newResidualFilter compiles each residual sub-expression into a CEL program,
+// reusing the type and reference information of the original checked AST.
+func (c *Converter) newResidualFilter(compiled *cel.Ast, exprs []celast.Expr) (*ResidualFilter, error) {
+	checked := compiled.NativeRep()
+	filter := &ResidualFilter{}
+	for _, expr := range exprs {
+		ast := celast.NewCheckedAST(celast.NewAST(expr, checked.SourceInfo()), checked.TypeMap(), checked.ReferenceMap())
+		prg, err := c.env.PlanProgram(ast)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile residual filter: %w", err)
+		}
+
+		// SECURITY: Never echo the values of masked fields
+		var secrets []string
+		source, err := c.maskedSource(expr, checked.SourceInfo(), &secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render residual filter: %w", err)
+		}
+
+		filter.programs = append(filter.programs, prg)
+		filter.expressions = append(filter.expressions, source)
+	}
+	return filter, nil
+}
+
+// splitConjuncts flattens a chain of && operators into its operands.
+func splitConjuncts(expr celast.Expr) []celast.Expr {
+	return appendConjuncts(nil, expr)
+}
+
+// appendConjuncts appends the operands of a chain of && operators to dst.
+func appendConjuncts(dst []celast.Expr, expr celast.Expr) []celast.Expr {
+	call := expr.AsCall()
+	if call.FunctionName() != "_&&_" || len(call.Args()) != 2 {
+		return append(dst, expr)
+	}
+	return appendConjuncts(appendConjuncts(dst, call.Args()[0]), call.Args()[1])
+}
+
+// isUntranslatable reports whether a conversion error stems from a construct
+// without SQL translation, as opposed to an invalid or forbidden filter.
+func isUntranslatable(err error) bool {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return convErr.ErrorCode == CodeUnsupportedOperation
+	}
+	return true
+}
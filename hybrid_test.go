@@ -0,0 +1,216 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ConvertHybrid(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "status"},
+			"name":   {Type: cel.StringType, Column: "user_name"},
+			"age":    {Type: cel.IntType, Column: "age"},
+			"tags":   {Type: cel.ListType(cel.StringType), Column: "tags"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	t.Run("fully convertible", func(t *testing.T) {
+		result, err := converter.ConvertHybrid(`status == "active" && age > 18`)
+		if err != nil {
+			t.Fatalf("ConvertHybrid() error = %v", err)
+		}
+		if result.Residual != nil {
+			t.Errorf("expected no residual, got %q", result.Residual)
+		}
+
+		sql, _, err := result.Where.ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+		if sql != "(status = ? AND age > ?)" {
+			t.Errorf("ToSql() = %v", sql)
+		}
+	})
+
+	t.Run("partially convertible", func(t *testing.T) {
+		result, err := converter.ConvertHybrid(`status == "active" && name.matches("^a.*") && size(tags) > 1`)
+		if err != nil {
+			t.Fatalf("ConvertHybrid() error = %v", err)
+		}
+
+		sql, args, err := result.Where.ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+		if sql != "status = ?" || len(args) != 1 || args[0] != "active" {
+			t.Errorf("ToSql() = %v %v", sql, args)
+		}
+
+		if result.Residual == nil {
+			t.Fatal("expected a residual filter")
+		}
+		if got := result.Residual.String(); got != `name.matches("^a.*") && size(tags) > 1` {
+			t.Errorf("Residual.String() = %q", got)
+		}
+
+		rows := []struct {
+			vars map[string]any
+			want bool
+		}{
+			{vars: map[string]any{"name": "alice", "tags": []string{"a", "b"}}, want: true},
+			{vars: map[string]any{"name": "bob", "tags": []string{"a", "b"}}, want: false},
+			{vars: map[string]any{"name": "alice", "tags": []string{"a"}}, want: false},
+		}
+		for _, row := range rows {
+			got, err := result.Residual.Eval(row.vars)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if got != row.want {
+				t.Errorf("Eval(%v) = %v, want %v", row.vars, got, row.want)
+			}
+		}
+	})
+
+	t.Run("nothing convertible", func(t *testing.T) {
+		result, err := converter.ConvertHybrid(`name.matches("^a") || age > 3`)
+		if err != nil {
+			t.Fatalf("ConvertHybrid() error = %v", err)
+		}
+
+		sql, _, err := result.Where.ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+		if sql != "TRUE" {
+			t.Errorf("ToSql() = %v, want TRUE", sql)
+		}
+		if result.Residual == nil {
+			t.Fatal("expected a residual filter")
+		}
+	})
+
+	t.Run("invalid expression still fails", func(t *testing.T) {
+		if _, err := converter.ConvertHybrid(`status ==`); err == nil {
+			t.Error("ConvertHybrid() should reject invalid syntax")
+		}
+	})
+
+	t.Run("missing variable fails evaluation", func(t *testing.T) {
+		result, err := converter.ConvertHybrid(`name.matches("^a")`)
+		if err != nil {
+			t.Fatalf("ConvertHybrid() error = %v", err)
+		}
+		if _, err := result.Residual.Eval(map[string]any{}); err == nil {
+			t.Error("Eval() should fail when a referenced field is missing")
+		}
+	})
+}
+
+func TestConverter_ConvertHybridContext_MandatoryConditions(t *testing.T) {
+	converter := newTenantConverter(t, Config{})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+
+	result, err := converter.ConvertHybridContext(ctx, `status == "active" && status.matches("^a")`)
+	if err != nil {
+		t.Fatalf("ConvertHybridContext() error = %v", err)
+	}
+	sql, args, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "(status = ? AND tenant_id = ?)"; sql != want {
+		t.Errorf("ToSql() = %v, want %v", sql, want)
+	}
+	if want := []interface{}{"active", "t1"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("ToSql() args = %v, want %v", args, want)
+	}
+	if result.Residual == nil {
+		t.Error("Residual = nil, want matches()")
+	}
+
+	// Without the request's context, the tenant is unknown
+	if _, err := converter.ConvertHybrid(`status == "active"`); !errors.Is(err, ErrScopeUnavailable) {
+		t.Errorf("ConvertHybrid() error = %v, want %v", err, ErrScopeUnavailable)
+	}
+}
+
+func TestConverter_ConvertHybridWithAuth(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"name":   {Type: cel.StringType},
+			"salary": {Type: cel.IntType},
+		},
+		PublicFields: []string{"status", "name"},
+		FieldACL:     map[string][]string{"salary": {"hr"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		expr         string
+		roles        []string
+		wantSQL      string
+		wantResidual string
+		wantCode     string
+	}{
+		{
+			name:         "public fields",
+			expr:         `status == "active" && name.matches("^a")`,
+			wantSQL:      "status = ?",
+			wantResidual: `name.matches("^a")`,
+		},
+		{
+			name:     "restricted field in SQL part",
+			expr:     `salary > 1000 && name.matches("^a")`,
+			roles:    []string{"user"},
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:     "restricted field in residual part",
+			expr:     `status == "active" && string(salary).matches("^1")`,
+			roles:    []string{"user"},
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:         "authorized role",
+			expr:         `salary > 1000 && name.matches("^a")`,
+			roles:        []string{"hr"},
+			wantSQL:      "salary > ?",
+			wantResidual: `name.matches("^a")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertHybridWithAuth(tt.expr, tt.roles)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ConvertHybridWithAuth() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ConvertHybridWithAuth() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if result.Residual == nil || result.Residual.String() != tt.wantResidual {
+				t.Errorf("ConvertHybridWithAuth() residual = %v, want %q", result.Residual, tt.wantResidual)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// JoinSpec describes the table a relation field lives on and how it is
+// joined to the filtered table, e.g. the users table of an author.name
+// field, joined on prompts.author_id = users.id. Relations must be
+// one-to-one, or many-to-one, so that joining never duplicates rows.
+type JoinSpec struct {
+	// Table is the joined table, optionally schema-qualified.
+	Table string
+	// Alias is the name the table is referred to by. Default: the name of
+	// Table.
+	Alias string
+	// On is the join condition, trusted configuration subject to the same
+	// restrictions as ColumnMapping.Expr.
+	On string
+}
+
+// name returns the name qualifying the columns of the joined table: its
+// alias, or its name without schema.
+func (j JoinSpec) name() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+	return j.Table[strings.LastIndex(j.Table, ".")+1:]
+}
+
+// Clause returns the join clause without the JOIN keyword, e.g.
+// "users u ON p.author_id = u.id", as expected by the join methods of
+// squirrel.SelectBuilder.
+func (j JoinSpec) Clause() string {
+	return j.from() + " ON " + j.On
+}
+
+// from returns the joined table with its alias.
+func (j JoinSpec) from() string {
+	if j.Alias != "" {
+		return j.Table + " " + j.Alias
+	}
+	return j.Table
+}
+
+// validateJoin checks the join of a relation field.
+func validateJoin(name string, join JoinSpec) error {
+	for _, part := range strings.Split(join.Table, ".") {
+		if !isSQLIdentifier(part) {
+			return fmt.Errorf("field %s: invalid join table %q", name, join.Table)
+		}
+	}
+	if join.Alias != "" && !isSQLIdentifier(join.Alias) {
+		return fmt.Errorf("field %s: invalid join alias %q", name, join.Alias)
+	}
+	if _, err := sqlFragmentTokens(join.On); err != nil {
+		return fmt.Errorf("field %s: invalid join condition: %w", name, err)
+	}
+	return nil
+}
+
+// newFieldJoins returns the joins of the relation fields, checking that
+// fields sharing a table name agree on how it is joined.
+func newFieldJoins(fields map[string]ColumnMapping) (map[string]JoinSpec, error) {
+	joins := make(map[string]JoinSpec)
+	byName := make(map[string]JoinSpec)
+	for name, mapping := range fields {
+		if mapping.Join == nil {
+			continue
+		}
+		join := *mapping.Join
+		join.On = strings.TrimSpace(join.On)
+		if err := validateJoin(name, join); err != nil {
+			return nil, err
+		}
+		if other, ok := byName[join.name()]; ok && other != join {
+			return nil, fmt.Errorf("field %s: conflicting joins for table %s", name, join.name())
+		}
+		byName[join.name()] = join
+		joins[name] = join
+	}
+	return joins, nil
+}
+
+// requireJoin records that the converted filter reads a field of a joined
+// table.
+func (c *Converter) requireJoin(field string) {
+	join, ok := c.fieldJoins[field]
+	if !ok || c.conv == nil {
+		return
+	}
+	if slices.Contains(c.conv.joins, join) {
+		return
+	}
+	c.conv.joins = append(c.conv.joins, join)
+}
+
+// ApplyJoins adds the joins required by the filter to a select builder, as
+// LEFT JOINs so that a missing related row does not hide the filtered one
+// from predicates that do not need it. See Apply for other builders.
+func (r *ConvertResult) ApplyJoins(builder squirrel.SelectBuilder) squirrel.SelectBuilder {
+	for _, join := range r.Joins {
+		builder = builder.LeftJoin(join.Clause())
+	}
+	return builder
+}
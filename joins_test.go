@@ -0,0 +1,156 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func newTestJoinConverter(t *testing.T) *Converter {
+	t.Helper()
+
+	users := &JoinSpec{Table: "users", Alias: "u", On: "p.author_id = u.id"}
+	converter, err := NewConverter(Config{
+		TableAlias: "p",
+		FieldDeclarations: map[string]ColumnMapping{
+			"title":        {Type: cel.StringType},
+			"author.name":  {Type: cel.StringType, Column: "name", Join: users},
+			"author.email": {Type: cel.StringType, Expr: "LOWER(u.email)", Join: users},
+			"team.name": {Type: cel.StringType, Column: "name", Join: &JoinSpec{
+				Table: "public.teams", On: "p.team_id = teams.id",
+			}},
+		},
+		AuditSQL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_Joins(t *testing.T) {
+	converter := newTestJoinConverter(t)
+	users := JoinSpec{Table: "users", Alias: "u", On: "p.author_id = u.id"}
+	teams := JoinSpec{Table: "public.teams", On: "p.team_id = teams.id"}
+
+	tests := []struct {
+		name      string
+		celExpr   string
+		wantSQL   string
+		wantJoins []JoinSpec
+	}{
+		{
+			name:    "no relation",
+			celExpr: `title == "a"`,
+			wantSQL: "p.title = ?",
+		},
+		{
+			name:      "relation",
+			celExpr:   `author.name == "ada"`,
+			wantSQL:   "u.name = ?",
+			wantJoins: []JoinSpec{users},
+		},
+		{
+			name:      "shared join",
+			celExpr:   `team.name == "core" || author.name == "ada" && author.email.endsWith("@example.com")`,
+			wantSQL:   "(teams.name = ? OR (u.name = ? AND LOWER(u.email) LIKE ?))",
+			wantJoins: []JoinSpec{teams, users},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(result.Joins, tt.wantJoins) {
+				t.Errorf("Joins = %v, want %v", result.Joins, tt.wantJoins)
+			}
+		})
+	}
+}
+
+func TestConvertResult_ApplyJoins(t *testing.T) {
+	converter := newTestJoinConverter(t)
+
+	result, err := converter.Convert(`author.name == "ada" && title != ""`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	query := result.ApplyJoins(squirrel.Select("p.*").From("prompts p")).Where(result.Where)
+	sql, args, err := query.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "SELECT p.* FROM prompts p LEFT JOIN users u ON p.author_id = u.id WHERE (u.name = ? AND p.title <> ?)"; sql != want {
+		t.Errorf("SQL = %q, want %q", sql, want)
+	}
+	if want := []any{"ada", ""}; !reflect.DeepEqual(args, want) {
+		t.Errorf("Args = %v, want %v", args, want)
+	}
+}
+
+func TestConverter_ConvertHybrid_Joins(t *testing.T) {
+	converter := newTestJoinConverter(t)
+
+	result, err := converter.ConvertHybrid(`title == "a" && author.name.matches("^A")`)
+	if err != nil {
+		t.Fatalf("ConvertHybrid() error = %v", err)
+	}
+	if len(result.Joins) != 0 {
+		t.Errorf("Joins = %v, want none for fields only read in memory", result.Joins)
+	}
+}
+
+func TestNewConverter_InvalidJoin(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]ColumnMapping
+	}{
+		{
+			name: "table",
+			fields: map[string]ColumnMapping{
+				"author.name": {Type: cel.StringType, Join: &JoinSpec{Table: "users u", On: "author_id = u.id"}},
+			},
+		},
+		{
+			name: "condition",
+			fields: map[string]ColumnMapping{
+				"author.name": {Type: cel.StringType, Join: &JoinSpec{Table: "users", On: "author_id = users.id; DROP TABLE users"}},
+			},
+		},
+		{
+			name: "table mismatch",
+			fields: map[string]ColumnMapping{
+				"author.name": {Type: cel.StringType, Table: "p", Join: &JoinSpec{Table: "users", On: "p.author_id = users.id"}},
+			},
+		},
+		{
+			name: "conflicting joins",
+			fields: map[string]ColumnMapping{
+				"author.name": {Type: cel.StringType, Join: &JoinSpec{Table: "users", On: "p.author_id = users.id"}},
+				"editor.name": {Type: cel.StringType, Join: &JoinSpec{Table: "users", On: "p.editor_id = users.id"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConverter(Config{FieldDeclarations: tt.fields}); err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// jsonPathColumn returns the SQL expression reading a field stored at
+// mapping.JSONPath inside the JSON column. Numeric fields are cast to the
+// dialect's numeric type so that comparisons are not lexicographic.
+func jsonPathColumn(name string, mapping ColumnMapping, dialect Dialect) (string, error) {
+	column := mapping.Column
+	if column == "" {
+		column = name
+	}
+
+	path := strings.Split(mapping.JSONPath, ".")
+	for _, segment := range path {
+		if !isJSONKey(segment) {
+			return "", fmt.Errorf("field %s: JSON path segment %q must be identifier-like", name, segment)
+		}
+	}
+
+	extract, ok := dialect.jsonPathText(column, path)
+	if !ok {
+		return "", fmt.Errorf("field %s: dialect %q does not support JSON paths", name, dialect)
+	}
+
+	if mapping.Type == nil {
+		return "", fmt.Errorf("field %s: JSON path fields require a type", name)
+	}
+	var function string
+	switch mapping.Type.String() {
+	case "string":
+		return extract, nil
+	case "int", "uint":
+		function = "int"
+	case "double":
+		function = "double"
+	default:
+		return "", fmt.Errorf("field %s: JSON path fields must be string, int, uint or double, got %s", name, mapping.Type)
+	}
+
+	sqlType, _ := dialect.castType(function)
+	return fmt.Sprintf("CAST(%s AS %s)", extract, sqlType), nil
+}
@@ -0,0 +1,146 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_JSONPath(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"city":  {Type: cel.StringType, Column: "profile", JSONPath: "address.city"},
+		"score": {Type: cel.IntType, Column: "profile", JSONPath: "stats.score"},
+		"ratio": {Type: cel.DoubleType, Column: "profile", JSONPath: "stats.ratio"},
+		"tier":  {Type: cel.StringType, Column: "profile", JSONPath: "tier"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "postgres string",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `city == "Paris"`,
+			wantSQL:  "profile #>> '{address,city}' = ?",
+			wantArgs: []any{"Paris"},
+		},
+		{
+			name:     "postgres numeric",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `score >= 10 && ratio < 0.5`,
+			wantSQL:  "(CAST(profile #>> '{stats,score}' AS BIGINT) >= ? AND CAST(profile #>> '{stats,ratio}' AS DOUBLE PRECISION) < ?)",
+			wantArgs: []any{int64(10), 0.5},
+		},
+		{
+			name:     "mysql string",
+			dialect:  DialectMySQL,
+			celExpr:  `city in ["Paris", "Lyon"]`,
+			wantSQL:  "JSON_UNQUOTE(JSON_EXTRACT(profile, '$.address.city')) IN (?,?)",
+			wantArgs: []any{"Paris", "Lyon"},
+		},
+		{
+			name:     "mysql numeric",
+			dialect:  DialectMySQL,
+			celExpr:  `score > 3`,
+			wantSQL:  "CAST(JSON_UNQUOTE(JSON_EXTRACT(profile, '$.stats.score')) AS SIGNED) > ?",
+			wantArgs: []any{int64(3)},
+		},
+		{
+			name:     "sqlite top-level key",
+			dialect:  DialectSQLite,
+			celExpr:  `tier.startsWith("pro")`,
+			wantSQL:  `json_extract(profile, '$.tier') LIKE ? ESCAPE '\'`,
+			wantArgs: []any{"pro%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect, AuditSQL: true})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Field_JSONPath(t *testing.T) {
+	converter, err := NewConverter(Config{
+		Dialect: DialectPostgreSQL,
+		FieldDeclarations: map[string]ColumnMapping{
+			"city": {Type: cel.StringType, Column: "profile", JSONPath: "address.city"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	mapping, ok := converter.Field("city")
+	if !ok {
+		t.Fatal("Field() did not find city")
+	}
+	if mapping.Column != "profile" || mapping.JSONPath != "address.city" {
+		t.Errorf("Field() = %+v, want the profile column and its path", mapping)
+	}
+}
+
+func TestNewConverter_InvalidJSONPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		mapping ColumnMapping
+	}{
+		{
+			name:    "default dialect",
+			mapping: ColumnMapping{Type: cel.StringType, Column: "profile", JSONPath: "city"},
+		},
+		{
+			name:    "invalid segment",
+			dialect: DialectPostgreSQL,
+			mapping: ColumnMapping{Type: cel.StringType, Column: "profile", JSONPath: "address.'city"},
+		},
+		{
+			name:    "empty segment",
+			dialect: DialectMySQL,
+			mapping: ColumnMapping{Type: cel.StringType, Column: "profile", JSONPath: "address..city"},
+		},
+		{
+			name:    "unsupported type",
+			dialect: DialectPostgreSQL,
+			mapping: ColumnMapping{Type: cel.ListType(cel.StringType), Column: "profile", JSONPath: "tags"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				Dialect:           tt.dialect,
+				FieldDeclarations: map[string]ColumnMapping{"field": tt.mapping},
+			})
+			if err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package cel2squirrel
+
+import "context"
+
+// Sqlizer returns a Sqlizer deferring the conversion of a filter expression
+// to its ToSql calls, for use in query builder chains: conversion errors
+// surface from the builder's ToSql. The expression is converted on every
+// call; use Prepare to convert stored filters once.
+func (c *Converter) Sqlizer(celExpr string, opts ...ConvertOption) Sqlizer {
+	return c.SqlizerContext(context.Background(), celExpr, opts...)
+}
+
+// SqlizerContext is like Sqlizer, converting the expression with ctx, e.g.
+// for the conditions of MandatoryConditionsFunc.
+func (c *Converter) SqlizerContext(ctx context.Context, celExpr string, opts ...ConvertOption) Sqlizer {
+	return &lazySqlizer{converter: c, ctx: ctx, celExpr: celExpr, opts: opts}
+}
+
+// lazySqlizer converts its expression when rendered.
+type lazySqlizer struct {
+	converter *Converter
+	ctx       context.Context
+	celExpr   string
+	opts      []ConvertOption
+}
+
+// ToSql converts the expression and renders its WHERE clause.
+func (s *lazySqlizer) ToSql() (string, []interface{}, error) {
+	result, err := s.converter.ConvertContext(s.ctx, s.celExpr, s.opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	return result.Where.ToSql()
+}
@@ -0,0 +1,117 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Sqlizer(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode string
+	}{
+		{
+			name:     "valid",
+			celExpr:  `status == "active" && age > 18`,
+			wantSQL:  "SELECT id FROM users WHERE (status = $1 AND age > $2)",
+			wantArgs: []interface{}{"active", int64(18)},
+		},
+		{
+			name:     "invalid syntax",
+			celExpr:  `status ==`,
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "undeclared field",
+			celExpr:  `unknown == 1`,
+			wantCode: "INVALID_SYNTAX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No conversion happens before ToSql
+			query := squirrel.Select("id").
+				From("users").
+				Where(converter.Sqlizer(tt.celExpr)).
+				PlaceholderFormat(squirrel.Dollar)
+
+			sql, args, err := query.ToSql()
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ToSql() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_SqlizerContext(t *testing.T) {
+	converter := newTenantConverter(t, Config{})
+
+	tests := []struct {
+		name     string
+		sqlizer  squirrel.Sqlizer
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{
+			name:     "tenant of the context",
+			sqlizer:  converter.SqlizerContext(context.WithValue(context.Background(), tenantKey{}, "t1"), `status == "active"`),
+			wantSQL:  "(status = ? AND tenant_id = ?)",
+			wantArgs: []interface{}{"active", "t1"},
+		},
+		{
+			name:    "without context",
+			sqlizer: converter.Sqlizer(`status == "active"`),
+			wantErr: ErrScopeUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.sqlizer.ToSql()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ToSql() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
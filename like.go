@@ -0,0 +1,136 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// likeExpr is a LIKE match against an escaped pattern with an explicit
+// ESCAPE clause, so that the backslash escaping of escapeLikePattern is
+// honored whatever the database's default escape character.
+type likeExpr struct {
+	// sql is the matched SQL expression, binding args.
+	sql  string
+	args []interface{}
+	// placeholder is the SQL of the bound pattern, e.g. ? or LOWER(?).
+	placeholder string
+	pattern     string
+	// op is LIKE or ILIKE.
+	op     string
+	escape string
+	not    bool
+}
+
+// ToSql implements squirrel.Sqlizer.
+func (e *likeExpr) ToSql() (string, []interface{}, error) {
+	op := e.op
+	if e.not {
+		op = "NOT " + op
+	}
+	sql := fmt.Sprintf("%s %s %s ESCAPE %s", e.sql, op, e.placeholder, e.escape)
+	return sql, append(append([]interface{}(nil), e.args...), e.pattern), nil
+}
+
+// likeEscape returns the ESCAPE clause literal for the backslash, when LIKE
+// patterns carry an explicit escape clause. SQLite has no default escape
+// character, so the clause is always emitted for it.
+func (c *Converter) likeEscape() (string, bool) {
+	if !c.explicitLikeEscape && c.dialect != DialectSQLite {
+		return "", false
+	}
+	if c.dialect == DialectMySQL {
+		// Backslashes are escapes within MySQL string literals
+		return `'\\'`, true
+	}
+	return `'\'`, true
+}
+
+// matchLike renders a LIKE match of lhs against an escaped bound pattern.
+func (c *Converter) matchLike(lhs operand, pattern string, insensitive bool) (squirrel.Sqlizer, error) {
+	if err := c.checkLikePattern(pattern); err != nil {
+		return nil, err
+	}
+
+	escape, ok := c.likeEscape()
+	if !ok {
+		if insensitive {
+			return lhs.ilike(pattern, c.dialect), nil
+		}
+		return lhs.like(pattern), nil
+	}
+
+	expr := &likeExpr{
+		sql:         lhs.sql,
+		args:        lhs.bound(),
+		placeholder: "?",
+		pattern:     pattern,
+		op:          "LIKE",
+		escape:      escape,
+	}
+	if insensitive {
+		if c.dialect == DialectPostgreSQL {
+			expr.op = "ILIKE"
+		} else {
+			expr.sql = fmt.Sprintf("LOWER(%s)", lhs.sql)
+			expr.placeholder = "LOWER(?)"
+		}
+	}
+	return expr, nil
+}
+
+// likeWildcards returns the number of interior % wildcards of a LIKE
+// pattern, honoring backslash escapes. Leading and trailing wildcards, as
+// in contains(), and runs of consecutive wildcards count once at most: the
+// matching cost of a pattern grows with the number of segments separated
+// by interior wildcards, each of which may be retried at every offset.
+func likeWildcards(pattern string) int {
+	var (
+		wildcards int
+		literal   bool // a literal was seen since the start
+		pending   bool // a wildcard run follows the last literal
+	)
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '%':
+			pending = literal
+			continue
+		case '\\':
+			i++
+		}
+		if pending {
+			wildcards++
+			pending = false
+		}
+		literal = true
+	}
+	return wildcards
+}
+
+// checkLikePattern records the cost of a bound LIKE pattern in the
+// complexity report and enforces the configured limits.
+func (c *Converter) checkLikePattern(pattern string) error {
+	wildcards := likeWildcards(pattern)
+	if c.conv != nil {
+		complexity := &c.conv.complexity
+		complexity.LikePatterns++
+		complexity.MaxLikePatternLength = max(complexity.MaxLikePatternLength, len(pattern))
+		complexity.MaxLikeWildcards = max(complexity.MaxLikeWildcards, wildcards)
+	}
+
+	if c.maxLikeLength > 0 && len(pattern) > c.maxLikeLength {
+		return newConversionError(
+			"LIKE pattern is too complex",
+			CodeLimitLikePattern,
+			fmt.Errorf("LIKE pattern of %d bytes exceeds maximum of %d", len(pattern), c.maxLikeLength),
+		)
+	}
+	if c.maxLikeWildcards > 0 && wildcards > c.maxLikeWildcards {
+		return newConversionError(
+			"LIKE pattern is too complex",
+			CodeLimitLikePattern,
+			fmt.Errorf("LIKE pattern with %d interior wildcards exceeds maximum of %d", wildcards, c.maxLikeWildcards),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_ExplicitLikeEscape(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "default dialect",
+			config:   Config{ExplicitLikeEscape: true},
+			celExpr:  `name.contains("50%")`,
+			wantSQL:  `name LIKE ? ESCAPE '\'`,
+			wantArgs: []any{`%50\%%`},
+		},
+		{
+			name:     "mysql",
+			config:   Config{ExplicitLikeEscape: true, Dialect: DialectMySQL},
+			celExpr:  `name.startsWith("a_b")`,
+			wantSQL:  `name LIKE ? ESCAPE '\\'`,
+			wantArgs: []any{`a\_b%`},
+		},
+		{
+			name:     "sqlite without option",
+			config:   Config{Dialect: DialectSQLite},
+			celExpr:  `name.endsWith("x")`,
+			wantSQL:  `name LIKE ? ESCAPE '\'`,
+			wantArgs: []any{"%x"},
+		},
+		{
+			name:     "postgres case-insensitive",
+			config:   Config{ExplicitLikeEscape: true, CaseInsensitiveLike: true, Dialect: DialectPostgreSQL},
+			celExpr:  `name.contains("x")`,
+			wantSQL:  `name ILIKE ? ESCAPE '\'`,
+			wantArgs: []any{"%x%"},
+		},
+		{
+			name:     "case-insensitive",
+			config:   Config{ExplicitLikeEscape: true, CaseInsensitiveLike: true},
+			celExpr:  `name.contains("x")`,
+			wantSQL:  `LOWER(name) LIKE LOWER(?) ESCAPE '\'`,
+			wantArgs: []any{"%x%"},
+		},
+		{
+			name:     "equalsIgnoreCase",
+			config:   Config{ExplicitLikeEscape: true, Dialect: DialectPostgreSQL},
+			celExpr:  `name.equalsIgnoreCase("a_b")`,
+			wantSQL:  `name ILIKE ? ESCAPE '\'`,
+			wantArgs: []any{`a\_b`},
+		},
+		{
+			name:     "pushed down negation",
+			config:   Config{ExplicitLikeEscape: true, PushDownNot: true},
+			celExpr:  `!name.contains("x")`,
+			wantSQL:  `name NOT LIKE ? ESCAPE '\'`,
+			wantArgs: []any{"%x%"},
+		},
+		{
+			name:     "field argument",
+			config:   Config{ExplicitLikeEscape: true},
+			celExpr:  `name.contains(other)`,
+			wantSQL:  "POSITION(other IN name) > 0",
+			wantArgs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.FieldDeclarations = map[string]ColumnMapping{
+				"name":  {Type: cel.StringType, Column: "name"},
+				"other": {Type: cel.StringType, Column: "other"},
+			}
+			converter, err := NewConverter(config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestLikeWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{pattern: "abc", want: 0},
+		{pattern: "%abc%", want: 0},
+		{pattern: "%%abc", want: 0},
+		{pattern: "a%b", want: 1},
+		{pattern: "%a%%b%c%", want: 2},
+		{pattern: `a\%b`, want: 0},
+		{pattern: `a\\%b`, want: 1},
+		{pattern: "%", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := likeWildcards(tt.pattern); got != tt.want {
+			t.Errorf("likeWildcards(%q) = %d, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestConverter_Convert_LikePatternLimits(t *testing.T) {
+	newConverter := func(config Config) *Converter {
+		t.Helper()
+		config.FieldDeclarations = map[string]ColumnMapping{
+			"name": {Type: cel.StringType},
+		}
+		config.Functions = []FunctionTemplate{{
+			Name:         "like",
+			ReceiverType: cel.StringType,
+			ArgTypes:     []*cel.Type{cel.StringType},
+			SQL:          "{col} LIKE {arg0}",
+		}}
+		converter, err := NewConverter(config)
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+		return converter
+	}
+
+	t.Run("complexity report", func(t *testing.T) {
+		result, err := newConverter(Config{}).Convert(`name.contains("50%") && name.like("a%b%c%d")`)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		complexity := result.Complexity
+		if complexity.LikePatterns != 2 || complexity.MaxLikePatternLength != 7 || complexity.MaxLikeWildcards != 3 {
+			t.Errorf("Complexity = %+v, want 2 patterns of at most 7 bytes and 3 wildcards", complexity)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		config   Config
+		celExpr  string
+		wantCode string
+	}{
+		{
+			name:    "escaped wildcards",
+			config:  Config{MaxLikeWildcards: 1},
+			celExpr: `name.contains("a%b%c%d")`,
+		},
+		{
+			name:     "raw wildcards",
+			config:   Config{MaxLikeWildcards: 2},
+			celExpr:  `name.like("a%b%c%d")`,
+			wantCode: "LIMIT_LIKE_PATTERN",
+		},
+		{
+			name:     "length",
+			config:   Config{MaxLikePatternLength: 8},
+			celExpr:  `name.startsWith("abcdefgh")`,
+			wantCode: "LIMIT_LIKE_PATTERN",
+		},
+		{
+			name:    "length within limit",
+			config:  Config{MaxLikePatternLength: 8},
+			celExpr: `name.startsWith("abcdefg")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newConverter(tt.config).Convert(tt.celExpr)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Convert() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Convert() expected error")
+			}
+			if code := string(err.(*ConversionError).ErrorCode); code != tt.wantCode {
+				t.Errorf("ErrorCode = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// referenceLike matches text against a LIKE pattern by backtracking on
+// every % wildcard, as simple database implementations do.
+func referenceLike(text, pattern string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for pattern = strings.TrimLeft(pattern, "%"); ; text = text[1:] {
+				if referenceLike(text, pattern) {
+					return true
+				}
+				if text == "" {
+					return false
+				}
+			}
+		case '_':
+			if text == "" {
+				return false
+			}
+		default:
+			if text == "" || text[0] != pattern[0] {
+				return false
+			}
+		}
+		text, pattern = text[1:], pattern[1:]
+	}
+	return text == ""
+}
+
+// likeCorpus returns rows of lowercase words, and adversarial rows made of
+// a single repeated letter that almost match patterns of that letter.
+func likeCorpus(rows, width int, adversarial bool) []string {
+	rng := rand.New(rand.NewSource(1))
+	corpus := make([]string, rows)
+	for i := range corpus {
+		if adversarial {
+			corpus[i] = strings.Repeat("a", width)
+			continue
+		}
+		var row strings.Builder
+		for row.Len() < width {
+			for n := 2 + rng.Intn(8); n > 0; n-- {
+				row.WriteByte(byte('a' + rng.Intn(26)))
+			}
+			row.WriteByte(' ')
+		}
+		corpus[i] = row.String()[:width]
+	}
+	return corpus
+}
+
+// BenchmarkLikeWildcards shows how the worst-case cost of matching a LIKE
+// pattern grows with its interior wildcards, to help tune
+// Config.MaxLikeWildcards.
+func BenchmarkLikeWildcards(b *testing.B) {
+	corpora := map[string][]string{
+		"words":       likeCorpus(1000, 256, false),
+		"adversarial": likeCorpus(100, 48, true),
+	}
+
+	for _, corpus := range []string{"words", "adversarial"} {
+		for wildcards := 0; wildcards <= 3; wildcards++ {
+			pattern := "%" + strings.Repeat("a%", wildcards) + "b"
+			b.Run(fmt.Sprintf("%s/%d", corpus, wildcards), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					for _, row := range corpora[corpus] {
+						referenceLike(row, pattern)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkLikeWildcardsAnalysis measures the analysis of long patterns.
+func BenchmarkLikeWildcardsAnalysis(b *testing.B) {
+	pattern := strings.Repeat(`ab\%c%`, 1000)
+	for i := 0; i < b.N; i++ {
+		likeWildcards(pattern)
+	}
+}
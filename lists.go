@@ -0,0 +1,127 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// ColumnKind describes how a list field is stored in its SQL column.
+type ColumnKind string
+
+const (
+	// KindJSON stores the list as a JSON array. It is the default.
+	KindJSON ColumnKind = "json"
+	// KindArray stores the list as a native SQL array, such as PostgreSQL
+	// text[] or int[].
+	KindArray ColumnKind = "array"
+)
+
+// getSizeExpr renders the number of elements of a list field, for
+// `size(tags)`, `tags.size()` and emptiness checks such as `tags == []`.
+func (c *Converter) getSizeExpr(expr celast.Expr) (operand, error) {
+	field, err := c.getFieldName(expr)
+	if err != nil {
+		return operand{}, err
+	}
+
+	mapping, ok := c.fieldDeclarations[field]
+	if !ok || mapping.Type == nil || mapping.Type.Kind() != types.ListKind {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("size() requires a list field, got %s", field),
+		)
+	}
+
+	length, ok := c.dialect.arrayLength(c.mapFieldName(field), mapping.Kind)
+	if !ok {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("dialect %q does not support the length of %s lists", c.dialect, kindName(mapping.Kind)),
+		)
+	}
+
+	return operand{sql: length}, nil
+}
+
+// isEmptyList reports whether expr is the empty list literal.
+func isEmptyList(expr celast.Expr) bool {
+	return expr.Kind() == celast.ListKind && expr.AsList().Size() == 0
+}
+
+// kindName returns the name of a column kind for error messages.
+func kindName(kind ColumnKind) ColumnKind {
+	if kind == "" {
+		return KindJSON
+	}
+	return kind
+}
+
+// getArrayColumn resolves a list field stored as a native PostgreSQL array,
+// the only storage supporting the array operators.
+func (c *Converter) getArrayColumn(expr celast.Expr) (string, error) {
+	field, err := c.getFieldName(expr)
+	if err != nil {
+		return "", err
+	}
+
+	mapping := c.fieldDeclarations[field]
+	if c.dialect != DialectPostgreSQL || mapping.Kind != KindArray {
+		return "", newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("list operators on %s require a PostgreSQL array column", field),
+		)
+	}
+	return c.mapFieldName(field), nil
+}
+
+// convertListMembership converts `value in tags` to `? = ANY(tags)`.
+func (c *Converter) convertListMembership(element, list celast.Expr) (squirrel.Sqlizer, error) {
+	column, err := c.getArrayColumn(list)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := c.getConstantValue(element)
+	if err != nil {
+		return nil, err
+	}
+
+	return squirrel.Expr(fmt.Sprintf("? = ANY(%s)", column), value), nil
+}
+
+// convertListContains converts `tags.containsAll([...])` to
+// `tags @> ARRAY[...]` and `tags.containsAny([...])` to `tags && ARRAY[...]`.
+// all reports the result for an empty element list.
+func (c *Converter) convertListContains(call celast.CallExpr, op string, all bool) (squirrel.Sqlizer, error) {
+	if len(call.Args()) != 1 || !call.IsMemberFunction() {
+		return nil, fmt.Errorf("%s() requires a list receiver and exactly 1 argument", call.FunctionName())
+	}
+
+	column, err := c.getArrayColumn(call.Target())
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.getListValues(call.Args()[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		if all {
+			return squirrel.Expr("(1=1)"), nil
+		}
+		return squirrel.Expr("(1=0)"), nil
+	}
+
+	return squirrel.Expr(
+		fmt.Sprintf("%s %s ARRAY[%s]", column, op, squirrel.Placeholders(len(values))),
+		values...,
+	), nil
+}
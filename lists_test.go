@@ -0,0 +1,246 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_ListSize(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"tags":   {Type: cel.ListType(cel.StringType), Column: "tags"},
+		"labels": {Type: cel.ListType(cel.StringType), Column: "label_ids", Kind: KindArray},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "empty json list",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `size(tags) == 0`,
+			wantSQL:  "jsonb_array_length(tags) = ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "empty list literal",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `tags == []`,
+			wantSQL:  "jsonb_array_length(tags) = ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "non-empty list literal",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `tags != []`,
+			wantSQL:  "jsonb_array_length(tags) <> ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "native array",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `size(labels) == 0`,
+			wantSQL:  "cardinality(label_ids) = ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "native array without dialect",
+			dialect:  DialectDefault,
+			celExpr:  `labels == []`,
+			wantSQL:  "cardinality(label_ids) = ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "member size",
+			dialect:  DialectMySQL,
+			celExpr:  `tags.size() >= 3`,
+			wantSQL:  "JSON_LENGTH(tags) >= ?",
+			wantArgs: []any{int64(3)},
+		},
+		{
+			name:     "sqlite",
+			dialect:  DialectSQLite,
+			celExpr:  `size(tags) > 0 && size(tags) < 10`,
+			wantSQL:  "(json_array_length(tags) > ? AND json_array_length(tags) < ?)",
+			wantArgs: []any{int64(0), int64(10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_ListSizeErrors(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"tags":   {Type: cel.ListType(cel.StringType), Column: "tags"},
+		"labels": {Type: cel.ListType(cel.StringType), Column: "label_ids", Kind: KindArray},
+		"name":   {Type: cel.StringType, Column: "name"},
+	}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		celExpr string
+	}{
+		{name: "json without dialect", dialect: DialectDefault, celExpr: `size(tags) == 0`},
+		{name: "native array on mysql", dialect: DialectMySQL, celExpr: `labels == []`},
+		{name: "string size", dialect: DialectPostgreSQL, celExpr: `size(name) == 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			if _, err := converter.Convert(tt.celExpr); err == nil {
+				t.Errorf("Convert(%q) should fail", tt.celExpr)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_ArrayOperators(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tags": {Type: cel.ListType(cel.StringType), Column: "tag_names", Kind: KindArray},
+			"ids":  {Type: cel.ListType(cel.IntType), Column: "ids", Kind: KindArray},
+		},
+		Dialect: DialectPostgreSQL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{name: "membership", celExpr: `"go" in tags`, wantSQL: "? = ANY(tag_names)", wantArgs: []any{"go"}},
+		{name: "int membership", celExpr: `7 in ids`, wantSQL: "? = ANY(ids)", wantArgs: []any{int64(7)}},
+		{name: "contains all", celExpr: `tags.containsAll(["go", "sql"])`, wantSQL: "tag_names @> ARRAY[?,?]", wantArgs: []any{"go", "sql"}},
+		{name: "contains any", celExpr: `ids.containsAny([1, 2, 3])`, wantSQL: "ids && ARRAY[?,?,?]", wantArgs: []any{int64(1), int64(2), int64(3)}},
+		{name: "contains all of nothing", celExpr: `tags.containsAll([])`, wantSQL: "(1=1)"},
+		{name: "contains any of nothing", celExpr: `tags.containsAny([])`, wantSQL: "(1=0)"},
+		{name: "negated membership", celExpr: `!("go" in tags)`, wantSQL: "NOT (? = ANY(tag_names))", wantArgs: []any{"go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_ArrayOperatorErrors(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"tags":  {Type: cel.ListType(cel.StringType), Column: "tags", Kind: KindArray},
+		"jsons": {Type: cel.ListType(cel.StringType), Column: "jsons"},
+	}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		celExpr string
+	}{
+		{name: "json list", dialect: DialectPostgreSQL, celExpr: `"go" in jsons`},
+		{name: "mysql", dialect: DialectMySQL, celExpr: `tags.containsAny(["go"])`},
+		{name: "oversized list", dialect: DialectPostgreSQL, celExpr: `tags.containsAll(["a", "b", "c"])`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect, MaxInClauseSize: 2})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			if _, err := converter.Convert(tt.celExpr); err == nil {
+				t.Errorf("Convert(%q) should fail", tt.celExpr)
+			}
+		})
+	}
+}
+
+func TestListContainsEvaluation(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tags": {Type: cel.ListType(cel.StringType), Kind: KindArray},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: `tags.containsAll(["a", "b"])`, want: true},
+		{expr: `tags.containsAll(["a", "z"])`, want: false},
+		{expr: `tags.containsAny(["z", "b"])`, want: true},
+		{expr: `tags.containsAny(["y", "z"])`, want: false},
+	}
+
+	for _, tt := range tests {
+		ast, issues := converter.env.Compile(tt.expr)
+		if issues != nil && issues.Err() != nil {
+			t.Fatalf("Compile(%q) error = %v", tt.expr, issues.Err())
+		}
+		prg, err := converter.env.Program(ast)
+		if err != nil {
+			t.Fatalf("Program(%q) error = %v", tt.expr, err)
+		}
+		out, _, err := prg.Eval(map[string]any{"tags": []string{"a", "b", "c"}})
+		if err != nil {
+			t.Fatalf("Eval(%q) error = %v", tt.expr, err)
+		}
+		if out.Value() != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, out.Value(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// applyMandatoryConditions ANDs the mandatory conditions, and the soft
+// delete condition unless the filter references the field, onto the WHERE
+// clause of a result.
+func (c *Converter) applyMandatoryConditions(ctx context.Context, result *ConvertResult) error {
+	conditions := c.mandatory
+	if c.softDeleteField != "" && !slices.ContainsFunc(result.Fields, c.isSoftDeleteField) {
+		deleted := squirrel.Eq{c.mapFieldName(c.softDeleteField): nil}
+		conditions = append([]squirrel.Sqlizer{deleted}, conditions...)
+	}
+	if c.mandatoryFunc != nil {
+		extra, err := contextConditions(ctx, c.mandatoryFunc)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions[:len(conditions):len(conditions)], extra...)
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	result.Where = append(squirrel.And{result.Where}, conditions...)
+	result.AlwaysTrue = false
+	return nil
+}
+
+// contextConditions returns the mandatory conditions fn returns for ctx,
+// failing with SCOPE_UNAVAILABLE when they cannot be resolved.
+func contextConditions(ctx context.Context, fn func(ctx context.Context) ([]squirrel.Sqlizer, error)) ([]squirrel.Sqlizer, error) {
+	conditions, err := fn(ctx)
+	if err != nil {
+		return nil, newConversionError(
+			"filter scope unavailable",
+			CodeScopeUnavailable,
+			fmt.Errorf("mandatory conditions: %w", err),
+		)
+	}
+	for i, condition := range conditions {
+		if condition == nil {
+			return nil, newConversionError(
+				"filter scope unavailable",
+				CodeScopeUnavailable,
+				fmt.Errorf("mandatory condition %d is nil", i),
+			)
+		}
+	}
+	return conditions, nil
+}
+
+// isSoftDeleteField reports whether a field, or the field it aliases, is the
+// soft delete field.
+func (c *Converter) isSoftDeleteField(name string) bool {
+	return c.canonicalField(name) == c.softDeleteField
+}
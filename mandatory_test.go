@@ -0,0 +1,282 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+type tenantKey struct{}
+
+// newTenantConverter returns a converter scoping rows to the tenant of the
+// context.
+func newTenantConverter(t *testing.T, config Config) *Converter {
+	t.Helper()
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+	}
+	config.MandatoryConditionsFunc = func(ctx context.Context) ([]squirrel.Sqlizer, error) {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return nil, errors.New("no tenant in context")
+		}
+		return []squirrel.Sqlizer{squirrel.Eq{"tenant_id": tenant}}, nil
+	}
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_MandatoryConditions(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+	}
+	tenantConditions := func(ctx context.Context) ([]squirrel.Sqlizer, error) {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return nil, errors.New("no tenant in context")
+		}
+		return []squirrel.Sqlizer{squirrel.Eq{"tenant_id": tenant}}, nil
+	}
+
+	tests := []struct {
+		name       string
+		config     Config
+		ctx        context.Context
+		celExpr    string
+		wantSQL    string
+		wantArgs   []interface{}
+		wantCode   string
+		alwaysTrue bool
+	}{
+		{
+			name:     "static",
+			config:   Config{MandatoryConditions: []squirrel.Sqlizer{squirrel.Eq{"deleted_at": nil}}},
+			celExpr:  `status == "active"`,
+			wantSQL:  "(status = ? AND deleted_at IS NULL)",
+			wantArgs: []interface{}{"active"},
+		},
+		{
+			name: "from context",
+			config: Config{
+				MandatoryConditions:     []squirrel.Sqlizer{squirrel.Eq{"deleted_at": nil}},
+				MandatoryConditionsFunc: tenantConditions,
+			},
+			ctx:      context.WithValue(context.Background(), tenantKey{}, "t1"),
+			celExpr:  `status == "active"`,
+			wantSQL:  "(status = ? AND deleted_at IS NULL AND tenant_id = ?)",
+			wantArgs: []interface{}{"active", "t1"},
+		},
+		{
+			name:     "match all filter",
+			config:   Config{MandatoryConditions: []squirrel.Sqlizer{squirrel.Expr("tenant_id = ?", "t1")}},
+			celExpr:  `true`,
+			wantSQL:  "(TRUE AND tenant_id = ?)",
+			wantArgs: []interface{}{"t1"},
+		},
+		{
+			name:     "unavailable",
+			config:   Config{MandatoryConditionsFunc: tenantConditions},
+			celExpr:  `status == "active"`,
+			wantCode: "SCOPE_UNAVAILABLE",
+		},
+		{
+			name: "nil from context",
+			config: Config{MandatoryConditionsFunc: func(context.Context) ([]squirrel.Sqlizer, error) {
+				return []squirrel.Sqlizer{nil}, nil
+			}},
+			celExpr:  `status == "active"`,
+			wantCode: "SCOPE_UNAVAILABLE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.FieldDeclarations = fields
+			converter, err := NewConverter(tt.config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			result, err := converter.ConvertContext(ctx, tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("ConvertContext() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ConvertContext() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ConvertContext() args = %v, want %v", args, tt.wantArgs)
+			}
+			if result.AlwaysTrue {
+				t.Error("AlwaysTrue = true, want false with mandatory conditions")
+			}
+		})
+	}
+
+	if _, err := NewConverter(Config{
+		FieldDeclarations:   fields,
+		MandatoryConditions: []squirrel.Sqlizer{nil},
+	}); err == nil {
+		t.Error("NewConverter() with a nil condition error = nil, want error")
+	}
+}
+
+func TestConverter_MandatoryConditions_Subqueries(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{
+		MandatoryConditions: []squirrel.Sqlizer{squirrel.Eq{"prompts.deleted_at": nil}},
+	})
+	result, err := converter.Convert(`comments.exists(c, c.flagged)`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	want := "(EXISTS (SELECT 1 FROM comments WHERE comments.prompt_id = prompts.id AND flagged = ?) AND prompts.deleted_at IS NULL)"
+	if sql != want {
+		t.Errorf("Convert() SQL = %q, want %q", sql, want)
+	}
+}
+
+func TestConverter_SoftDeleteField(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status":     {Type: cel.StringType},
+		"deleted_at": {Type: cel.TimestampType, Column: "removed_at"},
+	}
+
+	tests := []struct {
+		name     string
+		config   Config
+		celExpr  string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "not referenced",
+			celExpr:  `status == "active"`,
+			wantSQL:  "(status = ? AND removed_at IS NULL)",
+			wantArgs: []interface{}{"active"},
+		},
+		{
+			name:     "referenced",
+			celExpr:  `status == "active" && deleted_at != null`,
+			wantSQL:  "(status = ? AND removed_at IS NOT NULL)",
+			wantArgs: []interface{}{"active"},
+		},
+		{
+			name:     "table alias",
+			config:   Config{TableAlias: "p"},
+			celExpr:  `status == "active"`,
+			wantSQL:  "(p.status = ? AND p.removed_at IS NULL)",
+			wantArgs: []interface{}{"active"},
+		},
+		{
+			name:     "with mandatory conditions",
+			config:   Config{MandatoryConditions: []squirrel.Sqlizer{squirrel.Eq{"tenant_id": "t1"}}},
+			celExpr:  `status == "active"`,
+			wantSQL:  "(status = ? AND removed_at IS NULL AND tenant_id = ?)",
+			wantArgs: []interface{}{"active", "t1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.FieldDeclarations = fields
+			tt.config.SoftDeleteField = "deleted_at"
+			converter, err := NewConverter(tt.config)
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Convert() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Convert() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_SoftDeleteField_Scopes(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tenant_id":  {Type: cel.StringType},
+			"deleted_at": {Type: cel.TimestampType},
+		},
+		SoftDeleteField: "deleted_at",
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	scopes := ScopeStack{{Name: "tenant", Predicate: `tenant_id == "t1"`}}
+	result, err := converter.ConvertWithScopes(context.Background(), `deleted_at != null`, nil, scopes)
+	if err != nil {
+		t.Fatalf("ConvertWithScopes() error = %v", err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "(tenant_id = ? AND deleted_at IS NOT NULL)"; sql != want {
+		t.Errorf("ConvertWithScopes() SQL = %q, want %q", sql, want)
+	}
+}
+
+func TestNewConverter_SoftDeleteField_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]ColumnMapping
+		wantErr string
+	}{
+		{
+			name:    "undeclared",
+			fields:  map[string]ColumnMapping{"status": {Type: cel.StringType}},
+			wantErr: "soft delete field deleted_at is not declared",
+		},
+		{
+			name: "relation field",
+			fields: map[string]ColumnMapping{"deleted_at": {
+				Type: cel.TimestampType,
+				Join: &JoinSpec{Table: "users", On: "p.user_id = users.id"},
+			}},
+			wantErr: "soft delete field deleted_at must be a field of the filtered table",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{FieldDeclarations: tt.fields, SoftDeleteField: "deleted_at"})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("NewConverter() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package cel2squirrel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// redactedExpression replaces expressions that reference masked fields but
+// cannot be parsed, so their literals cannot be told apart.
+const redactedExpression = "<redacted>"
+
+// errMaskedDetails replaces internal error details that cannot be masked.
+var errMaskedDetails = errors.New("details withheld: the expression references masked fields")
+
+// maskValue returns the stand-in for a value of a masked field: a truncated
+// SHA-256 digest, so that occurrences of the same value can be correlated
+// without revealing it.
+func maskValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// exprMask carries what must be masked when echoing an expression that
+// references masked fields.
+type exprMask struct {
+	// source is the expression with the masked literals replaced.
+	source string
+	// secrets are the source texts of the masked literals, longest first.
+	secrets []string
+	// opaque is set when the expression could not be parsed: its literals
+	// are unknown, so nothing derived from it may be echoed.
+	opaque bool
+}
+
+// newExprMask returns the mask of an expression, or nil when it does not
+// reference masked fields.
+func (c *Converter) newExprMask(celExpr string) *exprMask {
+	if len(c.maskedFields) == 0 || !c.mentionsMaskedField(celExpr) {
+		return nil
+	}
+
+	parsed, issues := c.env.Parse(celExpr)
+	if issues != nil && issues.Err() != nil {
+		return &exprMask{source: redactedExpression, opaque: true}
+	}
+
+	mask := &exprMask{}
+	var err error
+	mask.source, err = c.maskedSource(parsed.NativeRep().Expr(), parsed.NativeRep().SourceInfo(), &mask.secrets)
+	if err != nil {
+		return &exprMask{source: redactedExpression, opaque: true}
+	}
+
+	sort.Slice(mask.secrets, func(i, j int) bool { return len(mask.secrets[i]) > len(mask.secrets[j]) })
+	return mask
+}
+
+// mentionsMaskedField reports whether the expression contains the name of a
+// masked field as an identifier.
+func (c *Converter) mentionsMaskedField(celExpr string) bool {
+	words := strings.FieldsFunc(celExpr, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if c.maskedFields[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// maskedSource renders expr with its masked literals replaced, including
+// those of the macro calls recorded in sourceInfo.
+func (c *Converter) maskedSource(expr celast.Expr, sourceInfo *celast.SourceInfo, secrets *[]string) (string, error) {
+	sourceInfo = celast.CopySourceInfo(sourceInfo)
+	for id, call := range sourceInfo.MacroCalls() {
+		sourceInfo.SetMacroCall(id, c.maskLiterals(call, secrets))
+	}
+	return cel.ExprToString(c.maskLiterals(expr, secrets), sourceInfo)
+}
+
+// maskLiterals returns a copy of expr in which the literals of predicates
+// and function calls reading a masked field are replaced with their
+// maskValue. The source texts of the replaced literals are appended to
+// secrets.
+func (c *Converter) maskLiterals(expr celast.Expr, secrets *[]string) celast.Expr {
+	masked := exprFactory.CopyExpr(expr)
+	c.maskCall(masked, secrets)
+	return masked
+}
+
+// maskCall masks in place the literals of the calls reading a masked field.
+// Logical operators are descended into so that the literals of unrelated
+// operands are preserved.
+func (c *Converter) maskCall(expr celast.Expr, secrets *[]string) {
+	if expr.Kind() != celast.CallKind {
+		return
+	}
+
+	switch expr.AsCall().FunctionName() {
+	case "_&&_", "_||_", "!_":
+		for _, arg := range expr.AsCall().Args() {
+			c.maskCall(arg, secrets)
+		}
+		return
+	}
+
+	if !c.readsMaskedField(expr) {
+		return
+	}
+	walkExpr(expr, func(e celast.Expr) {
+		text, ok := constantText(e.AsLiteral())
+		if !ok {
+			return
+		}
+		*secrets = append(*secrets, text)
+		e.SetKindCase(exprFactory.NewLiteral(e.ID(), types.String(maskValue(text))))
+	})
+}
+
+// readsMaskedField reports whether expr references a masked field.
+func (c *Converter) readsMaskedField(expr celast.Expr) bool {
+	found := false
+	walkExpr(expr, func(e celast.Expr) {
+		if c.maskedFields[e.AsIdent()] {
+			found = true
+		}
+	})
+	return found
+}
+
+// constantText returns the text of a literal worth masking.
+func constantText(constant ref.Val) (string, bool) {
+	switch v := constant.(type) {
+	case types.String:
+		return string(v), v != ""
+	case types.Bytes:
+		return string(v), len(v) > 0
+	case types.Int:
+		return strconv.FormatInt(int64(v), 10), true
+	case types.Uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case types.Double:
+		return strconv.FormatFloat(float64(v), 'g', -1, 64), true
+	default:
+		// Booleans and null reveal nothing about the masked value
+		return "", false
+	}
+}
+
+// expr returns the expression as it may be echoed.
+func (m *exprMask) expr(celExpr string) string {
+	if m == nil {
+		return celExpr
+	}
+	return m.source
+}
+
+// text masks the secrets occurring in a message derived from the expression.
+func (m *exprMask) text(message string) string {
+	if m == nil {
+		return message
+	}
+	if m.opaque {
+		return errMaskedDetails.Error()
+	}
+	// A single pass never masks the digests of other secrets
+	pairs := make([]string, 0, 2*len(m.secrets))
+	for _, secret := range m.secrets {
+		pairs = append(pairs, secret, maskValue(secret))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// error masks the internal error of a conversion error, flattening its chain
+// into the masked text. Public messages never contain values.
+func (m *exprMask) error(err error) error {
+	if m == nil || err == nil {
+		return err
+	}
+
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		return errors.New(m.text(err.Error()))
+	}
+
+	masked := &ConversionError{
+		PublicMessage: convErr.PublicMessage,
+		ErrorCode:     convErr.ErrorCode,
+	}
+	if convErr.Location != nil {
+		location := *convErr.Location
+		location.Token = m.text(location.Token)
+		masked.Location = &location
+	}
+	if convErr.InternalError != nil {
+		masked.InternalError = errors.New(m.text(convErr.InternalError.Error()))
+	}
+	return masked
+}
+
+// result masks the warnings of a conversion result.
+func (m *exprMask) result(result *ConvertResult) {
+	if m == nil || result == nil {
+		return
+	}
+	for i, warning := range result.Warnings {
+		result.Warnings[i].Message = m.text(warning.Message)
+	}
+}
+
+// maskOutput masks the values of masked fields echoed by the outcome of a
+// conversion of celExpr, localizing and detailing its error per
+// Config.ErrorMessages and Config.ErrorDetail.
+func (c *Converter) maskOutput(celExpr string, result *ConvertResult, err error) (*ConvertResult, error) {
+	if len(c.maskedFields) == 0 || (err == nil && len(result.Warnings) == 0) {
+		return result, c.detailError(c.localizeError(err))
+	}
+	mask := c.newExprMask(celExpr)
+	mask.result(result)
+	return result, c.detailError(c.localizeError(mask.error(err)))
+}
+
+// logExpr returns the expression passed to the SecurityLogger.
+func (c *Converter) logExpr(celExpr string) string {
+	return c.newExprMask(celExpr).expr(celExpr)
+}
+
+// logCall returns the rendering of a call passed to the SecurityLogger.
+func (c *Converter) logCall(call celast.CallExpr) string {
+	var expr celast.Expr
+	if call.IsMemberFunction() {
+		expr = exprFactory.NewMemberCall(0, call.FunctionName(), call.Target(), call.Args()...)
+	} else {
+		expr = exprFactory.NewCall(0, call.FunctionName(), call.Args()...)
+	}
+	if len(c.maskedFields) > 0 {
+		var secrets []string
+		expr = c.maskLiterals(expr, &secrets)
+	}
+	rendered, err := cel.ExprToString(expr, nil)
+	if err != nil {
+		return call.FunctionName()
+	}
+	return rendered
+}
+
+// displayValue renders a value of field for errors and warnings.
+func (c *Converter) displayValue(field, value string) string {
+	if c.maskedFields[field] {
+		return maskValue(value)
+	}
+	return value
+}
@@ -0,0 +1,207 @@
+package cel2squirrel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// expressionLogger records the expressions passed to the security events.
+type expressionLogger struct {
+	expressions []string
+}
+
+func (l *expressionLogger) LogConversionAttempt(_ context.Context, expr string, _ bool, _ error, _ time.Duration) {
+	l.expressions = append(l.expressions, expr)
+}
+
+func (l *expressionLogger) LogComplexExpression(_ context.Context, expr string, _ int, _ int) {
+	l.expressions = append(l.expressions, expr)
+}
+
+func (l *expressionLogger) LogUnauthorizedField(_ context.Context, expr string, _ string, _ []string) {
+	l.expressions = append(l.expressions, expr)
+}
+
+func (l *expressionLogger) LogUnsupportedOperation(_ context.Context, expr string, _ string) {
+	l.expressions = append(l.expressions, expr)
+}
+
+func newTestMaskedConverter(t *testing.T, config Config) (*Converter, *expressionLogger) {
+	t.Helper()
+
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"national_id": {Type: cel.StringType, Masked: true},
+		"birth":       {Type: cel.TimestampType, Masked: true, Granularity: 24 * time.Hour},
+		"name":        {Type: cel.StringType},
+		"age":         {Type: cel.IntType},
+	}
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	logger := &expressionLogger{}
+	converter.securityLogger = logger
+	return converter, logger
+}
+
+func TestConverter_Masked_ConvertsNormally(t *testing.T) {
+	converter, _ := newTestMaskedConverter(t, Config{})
+
+	result, err := converter.Convert(`national_id == "123-45-6789"`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, args, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if sql != "national_id = ?" || len(args) != 1 || args[0] != "123-45-6789" {
+		t.Errorf("ToSql() = %q, %v", sql, args)
+	}
+}
+
+func TestConverter_Masked_Errors(t *testing.T) {
+	converter, _ := newTestMaskedConverter(t, Config{})
+
+	tests := []struct {
+		name    string
+		celExpr string
+		secret  string
+	}{
+		{
+			name:    "compilation error",
+			celExpr: `national_id == "123-45-6789" && name ==`,
+			secret:  "123-45-6789",
+		},
+		{
+			name:    "type error",
+			celExpr: `national_id == "123-45-6789" && age == "x"`,
+			secret:  "123-45-6789",
+		},
+		{
+			name:    "invalid timestamp",
+			celExpr: `birth == timestamp("1984-13-01T00:00:00Z")`,
+			secret:  "1984-13-01T00:00:00Z",
+		},
+		{
+			name:    "precision",
+			celExpr: `birth == timestamp("1984-02-01T10:30:00Z")`,
+			secret:  "1984-02-01T10:30:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if err == nil {
+				t.Fatal("Convert() expected error")
+			}
+			convErr, ok := err.(*ConversionError)
+			if !ok {
+				t.Fatalf("error type = %T, want *ConversionError", err)
+			}
+			if strings.Contains(convErr.Error(), tt.secret) || strings.Contains(convErr.InternalError.Error(), tt.secret) {
+				t.Errorf("error echoes the masked value: %v", convErr.InternalError)
+			}
+		})
+	}
+}
+
+func TestConverter_Masked_UnmaskedValuesKept(t *testing.T) {
+	converter, _ := newTestMaskedConverter(t, Config{})
+
+	_, err := converter.Convert(`national_id == "123" && birth == timestamp("bad-2024")`)
+	if err == nil {
+		t.Fatal("Convert() expected error")
+	}
+	internal := err.(*ConversionError).InternalError.Error()
+	if strings.Contains(internal, "bad-2024") {
+		t.Errorf("error echoes the masked value: %s", internal)
+	}
+	if !strings.Contains(internal, maskValue("bad-2024")) {
+		t.Errorf("error = %s, want the digest of the masked value", internal)
+	}
+
+	_, err = converter.Convert(`name == "bob" && age == timestamp("2024-01-01T00:00:00Z")`)
+	if err == nil {
+		t.Fatal("Convert() expected error")
+	}
+	if internal := err.(*ConversionError).InternalError.Error(); !strings.Contains(internal, "bob") {
+		t.Errorf("error = %s, want unmasked values kept", internal)
+	}
+}
+
+func TestConverter_Masked_Warnings(t *testing.T) {
+	converter, _ := newTestMaskedConverter(t, Config{})
+	converter.fieldDeclarations["birth"] = ColumnMapping{
+		Type: cel.TimestampType, Masked: true, Granularity: 24 * time.Hour, TruncateToGranularity: true,
+	}
+
+	result, err := converter.Convert(`birth == timestamp("1984-02-01T10:30:00Z")`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Warnings = %v, want 1 warning", result.Warnings)
+	}
+	if strings.Contains(result.Warnings[0].Message, "1984") {
+		t.Errorf("warning echoes the masked value: %s", result.Warnings[0])
+	}
+}
+
+func TestConverter_Masked_SecurityLogger(t *testing.T) {
+	converter, logger := newTestMaskedConverter(t, Config{PublicFields: []string{"name"}})
+
+	if _, err := converter.ConvertWithAuth(`name == "bob" && national_id == "123-45-6789"`, nil); err == nil {
+		t.Fatal("ConvertWithAuth() expected error")
+	}
+	if _, err := converter.Convert(`national_id.matches("^123")`); err == nil {
+		t.Fatal("Convert() expected error")
+	}
+
+	// Each conversion logs its event and its attempt
+	if len(logger.expressions) != 4 {
+		t.Fatalf("logged %d events, want 4", len(logger.expressions))
+	}
+	want := `name == "bob" && national_id == "` + maskValue("123-45-6789") + `"`
+	for _, expr := range logger.expressions[:2] {
+		if expr != want {
+			t.Errorf("logged %q, want %q", expr, want)
+		}
+	}
+	for _, expr := range logger.expressions[2:] {
+		if strings.Contains(expr, "^123") {
+			t.Errorf("logged %q, which echoes the masked value", expr)
+		}
+	}
+}
+
+func TestConverter_Masked_Residual(t *testing.T) {
+	converter, _ := newTestMaskedConverter(t, Config{})
+
+	result, err := converter.ConvertHybrid(`age > 18 && national_id.matches("^123")`)
+	if err != nil {
+		t.Fatalf("ConvertHybrid() error = %v", err)
+	}
+	if result.Residual == nil {
+		t.Fatal("Residual = nil, want the matches() call")
+	}
+
+	want := `national_id.matches("` + maskValue("^123") + `")`
+	if got := result.Residual.String(); got != want {
+		t.Errorf("Residual = %q, want %q", got, want)
+	}
+
+	// The residual is still evaluated against the real value
+	matched, err := result.Residual.Eval(map[string]any{"national_id": "123-45-6789"})
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if !matched {
+		t.Error("Eval() = false, want true")
+	}
+}
@@ -0,0 +1,71 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// errorCodes is the set of the codes of conversion errors.
+var errorCodes = map[ErrorCode]bool{
+	CodeInvalidSyntax:        true,
+	CodeInvalidType:          true,
+	CodeTypeMismatch:         true,
+	CodeUnauthorizedField:    true,
+	CodeUnsupportedOperation: true,
+	CodeUnsupportedPrecision: true,
+	CodeInvalidTimestamp:     true,
+	CodeInvalidScope:         true,
+	CodeInvalidCoordinates:   true,
+	CodeInvalidValue:         true,
+	CodeOperatorNotAllowed:   true,
+	CodeLimitLength:          true,
+	CodeLimitDepth:           true,
+	CodeLimitInSize:          true,
+	CodeLimitMemory:          true,
+	CodeLimitLikePattern:     true,
+	CodeLimitPredicates:      true,
+	CodeLimitCost:            true,
+	CodeAuditViolation:       true,
+	CodeQuotaExceeded:        true,
+	CodeEmptyFilter:          true,
+	CodeScopeUnavailable:     true,
+	CodeCanceled:             true,
+}
+
+// validateErrorMessages checks that the messages replace the public
+// message of known error codes.
+func validateErrorMessages(messages map[ErrorCode]string) error {
+	for _, code := range slices.Sorted(maps.Keys(messages)) {
+		if !errorCodes[code] {
+			return fmt.Errorf("unknown error code %q", code)
+		}
+		if messages[code] == "" {
+			return fmt.Errorf("error message of %s is empty", code)
+		}
+	}
+	return nil
+}
+
+// localizeError replaces the public message of a conversion error with the
+// message of its code in Config.ErrorMessages.
+func (c *Converter) localizeError(err error) error {
+	if len(c.errorMessages) == 0 || err == nil {
+		return err
+	}
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		return err
+	}
+	message, ok := c.errorMessages[convErr.ErrorCode]
+	if !ok {
+		return err
+	}
+	return &ConversionError{
+		PublicMessage: message,
+		ErrorCode:     convErr.ErrorCode,
+		InternalError: convErr.InternalError,
+		Location:      convErr.Location,
+	}
+}
@@ -0,0 +1,101 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_ErrorMessages(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status": {Type: cel.StringType},
+		"ssn":    {Type: cel.StringType, Masked: true},
+	}
+	messages := map[ErrorCode]string{
+		CodeInvalidSyntax:        "expression de filtre invalide",
+		CodeUnsupportedOperation: "opération de filtre non supportée",
+	}
+	converter, err := NewConverter(Config{FieldDeclarations: fields, ErrorMessages: messages})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		celExpr      string
+		wantMessage  string
+		wantSentinel error
+	}{
+		{
+			name:         "translated message",
+			celExpr:      `statu == "open"`,
+			wantMessage:  "expression de filtre invalide",
+			wantSentinel: ErrInvalidSyntax,
+		},
+		{
+			name:         "translated message of a masked field",
+			celExpr:      `ssn.matches("123-45-6789")`,
+			wantMessage:  "opération de filtre non supportée",
+			wantSentinel: ErrUnsupportedOperation,
+		},
+		{
+			name:         "default message",
+			celExpr:      `status`,
+			wantMessage:  "filter expression must evaluate to boolean",
+			wantSentinel: ErrInvalidType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			if err == nil {
+				t.Fatal("Convert() error = nil, want an error")
+			}
+			if err.Error() != tt.wantMessage {
+				t.Errorf("Convert() error = %q, want %q", err, tt.wantMessage)
+			}
+			if !errors.Is(err, tt.wantSentinel) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.wantSentinel)
+			}
+			var convErr *ConversionError
+			if errors.As(err, &convErr) && convErr.InternalError == nil {
+				t.Error("InternalError = nil, want the internal error to be kept")
+			}
+		})
+	}
+
+	// Translated messages are detailed in development
+	full, err := NewConverter(Config{FieldDeclarations: fields, ErrorMessages: messages, ErrorDetail: ErrorDetailFull})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	_, err = full.Convert(`statu == "open"`)
+	if err == nil || !strings.HasPrefix(err.Error(), "expression de filtre invalide at line 1") {
+		t.Errorf("Convert() error = %v, want the translated message followed by details", err)
+	}
+}
+
+func TestConverter_ErrorMessagesValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages map[ErrorCode]string
+		wantErr  bool
+	}{
+		{name: "no messages", messages: nil},
+		{name: "known code", messages: map[ErrorCode]string{CodeLimitDepth: "filter too complex"}},
+		{name: "unknown code", messages: map[ErrorCode]string{"TOO_DEEP": "filter too complex"}, wantErr: true},
+		{name: "empty message", messages: map[ErrorCode]string{CodeLimitDepth: ""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{ErrorMessages: tt.messages})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewConverter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
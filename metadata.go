@@ -0,0 +1,106 @@
+package cel2squirrel
+
+import (
+	"slices"
+
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+)
+
+// describe records the fields, columns and operators a converted expression
+// uses, and counts its predicates.
+func (c *Converter) describe(expr celast.Expr, result *ConvertResult) {
+	w := walkFields(expr)
+	defer w.release()
+
+	// Reuse the scratch maps of the walker for the columns and operators
+	var fields []string
+	for field := range w.fields {
+		if _, ok := c.fieldDeclarations[field]; ok {
+			fields = append(fields, field)
+			w.columns[c.mapFieldName(field)] = true
+		} else if _, ok := c.collections[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	result.Fields = fields
+	result.Columns = sortedKeys(w.columns)
+
+	clear(w.fields)
+	collectOperators(expr, w.fields)
+	result.Operators = sortedKeys(w.fields)
+	result.Complexity.Predicates = countPredicates(expr)
+	c.reportDeprecations(fields, result)
+}
+
+// collectOperators adds the operators and functions called by an
+// expression to ops, by their CEL syntax, e.g. == or startsWith. The
+// exists() and all() macros are reported as such rather than by their
+// expansion.
+func collectOperators(expr celast.Expr, ops map[string]bool) {
+	switch expr.Kind() {
+	case celast.CallKind:
+		call := expr.AsCall()
+		name := call.FunctionName()
+		if display, ok := operators.FindReverse(name); ok && display != "" {
+			name = display
+		}
+		ops[name] = true
+		if call.IsMemberFunction() {
+			collectOperators(call.Target(), ops)
+		}
+		for _, arg := range call.Args() {
+			collectOperators(arg, ops)
+		}
+	case celast.SelectKind:
+		collectOperators(expr.AsSelect().Operand(), ops)
+	case celast.ListKind:
+		for _, elem := range expr.AsList().Elements() {
+			collectOperators(elem, ops)
+		}
+	case celast.MapKind:
+		for _, entry := range expr.AsMap().Entries() {
+			collectOperators(entry.AsMapEntry().Key(), ops)
+			collectOperators(entry.AsMapEntry().Value(), ops)
+		}
+	case celast.StructKind:
+		for _, field := range expr.AsStruct().Fields() {
+			collectOperators(field.AsStructField().Value(), ops)
+		}
+	case celast.ComprehensionKind:
+		comp := expr.AsComprehension()
+		collectOperators(comp.IterRange(), ops)
+		if pred, all, ok := quantifierPredicate(comp); ok {
+			if all {
+				ops["all"] = true
+			} else {
+				ops["exists"] = true
+			}
+			collectOperators(pred, ops)
+			return
+		}
+		collectOperators(comp.AccuInit(), ops)
+		collectOperators(comp.LoopCondition(), ops)
+		collectOperators(comp.LoopStep(), ops)
+		collectOperators(comp.Result(), ops)
+	}
+}
+
+// countPredicates counts the predicates combined by the logical operators
+// of an expression, e.g. 3 for `a == 1 && (b || !c)`.
+func countPredicates(expr celast.Expr) int {
+	if expr.Kind() != celast.CallKind {
+		return 1
+	}
+	call := expr.AsCall()
+	switch call.FunctionName() {
+	case operators.LogicalAnd, operators.LogicalOr, operators.LogicalNot:
+		count := 0
+		for _, arg := range call.Args() {
+			count += countPredicates(arg)
+		}
+		return count
+	}
+	return 1
+}
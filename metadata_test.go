@@ -0,0 +1,101 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConvertResult_Metadata(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "state"},
+			"age":    {Type: cel.IntType},
+			"name":   {Type: cel.StringType},
+			"email":  {Type: cel.StringType, Expr: "LOWER(email)"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		celExpr        string
+		wantFields     []string
+		wantColumns    []string
+		wantOperators  []string
+		wantPredicates int
+		wantDepth      int
+	}{
+		{
+			name:           "single comparison",
+			celExpr:        `age > 18`,
+			wantFields:     []string{"age"},
+			wantColumns:    []string{"age"},
+			wantOperators:  []string{">"},
+			wantPredicates: 1,
+			wantDepth:      2,
+		},
+		{
+			name:           "logical operators",
+			celExpr:        `status == "active" && (name.startsWith("a") || !(age in [1, 2]))`,
+			wantFields:     []string{"age", "name", "status"},
+			wantColumns:    []string{"age", "name", "state"},
+			wantOperators:  []string{"!", "&&", "==", "in", "startsWith", "||"},
+			wantPredicates: 3,
+			wantDepth:      6,
+		},
+		{
+			name:           "computed field",
+			celExpr:        `email == "a@example.com" && email != "b@example.com"`,
+			wantFields:     []string{"email"},
+			wantColumns:    []string{"LOWER(email)"},
+			wantOperators:  []string{"!=", "&&", "=="},
+			wantPredicates: 2,
+			wantDepth:      3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Fields, tt.wantFields) {
+				t.Errorf("Fields = %v, want %v", result.Fields, tt.wantFields)
+			}
+			if !reflect.DeepEqual(result.Columns, tt.wantColumns) {
+				t.Errorf("Columns = %v, want %v", result.Columns, tt.wantColumns)
+			}
+			if !reflect.DeepEqual(result.Operators, tt.wantOperators) {
+				t.Errorf("Operators = %v, want %v", result.Operators, tt.wantOperators)
+			}
+			if result.Complexity.Predicates != tt.wantPredicates {
+				t.Errorf("Complexity.Predicates = %d, want %d", result.Complexity.Predicates, tt.wantPredicates)
+			}
+			if result.Complexity.Depth != tt.wantDepth {
+				t.Errorf("Complexity.Depth = %d, want %d", result.Complexity.Depth, tt.wantDepth)
+			}
+		})
+	}
+}
+
+func TestConvertResult_Metadata_Collections(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{})
+	result, err := converter.Convert(`status == "open" && comments.exists(c, c.body.contains("spam"))`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if want := []string{"comments", "status"}; !reflect.DeepEqual(result.Fields, want) {
+		t.Errorf("Fields = %v, want %v", result.Fields, want)
+	}
+	if want := []string{"&&", "==", "contains", "exists"}; !reflect.DeepEqual(result.Operators, want) {
+		t.Errorf("Operators = %v, want %v", result.Operators, want)
+	}
+	if result.Complexity.Predicates != 2 {
+		t.Errorf("Complexity.Predicates = %d, want 2", result.Complexity.Predicates)
+	}
+}
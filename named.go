@@ -0,0 +1,95 @@
+package cel2squirrel
+
+import (
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// namedStopWords are the SQL words parameters are never named after.
+var namedStopWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "VALUES": true, "ROW": true, "EXISTS": true,
+}
+
+// NamedSql renders the filter with named parameters prefixed with marker,
+// e.g. status = :status_0 with ":" as used by sqlx and Oracle drivers, or
+// status = @status_0 with "@" as used by SQL Server, for drivers and ORMs
+// preferring named binding over positional placeholders. Parameters are
+// named after the column they are compared with, numbered in order of
+// appearance, and returned by name.
+func (r *ConvertResult) NamedSql(marker string) (string, map[string]interface{}, error) {
+	if marker == "" {
+		return "", nil, fmt.Errorf("a parameter marker is required")
+	}
+	if r.Where == nil {
+		return "", nil, fmt.Errorf("result has no WHERE clause")
+	}
+	query, args, err := r.Where.ToSql()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render SQL: %w", err)
+	}
+
+	var (
+		named  = make(map[string]interface{}, len(args))
+		counts = make(map[string]int)
+		out    strings.Builder
+		name   = "p"
+		next   int
+	)
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(query[i+1:], ch)
+			if end < 0 {
+				return "", nil, fmt.Errorf("unterminated quote at offset %d", i)
+			}
+			out.WriteString(query[i : i+end+2])
+			i += end + 2
+
+		case ch == '?':
+			if next >= len(args) {
+				return "", nil, fmt.Errorf("more placeholders than values")
+			}
+			param := fmt.Sprintf("%s_%d", name, counts[name])
+			counts[name]++
+			named[param] = args[next]
+			next++
+			out.WriteString(marker + param)
+			i++
+
+		case isIdentifierStart(ch):
+			j := i + 1
+			for j < len(query) && (isIdentifierStart(query[j]) || isDigit(query[j])) {
+				j++
+			}
+			ident := query[i:j]
+			if upper := strings.ToUpper(ident); !sqlKeywords[upper] && !namedStopWords[upper] {
+				name = strings.ToLower(ident)
+			}
+			out.WriteString(ident)
+			i = j
+
+		default:
+			out.WriteByte(ch)
+			i++
+		}
+	}
+	if next != len(args) {
+		return "", nil, fmt.Errorf("%d values for %d placeholders", len(args), next)
+	}
+	return out.String(), named, nil
+}
+
+// NamedArgs returns named values as sql.NamedArg values sorted by name, to
+// be passed to database/sql along with SQL rendered by NamedSql with the
+// driver's marker, such as "@" for SQL Server.
+func NamedArgs(named map[string]interface{}) []interface{} {
+	args := make([]interface{}, 0, len(named))
+	for _, name := range slices.Sorted(maps.Keys(named)) {
+		args = append(args, sql.Named(name, named[name]))
+	}
+	return args
+}
@@ -0,0 +1,85 @@
+package cel2squirrel
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConvertResult_NamedSql(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType, Column: "user_age", Table: "u"},
+			"email":  {Type: cel.StringType},
+		},
+		UseBetween: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		celExpr   string
+		marker    string
+		wantSQL   string
+		wantNamed map[string]interface{}
+	}{
+		{
+			name:      "equality",
+			celExpr:   `status == "active"`,
+			marker:    ":",
+			wantSQL:   "status = :status_0",
+			wantNamed: map[string]interface{}{"status_0": "active"},
+		},
+		{
+			name:      "IN list and range",
+			celExpr:   `status in ["a", "b"] && (age >= 18 && age <= 30)`,
+			marker:    "@",
+			wantSQL:   "(status IN (@status_0,@status_1) AND u.user_age BETWEEN @user_age_0 AND @user_age_1)",
+			wantNamed: map[string]interface{}{"status_0": "a", "status_1": "b", "user_age_0": int64(18), "user_age_1": int64(30)},
+		},
+		{
+			name:      "function",
+			celExpr:   `email.contains("?") || status == "x"`,
+			marker:    ":",
+			wantSQL:   "(email LIKE :email_0 OR status = :status_0)",
+			wantNamed: map[string]interface{}{"email_0": "%?%", "status_0": "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, named, err := result.NamedSql(tt.marker)
+			if err != nil {
+				t.Fatalf("NamedSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("NamedSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(named, tt.wantNamed) {
+				t.Errorf("NamedSql() named = %v, want %v", named, tt.wantNamed)
+			}
+		})
+	}
+
+	result, _ := converter.Convert(`status == "x"`)
+	if _, _, err := result.NamedSql(""); err == nil {
+		t.Error("NamedSql() should require a marker")
+	}
+}
+
+func TestNamedArgs(t *testing.T) {
+	got := NamedArgs(map[string]interface{}{"status_1": "b", "status_0": "a"})
+	want := []interface{}{sql.Named("status_0", "a"), sql.Named("status_1", "b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NamedArgs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package cel2squirrel
+
+import (
+	"github.com/Masterminds/squirrel"
+)
+
+// negate pushes a logical negation into a Sqlizer: comparisons are inverted
+// (= to <>, < to >=, LIKE to NOT LIKE, IN to NOT IN, IS NULL to IS NOT NULL),
+// AND/OR are rewritten with De Morgan's laws and double negations cancel out.
+// Anything else is wrapped in NOT (...).
+func negate(sqlizer squirrel.Sqlizer) squirrel.Sqlizer {
+	switch s := sqlizer.(type) {
+	case squirrel.Eq:
+		if len(s) == 1 {
+			return squirrel.NotEq(s)
+		}
+	case squirrel.NotEq:
+		if len(s) == 1 {
+			return squirrel.Eq(s)
+		}
+	case squirrel.Lt:
+		if len(s) == 1 {
+			return squirrel.GtOrEq(s)
+		}
+	case squirrel.GtOrEq:
+		if len(s) == 1 {
+			return squirrel.Lt(s)
+		}
+	case squirrel.LtOrEq:
+		if len(s) == 1 {
+			return squirrel.Gt(s)
+		}
+	case squirrel.Gt:
+		if len(s) == 1 {
+			return squirrel.LtOrEq(s)
+		}
+	case squirrel.Like:
+		if len(s) == 1 {
+			return squirrel.NotLike(s)
+		}
+	case squirrel.NotLike:
+		if len(s) == 1 {
+			return squirrel.Like(s)
+		}
+	case squirrel.ILike:
+		if len(s) == 1 {
+			return squirrel.NotILike(s)
+		}
+	case squirrel.NotILike:
+		if len(s) == 1 {
+			return squirrel.ILike(s)
+		}
+	case squirrel.And:
+		negated := make(squirrel.Or, len(s))
+		for i, operand := range s {
+			negated[i] = negate(operand)
+		}
+		return negated
+	case squirrel.Or:
+		negated := make(squirrel.And, len(s))
+		for i, operand := range s {
+			negated[i] = negate(operand)
+		}
+		return negated
+	case *likeExpr:
+		negated := *s
+		negated.not = !s.not
+		return &negated
+	case *valuesIn:
+		negated := *s
+		negated.not = !s.not
+		return &negated
+	case *subqueryIn:
+		negated := *s
+		negated.not = !s.not
+		return &negated
+	case *boolColumn:
+		negated := *s
+		negated.not = !s.not
+		return &negated
+	case *notSqlizer:
+		return s.inner
+	}
+
+	return &notSqlizer{inner: sqlizer}
+}
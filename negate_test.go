@@ -0,0 +1,63 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_PushDownNot(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"a":         {Type: cel.IntType, Column: "a"},
+			"is_draft":  {Type: cel.BoolType, Column: "is_draft"},
+			"label":     {Type: cel.StringType, Column: "label"},
+			"status":    {Type: cel.StringType, Column: "status"},
+			"deletedAt": {Type: cel.TimestampType, Column: "deleted_at"},
+		},
+		PushDownNot: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		wantSQL string
+	}{
+		{name: "equality", celExpr: `!(a == 1)`, wantSQL: "a <> ?"},
+		{name: "inequality", celExpr: `!(a != 1)`, wantSQL: "a = ?"},
+		{name: "less than", celExpr: `!(a < 1)`, wantSQL: "a >= ?"},
+		{name: "less or equal", celExpr: `!(a <= 1)`, wantSQL: "a > ?"},
+		{name: "greater than", celExpr: `!(a > 1)`, wantSQL: "a <= ?"},
+		{name: "greater or equal", celExpr: `!(a >= 1)`, wantSQL: "a < ?"},
+		{name: "null check", celExpr: `!(deletedAt == null)`, wantSQL: "deleted_at IS NOT NULL"},
+		{name: "IN list", celExpr: `!(status in ["a", "b"])`, wantSQL: "status NOT IN (?,?)"},
+		{name: "contains", celExpr: `!label.contains("x")`, wantSQL: "label NOT LIKE ?"},
+		{name: "boolean field", celExpr: `!is_draft`, wantSQL: "is_draft <> ?"},
+		{name: "De Morgan AND", celExpr: `!(a == 1 && is_draft)`, wantSQL: "(a <> ? OR is_draft <> ?)"},
+		{name: "De Morgan OR", celExpr: `!(a > 1 || label.startsWith("p"))`, wantSQL: "(a <= ? AND label NOT LIKE ?)"},
+		{name: "double negation", celExpr: `!!(a == 1)`, wantSQL: "a = ?"},
+		{name: "nested", celExpr: `!(a == 1 && !(status == "x" || is_draft))`, wantSQL: "(a <> ? OR (status = ? OR is_draft = ?))"},
+		{name: "constant", celExpr: `!true`, wantSQL: "NOT (TRUE)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// operand is the column side of a predicate: a SQL expression built from
+// field references, conversions and arithmetic, with the values it binds.
+type operand struct {
+	// field is the CEL field read by a plain or converted field reference.
+	// It is empty for arithmetic expressions and map lookups.
+	field string
+	// sql is the SQL expression.
+	sql string
+	// args are the values bound by placeholders within sql.
+	args []interface{}
+	// castTo is the CEL type of the outermost conversion or string
+	// function, if any.
+	castTo string
+}
+
+// arithmeticOperators maps CEL arithmetic functions to SQL operators.
+var arithmeticOperators = map[string]string{
+	"_+_": "+",
+	"_-_": "-",
+	"_*_": "*",
+	"_/_": "/",
+	"_%_": "%",
+}
+
+// getColumnExpr resolves the column side of a predicate. Field references are
+// mapped to their SQL column; int(), double() and string() conversions of
+// numbers and strings are rendered as a SQL CAST of their operand, doubles
+// being truncated toward zero first; numeric arithmetic over fields and
+// literals is rendered as SQL arithmetic with the literals bound as arguments;
+// optional map lookups with a default are rendered as COALESCE and the size of
+// list fields as the dialect's array length function.
+func (c *Converter) getColumnExpr(expr celast.Expr) (operand, error) {
+	if expr.Kind() != celast.CallKind {
+		field, err := c.getFieldName(expr)
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{field: field, sql: c.mapFieldName(field)}, nil
+	}
+	call := expr.AsCall()
+	function, args, member := call.FunctionName(), call.Args(), call.IsMemberFunction()
+
+	if !member {
+		if op, ok := arithmeticOperators[function]; ok && len(args) == 2 {
+			return c.getArithmeticExpr(op, args)
+		}
+		if function == "-_" && len(args) == 1 {
+			inner, err := c.getArithmeticOperand(args[0])
+			if err != nil {
+				return operand{}, err
+			}
+			return operand{sql: fmt.Sprintf("(-%s)", inner.sql), args: inner.args}, nil
+		}
+	}
+
+	if function == "size" {
+		if member && len(args) == 0 {
+			return c.getSizeExpr(call.Target())
+		}
+		if !member && len(args) == 1 {
+			return c.getSizeExpr(args[0])
+		}
+	}
+
+	if function == "orValue" && member {
+		return c.getOrValueExpr(call)
+	}
+
+	if _, ok := stringExtFunctions[function]; ok && c.stringExtensions && member {
+		return c.getStringExtExpr(call)
+	}
+
+	sqlType, ok := c.dialect.castType(function)
+	if !ok || member || len(args) != 1 {
+		return operand{}, fmt.Errorf("expression is not a field identifier: %s", exprKindName(expr))
+	}
+
+	inner, err := c.getColumnExpr(args[0])
+	if err != nil {
+		return operand{}, err
+	}
+	source := c.operandType(inner)
+	if source != "" && !castSources[source][function] {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("%s() of a %s has no SQL translation", function, source),
+		)
+	}
+	if function == "int" && source != "int" && source != "uint" && source != "string" {
+		// Doubles, or numbers of unknown type
+		sql, args := expandOperands(c.dialect.truncate(), inner)
+		inner = operand{field: inner.field, sql: sql, args: args}
+	}
+	return operand{
+		field:  inner.field,
+		sql:    fmt.Sprintf("CAST(%s AS %s)", inner.sql, sqlType),
+		args:   inner.args,
+		castTo: function,
+	}, nil
+}
+
+// operandType returns the CEL type of an operand, or "" when unknown, e.g.
+// for arithmetic and map lookups.
+func (c *Converter) operandType(o operand) string {
+	if o.castTo != "" {
+		return o.castTo
+	}
+	mapping, ok := c.fieldDeclarations[o.field]
+	if o.field == "" || !ok || mapping.Type == nil {
+		return ""
+	}
+	switch mapping.Type.Kind() {
+	case types.IntKind:
+		return "int"
+	case types.UintKind:
+		return "uint"
+	case types.DoubleKind:
+		return "double"
+	case types.StringKind:
+		return "string"
+	case types.BoolKind:
+		return "bool"
+	case types.TimestampKind:
+		return "timestamp"
+	case types.DurationKind:
+		return "duration"
+	}
+	return mapping.Type.String()
+}
+
+// getArithmeticExpr renders a binary arithmetic expression.
+func (c *Converter) getArithmeticExpr(op string, args []celast.Expr) (operand, error) {
+	left, err := c.getArithmeticOperand(args[0])
+	if err != nil {
+		return operand{}, err
+	}
+
+	right, err := c.getArithmeticOperand(args[1])
+	if err != nil {
+		return operand{}, err
+	}
+
+	return operand{
+		sql:  fmt.Sprintf("(%s %s %s)", left.sql, op, right.sql),
+		args: append(left.bound(), right.args...),
+	}, nil
+}
+
+// getArithmeticOperand resolves one side of an arithmetic expression: a
+// numeric literal or constant subexpression, bound as a single argument, or a
+// numeric column expression.
+func (c *Converter) getArithmeticOperand(expr celast.Expr) (operand, error) {
+	if expr.Kind() == celast.LiteralKind || isConstantArithmetic(expr) {
+		value, err := c.getConstantValue(expr)
+		if err != nil {
+			return operand{}, err
+		}
+		switch value.(type) {
+		case int64, uint64, float64:
+			return operand{sql: "?", args: []interface{}{value}}, nil
+		default:
+			return operand{}, fmt.Errorf("arithmetic requires numeric operands, got %T", value)
+		}
+	}
+
+	inner, err := c.getColumnExpr(expr)
+	if err != nil {
+		return operand{}, err
+	}
+
+	// Only numeric columns, numeric conversions and nested arithmetic
+	numeric := inner.field == "" && inner.castTo == ""
+	switch {
+	case inner.castTo != "":
+		numeric = inner.castTo == "int" || inner.castTo == "double"
+	case inner.field != "":
+		if mapping, ok := c.fieldDeclarations[inner.field]; ok && mapping.Type != nil {
+			switch mapping.Type.String() {
+			case "int", "uint", "double":
+				numeric = true
+			}
+		}
+	}
+	if !numeric {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("arithmetic requires numeric operands: %s", inner.sql),
+		)
+	}
+
+	return inner, nil
+}
+
+// getFieldArgument resolves a function argument referencing another field to
+// its SQL column. It reports false for constants and unsupported expressions.
+func (c *Converter) getFieldArgument(expr celast.Expr) (operand, bool) {
+	if expr.Kind() == celast.LiteralKind {
+		return operand{}, false
+	}
+	arg, err := c.getColumnExpr(expr)
+	return arg, err == nil
+}
+
+// bound returns a copy of the operand's arguments that can be appended to.
+func (o operand) bound() []interface{} {
+	return append([]interface{}(nil), o.args...)
+}
+
+// compare renders a comparison of a computed operand against a value.
+func (o operand) compare(op string, value interface{}) (squirrel.Sqlizer, error) {
+	if value == nil {
+		switch op {
+		case "=", "==":
+			return squirrel.Expr(o.sql+" IS NULL", o.bound()...), nil
+		case "!=":
+			return squirrel.Expr(o.sql+" IS NOT NULL", o.bound()...), nil
+		}
+	}
+
+	switch op {
+	case "==":
+		op = "="
+	case "!=":
+		op = "<>"
+	case "=", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("unsupported comparison operator: %s", op)
+	}
+
+	return squirrel.Expr(fmt.Sprintf("%s %s ?", o.sql, op), append(o.bound(), value)...), nil
+}
+
+// in renders a membership test of the operand against a list of values.
+func (o operand) in(values []interface{}) squirrel.Sqlizer {
+	if len(values) == 0 {
+		return squirrel.Expr("(1=0)")
+	}
+	return squirrel.Expr(
+		fmt.Sprintf("%s IN (%s)", o.sql, squirrel.Placeholders(len(values))),
+		append(o.bound(), values...)...,
+	)
+}
+
+// like renders a LIKE match of the operand against a bound pattern.
+func (o operand) like(pattern string) squirrel.Sqlizer {
+	if len(o.args) == 0 {
+		return squirrel.Like{o.sql: pattern}
+	}
+	return squirrel.Expr(o.sql+" LIKE ?", append(o.bound(), pattern)...)
+}
+
+// ilike renders a case-insensitive LIKE match of the operand against a bound
+// pattern: ILIKE on PostgreSQL, LOWER(operand) LIKE LOWER(?) elsewhere.
+func (o operand) ilike(pattern string, dialect Dialect) squirrel.Sqlizer {
+	if dialect == DialectPostgreSQL {
+		if len(o.args) == 0 {
+			return squirrel.ILike{o.sql: pattern}
+		}
+		return squirrel.Expr(o.sql+" ILIKE ?", append(o.bound(), pattern)...)
+	}
+	return squirrel.Expr(fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", o.sql), append(o.bound(), pattern)...)
+}
+
+// expandOperands renders a SQL template referencing operands with %[n]s
+// verbs, binding the arguments of each reference in order of appearance.
+func expandOperands(template string, operands ...operand) (string, []interface{}) {
+	var args []interface{}
+	for rest := template; ; {
+		i := strings.Index(rest, "%[")
+		if i < 0 || i+2 >= len(rest) {
+			break
+		}
+		if n := int(rest[i+2] - '1'); n >= 0 && n < len(operands) {
+			args = append(args, operands[n].args...)
+		}
+		rest = rest[i+2:]
+	}
+	sqls := make([]interface{}, len(operands))
+	for i, o := range operands {
+		sqls[i] = o.sql
+	}
+	return fmt.Sprintf(template, sqls...), args
+}
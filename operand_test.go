@@ -0,0 +1,142 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_Arithmetic(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"price":    {Type: cel.IntType, Column: "unit_price"},
+			"quantity": {Type: cel.IntType, Column: "qty"},
+			"credits":  {Type: cel.IntType, Column: "credits"},
+			"used":     {Type: cel.IntType, Column: "used"},
+			"ratio":    {Type: cel.DoubleType, Column: "ratio"},
+			"name":     {Type: cel.StringType, Column: "name"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "product of columns",
+			celExpr:  `price * quantity > 100`,
+			wantSQL:  "(unit_price * qty) > ?",
+			wantArgs: []any{int64(100)},
+		},
+		{
+			name:     "difference of columns",
+			celExpr:  `credits - used >= 0`,
+			wantSQL:  "(credits - used) >= ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "constant operand",
+			celExpr:  `price + 5 == 10`,
+			wantSQL:  "(unit_price + ?) = ?",
+			wantArgs: []any{int64(5), int64(10)},
+		},
+		{
+			name:     "nested arithmetic",
+			celExpr:  `(price - 1) * quantity % 3 != 0`,
+			wantSQL:  "(((unit_price - ?) * qty) % ?) <> ?",
+			wantArgs: []any{int64(1), int64(3), int64(0)},
+		},
+		{
+			name:     "unary minus",
+			celExpr:  `-credits < 0`,
+			wantSQL:  "(-credits) < ?",
+			wantArgs: []any{int64(0)},
+		},
+		{
+			name:     "division with conversion",
+			celExpr:  `double(used) / ratio <= 1.5`,
+			wantSQL:  "(CAST(used AS DOUBLE PRECISION) / ratio) <= ?",
+			wantArgs: []any{1.5},
+		},
+		{
+			name:     "IN list",
+			celExpr:  `price * 2 in [4, 6]`,
+			wantSQL:  "(unit_price * ?) IN (?,?)",
+			wantArgs: []any{int64(2), int64(4), int64(6)},
+		},
+		{
+			name:     "cast of arithmetic with LIKE",
+			celExpr:  `string(price * quantity).startsWith("1")`,
+			wantSQL:  "CAST((unit_price * qty) AS VARCHAR) LIKE ?",
+			wantArgs: []any{"1%"},
+		},
+		{
+			name:     "combined with other predicates",
+			celExpr:  `credits - used > 0 && name == "x"`,
+			wantSQL:  "((credits - used) > ? AND name = ?)",
+			wantArgs: []any{int64(0), "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+
+			for i, arg := range args {
+				if arg != tt.wantArgs[i] {
+					t.Errorf("arg %d = %v (%T), want %v (%T)", i, arg, arg, tt.wantArgs[i], tt.wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_ArithmeticErrors(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":  {Type: cel.StringType, Column: "name"},
+			"other": {Type: cel.StringType, Column: "other"},
+			"price": {Type: cel.IntType, Column: "price"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+	}{
+		{name: "string concatenation", celExpr: `name + other == "ab"`},
+		{name: "string conversion operand", celExpr: `string(price) + "x" == "1x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := converter.Convert(tt.celExpr); err == nil {
+				t.Errorf("Convert(%q) should fail", tt.celExpr)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/types"
+)
+
+// getOrValueExpr renders an optional map lookup with a default, such as
+// `metadata[?"region"].orValue("us")` or `metadata.?region.orValue("us")`,
+// as COALESCE over the dialect's JSON text extraction of the key, with the
+// default bound as an argument.
+func (c *Converter) getOrValueExpr(call celast.CallExpr) (operand, error) {
+	if len(call.Args()) != 1 {
+		return operand{}, fmt.Errorf("orValue() requires exactly 1 argument, got %d", len(call.Args()))
+	}
+
+	lookup := call.Target().AsCall()
+	lookupArgs := lookup.Args()
+	if len(lookupArgs) != 2 || (lookup.FunctionName() != "_[?_]" && lookup.FunctionName() != "_?._") {
+		return operand{}, fmt.Errorf("orValue() requires an optional map lookup")
+	}
+
+	field, err := c.getFieldName(lookupArgs[0])
+	if err != nil {
+		return operand{}, err
+	}
+
+	key, ok := lookupArgs[1].AsLiteral().(types.String)
+	if !ok || !isJSONKey(string(key)) {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("optional lookup on %s requires an identifier-like string key", field),
+		)
+	}
+
+	extract, ok := c.dialect.jsonText(c.mapFieldName(field), string(key))
+	if !ok {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("dialect %q does not support JSON key extraction", c.dialect),
+		)
+	}
+
+	fallback, err := c.getConstantValue(call.Args()[0])
+	if err != nil {
+		return operand{}, err
+	}
+
+	return operand{
+		sql:  fmt.Sprintf("COALESCE(%s, ?)", extract),
+		args: []interface{}{fallback},
+	}, nil
+}
+
+// isJSONKey reports whether key can be embedded in a JSON extraction
+// expression: an ASCII letter or underscore followed by letters, digits or
+// underscores.
+func isJSONKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_OptionalOrValue(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"metadata": {Type: cel.MapType(cel.StringType, cel.StringType), Column: "meta"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "optional index",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `metadata[?"region"].orValue("us") == "eu"`,
+			wantSQL:  "COALESCE(meta->>'region', ?) = ?",
+			wantArgs: []any{"us", "eu"},
+		},
+		{
+			name:     "optional select",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `metadata.?region.orValue("us") != "eu"`,
+			wantSQL:  "COALESCE(meta->>'region', ?) <> ?",
+			wantArgs: []any{"us", "eu"},
+		},
+		{
+			name:     "mysql",
+			dialect:  DialectMySQL,
+			celExpr:  `metadata[?"tier"].orValue("free") in ["pro", "team"]`,
+			wantSQL:  "COALESCE(JSON_UNQUOTE(JSON_EXTRACT(meta, '$.tier')), ?) IN (?,?)",
+			wantArgs: []any{"free", "pro", "team"},
+		},
+		{
+			name:     "sqlite",
+			dialect:  DialectSQLite,
+			celExpr:  `metadata[?"region"].orValue("us").startsWith("eu")`,
+			wantSQL:  `COALESCE(json_extract(meta, '$.region'), ?) LIKE ? ESCAPE '\'`,
+			wantArgs: []any{"us", "eu%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_OptionalOrValueErrors(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"metadata": {Type: cel.MapType(cel.StringType, cel.StringType), Column: "meta"},
+	}
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		celExpr string
+	}{
+		{name: "unsupported dialect", dialect: DialectDefault, celExpr: `metadata[?"region"].orValue("us") == "eu"`},
+		{name: "quote in key", dialect: DialectPostgreSQL, celExpr: `metadata[?"x' OR '1"].orValue("us") == "eu"`},
+		{name: "empty key", dialect: DialectPostgreSQL, celExpr: `metadata[?""].orValue("us") == "eu"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{FieldDeclarations: fields, Dialect: tt.dialect})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			if _, err := converter.Convert(tt.celExpr); err == nil {
+				t.Errorf("Convert(%q) should fail", tt.celExpr)
+			}
+		})
+	}
+}
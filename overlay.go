@@ -0,0 +1,107 @@
+package cel2squirrel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ConvertOption customizes a single conversion.
+type ConvertOption func(*convertOptions)
+
+// convertOptions are the options of a conversion.
+type convertOptions struct {
+	overlay             map[string]string
+	noCache             bool
+	noContextConditions bool
+}
+
+// WithMappingOverlay converts the filter against alternate columns for some
+// fields, e.g. those of a materialized view or rollup table with different
+// column names, so that one filter surface serves the base table and its
+// views. columns maps declared field names to SQL columns or expressions,
+// used verbatim: they are trusted configuration, are not qualified with a
+// table and do not require the field's join. Overlaid fields keep their other
+// settings, such as their collation, transform or access; the other fields
+// keep their configured columns.
+func WithMappingOverlay(columns map[string]string) ConvertOption {
+	return func(options *convertOptions) {
+		if options.overlay == nil {
+			options.overlay = make(map[string]string, len(columns))
+		}
+		maps.Copy(options.overlay, columns)
+	}
+}
+
+// withOptions returns the converter to use for a conversion with options:
+// c itself without options, or a copy reading the overlaid columns,
+// bypassing the cache or without the conditions of MandatoryConditionsFunc.
+func (c *Converter) withOptions(opts []ConvertOption) (*Converter, error) {
+	if len(opts) == 0 {
+		return c, nil
+	}
+	var options convertOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.noCache && c.cache != nil {
+		uncached := *c
+		uncached.cache = nil
+		c = &uncached
+	}
+	if options.noContextConditions && c.mandatoryFunc != nil {
+		unscoped := *c
+		unscoped.mandatoryFunc = nil
+		c = &unscoped
+	}
+	if len(options.overlay) == 0 {
+		return c, nil
+	}
+
+	// Results are cached by expression, regardless of the overlay
+	overlaid := *c
+	overlaid.cache = nil
+	overlaid.columnMappings = maps.Clone(c.columnMappings)
+	overlaid.fieldJoins = maps.Clone(c.fieldJoins)
+	overlaid.keyColumns = maps.Clone(c.keyColumns)
+	overlaid.partialIndexes = maps.Clone(c.partialIndexes)
+
+	h := sha256.New()
+	writeKeyPart(h, c.schema)
+	var trusted []string
+	for _, field := range slices.Sorted(maps.Keys(options.overlay)) {
+		if _, ok := c.fieldDeclarations[field]; !ok {
+			return nil, fmt.Errorf("invalid mapping overlay: field %s is not declared", field)
+		}
+		column, err := sqlExprColumn(field, ColumnMapping{Expr: options.overlay[field]})
+		if err != nil {
+			return nil, fmt.Errorf("invalid mapping overlay: %w", err)
+		}
+		// Only the column is overlaid: the field keeps its collation
+		if collation := c.fieldDeclarations[field].Collation; collation != "" {
+			if column, err = c.dialect.collate(column, collation); err != nil {
+				return nil, fmt.Errorf("invalid mapping overlay: field %s: %w", field, err)
+			}
+		}
+
+		original := c.columnMappings[field]
+		if c.keyColumns[original] {
+			overlaid.keyColumns[column] = true
+		}
+		// Partial indexes of the base table do not apply to the overlay
+		delete(overlaid.partialIndexes, original)
+		delete(overlaid.fieldJoins, field)
+		overlaid.columnMappings[field] = column
+		trusted = append(trusted, column)
+
+		writeKeyPart(h, field)
+		writeKeyPart(h, column)
+	}
+	overlaid.schema = hex.EncodeToString(h.Sum(nil))
+	if c.auditor != nil {
+		overlaid.auditor = c.auditor.with(trusted...)
+	}
+	return &overlaid, nil
+}
@@ -0,0 +1,117 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_MappingOverlay(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "order_status"},
+			"amount": {Type: cel.DoubleType, Column: "amount_cents"},
+			"author": {
+				Type:   cel.StringType,
+				Column: "name",
+				Join:   &JoinSpec{Table: "users", On: "orders.author_id = users.id"},
+			},
+		},
+		TableAlias: "orders",
+		KeyFields:  []string{"status"},
+		AuditSQL:   true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	celExpr := `status == "paid" && amount > 10.0 && author == "ada"`
+	base, err := converter.Convert(celExpr)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, _, _ := base.Where.ToSql()
+	if want := "((orders.order_status = ? AND orders.amount_cents > ?) AND users.name = ?)"; sql != want {
+		t.Errorf("base SQL = %q, want %q", sql, want)
+	}
+
+	overlay := WithMappingOverlay(map[string]string{
+		"status": "v.status",
+		"author": "author_name",
+		"amount": "amount_cents / 100.0",
+	})
+	view, err := converter.Convert(celExpr, overlay)
+	if err != nil {
+		t.Fatalf("Convert() with overlay error = %v", err)
+	}
+	sql, _, _ = view.Where.ToSql()
+	if want := "((v.status = ? AND (amount_cents / 100.0) > ?) AND author_name = ?)"; sql != want {
+		t.Errorf("overlay SQL = %q, want %q", sql, want)
+	}
+	if len(view.Joins) != 0 {
+		t.Errorf("overlay Joins = %v, want none", view.Joins)
+	}
+	if view.Class != ClassPointLookup {
+		t.Errorf("overlay Class = %q, want %q", view.Class, ClassPointLookup)
+	}
+
+	baseKey, _ := base.CacheKey()
+	viewKey, _ := view.CacheKey()
+	if baseKey == viewKey {
+		t.Error("overlay results should not share the cache key of base results")
+	}
+
+	// The converter itself is unchanged
+	again, err := converter.ConvertWithAuth(celExpr, nil)
+	if err != nil {
+		t.Fatalf("ConvertWithAuth() error = %v", err)
+	}
+	if sql, _, _ := again.Where.ToSql(); sql != "((orders.order_status = ? AND orders.amount_cents > ?) AND users.name = ?)" {
+		t.Errorf("SQL after overlay = %q", sql)
+	}
+
+	for name, columns := range map[string]map[string]string{
+		"undeclared field": {"unknown": "x"},
+		"placeholder":      {"status": "?"},
+		"comment":          {"status": "status -- x"},
+		"statement":        {"status": "status; DROP TABLE orders"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := converter.Convert(celExpr, WithMappingOverlay(columns)); err == nil {
+				t.Error("Convert() should reject the overlay")
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_MappingOverlayKeepsFieldSettings(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name": {Type: cel.StringType, Column: "user_name", Collation: "und-x-icu"},
+			"email": {
+				Type:      cel.StringType,
+				Transform: func(value any) (any, error) { return strings.ToLower(value.(string)), nil },
+			},
+		},
+		Dialect:  DialectPostgreSQL,
+		AuditSQL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`name == "ada" && email == "ADA@EXAMPLE.COM"`,
+		WithMappingOverlay(map[string]string{"name": "v.name", "email": "v.email"}))
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, args, _ := result.Where.ToSql()
+	if want := `(v.name COLLATE "und-x-icu" = ? AND v.email = ?)`; sql != want {
+		t.Errorf("overlay SQL = %q, want %q", sql, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"ada", "ada@example.com"}) {
+		t.Errorf("overlay args = %v", args)
+	}
+}
@@ -0,0 +1,134 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// OrderField is a sort key of a keyset-paginated query.
+type OrderField struct {
+	// Column is the SQL column sorted by.
+	Column string
+	// Descending sorts the column in descending order.
+	Descending bool
+	// Nullable marks columns that may hold NULL.
+	Nullable bool
+	// NullsFirst sorts NULL before non-NULL values; NULL sorts last
+	// otherwise. Only meaningful for nullable columns.
+	NullsFirst bool
+}
+
+// Keyset describes the ordering of a keyset-paginated query. Its ORDER BY
+// clauses and cursor predicates agree on where NULL values sort, whatever the
+// dialect's default NULL ordering. The last field should be unique (e.g. the
+// primary key) for the pagination to be stable.
+type Keyset struct {
+	// Dialect selects how NULL ordering is spelled.
+	Dialect Dialect
+	// Fields lists the sort keys, most significant first.
+	Fields []OrderField
+}
+
+// OrderBy returns the ORDER BY clauses of the keyset, e.g. for
+// squirrel.SelectBuilder.OrderBy. NULL ordering is explicit for nullable
+// columns: NULLS FIRST/LAST, or an IS NULL sort key on MySQL which lacks it.
+func (k Keyset) OrderBy() []string {
+	clauses := make([]string, 0, len(k.Fields))
+	for _, field := range k.Fields {
+		direction := "ASC"
+		if field.Descending {
+			direction = "DESC"
+		}
+
+		if !field.Nullable {
+			clauses = append(clauses, field.Column+" "+direction)
+			continue
+		}
+
+		if k.Dialect == DialectMySQL {
+			// NULL sorts first in ascending order: IS NULL is 1 for NULL
+			nulls := "ASC"
+			if field.NullsFirst {
+				nulls = "DESC"
+			}
+			clauses = append(clauses, fmt.Sprintf("%s IS NULL %s", field.Column, nulls), field.Column+" "+direction)
+			continue
+		}
+
+		nulls := "NULLS LAST"
+		if field.NullsFirst {
+			nulls = "NULLS FIRST"
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s %s", field.Column, direction, nulls))
+	}
+	return clauses
+}
+
+// After returns the predicate selecting the rows sorted after the row whose
+// sort key values are cursor, in Fields order. A nil cursor value stands for
+// NULL. Unlike a tuple comparison such as (a, b) > (?, ?), the predicate
+// places NULL values according to NullsFirst.
+func (k Keyset) After(cursor ...interface{}) (Sqlizer, error) {
+	if len(cursor) != len(k.Fields) {
+		return nil, fmt.Errorf("cursor has %d values, keyset has %d fields", len(cursor), len(k.Fields))
+	}
+
+	var (
+		after squirrel.Or
+		equal squirrel.And
+	)
+	for i, field := range k.Fields {
+		value := cursor[i]
+		if value == nil && !field.Nullable {
+			return nil, fmt.Errorf("cursor value for non-nullable column %s is nil", field.Column)
+		}
+
+		if next := field.after(value); next != nil {
+			after = append(after, append(append(squirrel.And{}, equal...), next))
+		}
+		equal = append(equal, field.compare("=", value))
+	}
+
+	if len(after) == 0 {
+		// The cursor is the last row
+		return squirrel.Expr("(1=0)"), nil
+	}
+	return after, nil
+}
+
+// after returns the predicate selecting the values of the field sorted after
+// value, or nil when there are none.
+func (field OrderField) after(value interface{}) squirrel.Sqlizer {
+	if value == nil {
+		if field.NullsFirst {
+			return squirrel.NotEq{field.Column: nil}
+		}
+		return nil
+	}
+
+	next := field.compare(">", value)
+	if field.Descending {
+		next = field.compare("<", value)
+	}
+	if field.Nullable && !field.NullsFirst {
+		return squirrel.Or{next, squirrel.Eq{field.Column: nil}}
+	}
+	return next
+}
+
+// compare returns the comparison of the column with a cursor value. Values
+// implementing driver.Valuer are bound unmodified.
+func (field OrderField) compare(op string, value interface{}) squirrel.Sqlizer {
+	if isValuer(value) {
+		return squirrel.Expr(fmt.Sprintf("%s %s ?", field.Column, op), value)
+	}
+	switch op {
+	case "<":
+		return squirrel.Lt{field.Column: value}
+	case ">":
+		return squirrel.Gt{field.Column: value}
+	default:
+		return squirrel.Eq{field.Column: value}
+	}
+}
@@ -0,0 +1,125 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeyset_OrderBy(t *testing.T) {
+	fields := []OrderField{
+		{Column: "priority", Descending: true, Nullable: true, NullsFirst: true},
+		{Column: "due_at", Nullable: true},
+		{Column: "id"},
+	}
+
+	tests := []struct {
+		dialect Dialect
+		want    []string
+	}{
+		{
+			dialect: DialectPostgreSQL,
+			want:    []string{"priority DESC NULLS FIRST", "due_at ASC NULLS LAST", "id ASC"},
+		},
+		{
+			dialect: DialectSQLite,
+			want:    []string{"priority DESC NULLS FIRST", "due_at ASC NULLS LAST", "id ASC"},
+		},
+		{
+			dialect: DialectMySQL,
+			want:    []string{"priority IS NULL DESC", "priority DESC", "due_at IS NULL ASC", "due_at ASC", "id ASC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			got := Keyset{Dialect: tt.dialect, Fields: fields}.OrderBy()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderBy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyset_After(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []OrderField
+		cursor   []any
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "non-nullable",
+			fields:   []OrderField{{Column: "created_at", Descending: true}, {Column: "id"}},
+			cursor:   []any{int64(10), int64(3)},
+			wantSQL:  "((created_at < ?) OR (created_at = ? AND id > ?))",
+			wantArgs: []any{int64(10), int64(10), int64(3)},
+		},
+		{
+			name:     "nulls last, non-null cursor",
+			fields:   []OrderField{{Column: "due_at", Nullable: true}, {Column: "id"}},
+			cursor:   []any{int64(5), int64(3)},
+			wantSQL:  "(((due_at > ? OR due_at IS NULL)) OR (due_at = ? AND id > ?))",
+			wantArgs: []any{int64(5), int64(5), int64(3)},
+		},
+		{
+			name:     "nulls last, null cursor",
+			fields:   []OrderField{{Column: "due_at", Nullable: true}, {Column: "id"}},
+			cursor:   []any{nil, int64(3)},
+			wantSQL:  "((due_at IS NULL AND id > ?))",
+			wantArgs: []any{int64(3)},
+		},
+		{
+			name:     "nulls first, non-null cursor",
+			fields:   []OrderField{{Column: "due_at", Nullable: true, NullsFirst: true}, {Column: "id"}},
+			cursor:   []any{int64(5), int64(3)},
+			wantSQL:  "((due_at > ?) OR (due_at = ? AND id > ?))",
+			wantArgs: []any{int64(5), int64(5), int64(3)},
+		},
+		{
+			name:     "nulls first, null cursor",
+			fields:   []OrderField{{Column: "due_at", Nullable: true, NullsFirst: true}, {Column: "id"}},
+			cursor:   []any{nil, int64(3)},
+			wantSQL:  "((due_at IS NOT NULL) OR (due_at IS NULL AND id > ?))",
+			wantArgs: []any{int64(3)},
+		},
+		{
+			name:     "last row",
+			fields:   []OrderField{{Column: "due_at", Nullable: true}},
+			cursor:   []any{nil},
+			wantSQL:  "(1=0)",
+			wantArgs: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, err := Keyset{Fields: tt.fields}.After(tt.cursor...)
+			if err != nil {
+				t.Fatalf("After() error = %v", err)
+			}
+
+			sql, args, err := where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestKeyset_AfterErrors(t *testing.T) {
+	keyset := Keyset{Fields: []OrderField{{Column: "name"}, {Column: "id"}}}
+
+	if _, err := keyset.After("a"); err == nil {
+		t.Error("expected error for short cursor")
+	}
+	if _, err := keyset.After(nil, int64(1)); err == nil {
+		t.Error("expected error for nil value of non-nullable column")
+	}
+}
@@ -0,0 +1,147 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// PartialIndex annotates a field whose column is only indexed for the rows
+// matching a condition, e.g. an index created with WHERE deleted_at IS NULL.
+// Filters using the field without restricting rows to the condition cannot
+// use the index: they are reported in ConvertResult.Warnings and classified
+// as if the field was not indexed.
+type PartialIndex struct {
+	// Condition is the index condition as a CEL expression over declared
+	// fields, e.g. `!deleted` for a deleted field with Expr
+	// "deleted_at IS NOT NULL". A filter is covered when its top-level &&
+	// operands include every && operand of Condition.
+	Condition string
+	// Description describes the index in warnings, e.g.
+	// "only indexed where deleted_at IS NULL". Default: "only indexed
+	// where " followed by Condition.
+	Description string
+}
+
+// partialIndex is a compiled PartialIndex.
+type partialIndex struct {
+	field       string
+	description string
+	// conjuncts are the keys of the && operands of the condition.
+	conjuncts []string
+}
+
+// initPartialIndexes compiles the partial index conditions of the declared
+// fields, keyed by column.
+func (c *Converter) initPartialIndexes(fields map[string]ColumnMapping) error {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		annotation := fields[name].PartialIndex
+		if annotation == nil {
+			continue
+		}
+
+		compiled, issues := c.env.Compile(annotation.Condition)
+		if issues != nil && issues.Err() != nil {
+			return fmt.Errorf("field %s: invalid condition: %w", name, issues.Err())
+		}
+		if compiled.OutputType() != cel.BoolType {
+			return fmt.Errorf("field %s: condition must be boolean, got %v", name, compiled.OutputType())
+		}
+		index := &partialIndex{field: name, description: annotation.Description}
+		if index.description == "" {
+			index.description = "only indexed where " + annotation.Condition
+		}
+		for _, conjunct := range splitConjuncts(compiled.NativeRep().Expr()) {
+			key, err := c.conjunctKey(conjunct)
+			if err != nil {
+				return fmt.Errorf("field %s: invalid condition: %w", name, err)
+			}
+			index.conjuncts = append(index.conjuncts, key)
+		}
+
+		if c.partialIndexes == nil {
+			c.partialIndexes = make(map[string]*partialIndex)
+		}
+		c.partialIndexes[c.mapFieldName(name)] = index
+	}
+	return nil
+}
+
+// conjunctKey identifies a predicate by the SQL and arguments it converts
+// to, so that equivalent CEL spellings match.
+func (c *Converter) conjunctKey(expr celast.Expr) (string, error) {
+	sqlizer, err := c.convertExpr(expr)
+	if err != nil {
+		return "", err
+	}
+	sql, args, err := sqlizer.ToSql()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %#v", sql, args), nil
+}
+
+// uncoveredIndexes returns the partial indexes of the columns a filter uses
+// in an indexable way whose condition the filter does not imply.
+func (c *Converter) uncoveredIndexes(expr celast.Expr, usages []ColumnUsage) []*partialIndex {
+	if len(c.partialIndexes) == 0 || expr == nil {
+		return nil
+	}
+
+	var (
+		uncovered []*partialIndex
+		keys      map[string]bool
+	)
+	for _, usage := range usages {
+		index, ok := c.partialIndexes[usage.Column]
+		if !ok || usage.Operator == OperatorPattern || slices.Contains(uncovered, index) {
+			continue
+		}
+		if keys == nil {
+			keys = make(map[string]bool)
+			for _, conjunct := range splitConjuncts(expr) {
+				if key, err := c.conjunctKey(conjunct); err == nil {
+					keys[key] = true
+				}
+			}
+		}
+		for _, key := range index.conjuncts {
+			if !keys[key] {
+				uncovered = append(uncovered, index)
+				break
+			}
+		}
+	}
+	return uncovered
+}
+
+// indexableUsages returns the column usages of a filter, downgrading those
+// on partial indexes the filter does not cover to OperatorPattern.
+func (c *Converter) indexableUsages(expr celast.Expr) []ColumnUsage {
+	usages := c.columnUsages(expr)
+	for _, index := range c.uncoveredIndexes(expr, usages) {
+		column := c.mapFieldName(index.field)
+		for i := range usages {
+			if usages[i].Column == column {
+				usages[i].Operator = OperatorPattern
+			}
+		}
+	}
+	return usages
+}
+
+// warnUncoveredIndexes reports the partial indexes a converted filter does
+// not cover in its warnings.
+func (c *Converter) warnUncoveredIndexes(result *ConvertResult) {
+	if len(c.partialIndexes) == 0 {
+		return
+	}
+	for _, index := range c.uncoveredIndexes(result.expr, c.columnUsages(result.expr)) {
+		result.Warnings = append(result.Warnings, newDiagnostic(DiagnosticUncoveredIndex, index.field,
+			"filter on %s is outside the coverage of its partial index (%s) and cannot use it",
+			index.field, index.description))
+	}
+}
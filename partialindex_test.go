@@ -0,0 +1,126 @@
+package cel2squirrel
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_PartialIndex(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"email": {
+				Type:         cel.StringType,
+				PartialIndex: &PartialIndex{Condition: `!deleted`, Description: "only indexed where deleted_at IS NULL"},
+			},
+			"sku": {
+				Type:         cel.StringType,
+				PartialIndex: &PartialIndex{Condition: `status == "active" && !deleted`},
+			},
+			"deleted": {Type: cel.BoolType, Expr: "deleted_at IS NOT NULL"},
+			"status":  {Type: cel.StringType},
+			"name":    {Type: cel.StringType},
+		},
+		KeyFields: []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		celExpr     string
+		wantWarning string
+		wantClass   ExpressionClass
+	}{
+		{
+			name:      "covered",
+			celExpr:   `email == "a@example.com" && !deleted`,
+			wantClass: ClassPointLookup,
+		},
+		{
+			name:        "uncovered",
+			celExpr:     `email == "a@example.com"`,
+			wantWarning: "filter on email is outside the coverage of its partial index (only indexed where deleted_at IS NULL) and cannot use it",
+			wantClass:   ClassFullScan,
+		},
+		{
+			name:        "condition in a disjunction",
+			celExpr:     `email == "a@example.com" && (!deleted || name == "x")`,
+			wantWarning: "filter on email is outside",
+			wantClass:   ClassFullScan,
+		},
+		{
+			name:      "multi-part condition covered in any order",
+			celExpr:   `!deleted && sku.startsWith("AB") && status == "active"`,
+			wantClass: ClassRangeScan,
+		},
+		{
+			name:        "multi-part condition partially covered",
+			celExpr:     `sku >= "AB" && status == "active"`,
+			wantWarning: "filter on sku is outside the coverage of its partial index (only indexed where status == \"active\" && !deleted)",
+			wantClass:   ClassRangeScan,
+		},
+		{
+			name:      "unindexable use",
+			celExpr:   `email.contains("example")`,
+			wantClass: ClassTextSearch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			uncovered := withCode(result.Warnings, DiagnosticUncoveredIndex)
+			warnings := strings.Join(warningMessages(uncovered), "\n")
+			if tt.wantWarning == "" && warnings != "" {
+				t.Errorf("Warnings = %v, want none", uncovered)
+			}
+			if !strings.Contains(warnings, tt.wantWarning) {
+				t.Errorf("Warnings = %v, want %q", result.Warnings, tt.wantWarning)
+			}
+			if result.Class != tt.wantClass {
+				t.Errorf("Class = %q, want %q", result.Class, tt.wantClass)
+			}
+		})
+	}
+
+	t.Run("covered by a scope", func(t *testing.T) {
+		result, err := converter.ConvertWithScopes(context.Background(), `email == "a@example.com"`, nil,
+			ScopeStack{{Name: "live", Predicate: `!deleted`}})
+		if err != nil {
+			t.Fatalf("ConvertWithScopes() error = %v", err)
+		}
+		if len(result.Warnings) > 0 {
+			t.Errorf("Warnings = %v, want none", result.Warnings)
+		}
+	})
+}
+
+func TestNewConverter_InvalidPartialIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+	}{
+		{name: "undeclared field", condition: `archived == false`},
+		{name: "not boolean", condition: `status`},
+		{name: "syntax", condition: `status ==`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{
+					"status": {Type: cel.StringType, PartialIndex: &PartialIndex{Condition: tt.condition}},
+				},
+			})
+			if err == nil {
+				t.Error("NewConverter() should reject the partial index condition")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common"
+)
+
+// SourceLocation locates the cause of an error in a filter expression, e.g.
+// for clients to highlight it.
+type SourceLocation struct {
+	// Line is the 1-based line of the location.
+	Line int
+	// Column is the 1-based column of the location, in characters.
+	Column int
+	// Offset is the 0-based offset of the location from the start of the
+	// expression, in characters.
+	Offset int
+	// Token is the offending token at the location, e.g. an undeclared
+	// identifier or an operator, if any.
+	Token string
+}
+
+// compileError reports the issues of a failed parse or type-check, located
+// at the first issue.
+func compileError(celExpr, stage string, issues *cel.Issues) error {
+	return &ConversionError{
+		PublicMessage: "invalid filter expression syntax",
+		ErrorCode:     CodeInvalidSyntax,
+		InternalError: fmt.Errorf("%s failed: %w", stage, issues.Err()),
+		Location:      issueLocation(celExpr, issues),
+	}
+}
+
+// issueLocation returns the location of the first issue with one.
+func issueLocation(celExpr string, issues *cel.Issues) *SourceLocation {
+	source := common.NewTextSource(celExpr)
+	for _, issue := range issues.Errors() {
+		if issue.Location == nil || issue.Location.Line() <= 0 {
+			continue
+		}
+		offset, ok := source.LocationOffset(issue.Location)
+		if !ok {
+			continue
+		}
+		return &SourceLocation{
+			Line:   issue.Location.Line(),
+			Column: issue.Location.Column() + 1,
+			Offset: int(offset),
+			Token:  tokenAt([]rune(celExpr), int(offset)),
+		}
+	}
+	return nil
+}
+
+// tokenAt returns the identifier, number or operator starting at offset.
+func tokenAt(runes []rune, offset int) string {
+	if offset < 0 || offset >= len(runes) || unicode.IsSpace(runes[offset]) {
+		return ""
+	}
+	isWord := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	isOperator := func(r rune) bool { return strings.ContainsRune("=!<>&|", r) }
+
+	end := offset + 1
+	switch {
+	case isWord(runes[offset]):
+		for end < len(runes) && isWord(runes[end]) {
+			end++
+		}
+	case isOperator(runes[offset]):
+		for end < len(runes) && isOperator(runes[end]) {
+			end++
+		}
+	}
+	return string(runes[offset:end])
+}
@@ -0,0 +1,76 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConversionError_Location(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+			"ssn":    {Type: cel.IntType, Masked: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		want    *SourceLocation
+	}{
+		{
+			name:    "undeclared field",
+			celExpr: `statu == "open"`,
+			want:    &SourceLocation{Line: 1, Column: 1, Offset: 0, Token: "statu"},
+		},
+		{
+			name:    "type mismatch on a later line",
+			celExpr: "status == \"a\" &&\n  age > \"x\"",
+			want:    &SourceLocation{Line: 2, Column: 7, Offset: 23, Token: ">"},
+		},
+		{
+			name:    "syntax error",
+			celExpr: `status == "a" && && age > 1`,
+			want:    &SourceLocation{Line: 1, Column: 18, Offset: 17, Token: "&&"},
+		},
+		{
+			name:    "multibyte characters",
+			celExpr: `status == "café" && agee > 1`,
+			want:    &SourceLocation{Line: 1, Column: 21, Offset: 20, Token: "agee"},
+		},
+		{
+			name:    "conversion error",
+			celExpr: `age > 1 && status.matches("x")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.Convert(tt.celExpr)
+			var convErr *ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("Convert() error = %v, want a ConversionError", err)
+			}
+			if !reflect.DeepEqual(convErr.Location, tt.want) {
+				t.Errorf("Location = %+v, want %+v", convErr.Location, tt.want)
+			}
+		})
+	}
+
+	// Tokens echoing the values of masked fields are masked
+	_, err = converter.Convert(`ssn == 123456789 && ssn == 123456789 == "x"`)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Location == nil {
+		t.Fatalf("Convert() error = %v, want a located ConversionError", err)
+	}
+	if convErr.Location.Token == "123456789" {
+		t.Errorf("Location.Token = %q echoes a masked value", convErr.Location.Token)
+	}
+}
@@ -0,0 +1,115 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// PreparedFilter is a filter expression converted once and reused, e.g. a
+// stored filter applied on every request. It is immutable and safe for
+// concurrent use.
+type PreparedFilter struct {
+	sql         string
+	args        []interface{}
+	fields      []string
+	fingerprint string
+	// mandatoryFunc returns the mandatory conditions of each request, which
+	// are not part of the prepared SQL.
+	mandatoryFunc func(ctx context.Context) ([]squirrel.Sqlizer, error)
+}
+
+// Prepare converts a filter expression into a PreparedFilter, reporting the
+// errors Convert would.
+func (c *Converter) Prepare(celExpr string, opts ...ConvertOption) (*PreparedFilter, error) {
+	return c.PrepareContext(context.Background(), celExpr, opts...)
+}
+
+// PrepareContext is like Prepare, passing ctx to the SecurityLogger. The
+// conditions of MandatoryConditionsFunc depend on the request and are not
+// prepared: they are resolved by WhereContext on every use.
+func (c *Converter) PrepareContext(ctx context.Context, celExpr string, opts ...ConvertOption) (*PreparedFilter, error) {
+	opts = append(opts[:len(opts):len(opts)], withoutContextConditions())
+	result, err := c.ConvertContext(ctx, celExpr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sql, args, err := result.Where.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SQL: %w", err)
+	}
+	fingerprint, err := result.CacheKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedFilter{
+		sql:           sql,
+		args:          args,
+		fields:        result.Fields,
+		fingerprint:   fingerprint,
+		mandatoryFunc: c.current().mandatoryFunc,
+	}, nil
+}
+
+// withoutContextConditions converts without the conditions of
+// MandatoryConditionsFunc, for filters reused across requests.
+func withoutContextConditions() ConvertOption {
+	return func(options *convertOptions) {
+		options.noContextConditions = true
+	}
+}
+
+// Where returns the WHERE clause, rendering the SQL converted by Prepare
+// with a copy of its bound values. It is WhereContext with the background
+// context.
+func (p *PreparedFilter) Where() Sqlizer {
+	return p.WhereContext(context.Background())
+}
+
+// WhereContext returns the WHERE clause, ANDing the conditions the
+// converter's MandatoryConditionsFunc returns for ctx onto the prepared SQL.
+// They are resolved when the clause is rendered, and their errors surface
+// from its ToSql with SCOPE_UNAVAILABLE.
+func (p *PreparedFilter) WhereContext(ctx context.Context) Sqlizer {
+	where := squirrel.Expr(p.sql, slices.Clone(p.args)...)
+	if p.mandatoryFunc == nil {
+		return where
+	}
+	return &scopedSqlizer{where: where, ctx: ctx, mandatoryFunc: p.mandatoryFunc}
+}
+
+// scopedSqlizer ANDs the mandatory conditions of a request onto a WHERE
+// clause when rendered.
+type scopedSqlizer struct {
+	where         squirrel.Sqlizer
+	ctx           context.Context
+	mandatoryFunc func(ctx context.Context) ([]squirrel.Sqlizer, error)
+}
+
+// ToSql resolves the mandatory conditions and renders the WHERE clause.
+func (s *scopedSqlizer) ToSql() (string, []interface{}, error) {
+	conditions, err := contextConditions(s.ctx, s.mandatoryFunc)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(conditions) == 0 {
+		return s.where.ToSql()
+	}
+	return append(squirrel.And{s.where}, conditions...).ToSql()
+}
+
+// Fields returns the sorted names of the fields and collections the filter
+// references.
+func (p *PreparedFilter) Fields() []string {
+	return slices.Clone(p.fields)
+}
+
+// Fingerprint identifies the filter: it is the CacheKey of its conversion,
+// shared by equivalent expressions of the same schema.
+func (p *PreparedFilter) Fingerprint() string {
+	return p.fingerprint
+}
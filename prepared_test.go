@@ -0,0 +1,185 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Prepare(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType, Column: "state"},
+			"age":    {Type: cel.IntType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		celExpr    string
+		wantSQL    string
+		wantArgs   []interface{}
+		wantFields []string
+		wantErr    bool
+	}{
+		{
+			name:       "simple",
+			celExpr:    `status == "active" && age > 18`,
+			wantSQL:    "(state = ? AND age > ?)",
+			wantArgs:   []interface{}{"active", int64(18)},
+			wantFields: []string{"age", "status"},
+		},
+		{
+			name:    "invalid",
+			celExpr: `status ==`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepared, err := converter.Prepare(tt.celExpr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Prepare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			sql, args, err := prepared.Where().ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Where() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Where() args = %v, want %v", args, tt.wantArgs)
+			}
+			if got := prepared.Fields(); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Fields() = %v, want %v", got, tt.wantFields)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			key, _ := result.CacheKey()
+			if prepared.Fingerprint() != key {
+				t.Errorf("Fingerprint() = %s, want the CacheKey %s", prepared.Fingerprint(), key)
+			}
+		})
+	}
+}
+
+func TestConverter_Prepare_CollectionFields(t *testing.T) {
+	converter := newTestCollectionConverter(t, Config{})
+	prepared, err := converter.Prepare(`status == "open" && comments.exists(c, c.flagged)`)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if got, want := prepared.Fields(), []string{"comments", "status"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+}
+
+func TestPreparedFilter_Immutable(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	prepared, err := converter.Prepare(`status in ["a", "b"]`)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	_, args, _ := prepared.Where().ToSql()
+	args[0] = "changed"
+	prepared.Fields()[0] = "changed"
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sql, args, err := squirrel.Select("*").From("t").Where(prepared.Where()).ToSql()
+			if err != nil || sql != "SELECT * FROM t WHERE status IN (?,?)" || args[0] != "a" {
+				t.Errorf("ToSql() = %q, %v, %v", sql, args, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := prepared.Fields(); !reflect.DeepEqual(got, []string{"status"}) {
+		t.Errorf("Fields() = %v, want [status]", got)
+	}
+}
+
+func TestPreparedFilter_WhereContext(t *testing.T) {
+	converter := newTenantConverter(t, Config{
+		MandatoryConditions: []squirrel.Sqlizer{squirrel.Eq{"deleted_at": nil}},
+	})
+
+	// Prepared for one tenant, the filter must not leak it to another
+	prepared, err := converter.PrepareContext(context.WithValue(context.Background(), tenantKey{}, "t1"), `status == "active"`)
+	if err != nil {
+		t.Fatalf("PrepareContext() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		where    squirrel.Sqlizer
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{
+			name:     "tenant of the request",
+			where:    prepared.WhereContext(context.WithValue(context.Background(), tenantKey{}, "t2")),
+			wantSQL:  "((status = ? AND deleted_at IS NULL) AND tenant_id = ?)",
+			wantArgs: []interface{}{"active", "t2"},
+		},
+		{
+			name:    "no tenant",
+			where:   prepared.WhereContext(context.Background()),
+			wantErr: ErrScopeUnavailable,
+		},
+		{
+			name:    "without context",
+			where:   prepared.Where(),
+			wantErr: ErrScopeUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.where.ToSql()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ToSql() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// OperatorSQL returns the SQL the converter emits for an operator or
+// function applied to a declared field, as listed by Capabilities, e.g.
+// "status = ?" for ("status", "==") or "LOWER(email) = LOWER(?)" for
+// ("email", "equalsIgnoreCase"), rendered for the configured dialect with
+// positional placeholders. It converts a sample expression through the
+// regular conversion path, so the SQL is the one actual filters produce, for
+// "preview SQL" features and contract tests of downstream services.
+func (c *Converter) OperatorSQL(field, operator string) (string, error) {
+	c = c.current()
+	mapping, ok := c.fieldDeclarations[field]
+	if !ok || mapping.Type == nil {
+		return "", fmt.Errorf("field %s is not declared", field)
+	}
+	if !slices.Contains(c.fieldOperators(mapping), operator) {
+		return "", fmt.Errorf("operator %s does not apply to field %s", operator, field)
+	}
+
+	celExpr, err := c.operatorExpr(field, mapping.Type, operator)
+	if err != nil {
+		return "", err
+	}
+	result, err := c.Convert(celExpr)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", field, operator, err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: failed to render SQL: %w", field, operator, err)
+	}
+	return sql, nil
+}
+
+// operatorExpr returns a sample CEL expression applying an operator to a
+// field of type t.
+func (c *Converter) operatorExpr(field string, t *cel.Type, operator string) (string, error) {
+	switch operator {
+	case "==", "!=", "<", "<=", ">", ">=":
+		if t.Kind() == types.ListKind {
+			// Lists only compare with the empty list
+			return fmt.Sprintf("%s %s []", field, operator), nil
+		}
+		return fmt.Sprintf("%s %s %s", field, operator, sampleLiteral(t, 0)), nil
+	case "!":
+		return "!" + field, nil
+	case "in":
+		if t.Kind() == types.ListKind {
+			return fmt.Sprintf("%s in %s", sampleLiteral(t.Parameters()[0], 0), field), nil
+		}
+		return fmt.Sprintf("%s in [%s, %s]", field, sampleLiteral(t, 0), sampleLiteral(t, 1)), nil
+	case "+", "-", "*", "/":
+		return fmt.Sprintf("%s %s %s == %s", field, operator, sampleLiteral(t, 1), sampleLiteral(t, 0)), nil
+	case "contains", "startsWith", "endsWith", "equalsIgnoreCase":
+		return fmt.Sprintf("%s.%s(%s)", field, operator, sampleLiteral(t, 0)), nil
+	case "trim", "lowerAscii", "upperAscii":
+		return fmt.Sprintf("%s.%s() == %s", field, operator, sampleLiteral(t, 0)), nil
+	case "replace":
+		return fmt.Sprintf(`%s.replace("a", "b") == %s`, field, sampleLiteral(t, 0)), nil
+	case "indexOf":
+		return fmt.Sprintf(`%s.indexOf("a") == 1`, field), nil
+	case "size":
+		return fmt.Sprintf("size(%s) == 1", field), nil
+	case "containsAll", "containsAny":
+		return fmt.Sprintf("%s.%s(%s)", field, operator, sampleLiteral(t, 0)), nil
+	case "[?]", "orValue":
+		value := sampleLiteral(t.Parameters()[1], 0)
+		return fmt.Sprintf(`%s[?"key"].orValue(%s) == %s`, field, value, value), nil
+	case ".?":
+		value := sampleLiteral(t.Parameters()[1], 0)
+		return fmt.Sprintf(`%s.?key.orValue(%s) == %s`, field, value, value), nil
+	case "near":
+		return fmt.Sprintf("near(%s, 0.0, 0.0, 1000.0)", field), nil
+	}
+
+	if tmpl, ok := c.functions[operator]; ok {
+		args := make([]string, len(tmpl.argTypes))
+		for i, argType := range tmpl.argTypes {
+			args[i] = sampleLiteral(argType, i)
+		}
+		return fmt.Sprintf("%s.%s(%s)", field, operator, strings.Join(args, ", ")), nil
+	}
+	return "", fmt.Errorf("no sample expression for operator %s", operator)
+}
+
+// sampleLiteral returns the i-th sample CEL literal of type t.
+func sampleLiteral(t *cel.Type, i int) string {
+	switch t.Kind() {
+	case types.BoolKind:
+		return "true"
+	case types.IntKind:
+		return fmt.Sprint(i + 1)
+	case types.UintKind:
+		return fmt.Sprintf("%du", i+1)
+	case types.DoubleKind:
+		return fmt.Sprintf("%d.5", i+1)
+	case types.TimestampKind:
+		return fmt.Sprintf(`timestamp("2024-01-0%dT00:00:00Z")`, i+1)
+	case types.DurationKind:
+		return fmt.Sprintf(`duration("%dh")`, i+1)
+	case types.ListKind:
+		return "[" + sampleLiteral(t.Parameters()[0], i) + "]"
+	default:
+		return fmt.Sprintf(`"v%d"`, i)
+	}
+}
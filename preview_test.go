@@ -0,0 +1,75 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_OperatorSQL(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":     {Type: cel.StringType},
+			"age":      {Type: cel.IntType, Column: "user_age"},
+			"score":    {Type: cel.DoubleType},
+			"active":   {Type: cel.BoolType},
+			"created":  {Type: cel.TimestampType},
+			"tags":     {Type: cel.ListType(cel.StringType), Kind: KindArray},
+			"metadata": {Type: cel.MapType(cel.StringType, cel.StringType)},
+			"location": {Type: GeoPointType, Kind: KindGeography},
+		},
+		Functions: []FunctionTemplate{{
+			Name:         "similarTo",
+			ReceiverType: cel.StringType,
+			ArgTypes:     []*cel.Type{cel.StringType, cel.IntType},
+			SQL:          "levenshtein({col}, {arg0}) < {arg1}",
+		}},
+		Dialect:          DialectPostgreSQL,
+		StringExtensions: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		field    string
+		operator string
+		want     string
+	}{
+		{field: "name", operator: "==", want: "name = ?"},
+		{field: "age", operator: ">=", want: "user_age >= ?"},
+		{field: "age", operator: "in", want: "user_age IN (?,?)"},
+		{field: "age", operator: "+", want: "(user_age + ?) = ?"},
+		{field: "name", operator: "startsWith", want: "name LIKE ?"},
+		{field: "name", operator: "similarTo", want: "levenshtein(name, ?) < ?"},
+		{field: "active", operator: "!", want: "NOT (active = ?)"},
+		{field: "location", operator: "near", want: "ST_DWithin(location, ST_MakePoint(?,?)::geography, ?)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field+" "+tt.operator, func(t *testing.T) {
+			got, err := converter.OperatorSQL(tt.field, tt.operator)
+			if err != nil {
+				t.Fatalf("OperatorSQL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("OperatorSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Every advertised operator has a preview
+	for _, field := range converter.Capabilities().Fields {
+		for _, operator := range field.Operators {
+			if _, err := converter.OperatorSQL(field.Name, operator); err != nil {
+				t.Errorf("OperatorSQL(%s, %s) error = %v", field.Name, operator, err)
+			}
+		}
+	}
+
+	if _, err := converter.OperatorSQL("unknown", "=="); err == nil {
+		t.Error("OperatorSQL() on an undeclared field should fail")
+	}
+	if _, err := converter.OperatorSQL("tags", "startsWith"); err == nil {
+		t.Error("OperatorSQL() with an operator not applying to the field should fail")
+	}
+}
@@ -0,0 +1,177 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ProtoOptions customizes ConfigFromProto.
+type ProtoOptions struct {
+	// JSONNames declares fields under their JSON names, e.g. createTime,
+	// instead of their proto names, e.g. create_time.
+	JSONNames bool
+	// EnumStrings declares enum fields as strings compared with value
+	// names, e.g. state == "ACTIVE", for enums stored by name. Default:
+	// ints compared with value numbers.
+	EnumStrings bool
+	// Columns overrides the columns of fields, keyed by declared field
+	// name. Default: the snake_case field name, e.g. create_time for
+	// createTime.
+	Columns map[string]string
+	// Exclude lists the declared field names not to declare, e.g. fields
+	// stored elsewhere or not meant to be filterable.
+	Exclude []string
+}
+
+// ConfigFromProto derives a Config from a message descriptor, such as the
+// resource of a gRPC List API, declaring its fields so that the resource
+// schema is exactly what is filterable:
+//
+//	config, err := cel2squirrel.ConfigFromProto(
+//		(&pb.Book{}).ProtoReflect().Descriptor(),
+//		cel2squirrel.ProtoOptions{Exclude: []string{"etag"}},
+//	)
+//
+// Scalar fields map to the CEL type of their kind, with enums as ints or
+// strings, google.protobuf.Timestamp and Duration fields to timestamps and
+// durations, and wrapper types to their scalar type. Repeated fields are
+// declared as JSON lists and maps with string keys as JSON maps, when their
+// values have one of these types. Fields of other types, such as bytes and
+// nested messages, are not declared.
+//
+// The Config starts from DefaultConfig.
+func ConfigFromProto(md protoreflect.MessageDescriptor, opts ProtoOptions) (Config, error) {
+	if md == nil {
+		return Config{}, fmt.Errorf("nil message descriptor")
+	}
+
+	builder := NewConfigBuilder()
+	declared := make(map[string]bool)
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		name := string(fd.Name())
+		if opts.JSONNames {
+			name = fd.JSONName()
+		}
+		declared[name] = true
+		if slices.Contains(opts.Exclude, name) {
+			continue
+		}
+
+		celType, ok := protoFieldType(fd, opts.EnumStrings)
+		if !ok {
+			continue
+		}
+		mapping := ColumnMapping{Type: celType}
+		column, overridden := opts.Columns[name]
+		if !overridden {
+			column = snakeCase(name)
+		}
+		if !plainColumnName.MatchString(column) {
+			return Config{}, fmt.Errorf("field %s: invalid column name %q", name, column)
+		}
+		if column != name {
+			mapping.Column = column
+		}
+		if err := builder.checkName(name); err != nil {
+			return Config{}, fmt.Errorf("field %s: %w", name, err)
+		}
+		builder.config.FieldDeclarations[name] = mapping
+	}
+
+	for _, name := range slices.Concat(slices.Sorted(maps.Keys(opts.Columns)), opts.Exclude) {
+		if !declared[name] {
+			return Config{}, fmt.Errorf("field %s is not a field of %s", name, md.FullName())
+		}
+	}
+	return builder.Build()
+}
+
+// protoFieldType returns the CEL type of a proto field, if it has one.
+func protoFieldType(fd protoreflect.FieldDescriptor, enumStrings bool) (*cel.Type, bool) {
+	switch {
+	case fd.IsMap():
+		if fd.MapKey().Kind() != protoreflect.StringKind {
+			return nil, false
+		}
+		value, ok := protoValueType(fd.MapValue(), enumStrings)
+		if !ok {
+			return nil, false
+		}
+		return cel.MapType(cel.StringType, value), true
+	case fd.IsList():
+		elem, ok := protoValueType(fd, enumStrings)
+		if !ok {
+			return nil, false
+		}
+		return cel.ListType(elem), true
+	}
+	return protoValueType(fd, enumStrings)
+}
+
+// protoValueType returns the CEL type of the values of a proto field, if it
+// has one.
+func protoValueType(fd protoreflect.FieldDescriptor, enumStrings bool) (*cel.Type, bool) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return cel.StringType, true
+	case protoreflect.BoolKind:
+		return cel.BoolType, true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return cel.IntType, true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return cel.UintType, true
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return cel.DoubleType, true
+	case protoreflect.EnumKind:
+		if enumStrings {
+			return cel.StringType, true
+		}
+		return cel.IntType, true
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch fd.Message().FullName() {
+		case "google.protobuf.Timestamp":
+			return cel.TimestampType, true
+		case "google.protobuf.Duration":
+			return cel.DurationType, true
+		case "google.protobuf.StringValue":
+			return cel.StringType, true
+		case "google.protobuf.BoolValue":
+			return cel.BoolType, true
+		case "google.protobuf.Int32Value", "google.protobuf.Int64Value":
+			return cel.IntType, true
+		case "google.protobuf.UInt32Value", "google.protobuf.UInt64Value":
+			return cel.UintType, true
+		case "google.protobuf.FloatValue", "google.protobuf.DoubleValue":
+			return cel.DoubleType, true
+		}
+	}
+	return nil, false
+}
+
+// snakeCase converts a field name to snake_case, e.g. createTime and
+// CreateTime to create_time, and HTTPStatus to http_status.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
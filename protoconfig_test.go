@@ -0,0 +1,244 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testBookDescriptor returns the descriptor of:
+//
+//	message Book {
+//	  enum State { STATE_UNSPECIFIED = 0; PUBLISHED = 1; }
+//	  message Author { string name = 1; }
+//	  string name = 1;
+//	  string displayTitle = 2;
+//	  int32 page_count = 3;
+//	  uint64 isbn = 4;
+//	  double rating = 5;
+//	  bool in_print = 6;
+//	  State state = 7;
+//	  google.protobuf.Timestamp create_time = 8;
+//	  google.protobuf.Duration read_time = 9;
+//	  google.protobuf.StringValue subtitle = 10;
+//	  repeated string tags = 11;
+//	  map<string, int64> counters = 12;
+//	  bytes cover = 13;
+//	  Author author = 14;
+//	  repeated Author editors = 15;
+//	  map<int32, string> chapters = 16;
+//	}
+func testBookDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	field := func(name string, number int32, label *descriptorpb.FieldDescriptorProto_Label,
+		kind descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		fd := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  label,
+			Type:   kind.Enum(),
+		}
+		if typeName != "" {
+			fd.TypeName = proto.String(typeName)
+		}
+		return fd
+	}
+	entry := func(name string, key, value descriptorpb.FieldDescriptorProto_Type) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{
+			Name: proto.String(name),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("key", 1, optional, key, ""),
+				field("value", 2, optional, value, ""),
+			},
+			Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+		}
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("library/book.proto"),
+		Package: proto.String("library"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"google/protobuf/timestamp.proto",
+			"google/protobuf/duration.proto",
+			"google/protobuf/wrappers.proto",
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Book"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("displayTitle", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("page_count", 3, optional, descriptorpb.FieldDescriptorProto_TYPE_INT32, ""),
+				field("isbn", 4, optional, descriptorpb.FieldDescriptorProto_TYPE_UINT64, ""),
+				field("rating", 5, optional, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, ""),
+				field("in_print", 6, optional, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ""),
+				field("state", 7, optional, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".library.Book.State"),
+				field("create_time", 8, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				field("read_time", 9, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Duration"),
+				field("subtitle", 10, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.StringValue"),
+				field("tags", 11, repeated, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("counters", 12, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".library.Book.CountersEntry"),
+				field("cover", 13, optional, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				field("author", 14, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".library.Book.Author"),
+				field("editors", 15, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".library.Book.Author"),
+				field("chapters", 16, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".library.Book.ChaptersEntry"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{
+				{
+					Name: proto.String("Author"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					},
+				},
+				entry("CountersEntry", descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				entry("ChaptersEntry", descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			},
+			EnumType: []*descriptorpb.EnumDescriptorProto{{
+				Name: proto.String("State"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("STATE_UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("PUBLISHED"), Number: proto.Int32(1)},
+				},
+			}},
+		}},
+	}
+
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatalf("failed to build descriptor: %v", err)
+	}
+	return fd.Messages().ByName("Book")
+}
+
+func TestConfigFromProto(t *testing.T) {
+	md := testBookDescriptor(t)
+
+	tests := []struct {
+		name string
+		opts ProtoOptions
+		want map[string]ColumnMapping
+	}{
+		{
+			name: "proto names",
+			opts: ProtoOptions{},
+			want: map[string]ColumnMapping{
+				"name":         {Type: cel.StringType},
+				"displayTitle": {Type: cel.StringType, Column: "display_title"},
+				"page_count":   {Type: cel.IntType},
+				"isbn":         {Type: cel.UintType},
+				"rating":       {Type: cel.DoubleType},
+				"in_print":     {Type: cel.BoolType},
+				"state":        {Type: cel.IntType},
+				"create_time":  {Type: cel.TimestampType},
+				"read_time":    {Type: cel.DurationType},
+				"subtitle":     {Type: cel.StringType},
+				"tags":         {Type: cel.ListType(cel.StringType)},
+				"counters":     {Type: cel.MapType(cel.StringType, cel.IntType)},
+			},
+		},
+		{
+			name: "json names",
+			opts: ProtoOptions{
+				JSONNames:   true,
+				EnumStrings: true,
+				Columns:     map[string]string{"name": "book_id"},
+				Exclude:     []string{"tags", "counters", "subtitle", "readTime", "rating", "isbn"},
+			},
+			want: map[string]ColumnMapping{
+				"name":         {Type: cel.StringType, Column: "book_id"},
+				"displayTitle": {Type: cel.StringType, Column: "display_title"},
+				"pageCount":    {Type: cel.IntType, Column: "page_count"},
+				"inPrint":      {Type: cel.BoolType, Column: "in_print"},
+				"state":        {Type: cel.StringType},
+				"createTime":   {Type: cel.TimestampType, Column: "create_time"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ConfigFromProto(md, tt.opts)
+			if err != nil {
+				t.Fatalf("ConfigFromProto() error = %v", err)
+			}
+			if !reflect.DeepEqual(config.FieldDeclarations, tt.want) {
+				t.Errorf("FieldDeclarations = %v, want %v", config.FieldDeclarations, tt.want)
+			}
+			if _, err := NewConverter(config); err != nil {
+				t.Errorf("NewConverter() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigFromProto_Errors(t *testing.T) {
+	md := testBookDescriptor(t)
+
+	tests := []struct {
+		name    string
+		md      protoreflect.MessageDescriptor
+		opts    ProtoOptions
+		wantErr string
+	}{
+		{
+			name:    "nil descriptor",
+			wantErr: "nil message descriptor",
+		},
+		{
+			name:    "unknown excluded field",
+			md:      md,
+			opts:    ProtoOptions{Exclude: []string{"title"}},
+			wantErr: "field title is not a field of library.Book",
+		},
+		{
+			name:    "unknown column override",
+			md:      md,
+			opts:    ProtoOptions{JSONNames: true, Columns: map[string]string{"page_count": "pages"}},
+			wantErr: "field page_count is not a field of library.Book",
+		},
+		{
+			name:    "invalid column",
+			md:      md,
+			opts:    ProtoOptions{Columns: map[string]string{"name": "name; --"}},
+			wantErr: `field name: invalid column name "name; --"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfigFromProto(tt.md, tt.opts)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ConfigFromProto() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"create_time": "create_time",
+		"createTime":  "create_time",
+		"CreateTime":  "create_time",
+		"HTTPStatus":  "http_status",
+		"userID":      "user_id",
+		"v2Name":      "v2_name",
+		"name":        "name",
+	}
+	for name, want := range tests {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// fieldRegistry holds the converter built from the fields registered at
+// runtime. It is shared by pointer so that copies of a converter never copy
+// its lock.
+type fieldRegistry struct {
+	mu sync.RWMutex
+	// current is the converter declaring the registered fields, nil until a
+	// field is registered or unregistered.
+	current *Converter
+}
+
+// RegisterField declares a field at runtime, e.g. a custom attribute defined
+// by a tenant. The CEL environment is rebuilt from the configuration and the
+// registered fields; conversions in flight complete with the previous fields.
+// As with With, a registered field absent from PublicFields and FieldACL
+// can only be filtered without authorization. Registered fields may not
+// redeclare existing ones.
+func (c *Converter) RegisterField(name string, mapping ColumnMapping) error {
+	c.registry.mu.Lock()
+	defer c.registry.mu.Unlock()
+
+	next, err := c.latest().withFields(map[string]ColumnMapping{name: mapping})
+	if err != nil {
+		return err
+	}
+	c.registry.current = next
+	return nil
+}
+
+// UnregisterField removes a declared field at runtime. Removing a field that
+// the configuration still references, e.g. in FieldACL or SoftDeleteField,
+// fails as NewConverter would.
+func (c *Converter) UnregisterField(name string) error {
+	c.registry.mu.Lock()
+	defer c.registry.mu.Unlock()
+
+	latest := c.latest()
+	if _, ok := latest.config.FieldDeclarations[name]; !ok {
+		return fmt.Errorf("field %s is not declared", name)
+	}
+	config := latest.config
+	config.FieldDeclarations = maps.Clone(latest.config.FieldDeclarations)
+	delete(config.FieldDeclarations, name)
+
+	next, err := NewConverter(config)
+	if err != nil {
+		return fmt.Errorf("failed to unregister field %s: %w", name, err)
+	}
+	c.registry.current = next
+	return nil
+}
+
+// current returns the converter declaring the fields registered at runtime,
+// which every exported method converts with.
+func (c *Converter) current() *Converter {
+	if c.registry == nil {
+		return c
+	}
+	c.registry.mu.RLock()
+	defer c.registry.mu.RUnlock()
+	return c.latest()
+}
+
+// latest returns the converter declaring the registered fields. The caller
+// holds the registry lock.
+func (c *Converter) latest() *Converter {
+	if c.registry.current == nil {
+		return c
+	}
+	return c.registry.current
+}
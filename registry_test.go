@@ -0,0 +1,121 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_RegisterField(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status":     {Type: cel.StringType},
+			"deleted_at": {Type: cel.TimestampType},
+		},
+		SoftDeleteField: "deleted_at",
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	if err := converter.RegisterField("tier", ColumnMapping{Type: cel.StringType, Column: "attrs->>'tier'"}); err != nil {
+		t.Fatalf("RegisterField() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantCode string
+	}{
+		{
+			name:    "registered field",
+			celExpr: `tier == "gold" && status == "open"`,
+			wantSQL: "((attrs->>'tier' = ? AND status = ?) AND deleted_at IS NULL)",
+		},
+		{
+			name:     "unknown field",
+			celExpr:  `region == "eu"`,
+			wantCode: "INVALID_SYNTAX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Convert() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	if _, ok := converter.Field("tier"); !ok {
+		t.Error("Field(tier) not found after RegisterField()")
+	}
+	if err := converter.RegisterField("status", ColumnMapping{Type: cel.IntType}); err == nil || err.Error() != "field status is already declared" {
+		t.Errorf("RegisterField() error = %v, want a redeclaration error", err)
+	}
+
+	if err := converter.UnregisterField("tier"); err != nil {
+		t.Fatalf("UnregisterField() error = %v", err)
+	}
+	if _, err := converter.Convert(`tier == "gold"`); errorCode(err) != "INVALID_SYNTAX" {
+		t.Errorf("Convert() after UnregisterField() error = %v, want INVALID_SYNTAX", err)
+	}
+	if err := converter.UnregisterField("tier"); err == nil || err.Error() != "field tier is not declared" {
+		t.Errorf("UnregisterField() error = %v, want an undeclared field error", err)
+	}
+	if err := converter.UnregisterField("deleted_at"); err == nil {
+		t.Error("UnregisterField() of the soft delete field succeeded, want an error")
+	}
+	if _, err := converter.Convert(`status == "open"`); err != nil {
+		t.Errorf("Convert() after a failed UnregisterField() error = %v", err)
+	}
+}
+
+func TestConverter_RegisterField_Concurrent(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := converter.RegisterField(fmt.Sprintf("attr%d", i), ColumnMapping{Type: cel.StringType}); err != nil {
+				t.Errorf("RegisterField() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := converter.Convert(`status == "open"`); err != nil {
+				t.Errorf("Convert() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range 8 {
+		if _, err := converter.Convert(fmt.Sprintf(`attr%d == "x"`, i)); err != nil {
+			t.Errorf("Convert() of registered field attr%d error = %v", i, err)
+		}
+	}
+}
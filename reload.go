@@ -0,0 +1,65 @@
+package cel2squirrel
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ReloadableConverter converts with a converter whose configuration can be
+// replaced at runtime, e.g. when a control plane publishes schema or ACL
+// changes. It is safe for concurrent use: conversions in flight complete with
+// the configuration they started with.
+type ReloadableConverter struct {
+	current atomic.Pointer[Converter]
+}
+
+// NewReloadableConverter creates a reloadable converter from config.
+func NewReloadableConverter(config Config) (*ReloadableConverter, error) {
+	converter, err := NewConverter(config)
+	if err != nil {
+		return nil, err
+	}
+	r := &ReloadableConverter{}
+	r.current.Store(converter)
+	return r, nil
+}
+
+// Swap replaces the configuration. When config is invalid, the error is
+// returned and the current configuration kept.
+func (r *ReloadableConverter) Swap(config Config) error {
+	converter, err := NewConverter(config)
+	if err != nil {
+		return err
+	}
+	r.current.Store(converter)
+	return nil
+}
+
+// Converter returns the current converter. Use it for several calls that
+// must share a configuration, e.g. Capabilities and ConvertWithAuth.
+func (r *ReloadableConverter) Converter() *Converter {
+	return r.current.Load()
+}
+
+// Convert converts with the current configuration, see Converter.Convert.
+func (r *ReloadableConverter) Convert(celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+	return r.Converter().Convert(celExpr, opts...)
+}
+
+// ConvertContext converts with the current configuration, see
+// Converter.ConvertContext.
+func (r *ReloadableConverter) ConvertContext(ctx context.Context, celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+	return r.Converter().ConvertContext(ctx, celExpr, opts...)
+}
+
+// ConvertWithAuth converts with the current configuration, see
+// Converter.ConvertWithAuth.
+func (r *ReloadableConverter) ConvertWithAuth(celExpr string, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	return r.Converter().ConvertWithAuth(celExpr, userRoles, opts...)
+}
+
+// ConvertWithAuthContext converts with the current configuration, see
+// Converter.ConvertWithAuthContext.
+func (r *ReloadableConverter) ConvertWithAuthContext(ctx context.Context, celExpr string, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	return r.Converter().ConvertWithAuthContext(ctx, celExpr, userRoles, opts...)
+}
@@ -0,0 +1,122 @@
+package cel2squirrel
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestReloadableConverter_Swap(t *testing.T) {
+	converter, err := NewReloadableConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+		},
+		PublicFields: []string{"status"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	if _, err := converter.ConvertWithAuth(`status == "open"`, nil); err != nil {
+		t.Fatalf("ConvertWithAuth() error = %v", err)
+	}
+	before := converter.Converter()
+
+	if err := converter.Swap(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status":   {Type: cel.StringType, Column: "state"},
+			"priority": {Type: cel.IntType},
+		},
+		PublicFields: []string{"priority"},
+	}); err != nil {
+		t.Fatalf("Swap() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		auth     bool
+		wantSQL  string
+		wantCode string
+	}{
+		{
+			name:    "new schema",
+			celExpr: `status == "open" && priority > 1`,
+			wantSQL: "(state = ? AND priority > ?)",
+		},
+		{
+			name:     "new ACL",
+			celExpr:  `status == "open"`,
+			auth:     true,
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convert := converter.Convert
+			if tt.auth {
+				convert = func(celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+					return converter.ConvertWithAuth(celExpr, nil, opts...)
+				}
+			}
+			result, err := convert(tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Convert() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	if _, err := before.ConvertWithAuth(`status == "open"`, nil); err != nil {
+		t.Errorf("ConvertWithAuth() with the previous converter error = %v", err)
+	}
+
+	current := converter.Converter()
+	if err := converter.Swap(Config{SoftDeleteField: "deleted_at"}); err == nil {
+		t.Error("Swap() with an invalid config succeeded, want an error")
+	}
+	if converter.Converter() != current {
+		t.Error("Swap() with an invalid config replaced the converter")
+	}
+}
+
+func TestReloadableConverter_Concurrent(t *testing.T) {
+	config := Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+		},
+	}
+	converter, err := NewReloadableConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := converter.Swap(config); err != nil {
+				t.Errorf("Swap() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := converter.Convert(`status == "open"`); err != nil {
+				t.Errorf("Convert() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
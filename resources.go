@@ -0,0 +1,111 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// Registry manages the converters of a service's resources, e.g. "prompts"
+// and "users", created from shared defaults and per-resource overrides. It is
+// safe for concurrent use.
+type Registry struct {
+	defaults   Config
+	mu         sync.RWMutex
+	converters map[string]*Converter
+}
+
+// NewRegistry creates a registry whose converters default to defaults.
+func NewRegistry(defaults Config) *Registry {
+	return &Registry{
+		defaults:   defaults,
+		converters: make(map[string]*Converter),
+	}
+}
+
+// Register creates the converter of a resource. The settings left unset in
+// config, i.e. zero, take the value of the defaults, so a default boolean
+// setting cannot be turned off per resource. The field declarations of the
+// defaults, e.g. tenant_id, are declared for every resource, and config may
+// redeclare them.
+func (r *Registry) Register(name string, config Config) error {
+	converter, err := NewConverter(r.resourceConfig(config))
+	if err != nil {
+		return fmt.Errorf("resource %s: %w", name, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.converters[name]; ok {
+		return fmt.Errorf("resource %s is already registered", name)
+	}
+	r.converters[name] = converter
+	return nil
+}
+
+// Converter returns the converter of a resource.
+func (r *Registry) Converter(name string) (*Converter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	converter, ok := r.converters[name]
+	return converter, ok
+}
+
+// Convert converts a filter expression on a resource, see Converter.Convert.
+func (r *Registry) Convert(name, celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+	return r.ConvertContext(context.Background(), name, celExpr, opts...)
+}
+
+// ConvertContext is like Convert, passing ctx to the SecurityLogger.
+func (r *Registry) ConvertContext(ctx context.Context, name, celExpr string, opts ...ConvertOption) (*ConvertResult, error) {
+	converter, err := r.resource(name)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertContext(ctx, celExpr, opts...)
+}
+
+// ConvertWithAuth converts a filter expression on a resource, authorizing
+// the fields it references, see Converter.ConvertWithAuth.
+func (r *Registry) ConvertWithAuth(name, celExpr string, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	return r.ConvertWithAuthContext(context.Background(), name, celExpr, userRoles, opts...)
+}
+
+// ConvertWithAuthContext is like ConvertWithAuth, passing ctx to the
+// SecurityLogger.
+func (r *Registry) ConvertWithAuthContext(ctx context.Context, name, celExpr string, userRoles []string, opts ...ConvertOption) (*ConvertResult, error) {
+	converter, err := r.resource(name)
+	if err != nil {
+		return nil, err
+	}
+	return converter.ConvertWithAuthContext(ctx, celExpr, userRoles, opts...)
+}
+
+// resource returns the converter of a registered resource.
+func (r *Registry) resource(name string) (*Converter, error) {
+	converter, ok := r.Converter(name)
+	if !ok {
+		return nil, fmt.Errorf("resource %s is not registered", name)
+	}
+	return converter, nil
+}
+
+// resourceConfig fills the settings left unset in config with the defaults.
+func (r *Registry) resourceConfig(config Config) Config {
+	merged := reflect.ValueOf(&config).Elem()
+	defaults := reflect.ValueOf(r.defaults)
+	for i := range merged.NumField() {
+		if field := merged.Field(i); field.IsZero() {
+			field.Set(defaults.Field(i))
+		}
+	}
+
+	if len(r.defaults.FieldDeclarations) > 0 {
+		declarations := maps.Clone(r.defaults.FieldDeclarations)
+		maps.Copy(declarations, config.FieldDeclarations)
+		config.FieldDeclarations = declarations
+	}
+	return config
+}
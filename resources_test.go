@@ -0,0 +1,128 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tenant_id": {Type: cel.StringType},
+		},
+		MaxInClauseSize: 2,
+	})
+	if err := registry.Register("prompts", Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"title": {Type: cel.StringType},
+		},
+	}); err != nil {
+		t.Fatalf("Register(prompts) error = %v", err)
+	}
+	if err := registry.Register("users", Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tenant_id": {Type: cel.StringType, Column: "org_id"},
+			"name":      {Type: cel.StringType},
+		},
+		MaxInClauseSize: 5,
+		PublicFields:    []string{"name"},
+	}); err != nil {
+		t.Fatalf("Register(users) error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		celExpr  string
+		auth     bool
+		wantSQL  string
+		wantCode string
+		wantErr  string
+	}{
+		{
+			name:     "shared field",
+			resource: "prompts",
+			celExpr:  `tenant_id == "t1" && title == "x"`,
+			wantSQL:  "(tenant_id = ? AND title = ?)",
+		},
+		{
+			name:     "default limit",
+			resource: "prompts",
+			celExpr:  `title in ["a", "b", "c"]`,
+			wantCode: "LIMIT_IN_SIZE",
+		},
+		{
+			name:     "overridden field",
+			resource: "users",
+			celExpr:  `tenant_id == "t1"`,
+			wantSQL:  "org_id = ?",
+		},
+		{
+			name:     "overridden limit",
+			resource: "users",
+			celExpr:  `name in ["a", "b", "c"]`,
+			wantSQL:  "name IN (?,?,?)",
+		},
+		{
+			name:     "resource fields",
+			resource: "prompts",
+			celExpr:  `name == "x"`,
+			wantCode: "INVALID_SYNTAX",
+		},
+		{
+			name:     "resource authorization",
+			resource: "users",
+			celExpr:  `tenant_id == "t1"`,
+			auth:     true,
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:     "unknown resource",
+			resource: "orders",
+			celExpr:  `tenant_id == "t1"`,
+			wantErr:  "resource orders is not registered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result *ConvertResult
+			var err error
+			if tt.auth {
+				result, err = registry.ConvertWithAuth(tt.resource, tt.celExpr, nil)
+			} else {
+				result, err = registry.Convert(tt.resource, tt.celExpr)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Convert() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, _, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("Convert() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+		})
+	}
+
+	if err := registry.Register("prompts", Config{}); err == nil || err.Error() != "resource prompts is already registered" {
+		t.Errorf("Register() error = %v, want a duplicate resource error", err)
+	}
+	if err := registry.Register("orders", Config{SoftDeleteField: "deleted_at"}); err == nil {
+		t.Error("Register() with an invalid config succeeded, want an error")
+	}
+	if _, ok := registry.Converter("orders"); ok {
+		t.Error("Converter(orders) found after a failed Register()")
+	}
+}
@@ -0,0 +1,163 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// Scope is a layer of constraints applied to a user filter, e.g. by the
+// platform, an organization administrator or the filtered resource's owner.
+type Scope struct {
+	// Name identifies the layer in errors and logs, e.g. "tenant".
+	Name string
+	// Predicate is a trusted CEL expression every row must satisfy, e.g.
+	// `tenant_id == "t1"`. It is ANDed with the user filter and may read
+	// fields the user cannot. Optional.
+	Predicate string
+	// Fields, when not empty, lists the only fields the user filter may
+	// reference.
+	Fields []string
+	// HiddenFields lists fields the user filter may not reference.
+	HiddenFields []string
+}
+
+// ScopeStack is an ordered list of scope layers. Predicates are applied in
+// order and each layer can only narrow the fields visible to the user
+// filter, so that platform, organization and user constraints compose
+// predictably. Push does not modify its receiver, so a base stack can be
+// shared across requests.
+type ScopeStack []Scope
+
+// Push returns a new stack with scopes added on top of s.
+func (s ScopeStack) Push(scopes ...Scope) ScopeStack {
+	return append(s[:len(s):len(s)], scopes...)
+}
+
+// hidingScope returns the name of the first layer hiding field from the
+// user filter.
+func (s ScopeStack) hidingScope(field string) (string, bool) {
+	for _, scope := range s {
+		if (len(scope.Fields) > 0 && !slices.Contains(scope.Fields, field)) || slices.Contains(scope.HiddenFields, field) {
+			return scope.Name, true
+		}
+	}
+	return "", false
+}
+
+// ConvertWithScopes converts a user filter constrained by a stack of scopes.
+// The predicates of the scopes are ANDed, in order, with the user filter,
+// whose fields must be visible in every layer and authorized for the user's
+// roles as with ConvertWithAuth.
+func (c *Converter) ConvertWithScopes(ctx context.Context, celExpr string, userRoles []string, scopes ScopeStack) (result *ConvertResult, err error) {
+	c = c.current()
+	defer c.logAttempt(ctx, celExpr, time.Now(), &err)
+
+	result, err = c.convertWithScopes(ctx, celExpr, userRoles, scopes)
+	result, err = c.finalize(ctx, celExpr, result, err)
+	return c.maskOutput(celExpr, result, err)
+}
+
+// convertWithScopes converts the scope predicates and the user filter, and
+// combines them.
+func (c *Converter) convertWithScopes(ctx context.Context, celExpr string, userRoles []string, scopes ScopeStack) (*ConvertResult, error) {
+	var parts []*ConvertResult
+	for _, scope := range scopes {
+		if scope.Predicate == "" {
+			continue
+		}
+		converted, err := c.convertScopePredicate(ctx, scope.Predicate)
+		if err != nil {
+			return nil, newConversionError(
+				"invalid filter scope",
+				CodeInvalidScope,
+				fmt.Errorf("scope %s: %w", scope.Name, err),
+			)
+		}
+		parts = append(parts, converted)
+	}
+
+	converted, err := c.convertWithAuth(ctx, celExpr, userRoles, scopes)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return converted, nil
+	}
+	combined := combineResults(append(parts, converted))
+	combined.Class = c.classify(combined.expr, combined.AlwaysFalse)
+	return combined, nil
+}
+
+// convertScopePredicate converts a trusted scope predicate.
+func (c *Converter) convertScopePredicate(ctx context.Context, predicate string) (*ConvertResult, error) {
+	_, checkedExpr, err := c.compile(ctx, predicate)
+	if err != nil {
+		return nil, err
+	}
+	return c.convertChecked(ctx, checkedExpr.Expr(), checkedExpr.cost)
+}
+
+// combineResults ANDs the results of several conversions, in order.
+func combineResults(parts []*ConvertResult) *ConvertResult {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+
+	combined := &ConvertResult{
+		Args:        []interface{}{},
+		AlwaysTrue:  true,
+		schema:      parts[0].schema,
+		placeholder: parts[0].placeholder,
+	}
+	var (
+		where squirrel.And
+		exprs []celast.Expr
+	)
+	for _, part := range parts {
+		where = append(where, part.Where)
+		exprs = append(exprs, part.expr)
+		combined.Warnings = append(combined.Warnings, part.Warnings...)
+		combined.AlwaysTrue = combined.AlwaysTrue && part.AlwaysTrue
+		combined.AlwaysFalse = combined.AlwaysFalse || part.AlwaysFalse
+		for _, join := range part.Joins {
+			if !slices.Contains(combined.Joins, join) {
+				combined.Joins = append(combined.Joins, join)
+			}
+		}
+
+		combined.Fields = mergeSorted(combined.Fields, part.Fields)
+		combined.Columns = mergeSorted(combined.Columns, part.Columns)
+		combined.Operators = mergeSorted(combined.Operators, part.Operators)
+		for _, deprecation := range part.Deprecations {
+			if !slices.Contains(combined.Deprecations, deprecation) {
+				combined.Deprecations = append(combined.Deprecations, deprecation)
+			}
+		}
+
+		complexity := &combined.Complexity
+		complexity.Depth = max(complexity.Depth, part.Complexity.Depth)
+		complexity.Predicates += part.Complexity.Predicates
+		complexity.Nodes += part.Complexity.Nodes
+		complexity.Values += part.Complexity.Values
+		complexity.ApproxBytes += part.Complexity.ApproxBytes
+		complexity.LikePatterns += part.Complexity.LikePatterns
+		complexity.MaxLikePatternLength = max(complexity.MaxLikePatternLength, part.Complexity.MaxLikePatternLength)
+		complexity.MaxLikeWildcards = max(complexity.MaxLikeWildcards, part.Complexity.MaxLikeWildcards)
+		complexity.Cost = addCost(complexity.Cost, part.Complexity.Cost)
+	}
+	combined.Where = where
+	combined.expr = newCall(0, "_&&_", exprs...)
+	return combined
+}
+
+// mergeSorted returns the sorted union of two sorted lists.
+func mergeSorted(a, b []string) []string {
+	merged := slices.Concat(a, b)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
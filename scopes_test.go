@@ -0,0 +1,156 @@
+package cel2squirrel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newTestScopeConverter(t *testing.T, config Config) *Converter {
+	t.Helper()
+
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"tenant_id":  {Type: cel.StringType},
+		"project_id": {Type: cel.StringType},
+		"owner_id":   {Type: cel.StringType},
+		"status":     {Type: cel.StringType},
+		"salary":     {Type: cel.IntType},
+	}
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_ConvertWithScopes(t *testing.T) {
+	converter := newTestScopeConverter(t, Config{})
+
+	platform := ScopeStack{}.Push(Scope{Name: "tenant", Predicate: `tenant_id == "t1"`, HiddenFields: []string{"tenant_id"}})
+	project := platform.Push(Scope{Name: "project", Predicate: `project_id == "p1"`, Fields: []string{"status", "owner_id", "salary"}})
+	owner := project.Push(Scope{Name: "owner", Predicate: `owner_id == "u1"`, HiddenFields: []string{"salary"}})
+
+	tests := []struct {
+		name     string
+		scopes   ScopeStack
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "no scope",
+			celExpr:  `status == "open"`,
+			wantSQL:  "status = ?",
+			wantArgs: []any{"open"},
+		},
+		{
+			name:     "platform",
+			scopes:   platform,
+			celExpr:  `status == "open"`,
+			wantSQL:  "(tenant_id = ? AND status = ?)",
+			wantArgs: []any{"t1", "open"},
+		},
+		{
+			name:     "stacked",
+			scopes:   owner,
+			celExpr:  `status == "open" || owner_id == "u2"`,
+			wantSQL:  "(tenant_id = ? AND project_id = ? AND owner_id = ? AND (status = ? OR owner_id = ?))",
+			wantArgs: []any{"t1", "p1", "u1", "open", "u2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertWithScopes(context.Background(), tt.celExpr, nil, tt.scopes)
+			if err != nil {
+				t.Fatalf("ConvertWithScopes() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	if len(platform) != 1 || len(project) != 2 {
+		t.Error("Push() modified the stack it was called on")
+	}
+}
+
+func TestConverter_ConvertWithScopes_Errors(t *testing.T) {
+	converter := newTestScopeConverter(t, Config{
+		PublicFields: []string{"status", "owner_id"},
+		FieldACL:     map[string][]string{"salary": {"hr"}},
+	})
+
+	scopes := ScopeStack{
+		{Name: "tenant", Predicate: `tenant_id == "t1"`, Fields: []string{"status", "owner_id", "salary"}},
+		{Name: "owner", HiddenFields: []string{"owner_id"}},
+	}
+
+	tests := []struct {
+		name      string
+		scopes    ScopeStack
+		celExpr   string
+		userRoles []string
+		wantCode  string
+	}{
+		{
+			name:      "authorized",
+			scopes:    scopes,
+			celExpr:   `status == "open" && salary > 10`,
+			userRoles: []string{"hr"},
+		},
+		{
+			name:     "role",
+			scopes:   scopes,
+			celExpr:  `salary > 10`,
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:     "outside visible fields",
+			scopes:   scopes,
+			celExpr:  `tenant_id == "t2"`,
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:     "hidden by a later layer",
+			scopes:   scopes,
+			celExpr:  `owner_id == "u1"`,
+			wantCode: "UNAUTHORIZED_FIELD",
+		},
+		{
+			name:     "invalid predicate",
+			scopes:   ScopeStack{{Name: "tenant", Predicate: `tenant_id == 1`}},
+			celExpr:  `status == "open"`,
+			wantCode: "INVALID_SCOPE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := converter.ConvertWithScopes(context.Background(), tt.celExpr, tt.userRoles, tt.scopes)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("ConvertWithScopes() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ConvertWithScopes() expected error")
+			}
+			if code := string(err.(*ConversionError).ErrorCode); code != tt.wantCode {
+				t.Errorf("ErrorCode = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package cel2squirrel
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// Select converts a filter expression and returns a query selecting the
+// columns, all when none are given, of the matching rows of table, ready to
+// be extended with ordering and paging. The query has the joins and WHERE
+// clause of the conversion and the converter's placeholder format. When
+// TableAlias is set and table has no alias, the table is aliased.
+func (c *Converter) Select(table, celExpr string, columns ...string) (squirrel.SelectBuilder, error) {
+	return c.SelectContext(context.Background(), table, celExpr, columns...)
+}
+
+// SelectContext is like Select, converting the expression with ctx, e.g. for
+// the conditions of MandatoryConditionsFunc.
+func (c *Converter) SelectContext(ctx context.Context, table, celExpr string, columns ...string) (squirrel.SelectBuilder, error) {
+	result, err := c.ConvertContext(ctx, celExpr)
+	if err != nil {
+		return squirrel.SelectBuilder{}, err
+	}
+	return c.selectBuilder(table, result, columns), nil
+}
+
+// SelectWithCount is like Select, also returning a query counting all the
+// matching rows with the same FROM and WHERE clauses, e.g. for the total of
+// a list endpoint. The expression is converted once. Page the rows query
+// only: predicates added to it, such as keyset cursors, are not counted.
+func (c *Converter) SelectWithCount(table, celExpr string, columns ...string) (rows, count squirrel.SelectBuilder, err error) {
+	return c.SelectWithCountContext(context.Background(), table, celExpr, columns...)
+}
+
+// SelectWithCountContext is like SelectWithCount, converting the expression
+// with ctx, e.g. for the conditions of MandatoryConditionsFunc.
+func (c *Converter) SelectWithCountContext(ctx context.Context, table, celExpr string, columns ...string) (rows, count squirrel.SelectBuilder, err error) {
+	result, err := c.ConvertContext(ctx, celExpr)
+	if err != nil {
+		return squirrel.SelectBuilder{}, squirrel.SelectBuilder{}, err
+	}
+	return c.selectBuilder(table, result, columns), c.selectBuilder(table, result, []string{"COUNT(*)"}), nil
+}
+
+// selectBuilder returns a query selecting the columns of the rows of table
+// matching a conversion result.
+func (c *Converter) selectBuilder(table string, result *ConvertResult, columns []string) squirrel.SelectBuilder {
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+	if c.tableAlias != "" && c.tableAlias != table && !strings.ContainsAny(table, " \t\n") {
+		table += " " + c.tableAlias
+	}
+
+	builder := Apply(SquirrelBuilder{}, squirrel.Select(columns...).From(table), result)
+	if c.placeholderFormat != nil {
+		builder = builder.PlaceholderFormat(c.placeholderFormat)
+	}
+	return builder
+}
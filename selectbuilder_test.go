@@ -0,0 +1,196 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Select(t *testing.T) {
+	postgres, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+		},
+		Dialect: DialectPostgreSQL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		converter *Converter
+		table     string
+		celExpr   string
+		columns   []string
+		wantSQL   string
+		wantArgs  []interface{}
+		wantCode  string
+	}{
+		{
+			name:      "dialect placeholders",
+			converter: postgres,
+			table:     "users",
+			celExpr:   `status == "active" && age > 18`,
+			columns:   []string{"id", "name"},
+			wantSQL:   "SELECT id, name FROM users WHERE (status = $1 AND age > $2)",
+			wantArgs:  []interface{}{"active", int64(18)},
+		},
+		{
+			name:      "all columns",
+			converter: postgres,
+			table:     "users",
+			celExpr:   `age > 18`,
+			wantSQL:   "SELECT * FROM users WHERE age > $1",
+			wantArgs:  []interface{}{int64(18)},
+		},
+		{
+			name:      "table alias and joins",
+			converter: newTestJoinConverter(t),
+			table:     "prompts",
+			celExpr:   `author.name == "ada"`,
+			columns:   []string{"p.id"},
+			wantSQL:   "SELECT p.id FROM prompts p LEFT JOIN users u ON p.author_id = u.id WHERE u.name = ?",
+			wantArgs:  []interface{}{"ada"},
+		},
+		{
+			name:      "aliased table",
+			converter: newTestJoinConverter(t),
+			table:     "prompts AS p",
+			celExpr:   `title == "a"`,
+			wantSQL:   "SELECT * FROM prompts AS p WHERE p.title = ?",
+			wantArgs:  []interface{}{"a"},
+		},
+		{
+			name:      "invalid",
+			converter: postgres,
+			table:     "users",
+			celExpr:   `unknown == 1`,
+			wantCode:  "INVALID_SYNTAX",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, err := tt.converter.Select(tt.table, tt.celExpr, tt.columns...)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("Select() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+
+			sql, args, err := builder.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_SelectWithCount(t *testing.T) {
+	converter := newTestJoinConverter(t)
+
+	rows, count, err := converter.SelectWithCount("prompts", `author.name == "ada" && title.startsWith("a")`, "p.id", "p.title")
+	if err != nil {
+		t.Fatalf("SelectWithCount() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   squirrel.Sqlizer
+		wantSQL string
+	}{
+		{
+			name:  "rows",
+			query: rows.OrderBy("p.id").Limit(20).Offset(40),
+			wantSQL: "SELECT p.id, p.title FROM prompts p LEFT JOIN users u ON p.author_id = u.id" +
+				" WHERE (u.name = ? AND p.title LIKE ?) ORDER BY p.id LIMIT 20 OFFSET 40",
+		},
+		{
+			name:  "count",
+			query: count,
+			wantSQL: "SELECT COUNT(*) FROM prompts p LEFT JOIN users u ON p.author_id = u.id" +
+				" WHERE (u.name = ? AND p.title LIKE ?)",
+		},
+	}
+
+	wantArgs := []interface{}{"ada", "a%"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.query.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, wantArgs)
+			}
+		})
+	}
+
+	if _, _, err := converter.SelectWithCount("prompts", `unknown == 1`); errorCode(err) != "INVALID_SYNTAX" {
+		t.Errorf("SelectWithCount() error = %v, want INVALID_SYNTAX", err)
+	}
+}
+
+func TestConverter_SelectContext(t *testing.T) {
+	converter := newTenantConverter(t, Config{})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "t1")
+	wantArgs := []interface{}{"active", "t1"}
+
+	builder, err := converter.SelectContext(ctx, "users", `status == "active"`, "id")
+	if err != nil {
+		t.Fatalf("SelectContext() error = %v", err)
+	}
+	rows, count, err := converter.SelectWithCountContext(ctx, "users", `status == "active"`, "id")
+	if err != nil {
+		t.Fatalf("SelectWithCountContext() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   squirrel.Sqlizer
+		wantSQL string
+	}{
+		{name: "select", query: builder, wantSQL: "SELECT id FROM users WHERE (status = ? AND tenant_id = ?)"},
+		{name: "rows", query: rows, wantSQL: "SELECT id FROM users WHERE (status = ? AND tenant_id = ?)"},
+		{name: "count", query: count, wantSQL: "SELECT COUNT(*) FROM users WHERE (status = ? AND tenant_id = ?)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.query.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, wantArgs)
+			}
+		})
+	}
+
+	// Without context, the tenant is unknown
+	if _, err := converter.Select("users", `status == "active"`); !errors.Is(err, ErrScopeUnavailable) {
+		t.Errorf("Select() error = %v, want %v", err, ErrScopeUnavailable)
+	}
+	if _, _, err := converter.SelectWithCount("users", `status == "active"`); !errors.Is(err, ErrScopeUnavailable) {
+		t.Errorf("SelectWithCount() error = %v, want %v", err, ErrScopeUnavailable)
+	}
+}
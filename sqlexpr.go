@@ -0,0 +1,118 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlStatementKeywords are the keywords rejected in SQL expression column
+// mappings: they would turn a scalar expression into a subquery or a
+// statement of its own.
+var sqlStatementKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true,
+	"DROP": true, "CREATE": true, "ALTER": true, "TRUNCATE": true, "GRANT": true,
+	"REVOKE": true, "EXEC": true, "EXECUTE": true, "CALL": true, "INTO": true,
+	"SLEEP": true, "PG_SLEEP": true, "BENCHMARK": true, "WAITFOR": true,
+}
+
+// sqlExprColumn validates the SQL expression of a computed field and returns
+// it as a self-contained operand. The expression must be made of
+// identifiers, literals, numbers, operators and balanced parentheses only:
+// placeholders, comments, statement separators and statement keywords are
+// rejected. Expressions that are not a single identifier, function call or
+// parenthesized group are wrapped in parentheses.
+func sqlExprColumn(name string, mapping ColumnMapping) (string, error) {
+	if mapping.Column != "" || mapping.JSONPath != "" || mapping.Table != "" {
+		return "", fmt.Errorf("field %s: Expr cannot be combined with Column, Table or JSONPath", name)
+	}
+
+	expr := strings.TrimSpace(mapping.Expr)
+	tokens, err := sqlFragmentTokens(expr)
+	if err != nil {
+		return "", fmt.Errorf("field %s: %w", name, err)
+	}
+
+	if isAtomicSQL(tokens) {
+		return expr, nil
+	}
+	return "(" + expr + ")", nil
+}
+
+// sqlFragmentTokens tokenizes a trusted SQL fragment, checking that it is
+// made of identifiers, literals, numbers, operators and balanced parentheses
+// only.
+func sqlFragmentTokens(expr string) ([]sqlToken, error) {
+	tokens, err := tokenizeSQL(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQL expression: %w", err)
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch token.kind {
+		case tokenPlaceholder:
+			return nil, fmt.Errorf("SQL expression must not contain ? placeholders")
+		case tokenIdentifier:
+			if sqlStatementKeywords[strings.ToUpper(token.text)] {
+				return nil, fmt.Errorf("SQL expression must not contain %s", token.text)
+			}
+		case tokenOperator:
+			switch {
+			case token.text == "(":
+				depth++
+			case token.text == ")":
+				depth--
+				if depth < 0 {
+					return nil, fmt.Errorf("unbalanced ')' in SQL expression")
+				}
+			case strings.Contains(token.text, "--"), strings.Contains(token.text, "/*"), strings.Contains(token.text, "*/"):
+				return nil, fmt.Errorf("SQL expression must not contain comments")
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced '(' in SQL expression")
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty SQL expression")
+	}
+	return tokens, nil
+}
+
+// isAtomicSQL reports whether balanced tokens form a single operand: an
+// identifier, possibly qualified, a function call or a parenthesized group.
+func isAtomicSQL(tokens []sqlToken) bool {
+	i := 0
+	if tokens[0].kind == tokenIdentifier {
+		// Qualified identifiers such as t.email
+		i++
+		for i+1 < len(tokens) && tokens[i].text == "." && tokens[i+1].kind == tokenIdentifier {
+			i += 2
+		}
+		if i == len(tokens) {
+			return true
+		}
+	}
+
+	// A parenthesized group, or the arguments of a call, closing last
+	if tokens[i].kind != tokenOperator || tokens[i].text != "(" {
+		return false
+	}
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		if tokens[j].kind != tokenOperator {
+			continue
+		}
+		switch tokens[j].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return j == len(tokens)-1
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_SQLExpr(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"email":     {Type: cel.StringType, Expr: "LOWER(email)"},
+			"full_name": {Type: cel.StringType, Expr: "first_name || ' ' || last_name"},
+			"total":     {Type: cel.DoubleType, Expr: "(price * quantity)"},
+			"owner":     {Type: cel.StringType, Expr: "u.login"},
+		},
+		AuditSQL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "function call",
+			celExpr:  `email == "bob@example.com"`,
+			wantSQL:  "LOWER(email) = ?",
+			wantArgs: []any{"bob@example.com"},
+		},
+		{
+			name:     "wrapped operator expression",
+			celExpr:  `full_name.startsWith("Ada")`,
+			wantSQL:  "(first_name || ' ' || last_name) LIKE ?",
+			wantArgs: []any{"Ada%"},
+		},
+		{
+			name:     "parenthesized group",
+			celExpr:  `total > 100.0 && owner in ["a", "b"]`,
+			wantSQL:  "((price * quantity) > ? AND u.login IN (?,?))",
+			wantArgs: []any{100.0, "a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNewConverter_InvalidSQLExpr(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping ColumnMapping
+	}{
+		{name: "statement separator", mapping: ColumnMapping{Expr: "email; DROP TABLE users"}},
+		{name: "comment", mapping: ColumnMapping{Expr: "email -- trailing"}},
+		{name: "block comment", mapping: ColumnMapping{Expr: "email /* x */"}},
+		{name: "placeholder", mapping: ColumnMapping{Expr: "COALESCE(email, ?)"}},
+		{name: "subquery", mapping: ColumnMapping{Expr: "(SELECT email FROM admins LIMIT 1)"}},
+		{name: "unbalanced", mapping: ColumnMapping{Expr: "LOWER(email"}},
+		{name: "closing first", mapping: ColumnMapping{Expr: "email) OR (1=1"}},
+		{name: "unterminated literal", mapping: ColumnMapping{Expr: "email || 'x"}},
+		{name: "with column", mapping: ColumnMapping{Expr: "LOWER(email)", Column: "email"}},
+		{name: "blank", mapping: ColumnMapping{Expr: "  "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mapping.Type = cel.StringType
+			_, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{"email": tt.mapping},
+			})
+			if err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+}
+
+func TestIsAtomicSQL(t *testing.T) {
+	tests := map[string]bool{
+		"email":                   true,
+		"u.email":                 true,
+		`"User"."email"`:          true,
+		"LOWER(email)":            true,
+		"(a + b)":                 true,
+		"(a) + (b)":               false,
+		"LOWER(a) || LOWER(b)":    false,
+		"a + b":                   false,
+		"COALESCE(a, b) IS NULL":  false,
+		"CAST(a AS INTEGER)":      true,
+		"first_name || last_name": false,
+	}
+
+	for expr, want := range tests {
+		tokens, err := tokenizeSQL(expr)
+		if err != nil {
+			t.Fatalf("tokenizeSQL(%q) error = %v", expr, err)
+		}
+		if got := isAtomicSQL(tokens); got != want {
+			t.Errorf("isAtomicSQL(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package cel2squirrel
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// OperatorClass groups predicates by how an index can serve them.
+type OperatorClass string
+
+const (
+	// OperatorEquality covers =, IN and IS NULL predicates.
+	OperatorEquality OperatorClass = "equality"
+	// OperatorRange covers <, <=, > and >= predicates.
+	OperatorRange OperatorClass = "range"
+	// OperatorPrefix covers startsWith() predicates (LIKE 'x%').
+	OperatorPrefix OperatorClass = "prefix"
+	// OperatorPattern covers other LIKE-based and inequality predicates,
+	// which B-tree indexes generally cannot serve.
+	OperatorPattern OperatorClass = "pattern"
+)
+
+// ColumnUsage is a column filtered with a given operator class.
+type ColumnUsage struct {
+	Column   string
+	Operator OperatorClass
+}
+
+// FilterStats records which columns and operator classes converted filters
+// use together. It is safe for concurrent use; attach it to converters with
+// Config.Stats.
+type FilterStats struct {
+	mu       sync.Mutex
+	total    int
+	patterns map[string]*filterPattern
+}
+
+// filterPattern is a distinct combination of column usages.
+type filterPattern struct {
+	usages []ColumnUsage
+	count  int
+}
+
+// IndexSuggestion is a composite index recommended by FilterStats.
+type IndexSuggestion struct {
+	// Columns lists the index columns in order: equality columns first, then
+	// at most one range or prefix column.
+	Columns []string
+	// Operators lists the operator class served by each column.
+	Operators []OperatorClass
+	// Count is the number of recorded filters the index would serve.
+	Count int
+	// Share is Count relative to the number of recorded filters.
+	Share float64
+}
+
+// NewFilterStats creates an empty filter statistics recorder.
+func NewFilterStats() *FilterStats {
+	return &FilterStats{patterns: make(map[string]*filterPattern)}
+}
+
+// Record adds the column usages of a filter to the statistics.
+func (s *FilterStats) Record(usages []ColumnUsage) {
+	usages = slices.Clone(usages)
+	slices.SortFunc(usages, func(a, b ColumnUsage) int {
+		return cmp.Or(cmp.Compare(a.Column, b.Column), cmp.Compare(a.Operator, b.Operator))
+	})
+	usages = slices.Compact(usages)
+
+	keys := make([]string, len(usages))
+	for i, usage := range usages {
+		keys[i] = usage.Column + " " + string(usage.Operator)
+	}
+	key := strings.Join(keys, ",")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total++
+	if pattern, ok := s.patterns[key]; ok {
+		pattern.count++
+		return
+	}
+	s.patterns[key] = &filterPattern{usages: usages, count: 1}
+}
+
+// Total returns the number of recorded filters.
+func (s *FilterStats) Total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// SuggestIndexes returns composite index suggestions for the recorded filter
+// traffic, most frequently useful first. Suggestions serving fewer than
+// minCount filters are omitted.
+func (s *FilterStats) SuggestIndexes(minCount int) []IndexSuggestion {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Column popularity orders equality columns within an index
+	popularity := make(map[string]int)
+	for _, pattern := range s.patterns {
+		for _, usage := range pattern.usages {
+			popularity[usage.Column] += pattern.count
+		}
+	}
+
+	suggestions := make(map[string]*IndexSuggestion)
+	for _, pattern := range s.patterns {
+		suggestion := suggestIndex(pattern.usages, popularity)
+		if suggestion == nil {
+			continue
+		}
+		key := strings.Join(suggestion.Columns, ",")
+		if existing, ok := suggestions[key]; ok {
+			existing.Count += pattern.count
+			continue
+		}
+		suggestion.Count = pattern.count
+		suggestions[key] = suggestion
+	}
+
+	result := make([]IndexSuggestion, 0, len(suggestions))
+	for _, key := range slices.Sorted(maps.Keys(suggestions)) {
+		suggestion := suggestions[key]
+		if suggestion.Count < minCount {
+			continue
+		}
+		suggestion.Share = float64(suggestion.Count) / float64(s.total)
+		result = append(result, *suggestion)
+	}
+	slices.SortStableFunc(result, func(a, b IndexSuggestion) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	return result
+}
+
+// suggestIndex designs a composite index for a combination of column usages:
+// equality columns by decreasing popularity, followed by the most popular
+// range or prefix column. Pattern predicates cannot use the index.
+func suggestIndex(usages []ColumnUsage, popularity map[string]int) *IndexSuggestion {
+	var equality, ranged []string
+	seen := make(map[string]bool)
+	for _, usage := range usages {
+		switch usage.Operator {
+		case OperatorEquality:
+			equality = append(equality, usage.Column)
+			seen[usage.Column] = true
+		case OperatorRange, OperatorPrefix:
+			ranged = append(ranged, usage.Column)
+		}
+	}
+
+	byPopularity := func(a, b string) int {
+		return cmp.Or(cmp.Compare(popularity[b], popularity[a]), cmp.Compare(a, b))
+	}
+	slices.SortFunc(equality, byPopularity)
+	slices.SortFunc(ranged, byPopularity)
+
+	suggestion := &IndexSuggestion{}
+	for _, column := range equality {
+		suggestion.Columns = append(suggestion.Columns, column)
+		suggestion.Operators = append(suggestion.Operators, OperatorEquality)
+	}
+	for _, column := range ranged {
+		if seen[column] {
+			continue
+		}
+		operator := OperatorRange
+		for _, usage := range usages {
+			if usage.Column == column && usage.Operator == OperatorPrefix {
+				operator = OperatorPrefix
+			}
+		}
+		suggestion.Columns = append(suggestion.Columns, column)
+		suggestion.Operators = append(suggestion.Operators, operator)
+		break
+	}
+
+	if len(suggestion.Columns) == 0 {
+		return nil
+	}
+	return suggestion
+}
+
+// columnUsages extracts the indexable column usages of a filter: the simple
+// predicates on plain fields among its top-level && operands. Disjunctions
+// and computed columns are ignored.
+func (c *Converter) columnUsages(expr celast.Expr) []ColumnUsage {
+	var usages []ColumnUsage
+	for _, conjunct := range splitConjuncts(expr) {
+		var (
+			target   celast.Expr
+			operator OperatorClass
+		)
+
+		if conjunct.Kind() == celast.IdentKind {
+			usages = append(usages, ColumnUsage{Column: c.mapFieldName(conjunct.AsIdent()), Operator: OperatorEquality})
+			continue
+		}
+
+		if conjunct.Kind() != celast.CallKind {
+			continue
+		}
+		call := conjunct.AsCall()
+
+		switch call.FunctionName() {
+		case "_==_", "@in":
+			operator = OperatorEquality
+		case "_<_", "_<=_", "_>_", "_>=_":
+			operator = OperatorRange
+		case "_!=_":
+			operator = OperatorPattern
+		case "startsWith":
+			target, operator = call.Target(), OperatorPrefix
+		case "contains", "endsWith", "equalsIgnoreCase":
+			target, operator = call.Target(), OperatorPattern
+		default:
+			continue
+		}
+		if target == nil {
+			if len(call.Args()) == 0 {
+				continue
+			}
+			target = call.Args()[0]
+		}
+
+		field, err := c.getFieldName(target)
+		if err != nil {
+			continue
+		}
+		usages = append(usages, ColumnUsage{Column: c.mapFieldName(field), Operator: operator})
+	}
+	return usages
+}
@@ -0,0 +1,154 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func newTestStatsConverter(t *testing.T, stats *FilterStats) *Converter {
+	t.Helper()
+
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"tenant":    {Type: cel.StringType, Column: "tenant_id"},
+			"status":    {Type: cel.StringType},
+			"name":      {Type: cel.StringType},
+			"createdAt": {Type: cel.IntType, Column: "created_at"},
+			"active":    {Type: cel.BoolType},
+		},
+		Stats: stats,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestFilterStats_ColumnUsages(t *testing.T) {
+	converter := newTestStatsConverter(t, nil)
+
+	tests := []struct {
+		name string
+		expr string
+		want []ColumnUsage
+	}{
+		{
+			name: "equality and range",
+			expr: `tenant == "t1" && createdAt > 100`,
+			want: []ColumnUsage{
+				{Column: "tenant_id", Operator: OperatorEquality},
+				{Column: "created_at", Operator: OperatorRange},
+			},
+		},
+		{
+			name: "in, prefix and pattern",
+			expr: `status in ["a", "b"] && name.startsWith("x") && name.contains("y")`,
+			want: []ColumnUsage{
+				{Column: "status", Operator: OperatorEquality},
+				{Column: "name", Operator: OperatorPrefix},
+				{Column: "name", Operator: OperatorPattern},
+			},
+		},
+		{
+			name: "boolean field",
+			expr: `active && status != "x"`,
+			want: []ColumnUsage{
+				{Column: "active", Operator: OperatorEquality},
+				{Column: "status", Operator: OperatorPattern},
+			},
+		},
+		{
+			name: "disjunctions are ignored",
+			expr: `tenant == "t1" && (status == "a" || createdAt > 1)`,
+			want: []ColumnUsage{
+				{Column: "tenant_id", Operator: OperatorEquality},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checked, issues := converter.env.Compile(tt.expr)
+			if issues != nil && issues.Err() != nil {
+				t.Fatalf("Compile() error = %v", issues.Err())
+			}
+			got := converter.columnUsages(checked.NativeRep().Expr())
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columnUsages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterStats_SuggestIndexes(t *testing.T) {
+	stats := NewFilterStats()
+	converter := newTestStatsConverter(t, stats)
+
+	filters := []string{
+		`tenant == "t1" && status == "open" && createdAt > 100`,
+		`status == "closed" && tenant == "t2" && createdAt < 5`,
+		`tenant == "t1" && status == "open" && createdAt >= 7`,
+		`tenant == "t1" && name.startsWith("a")`,
+		`name.contains("x")`,
+	}
+	for _, filter := range filters {
+		if _, err := converter.Convert(filter); err != nil {
+			t.Fatalf("Convert(%q) error = %v", filter, err)
+		}
+	}
+	if _, err := converter.Convert(`unknown == 1`); err == nil {
+		t.Fatal("expected error for undeclared field")
+	}
+
+	if got := stats.Total(); got != len(filters) {
+		t.Fatalf("Total() = %d, want %d", got, len(filters))
+	}
+
+	want := []IndexSuggestion{
+		{
+			Columns:   []string{"tenant_id", "status", "created_at"},
+			Operators: []OperatorClass{OperatorEquality, OperatorEquality, OperatorRange},
+			Count:     3,
+			Share:     0.6,
+		},
+		{
+			Columns:   []string{"tenant_id", "name"},
+			Operators: []OperatorClass{OperatorEquality, OperatorPrefix},
+			Count:     1,
+			Share:     0.2,
+		},
+	}
+	if got := stats.SuggestIndexes(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("SuggestIndexes(1) = %+v, want %+v", got, want)
+	}
+
+	if got := stats.SuggestIndexes(2); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("SuggestIndexes(2) = %+v, want %+v", got, want[:1])
+	}
+}
+
+func TestFilterStats_Concurrent(t *testing.T) {
+	stats := NewFilterStats()
+	converter := newTestStatsConverter(t, stats)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10 {
+				if _, err := converter.Convert(`tenant == "t1"`); err != nil {
+					t.Errorf("Convert() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := stats.Total(); got != 80 {
+		t.Errorf("Total() = %d, want 80", got)
+	}
+}
@@ -0,0 +1,91 @@
+package cel2squirrel
+
+import (
+	"fmt"
+
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// stringExtFunctions maps the translated functions of the cel-go strings
+// extension to their number of arguments.
+var stringExtFunctions = map[string]int{
+	"trim":       0,
+	"lowerAscii": 0,
+	"upperAscii": 0,
+	"replace":    2,
+	"indexOf":    1,
+}
+
+// getStringExtExpr renders a call to the strings extension on a string
+// operand: trim(), lowerAscii() and upperAscii() as TRIM, LOWER and UPPER,
+// replace() with constant arguments as REPLACE and indexOf() with a constant
+// argument as the dialect's 0-based substring position.
+func (c *Converter) getStringExtExpr(call celast.CallExpr) (operand, error) {
+	function, args := call.FunctionName(), call.Args()
+	if arity := stringExtFunctions[function]; len(args) != arity {
+		return operand{}, newConversionError(
+			"unsupported filter operation",
+			CodeUnsupportedOperation,
+			fmt.Errorf("%s() is only supported with %d arguments, got %d", function, arity, len(args)),
+		)
+	}
+
+	inner, err := c.getColumnExpr(call.Target())
+	if err != nil {
+		return operand{}, err
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		value, err := c.getConstantValue(arg)
+		if err != nil {
+			return operand{}, err
+		}
+		str, ok := value.(string)
+		if !ok {
+			return operand{}, fmt.Errorf("%s() requires string arguments, got %T", function, value)
+		}
+		values[i] = str
+	}
+
+	switch function {
+	case "trim":
+		return inner.apply("TRIM(%s)", "string"), nil
+	case "lowerAscii":
+		return inner.apply("LOWER(%s)", "string"), nil
+	case "upperAscii":
+		return inner.apply("UPPER(%s)", "string"), nil
+	case "replace":
+		if values[0] == "" {
+			// CEL inserts the replacement between every character
+			return operand{}, newConversionError(
+				"unsupported filter operation",
+				CodeUnsupportedOperation,
+				fmt.Errorf("replace() of an empty string has no SQL translation"),
+			)
+		}
+		replaced := inner.apply("REPLACE(%s, ?, ?)", "string")
+		replaced.args = append(replaced.args, values[0], values[1])
+		return replaced, nil
+	default: // indexOf
+		template, needleFirst := c.dialect.indexOf()
+		position := inner.apply(template, "int")
+		if needleFirst {
+			position.args = append([]interface{}{values[0]}, inner.args...)
+		} else {
+			position.args = append(position.args, values[0])
+		}
+		return position, nil
+	}
+}
+
+// apply wraps the operand in a SQL function template with a single %s verb,
+// typed as the given CEL type.
+func (o operand) apply(template, celType string) operand {
+	return operand{
+		field:  o.field,
+		sql:    fmt.Sprintf(template, o.sql),
+		args:   o.bound(),
+		castTo: celType,
+	}
+}
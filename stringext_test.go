@@ -0,0 +1,153 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_StringExtensions(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"name":  {Type: cel.StringType, Column: "user_name"},
+		"email": {Type: cel.StringType},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "trim",
+			celExpr:  `name.trim() == "bob"`,
+			wantSQL:  "TRIM(user_name) = ?",
+			wantArgs: []any{"bob"},
+		},
+		{
+			name:     "case conversion",
+			celExpr:  `email.lowerAscii().endsWith("@example.com") && name.upperAscii() != "ROOT"`,
+			wantSQL:  "(LOWER(email) LIKE ? AND UPPER(user_name) <> ?)",
+			wantArgs: []any{"%@example.com", "ROOT"},
+		},
+		{
+			name:     "replace",
+			celExpr:  `name.replace("-", "").startsWith("ab")`,
+			wantSQL:  "REPLACE(user_name, ?, ?) LIKE ?",
+			wantArgs: []any{"-", "", "ab%"},
+		},
+		{
+			name:     "nested in",
+			celExpr:  `name.trim().lowerAscii() in ["a", "b"]`,
+			wantSQL:  "LOWER(TRIM(user_name)) IN (?,?)",
+			wantArgs: []any{"a", "b"},
+		},
+		{
+			name:     "indexOf default",
+			celExpr:  `email.indexOf("@") > 0`,
+			wantSQL:  "(POSITION(? IN email) - 1) > ?",
+			wantArgs: []any{"@", int64(0)},
+		},
+		{
+			name:     "indexOf postgres",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `name.replace(".", "").indexOf("x") == -1`,
+			wantSQL:  "(STRPOS(REPLACE(user_name, ?, ?), ?) - 1) = ?",
+			wantArgs: []any{".", "", "x", int64(-1)},
+		},
+		{
+			name:     "indexOf mysql",
+			dialect:  DialectMySQL,
+			celExpr:  `name.replace(".", "").indexOf("x") >= 2`,
+			wantSQL:  "(LOCATE(?, REPLACE(user_name, ?, ?)) - 1) >= ?",
+			wantArgs: []any{"x", ".", "", int64(2)},
+		},
+		{
+			name:     "indexOf sqlite",
+			dialect:  DialectSQLite,
+			celExpr:  `email.indexOf("@") < 3`,
+			wantSQL:  "(INSTR(email, ?) - 1) < ?",
+			wantArgs: []any{"@", int64(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{
+				FieldDeclarations: fields,
+				Dialect:           tt.dialect,
+				StringExtensions:  true,
+				AuditSQL:          true,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_StringExtensionsUnsupported(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"name": {Type: cel.StringType},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		converter, err := NewConverter(Config{FieldDeclarations: fields})
+		if err != nil {
+			t.Fatalf("failed to create converter: %v", err)
+		}
+		_, err = converter.Convert(`name.trim() == "bob"`)
+		if err == nil || err.(*ConversionError).ErrorCode != "INVALID_SYNTAX" {
+			t.Errorf("Convert() error = %v, want INVALID_SYNTAX", err)
+		}
+	})
+
+	converter, err := NewConverter(Config{FieldDeclarations: fields, StringExtensions: true})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	for _, celExpr := range []string{
+		`name.replace("a", "b", 1) == "x"`,
+		`name.replace("", "-") == "x"`,
+		`name.indexOf("a", 2) == 3`,
+		`name.reverse() == "x"`,
+		`name.replace(name, "b") == "x"`,
+	} {
+		t.Run(celExpr, func(t *testing.T) {
+			_, err := converter.Convert(celExpr)
+			if err == nil || err.(*ConversionError).ErrorCode != "UNSUPPORTED_OPERATION" {
+				t.Errorf("Convert() error = %v, want UNSUPPORTED_OPERATION", err)
+			}
+		})
+	}
+
+	t.Run("residual", func(t *testing.T) {
+		result, err := converter.ConvertHybrid(`name.trim() == "bob" && name.reverse() == "bob"`)
+		if err != nil {
+			t.Fatalf("ConvertHybrid() error = %v", err)
+		}
+		matched, err := result.Residual.Eval(map[string]any{"name": "bob"})
+		if err != nil || !matched {
+			t.Errorf("Eval() = %v, %v, want true", matched, err)
+		}
+	})
+}
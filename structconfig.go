@@ -0,0 +1,189 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// tagTypes are the CEL types accepted by the type option of cel tags.
+var tagTypes = map[string]*cel.Type{
+	"string":    cel.StringType,
+	"int":       cel.IntType,
+	"uint":      cel.UintType,
+	"double":    cel.DoubleType,
+	"bool":      cel.BoolType,
+	"timestamp": cel.TimestampType,
+	"duration":  cel.DurationType,
+}
+
+// ConfigFromStruct derives a Config from the tags of a model struct, or a
+// pointer to one, so that the filter schema follows the model:
+//
+//	type User struct {
+//		ID        uuid.UUID `db:"id" cel:"id,type=string"`
+//		Email     string    `db:"email_address" cel:"email"`
+//		Age       int       `db:"age"`
+//		CreatedAt time.Time `db:"created_at"`
+//		Password  string    `db:"password_hash" cel:"-"`
+//	}
+//
+//	config, err := cel2squirrel.ConfigFromStruct(User{})
+//
+// Exported fields with a db or cel tag are declared: the cel tag names the
+// CEL field, defaulting to the db column, and the db tag names the column,
+// defaulting to the CEL field. Fields tagged "-" in either tag are skipped,
+// as are untagged fields, except embedded structs, whose fields are
+// declared as if they were the outer struct's.
+//
+// The CEL type is inferred from the Go type: strings, signed and unsigned
+// integers, floats, bools, time.Time, time.Duration, the database/sql Null
+// types, pointers to these, slices of these as JSON lists and string-keyed
+// maps of these. Other types, such as UUIDs, require a type option naming the
+// CEL type: string, int, uint, double, bool, timestamp or duration.
+//
+// The Config starts from DefaultConfig.
+func ConfigFromStruct(v any) (Config, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return Config{}, fmt.Errorf("expected a struct, got %T", v)
+	}
+
+	builder := NewConfigBuilder()
+	if err := declareStructFields(builder, t, nil); err != nil {
+		return Config{}, err
+	}
+	return builder.Build()
+}
+
+// declareStructFields declares the tagged fields of struct type t, nested in
+// the embedded structs of path.
+func declareStructFields(builder *ConfigBuilder, t reflect.Type, path []string) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		celTag, celTagged := field.Tag.Lookup("cel")
+		dbTag, dbTagged := field.Tag.Lookup("db")
+		if celTag == "-" || dbTag == "-" {
+			continue
+		}
+		fieldPath := strings.Join(append(path, field.Name), ".")
+
+		if !celTagged && !dbTagged {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			// The exported fields of unexported embedded structs are promoted
+			if field.Anonymous && embedded.Kind() == reflect.Struct && embedded != timeType {
+				if err := declareStructFields(builder, embedded, append(path, field.Name)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(celTag, ",")
+		column, _, _ := strings.Cut(dbTag, ",")
+		if name == "" {
+			name = column
+		}
+		if name == "" {
+			return fmt.Errorf("field %s: tags do not name a CEL field", fieldPath)
+		}
+
+		var celType *cel.Type
+		for option := range strings.SplitSeq(options, ",") {
+			switch key, value, _ := strings.Cut(option, "="); key {
+			case "":
+			case "type":
+				var ok bool
+				if celType, ok = tagTypes[value]; !ok {
+					return fmt.Errorf("field %s: unknown type %q", fieldPath, value)
+				}
+			default:
+				return fmt.Errorf("field %s: unknown cel tag option %q", fieldPath, option)
+			}
+		}
+		if celType == nil {
+			var err error
+			if celType, err = inferCELType(field.Type); err != nil {
+				return fmt.Errorf("field %s: %w", fieldPath, err)
+			}
+		}
+
+		mapping := ColumnMapping{Type: celType}
+		if column != "" && column != name {
+			if !plainColumnName.MatchString(column) {
+				return fmt.Errorf("field %s: invalid column name %q", fieldPath, column)
+			}
+			mapping.Column = column
+		}
+		if err := builder.checkName(name); err != nil {
+			return fmt.Errorf("field %s: %w", fieldPath, err)
+		}
+		builder.config.FieldDeclarations[name] = mapping
+	}
+	return nil
+}
+
+// inferCELType returns the CEL type of values of Go type t.
+func inferCELType(t reflect.Type) (*cel.Type, error) {
+	switch {
+	case t == timeType:
+		return cel.TimestampType, nil
+	case t == durationType:
+		return cel.DurationType, nil
+	case t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null") &&
+		t.Kind() == reflect.Struct && t.NumField() == 2:
+		// sql.NullString{String, Valid}, sql.Null[T]{V, Valid}, ...
+		return inferCELType(t.Field(0).Type)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return cel.StringType, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cel.IntType, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cel.UintType, nil
+	case reflect.Float32, reflect.Float64:
+		return cel.DoubleType, nil
+	case reflect.Bool:
+		return cel.BoolType, nil
+	case reflect.Pointer:
+		return inferCELType(t.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, fmt.Errorf("unsupported type %s, set the type option of its cel tag", t)
+		}
+		elem, err := inferCELType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return cel.ListType(elem), nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map type %s, keys must be strings", t)
+		}
+		elem, err := inferCELType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return cel.MapType(cel.StringType, elem), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s, set the type option of its cel tag", t)
+}
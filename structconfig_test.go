@@ -0,0 +1,156 @@
+package cel2squirrel
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+type structConfigTimestamps struct {
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type structConfigUser struct {
+	structConfigTimestamps
+	ID         [16]byte                `db:"id" cel:"id,type=string"`
+	Email      string                  `db:"email_address" cel:"email"`
+	Age        int32                   `db:"age"`
+	Visits     uint                    `cel:"visits"`
+	Score      float64                 `db:"score,omitempty"`
+	Active     bool                    `db:"is_active" cel:"active"`
+	Nickname   sql.NullString          `db:"nickname"`
+	Rank       sql.Null[int64]         `db:"rank"`
+	Timeout    time.Duration           `db:"timeout"`
+	Tags       []string                `db:"tags"`
+	Attributes map[string]string       `db:"attributes"`
+	Password   string                  `db:"password_hash" cel:"-"`
+	Internal   string                  `db:"-"`
+	Untagged   string                  // skipped: untagged
+	unexported string                  `db:"unexported"` //nolint:unused // skipped: unexported
+	Extra      map[string]sql.NullBool `db:"extra" cel:"extra"`
+}
+
+func TestConfigFromStruct(t *testing.T) {
+	config, err := ConfigFromStruct(&structConfigUser{})
+	if err != nil {
+		t.Fatalf("ConfigFromStruct() error = %v", err)
+	}
+
+	want := map[string]ColumnMapping{
+		"created_at": {Type: cel.TimestampType},
+		"deleted_at": {Type: cel.TimestampType},
+		"id":         {Type: cel.StringType},
+		"email":      {Type: cel.StringType, Column: "email_address"},
+		"age":        {Type: cel.IntType},
+		"visits":     {Type: cel.UintType},
+		"score":      {Type: cel.DoubleType},
+		"active":     {Type: cel.BoolType, Column: "is_active"},
+		"nickname":   {Type: cel.StringType},
+		"rank":       {Type: cel.IntType},
+		"timeout":    {Type: cel.DurationType},
+		"tags":       {Type: cel.ListType(cel.StringType)},
+		"attributes": {Type: cel.MapType(cel.StringType, cel.StringType)},
+		"extra":      {Type: cel.MapType(cel.StringType, cel.BoolType)},
+	}
+	if !reflect.DeepEqual(config.FieldDeclarations, want) {
+		t.Errorf("FieldDeclarations = %v, want %v", config.FieldDeclarations, want)
+	}
+
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("NewConverter() error = %v", err)
+	}
+	result, err := converter.Convert(`email.endsWith("@example.com") && active && deleted_at == null`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "((email_address LIKE ? AND is_active = ?) AND deleted_at IS NULL)"; sql != want {
+		t.Errorf("SQL = %q, want %q", sql, want)
+	}
+}
+
+func TestConfigFromStruct_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       any
+		wantErr string
+	}{
+		{
+			name:    "not a struct",
+			v:       map[string]string{},
+			wantErr: "expected a struct, got map[string]string",
+		},
+		{
+			name:    "nil",
+			v:       nil,
+			wantErr: "expected a struct, got <nil>",
+		},
+		{
+			name: "unsupported type",
+			v: struct {
+				ID [16]byte `db:"id"`
+			}{},
+			wantErr: "field ID: unsupported type [16]uint8, set the type option of its cel tag",
+		},
+		{
+			name: "unknown type option",
+			v: struct {
+				ID [16]byte `db:"id" cel:"id,type=uuid"`
+			}{},
+			wantErr: `field ID: unknown type "uuid"`,
+		},
+		{
+			name: "unknown option",
+			v: struct {
+				ID string `cel:"id,public"`
+			}{},
+			wantErr: `field ID: unknown cel tag option "public"`,
+		},
+		{
+			name: "unnamed",
+			v: struct {
+				ID string `db:",omitempty"`
+			}{},
+			wantErr: "field ID: tags do not name a CEL field",
+		},
+		{
+			name: "duplicate",
+			v: struct {
+				structConfigTimestamps
+				Created time.Time `db:"created" cel:"created_at"`
+			}{},
+			wantErr: "field Created: field created_at is already declared",
+		},
+		{
+			name: "invalid column",
+			v: struct {
+				Name string `db:"full name" cel:"name"`
+			}{},
+			wantErr: `field Name: invalid column name "full name"`,
+		},
+		{
+			name: "non-string map keys",
+			v: struct {
+				Counts map[int]int `db:"counts"`
+			}{},
+			wantErr: "field Counts: unsupported map type map[int]int, keys must be strings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConfigFromStruct(tt.v)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ConfigFromStruct() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
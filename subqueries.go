@@ -0,0 +1,109 @@
+package cel2squirrel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// SubqueryFunction declares a CEL function without arguments standing for
+// the set of values selected by a SQL subquery, e.g. allowedOrgs() in
+// `orgId in allowedOrgs()`, converted to
+// org_id IN (SELECT org_id FROM memberships WHERE user_id = ?). It lets
+// servers express authorization-scoped membership checks without fetching
+// the values first. The function may only be used on the right of `in`.
+type SubqueryFunction struct {
+	// Name is the CEL function name.
+	Name string
+	// ElemType is the type of the selected values.
+	ElemType *cel.Type
+	// Subquery returns the subquery selecting the values, given the context
+	// of the conversion, e.g. to read the identity of the caller. It is
+	// trusted, must select a single column and must use question mark
+	// placeholders, e.g.
+	// squirrel.Select("org_id").From("memberships").Where(squirrel.Eq{"user_id": id}).
+	Subquery func(ctx context.Context) (squirrel.Sqlizer, error)
+}
+
+// newSubqueries validates the subquery functions and declares them.
+func newSubqueries(declared []SubqueryFunction, fields map[string]ColumnMapping) (map[string]SubqueryFunction, []cel.EnvOption, error) {
+	subqueries := make(map[string]SubqueryFunction, len(declared))
+	var opts []cel.EnvOption
+	for _, fn := range declared {
+		if fn.Name == "" || fn.ElemType == nil || fn.Subquery == nil {
+			return nil, nil, fmt.Errorf("invalid subquery function %q: name, element type and subquery are required", fn.Name)
+		}
+		if _, exists := subqueries[fn.Name]; exists {
+			return nil, nil, fmt.Errorf("invalid subquery function: duplicate function %s", fn.Name)
+		}
+		if _, clash := fields[fn.Name]; clash {
+			return nil, nil, fmt.Errorf("subquery function %s conflicts with a field of the same name", fn.Name)
+		}
+		subqueries[fn.Name] = fn
+		overloadID := fmt.Sprintf("cel2squirrel_subquery_%s", fn.Name)
+		opts = append(opts, cel.Function(fn.Name, cel.Overload(overloadID, nil, cel.ListType(fn.ElemType))))
+	}
+	return subqueries, opts, nil
+}
+
+// subqueryIn is the membership of an operand in the values selected by a
+// subquery.
+type subqueryIn struct {
+	lhs      operand
+	subquery squirrel.Sqlizer
+	not      bool
+}
+
+// ToSql implements squirrel.Sqlizer.
+func (e *subqueryIn) ToSql() (string, []interface{}, error) {
+	sql, args, err := e.subquery.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	op := "IN"
+	if e.not {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", e.lhs.sql, op, sql), append(e.lhs.bound(), args...), nil
+}
+
+// subqueryFunction returns the subquery function called by expr, if any.
+func (c *Converter) subqueryFunction(expr celast.Expr) (SubqueryFunction, bool) {
+	call := expr.AsCall()
+	if expr.Kind() != celast.CallKind || call.IsMemberFunction() || len(call.Args()) != 0 {
+		return SubqueryFunction{}, false
+	}
+	fn, ok := c.subqueries[call.FunctionName()]
+	return fn, ok
+}
+
+// convertSubqueryMembership converts `value in fn()` to value IN (subquery).
+func (c *Converter) convertSubqueryMembership(element celast.Expr, fn SubqueryFunction) (squirrel.Sqlizer, error) {
+	lhs, err := c.getColumnExpr(element)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.conv != nil {
+		c.conv.contextual = true
+	}
+	subquery, err := fn.Subquery(c.context())
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", fn.Name, err)
+	}
+	sql, args, err := subquery.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s(): failed to render subquery: %w", fn.Name, err)
+	}
+
+	if err := c.charge(0, len(args), len(sql)); err != nil {
+		return nil, err
+	}
+	if c.conv != nil {
+		c.conv.trusted = append(c.conv.trusted, sql)
+	}
+	return &subqueryIn{lhs: lhs, subquery: subquery}, nil
+}
@@ -0,0 +1,135 @@
+package cel2squirrel
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+)
+
+type userIDKey struct{}
+
+func newTestSubqueryConverter(t *testing.T, config Config) *Converter {
+	t.Helper()
+
+	config.FieldDeclarations = map[string]ColumnMapping{
+		"orgId":  {Type: cel.StringType, Column: "org_id"},
+		"status": {Type: cel.StringType},
+	}
+	config.Subqueries = []SubqueryFunction{{
+		Name:     "allowedOrgs",
+		ElemType: cel.StringType,
+		Subquery: func(ctx context.Context) (squirrel.Sqlizer, error) {
+			userID, ok := ctx.Value(userIDKey{}).(string)
+			if !ok {
+				return nil, errors.New("no user")
+			}
+			return squirrel.Select("org_id").From("memberships").Where(squirrel.Eq{"user_id": userID}), nil
+		},
+	}}
+	converter, err := NewConverter(config)
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	return converter
+}
+
+func TestConverter_Convert_Subquery(t *testing.T) {
+	converter := newTestSubqueryConverter(t, Config{AuditSQL: true, PushDownNot: true})
+	ctx := context.WithValue(context.Background(), userIDKey{}, "u1")
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "membership",
+			celExpr:  `orgId in allowedOrgs()`,
+			wantSQL:  "org_id IN (SELECT org_id FROM memberships WHERE user_id = ?)",
+			wantArgs: []interface{}{"u1"},
+		},
+		{
+			name:     "combined",
+			celExpr:  `status == "active" && orgId in allowedOrgs()`,
+			wantSQL:  "(status = ? AND org_id IN (SELECT org_id FROM memberships WHERE user_id = ?))",
+			wantArgs: []interface{}{"active", "u1"},
+		},
+		{
+			name:     "negated",
+			celExpr:  `!(orgId in allowedOrgs())`,
+			wantSQL:  "org_id NOT IN (SELECT org_id FROM memberships WHERE user_id = ?)",
+			wantArgs: []interface{}{"u1"},
+		},
+		{
+			name:     "converted operand",
+			celExpr:  `string(orgId) in allowedOrgs()`,
+			wantSQL:  "CAST(org_id AS VARCHAR) IN (SELECT org_id FROM memberships WHERE user_id = ?)",
+			wantArgs: []interface{}{"u1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.ConvertContext(ctx, tt.celExpr)
+			if err != nil {
+				t.Fatalf("ConvertContext() error = %v", err)
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_SubqueryErrors(t *testing.T) {
+	converter := newTestSubqueryConverter(t, Config{})
+
+	if _, err := converter.Convert(`orgId in allowedOrgs()`); err == nil {
+		t.Error("Convert() without a user should fail")
+	}
+
+	ctx := context.WithValue(context.Background(), userIDKey{}, "u1")
+	_, err := converter.ConvertContext(ctx, `size(allowedOrgs()) > 0`)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.ErrorCode != "UNSUPPORTED_OPERATION" {
+		t.Errorf("ConvertContext() error = %v, want UNSUPPORTED_OPERATION", err)
+	}
+}
+
+func TestNewConverter_InvalidSubquery(t *testing.T) {
+	tests := []struct {
+		name       string
+		subqueries []SubqueryFunction
+	}{
+		{name: "missing subquery", subqueries: []SubqueryFunction{{Name: "allowedOrgs", ElemType: cel.StringType}}},
+		{name: "field clash", subqueries: []SubqueryFunction{{
+			Name:     "orgId",
+			ElemType: cel.StringType,
+			Subquery: func(context.Context) (squirrel.Sqlizer, error) { return squirrel.Expr("SELECT 1"), nil },
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConverter(Config{
+				FieldDeclarations: map[string]ColumnMapping{"orgId": {Type: cel.StringType}},
+				Subqueries:        tt.subqueries,
+			})
+			if err == nil {
+				t.Error("NewConverter() should fail")
+			}
+		})
+	}
+}
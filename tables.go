@@ -0,0 +1,47 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldTable returns the table or alias qualifying the column of a field:
+// its own Table, the table it is joined from, or the converter's TableAlias.
+func fieldTable(name string, mapping ColumnMapping, alias string) (string, error) {
+	table := mapping.Table
+	if mapping.Join != nil {
+		if table != "" && table != mapping.Join.name() {
+			return "", fmt.Errorf("field %s: table %s does not match its join", name, table)
+		}
+		if mapping.Expr != "" {
+			return "", nil
+		}
+		table = mapping.Join.name()
+	}
+	if table == "" {
+		if mapping.Expr != "" {
+			// Computed fields qualify their columns themselves
+			return "", nil
+		}
+		table = alias
+	}
+	if table != "" && !isSQLIdentifier(table) {
+		return "", fmt.Errorf("field %s: invalid table %q", name, table)
+	}
+	return table, nil
+}
+
+// qualifyColumn prefixes a column with its table. Columns that are already
+// qualified are left untouched.
+func qualifyColumn(table, column string) string {
+	if table == "" || strings.Contains(column, ".") {
+		return column
+	}
+	return table + "." + column
+}
+
+// isSQLIdentifier reports whether s is a single bare or quoted identifier.
+func isSQLIdentifier(s string) bool {
+	tokens, err := tokenizeSQL(s)
+	return err == nil && len(tokens) == 1 && tokens[0].kind == tokenIdentifier && tokens[0].text == s
+}
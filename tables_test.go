@@ -0,0 +1,132 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_TableQualified(t *testing.T) {
+	converter, err := NewConverter(Config{
+		Dialect:    DialectPostgreSQL,
+		TableAlias: "p",
+		FieldDeclarations: map[string]ColumnMapping{
+			"status":   {Type: cel.StringType},
+			"author":   {Type: cel.StringType, Column: "name", Table: "u"},
+			"created":  {Type: cel.IntType, Column: "c.created_at"},
+			"city":     {Type: cel.StringType, Column: "profile", JSONPath: "address.city"},
+			"email":    {Type: cel.StringType, Expr: "LOWER(u.email)"},
+			"tags":     {Type: cel.ListType(cel.StringType), Kind: KindArray},
+			"category": {Type: cel.StringType, Table: `"Category"`},
+		},
+		AuditSQL: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "table alias",
+			celExpr:  `status == "published"`,
+			wantSQL:  "p.status = ?",
+			wantArgs: []any{"published"},
+		},
+		{
+			name:     "field table",
+			celExpr:  `author.startsWith("A") && category != "misc"`,
+			wantSQL:  `(u.name LIKE ? AND "Category".category <> ?)`,
+			wantArgs: []any{"A%", "misc"},
+		},
+		{
+			name:     "qualified column",
+			celExpr:  `created > 10`,
+			wantSQL:  "c.created_at > ?",
+			wantArgs: []any{int64(10)},
+		},
+		{
+			name:     "json path",
+			celExpr:  `city == "Paris"`,
+			wantSQL:  "p.profile #>> '{address,city}' = ?",
+			wantArgs: []any{"Paris"},
+		},
+		{
+			name:     "computed field",
+			celExpr:  `email == "a@b.c"`,
+			wantSQL:  "LOWER(u.email) = ?",
+			wantArgs: []any{"a@b.c"},
+		},
+		{
+			name:     "array",
+			celExpr:  `"go" in tags`,
+			wantSQL:  "? = ANY(p.tags)",
+			wantArgs: []any{"go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	mapping, _ := converter.Field("status")
+	if mapping.Column != "status" || mapping.Table != "p" {
+		t.Errorf("Field(status) = %+v, want column status of table p", mapping)
+	}
+}
+
+func TestNewConverter_InvalidTable(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{
+			name: "alias",
+			config: Config{
+				TableAlias:        "p; DROP TABLE x",
+				FieldDeclarations: map[string]ColumnMapping{"status": {Type: cel.StringType}},
+			},
+		},
+		{
+			name: "field table",
+			config: Config{
+				FieldDeclarations: map[string]ColumnMapping{"status": {Type: cel.StringType, Table: "a.b"}},
+			},
+		},
+		{
+			name: "computed field",
+			config: Config{
+				FieldDeclarations: map[string]ColumnMapping{"email": {Type: cel.StringType, Table: "u", Expr: "LOWER(email)"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConverter(tt.config); err == nil {
+				t.Fatal("NewConverter() expected error")
+			}
+		})
+	}
+}
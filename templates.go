@@ -0,0 +1,177 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// FunctionTemplate declares a custom boolean CEL member function whose SQL
+// translation is given by a template. The function is called on a field,
+// e.g. `name.similarTo("bob", 3)`, and the template references the field's
+// column as {col} and the call arguments as {arg0}, {arg1}, ...
+//
+// Arguments must be literals; each reference is bound as a query parameter.
+//
+// Example:
+//
+//	FunctionTemplate{
+//	    Name:         "similarTo",
+//	    ReceiverType: cel.StringType,
+//	    ArgTypes:     []*cel.Type{cel.StringType, cel.IntType},
+//	    SQL:          "levenshtein({col}, {arg0}) < {arg1}",
+//	}
+type FunctionTemplate struct {
+	// Name is the CEL function name.
+	Name string
+	// ReceiverType is the CEL type of the field the function is called on.
+	ReceiverType *cel.Type
+	// ArgTypes are the CEL types of the function arguments.
+	ArgTypes []*cel.Type
+	// SQL is the template rendered for each call.
+	SQL string
+}
+
+// templatePart is a literal SQL fragment followed by an optional placeholder.
+type templatePart struct {
+	literal string
+	// arg is the argument index of the placeholder, -1 for {col} and -2 when
+	// the part has no placeholder.
+	arg int
+	// like is set for arguments used as raw LIKE patterns, e.g. in
+	// "{col} LIKE {arg0}", which are analyzed like escaped ones.
+	like bool
+}
+
+const (
+	templateColumn = -1
+	templateNone   = -2
+)
+
+// sqlTemplate is a parsed FunctionTemplate.
+type sqlTemplate struct {
+	name     string
+	receiver *cel.Type
+	arity    int
+	argTypes []*cel.Type
+	parts    []templatePart
+}
+
+// parseSQLTemplate validates a FunctionTemplate and splits its SQL into
+// literal fragments and placeholders. Unknown placeholders, references to
+// undeclared arguments, unbalanced braces and raw ? markers are rejected so
+// that every bound value is accounted for.
+func parseSQLTemplate(fn FunctionTemplate) (*sqlTemplate, error) {
+	if fn.Name == "" {
+		return nil, fmt.Errorf("function template requires a name")
+	}
+	if fn.ReceiverType == nil {
+		return nil, fmt.Errorf("function %s requires a receiver type", fn.Name)
+	}
+	if strings.Contains(fn.SQL, "?") {
+		return nil, fmt.Errorf("function %s: SQL template must not contain raw ? placeholders", fn.Name)
+	}
+
+	tmpl := &sqlTemplate{name: fn.Name, receiver: fn.ReceiverType, arity: len(fn.ArgTypes), argTypes: fn.ArgTypes}
+	rest := fn.SQL
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if closing := strings.IndexByte(rest, '}'); closing >= 0 && (open < 0 || closing < open) {
+			return nil, fmt.Errorf("function %s: unbalanced '}' in SQL template", fn.Name)
+		}
+		if open < 0 {
+			tmpl.parts = append(tmpl.parts, templatePart{literal: rest, arg: templateNone})
+			break
+		}
+
+		closing := strings.IndexByte(rest[open:], '}')
+		if closing < 0 {
+			return nil, fmt.Errorf("function %s: unbalanced '{' in SQL template", fn.Name)
+		}
+
+		name := rest[open+1 : open+closing]
+		part := templatePart{literal: rest[:open]}
+		switch {
+		case name == "col":
+			part.arg = templateColumn
+		case strings.HasPrefix(name, "arg"):
+			index, err := strconv.Atoi(strings.TrimPrefix(name, "arg"))
+			if err != nil || index < 0 || index >= tmpl.arity {
+				return nil, fmt.Errorf("function %s: placeholder {%s} does not match a declared argument", fn.Name, name)
+			}
+			part.arg = index
+			fields := strings.Fields(strings.ToUpper(part.literal))
+			part.like = len(fields) > 0 && (fields[len(fields)-1] == "LIKE" || fields[len(fields)-1] == "ILIKE")
+		default:
+			return nil, fmt.Errorf("function %s: unknown placeholder {%s} in SQL template", fn.Name, name)
+		}
+
+		tmpl.parts = append(tmpl.parts, part)
+		rest = rest[open+closing+1:]
+	}
+
+	return tmpl, nil
+}
+
+// declaration returns the CEL declaration of the templated function.
+func (fn FunctionTemplate) declaration() cel.EnvOption {
+	argTypes := append([]*cel.Type{fn.ReceiverType}, fn.ArgTypes...)
+	overloadID := fmt.Sprintf("cel2squirrel_template_%s", fn.Name)
+	return cel.Function(fn.Name, cel.MemberOverload(overloadID, argTypes, cel.BoolType))
+}
+
+// matchesLike reports whether the template matches an argument as a LIKE
+// pattern.
+func (t *sqlTemplate) matchesLike() bool {
+	return slices.ContainsFunc(t.parts, func(part templatePart) bool { return part.like })
+}
+
+// convertTemplateCall renders a templated function call.
+func (c *Converter) convertTemplateCall(tmpl *sqlTemplate, call celast.CallExpr) (squirrel.Sqlizer, error) {
+	if len(call.Args()) != tmpl.arity {
+		return nil, fmt.Errorf("%s() requires exactly %d arguments, got %d", tmpl.name, tmpl.arity, len(call.Args()))
+	}
+
+	// Get the column (receiver/target)
+	lhs, err := c.getColumnExpr(call.Target())
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the argument values
+	values := make([]interface{}, len(call.Args()))
+	for i, arg := range call.Args() {
+		if values[i], err = c.getConstantValue(arg); err != nil {
+			return nil, fmt.Errorf("%s() argument %d: %w", tmpl.name, i, err)
+		}
+	}
+
+	var (
+		sql  strings.Builder
+		args []interface{}
+	)
+	for _, part := range tmpl.parts {
+		sql.WriteString(part.literal)
+		switch part.arg {
+		case templateNone:
+		case templateColumn:
+			sql.WriteString(lhs.sql)
+			args = append(args, lhs.args...)
+		default:
+			if pattern, ok := values[part.arg].(string); ok && part.like {
+				if err := c.checkLikePattern(pattern); err != nil {
+					return nil, err
+				}
+			}
+			sql.WriteString("?")
+			args = append(args, values[part.arg])
+		}
+	}
+
+	return squirrel.Expr(sql.String(), args...), nil
+}
@@ -0,0 +1,134 @@
+package cel2squirrel
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_FunctionTemplates(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"name":     {Type: cel.StringType, Column: "user_name"},
+			"location": {Type: cel.StringType, Column: "geo"},
+		},
+		Functions: []FunctionTemplate{
+			{
+				Name:         "similarTo",
+				ReceiverType: cel.StringType,
+				ArgTypes:     []*cel.Type{cel.StringType, cel.IntType},
+				SQL:          "levenshtein({col}, {arg0}) < {arg1}",
+			},
+			{
+				Name:         "isNonEmpty",
+				ReceiverType: cel.StringType,
+				SQL:          "{col} <> ''",
+			},
+			{
+				Name:         "nearBoth",
+				ReceiverType: cel.StringType,
+				ArgTypes:     []*cel.Type{cel.StringType},
+				SQL:          "(ST_Near({col}, {arg0}) OR ST_Near({arg0}, {col}))",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:     "column and arguments",
+			celExpr:  `name.similarTo("bob", 3)`,
+			wantSQL:  "levenshtein(user_name, ?) < ?",
+			wantArgs: []any{"bob", int64(3)},
+		},
+		{
+			name:     "no arguments",
+			celExpr:  `name.isNonEmpty()`,
+			wantSQL:  "user_name <> ''",
+			wantArgs: []any{},
+		},
+		{
+			name:     "repeated placeholder",
+			celExpr:  `location.nearBoth("x")`,
+			wantSQL:  "(ST_Near(geo, ?) OR ST_Near(?, geo))",
+			wantArgs: []any{"x", "x"},
+		},
+		{
+			name:     "combined",
+			celExpr:  `name.similarTo("bob", 2) && !name.isNonEmpty()`,
+			wantSQL:  "(levenshtein(user_name, ?) < ? AND NOT (user_name <> ''))",
+			wantArgs: []any{"bob", int64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("expected %d args, got %d", len(tt.wantArgs), len(args))
+			}
+
+			for i, arg := range args {
+				if arg != tt.wantArgs[i] {
+					t.Errorf("arg %d = %v, want %v", i, arg, tt.wantArgs[i])
+				}
+			}
+		})
+	}
+
+	t.Run("non-literal argument", func(t *testing.T) {
+		if _, err := converter.Convert(`name.similarTo(location, 3)`); err == nil {
+			t.Error("Convert() should reject field arguments")
+		}
+	})
+}
+
+func TestNewConverter_InvalidFunctionTemplates(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   FunctionTemplate
+	}{
+		{name: "missing name", fn: FunctionTemplate{ReceiverType: cel.StringType, SQL: "{col}"}},
+		{name: "missing receiver", fn: FunctionTemplate{Name: "f", SQL: "{col}"}},
+		{name: "unknown placeholder", fn: FunctionTemplate{Name: "f", ReceiverType: cel.StringType, SQL: "{column} = 1"}},
+		{name: "undeclared argument", fn: FunctionTemplate{Name: "f", ReceiverType: cel.StringType, SQL: "{col} = {arg0}"}},
+		{name: "unbalanced open", fn: FunctionTemplate{Name: "f", ReceiverType: cel.StringType, SQL: "{col = 1"}},
+		{name: "unbalanced close", fn: FunctionTemplate{Name: "f", ReceiverType: cel.StringType, SQL: "col} = 1"}},
+		{name: "raw placeholder", fn: FunctionTemplate{Name: "f", ReceiverType: cel.StringType, SQL: "{col} = ?"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConverter(Config{Functions: []FunctionTemplate{tt.fn}}); err == nil {
+				t.Error("NewConverter() should reject invalid function template")
+			}
+		})
+	}
+
+	t.Run("duplicate function", func(t *testing.T) {
+		fn := FunctionTemplate{Name: "f", ReceiverType: cel.StringType, SQL: "{col} = 1"}
+		if _, err := NewConverter(Config{Functions: []FunctionTemplate{fn, fn}}); err == nil {
+			t.Error("NewConverter() should reject duplicate function templates")
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+)
+
+// getTimestampLiteral evaluates a timestamp("...") call with a constant
+// RFC 3339 argument into a time.Time value.
+func (c *Converter) getTimestampLiteral(call celast.CallExpr) (time.Time, error) {
+	args := call.Args()
+	if len(args) != 1 || call.IsMemberFunction() {
+		return time.Time{}, fmt.Errorf("timestamp() requires exactly 1 argument, got %d", len(args))
+	}
+
+	if args[0].Kind() != celast.LiteralKind {
+		return time.Time{}, fmt.Errorf("timestamp() requires a constant string argument")
+	}
+	raw, _ := args[0].AsLiteral().(types.String)
+
+	ts, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return time.Time{}, newConversionError(
+			"invalid timestamp value",
+			CodeInvalidTimestamp,
+			fmt.Errorf("failed to parse timestamp literal: %w", err),
+		)
+	}
+
+	return ts.UTC(), nil
+}
+
+// applyGranularity enforces the timestamp precision declared for a field.
+// Values finer than the granularity are truncated (with a warning) when the
+// field allows it and rejected otherwise. Non-timestamp values pass through.
+func (c *Converter) applyGranularity(field string, value interface{}) (interface{}, error) {
+	ts, ok := value.(time.Time)
+	if !ok {
+		return value, nil
+	}
+
+	mapping, exists := c.fieldDeclarations[field]
+	if !exists || mapping.Granularity <= 0 {
+		return value, nil
+	}
+
+	truncated := ts.Truncate(mapping.Granularity)
+	if truncated.Equal(ts) {
+		return value, nil
+	}
+
+	if !mapping.TruncateToGranularity {
+		return nil, newConversionError(
+			"timestamp precision not supported for this field",
+			CodeUnsupportedPrecision,
+			fmt.Errorf("field %s only supports a granularity of %s, got %s",
+				field, mapping.Granularity, c.displayValue(field, ts.Format(time.RFC3339Nano))),
+		)
+	}
+
+	c.warnf(DiagnosticTimestampTruncated, field, "timestamp %s truncated to %s for field %s (granularity %s)",
+		c.displayValue(field, ts.Format(time.RFC3339Nano)),
+		c.displayValue(field, truncated.Format(time.RFC3339Nano)),
+		field, mapping.Granularity)
+	return truncated, nil
+}
+
+// isTimestampField reports whether field is declared as a timestamp.
+func (c *Converter) isTimestampField(field string) bool {
+	mapping, ok := c.fieldDeclarations[field]
+	return ok && mapping.Type != nil && mapping.Type.Kind() == types.TimestampKind
+}
+
+// timestampComparisons are the operators whose string literal operands
+// compared with a timestamp field are read as timestamps.
+var timestampComparisons = map[string]bool{
+	operators.Equals: true, operators.NotEquals: true,
+	operators.Less: true, operators.LessEquals: true,
+	operators.Greater: true, operators.GreaterEquals: true,
+}
+
+// compileCEL parses and type-checks a CEL expression. With TimestampStrings,
+// the string literals compared with timestamp fields, e.g. in
+// `created_at >= "2024-01-01T00:00:00Z"`, are wrapped in timestamp() calls
+// before type-checking, so that they are validated and converted as such.
+func (c *Converter) compileCEL(celExpr string) (*cel.Ast, *cel.Issues) {
+	if !c.timestampStrings {
+		return c.env.Compile(celExpr)
+	}
+
+	parsed, issues := c.env.Parse(celExpr)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues
+	}
+	c.wrapTimestampStrings(parsed.NativeRep().Expr())
+	return c.env.Check(parsed)
+}
+
+// wrapTimestampStrings wraps the string literals compared with timestamp
+// fields, or listed in the right operand of `in`, into timestamp() calls.
+func (c *Converter) wrapTimestampStrings(expr celast.Expr) {
+	var nextID int64
+	walkExpr(expr, func(e celast.Expr) {
+		nextID = max(nextID, e.ID()+1)
+	})
+
+	wrap := func(e celast.Expr) {
+		value, ok := e.AsLiteral().(types.String)
+		if !ok {
+			return
+		}
+		literal := exprFactory.NewLiteral(nextID, value)
+		nextID++
+		e.SetKindCase(exprFactory.NewCall(e.ID(), "timestamp", literal))
+	}
+
+	walkExpr(expr, func(e celast.Expr) {
+		call := e.AsCall()
+		args := call.Args()
+		if e.Kind() != celast.CallKind || call.IsMemberFunction() || len(args) != 2 {
+			return
+		}
+		switch {
+		case timestampComparisons[call.FunctionName()]:
+			if c.isTimestampField(qualifiedName(args[0])) {
+				wrap(args[1])
+			} else if c.isTimestampField(qualifiedName(args[1])) {
+				wrap(args[0])
+			}
+		case call.FunctionName() == operators.In && c.isTimestampField(qualifiedName(args[0])):
+			for _, elem := range args[1].AsList().Elements() {
+				wrap(elem)
+			}
+		}
+	})
+}
+
+// qualifiedName returns the dotted name of a parsed identifier or field
+// selection, e.g. author.created_at, or "".
+func qualifiedName(expr celast.Expr) string {
+	switch expr.Kind() {
+	case celast.IdentKind:
+		return expr.AsIdent()
+	case celast.SelectKind:
+		sel := expr.AsSelect()
+		if operand := qualifiedName(sel.Operand()); operand != "" && !sel.IsTestOnly() {
+			return operand + "." + sel.FieldName()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,175 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_TimestampLiterals(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"createTime": {Type: cel.TimestampType, Column: "create_time"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`createTime >= timestamp("2024-03-01T10:30:00+02:00")`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+
+	sql, args, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+
+	if sql != "create_time >= ?" {
+		t.Errorf("ToSql() = %v, want create_time >= ?", sql)
+	}
+
+	want := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	if len(args) != 1 || !args[0].(time.Time).Equal(want) {
+		t.Errorf("args = %v, want [%v]", args, want)
+	}
+
+	if _, err := converter.Convert(`createTime < timestamp("yesterday")`); err == nil {
+		t.Error("Convert() should reject malformed timestamp literals")
+	}
+}
+
+func TestConverter_Convert_TimeGranularity(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"day":       {Type: cel.TimestampType, Column: "day", Granularity: 24 * time.Hour},
+		"truncated": {Type: cel.TimestampType, Column: "bucket", Granularity: time.Hour, TruncateToGranularity: true},
+		"precise":   {Type: cel.TimestampType, Column: "precise"},
+	}
+
+	converter, err := NewConverter(Config{FieldDeclarations: fields})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	t.Run("aligned literal accepted", func(t *testing.T) {
+		result, err := converter.Convert(`day == timestamp("2024-03-01T00:00:00Z")`)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+		if len(result.Warnings) != 0 {
+			t.Errorf("unexpected warnings: %v", result.Warnings)
+		}
+	})
+
+	t.Run("finer literal rejected", func(t *testing.T) {
+		_, err := converter.Convert(`day == timestamp("2024-03-01T12:00:00Z")`)
+		var convErr *ConversionError
+		if !errors.As(err, &convErr) || convErr.ErrorCode != "UNSUPPORTED_PRECISION" {
+			t.Fatalf("expected UNSUPPORTED_PRECISION error, got %v", err)
+		}
+	})
+
+	t.Run("finer literal in list rejected", func(t *testing.T) {
+		_, err := converter.Convert(`day in [timestamp("2024-03-01T00:00:00Z"), timestamp("2024-03-02T00:00:01Z")]`)
+		if err == nil {
+			t.Fatal("expected error for misaligned list element")
+		}
+	})
+
+	t.Run("finer literal truncated with warning", func(t *testing.T) {
+		result, err := converter.Convert(`truncated > timestamp("2024-03-01T12:34:56Z")`)
+		if err != nil {
+			t.Fatalf("Convert() error = %v", err)
+		}
+
+		_, args, err := result.Where.ToSql()
+		if err != nil {
+			t.Fatalf("ToSql() error = %v", err)
+		}
+
+		want := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		if !args[0].(time.Time).Equal(want) {
+			t.Errorf("arg = %v, want %v", args[0], want)
+		}
+
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Message, "truncated") {
+			t.Errorf("expected one truncation warning, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("no granularity", func(t *testing.T) {
+		if _, err := converter.Convert(`precise == timestamp("2024-03-01T12:34:56.789Z")`); err != nil {
+			t.Errorf("Convert() error = %v", err)
+		}
+	})
+}
+
+func TestConverter_Convert_TimestampStrings(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"created": {Type: cel.TimestampType, Column: "created_at"},
+		"day":     {Type: cel.TimestampType, Granularity: 24 * time.Hour},
+		"name":    {Type: cel.StringType},
+	}
+
+	strict, err := NewConverter(Config{FieldDeclarations: fields})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	if _, err := strict.Convert(`created >= "2024-01-01T00:00:00Z"`); err == nil {
+		t.Error("strict converter should reject timestamp strings")
+	}
+
+	converter, err := NewConverter(Config{FieldDeclarations: fields, TimestampStrings: true})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		celExpr string
+		wantSQL string
+		want    time.Time
+	}{
+		{celExpr: `created >= "2024-01-01T00:00:00Z"`, wantSQL: "created_at >= ?", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{celExpr: `created < "2024-01-01T02:00:00+02:00"`, wantSQL: "created_at < ?", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{celExpr: `created == "2024-01-01T00:00:00.5Z"`, wantSQL: "created_at = ?", want: time.Date(2024, 1, 1, 0, 0, 0, 5e8, time.UTC)},
+		{celExpr: `day != "2024-03-01T00:00:00Z"`, wantSQL: "day <> ?", want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.celExpr, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if len(args) != 1 || !args[0].(time.Time).Equal(tt.want) {
+				t.Errorf("ToSql() args = %v, want [%v]", args, tt.want)
+			}
+		})
+	}
+
+	for _, celExpr := range []string{
+		`created >= "yesterday"`,
+		`day == "2024-03-01T12:00:00Z"`,
+	} {
+		t.Run(celExpr, func(t *testing.T) {
+			if _, err := converter.Convert(celExpr); err == nil {
+				t.Errorf("Convert(%s) should fail", celExpr)
+			}
+		})
+	}
+
+	// String fields are not affected
+	if _, err := converter.Convert(`name == "2024-01-01T00:00:00Z"`); err != nil {
+		t.Errorf("Convert() error = %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package cel2squirrel
+
+import "fmt"
+
+// transformValue applies the Transform of a field to a value compared with
+// it.
+func (c *Converter) transformValue(field string, value interface{}) (interface{}, error) {
+	transform := c.fieldDeclarations[field].Transform
+	if transform == nil || value == nil {
+		return value, nil
+	}
+	transformed, err := transform(value)
+	if err != nil {
+		return nil, newConversionError(
+			"invalid filter value",
+			CodeInvalidValue,
+			fmt.Errorf("failed to transform value of field %s: %w", field, err),
+		)
+	}
+	return transformed, nil
+}
@@ -0,0 +1,92 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Transform(t *testing.T) {
+	externalIDs := map[string]int64{"usr_a": 1, "usr_b": 2}
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"email": {Type: cel.StringType, Transform: func(value any) (any, error) {
+				return strings.ToLower(strings.TrimSpace(value.(string))), nil
+			}},
+			"user_id": {Type: cel.StringType, Transform: func(value any) (any, error) {
+				id, ok := externalIDs[value.(string)]
+				if !ok {
+					return nil, errors.New("unknown user")
+				}
+				return id, nil
+			}},
+			"name": {Type: cel.StringType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		wantSQL  string
+		wantArgs []interface{}
+		wantCode string
+	}{
+		{
+			name:     "comparison",
+			celExpr:  `email == " Bob@Example.com "`,
+			wantSQL:  "email = ?",
+			wantArgs: []interface{}{"bob@example.com"},
+		},
+		{
+			name:     "in list",
+			celExpr:  `user_id in ["usr_a", "usr_b"]`,
+			wantSQL:  "user_id IN (?,?)",
+			wantArgs: []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:     "inequality",
+			celExpr:  `email != "A@B.C"`,
+			wantSQL:  "email <> ?",
+			wantArgs: []interface{}{"a@b.c"},
+		},
+		{
+			name:     "untransformed field",
+			celExpr:  `name == " Bob "`,
+			wantSQL:  "name = ?",
+			wantArgs: []interface{}{" Bob "},
+		},
+		{
+			name:     "transform error",
+			celExpr:  `user_id == "usr_z"`,
+			wantCode: "INVALID_VALUE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := converter.Convert(tt.celExpr)
+			if got := errorCode(err); got != tt.wantCode {
+				t.Fatalf("Convert() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("SQL = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("Args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
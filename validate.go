@@ -0,0 +1,82 @@
+package cel2squirrel
+
+import (
+	"context"
+
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// Validate checks a filter expression without converting it: its syntax,
+// its boolean type and the length, depth and IN clause limits, reporting
+// the errors Convert would, e.g. for request pre-validation endpoints and
+// linting stored filters. Errors raised while translating to SQL, such as
+// unsupported operations, are only reported by Convert.
+func (c *Converter) Validate(celExpr string) error {
+	return c.ValidateContext(context.Background(), celExpr)
+}
+
+// ValidateContext is like Validate, passing ctx to the SecurityLogger.
+func (c *Converter) ValidateContext(ctx context.Context, celExpr string) error {
+	c = c.current()
+	_, checkedExpr, err := c.compile(ctx, celExpr)
+	if err == nil {
+		err = c.checkExpr(checkedExpr.Expr())
+	}
+	return c.maskError(celExpr, err)
+}
+
+// ValidateWithAuth is like Validate, also checking that the user,
+// identified by their roles, may filter by every field referenced, as
+// ConvertWithAuth does.
+func (c *Converter) ValidateWithAuth(celExpr string, userRoles []string) error {
+	return c.ValidateWithAuthContext(context.Background(), celExpr, userRoles)
+}
+
+// ValidateWithAuthContext is like ValidateWithAuth, passing ctx to the
+// SecurityLogger.
+func (c *Converter) ValidateWithAuthContext(ctx context.Context, celExpr string, userRoles []string) error {
+	c = c.current()
+	if !c.authorization() {
+		return c.ValidateContext(ctx, celExpr)
+	}
+
+	checkedExpr, err := c.checkWithAuth(ctx, celExpr, userRoles, nil)
+	if err == nil {
+		err = c.checkExpr(checkedExpr.Expr())
+	}
+	return c.maskError(celExpr, err)
+}
+
+// checkExpr enforces the limits and restrictions checked during conversion
+// on a compiled expression.
+func (c *Converter) checkExpr(expr celast.Expr) error {
+	if err := c.checkInClauses(expr); err != nil {
+		return err
+	}
+	return c.checkAllowedOps(expr)
+}
+
+// checkInClauses enforces the maximum size of the IN clauses of an
+// expression, listed as literals.
+func (c *Converter) checkInClauses(expr celast.Expr) error {
+	var err error
+	walkExpr(expr, func(e celast.Expr) {
+		call := e.AsCall()
+		if err != nil || call.FunctionName() != "@in" || len(call.Args()) != 2 {
+			return
+		}
+		if list := call.Args()[1]; list.Kind() == celast.ListKind {
+			err = c.checkInClauseSize(list.AsList().Size())
+		}
+	})
+	return err
+}
+
+// maskError masks the values of masked fields in a validation error.
+func (c *Converter) maskError(celExpr string, err error) error {
+	if err == nil {
+		return nil
+	}
+	_, err = c.maskOutput(celExpr, nil, err)
+	return err
+}
@@ -0,0 +1,110 @@
+package cel2squirrel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Validate(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"status": {Type: cel.StringType},
+			"age":    {Type: cel.IntType},
+			"salary": {Type: cel.IntType},
+			"email":  {Type: cel.StringType, Masked: true},
+		},
+		PublicFields:        []string{"status", "age", "email"},
+		FieldACL:            map[string][]string{"salary": {"hr"}},
+		MaxExpressionLength: 100,
+		MaxExpressionDepth:  4,
+		MaxInClauseSize:     3,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		celExpr  string
+		roles    []string
+		wantCode string
+		// convertOnly errors are only detected by Convert
+		convertOnly bool
+	}{
+		{name: "valid", celExpr: `status == "active" && age > 18`},
+		{name: "valid restricted with role", celExpr: `salary > 1000`, roles: []string{"hr"}},
+		{name: "syntax", celExpr: `status ==`, wantCode: "INVALID_SYNTAX"},
+		{name: "undeclared field", celExpr: `unknown == 1`, wantCode: "INVALID_SYNTAX"},
+		{name: "not boolean", celExpr: `age + 1`, wantCode: "INVALID_TYPE"},
+		{name: "length", celExpr: `status == "` + strings.Repeat("x", 100) + `"`, wantCode: "LIMIT_LENGTH"},
+		{name: "depth", celExpr: `age == 1 || (age == 2 && (age == 3 || (age == 4 && age == 5)))`, wantCode: "LIMIT_DEPTH"},
+		{name: "in clause size", celExpr: `status in ["a", "b", "c", "d"]`, wantCode: "LIMIT_IN_SIZE"},
+		{name: "nested in clause size", celExpr: `age > 1 && age in [1, 2, 3, 4]`, wantCode: "LIMIT_IN_SIZE"},
+		{name: "unauthorized", celExpr: `salary > 1000`, roles: []string{"viewer"}, wantCode: "UNAUTHORIZED_FIELD"},
+		{name: "unsupported operation", celExpr: `status.matches("^a")`, wantCode: "UNSUPPORTED_OPERATION", convertOnly: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := converter.ValidateWithAuth(tt.celExpr, tt.roles)
+			_, convertErr := converter.ConvertWithAuth(tt.celExpr, tt.roles)
+
+			if tt.convertOnly {
+				if err != nil {
+					t.Errorf("ValidateWithAuth() error = %v, want nil", err)
+				}
+				if errorCode(convertErr) != tt.wantCode {
+					t.Errorf("ConvertWithAuth() error = %v, want %s", convertErr, tt.wantCode)
+				}
+				return
+			}
+			if got := errorCode(err); got != tt.wantCode {
+				t.Errorf("ValidateWithAuth() error = %v (%s), want %q", err, got, tt.wantCode)
+			}
+			if got := errorCode(convertErr); got != tt.wantCode {
+				t.Errorf("ConvertWithAuth() error = %v (%s), want %q as ValidateWithAuth", convertErr, got, tt.wantCode)
+			}
+		})
+	}
+
+	// Without roles, Validate skips authorization like Convert
+	if err := converter.Validate(`salary > 1000`); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestConverter_Validate_Masked(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{
+			"email": {Type: cel.StringType, Masked: true},
+		},
+		MaxInClauseSize: 1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	err = converter.Validate(`email in ["alice@example.com", "bob@example.com"]`)
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) {
+		t.Fatalf("Validate() error = %v, want a ConversionError", err)
+	}
+	if strings.Contains(convErr.InternalError.Error(), "alice@example.com") {
+		t.Errorf("Validate() internal error = %v, want masked values", convErr.InternalError)
+	}
+}
+
+// errorCode returns the code of a ConversionError, or "" for nil.
+func errorCode(err error) string {
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		return string(convErr.ErrorCode)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
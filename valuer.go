@@ -0,0 +1,15 @@
+package cel2squirrel
+
+import (
+	"database/sql/driver"
+)
+
+// isValuer reports whether value implements driver.Valuer, as custom ID or
+// decimal types do. Such values are bound unmodified, leaving their
+// representation to the driver, rather than through squirrel.Eq and its
+// siblings, which bind the result of their Value method instead and expand
+// those returning a []byte into an IN list.
+func isValuer(value interface{}) bool {
+	_, ok := value.(driver.Valuer)
+	return ok
+}
@@ -0,0 +1,59 @@
+package cel2squirrel
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// binaryID is an ID bound as bytes.
+type binaryID [4]byte
+
+func (id binaryID) Value() (driver.Value, error) {
+	return id[:], nil
+}
+
+func TestValidateValueType_Valuer(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		value     interface{}
+	}{
+		{fieldType: "string", value: uuid.MustParse("8f14e45f-ceea-467f-a8e8-3a4c6e1b7c2d")},
+		{fieldType: "double", value: decimal.RequireFromString("19.99")},
+		{fieldType: "int", value: binaryID{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		if err := validateValueType(tt.fieldType, tt.value); err != nil {
+			t.Errorf("validateValueType(%s, %T) error = %v", tt.fieldType, tt.value, err)
+		}
+	}
+}
+
+func TestKeyset_After_Valuer(t *testing.T) {
+	id := uuid.MustParse("8f14e45f-ceea-467f-a8e8-3a4c6e1b7c2d")
+	price := decimal.RequireFromString("19.99")
+	tenant := binaryID{1, 2, 3, 4}
+
+	keyset := Keyset{Fields: []OrderField{{Column: "tenant"}, {Column: "price", Descending: true}, {Column: "id"}}}
+	after, err := keyset.After(tenant, price, id)
+	if err != nil {
+		t.Fatalf("After() error = %v", err)
+	}
+
+	sql, args, err := after.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	wantSQL := "((tenant > ?) OR (tenant = ? AND price < ?) OR (tenant = ? AND price = ? AND id > ?))"
+	if sql != wantSQL {
+		t.Errorf("ToSql() = %v, want %v", sql, wantSQL)
+	}
+	wantArgs := []interface{}{tenant, tenant, price, tenant, price, id}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("ToSql() args = %v, want %v", args, wantArgs)
+	}
+}
@@ -0,0 +1,95 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// valuesIn is an IN predicate whose list is materialized as a VALUES table,
+// e.g. status IN (SELECT v.x FROM (VALUES (?),(?),(?)) AS v(x)).
+type valuesIn struct {
+	lhs    operand
+	values []interface{}
+	// table is the subquery selecting the values.
+	table string
+	not   bool
+}
+
+// ToSql implements squirrel.Sqlizer.
+func (e *valuesIn) ToSql() (string, []interface{}, error) {
+	op := "IN"
+	if e.not {
+		op = "NOT IN"
+	}
+	sql := fmt.Sprintf("%s %s (%s)", e.lhs.sql, op, e.table)
+	return sql, append(e.lhs.bound(), e.values...), nil
+}
+
+// valuesIn renders the membership of lhs in a list of values materialized
+// as a VALUES table.
+func (c *Converter) valuesIn(lhs operand, values []interface{}) *valuesIn {
+	return &valuesIn{
+		lhs:    lhs,
+		values: values,
+		table:  c.dialect.valuesTable(len(values), c.dialect.valuesType(values[0])),
+	}
+}
+
+// valuesType returns the SQL type the values of a VALUES table are cast to,
+// if any. PostgreSQL types the untyped parameters of VALUES as text, which
+// does not compare with other column types.
+func (d Dialect) valuesType(value interface{}) string {
+	if d != DialectPostgreSQL {
+		return ""
+	}
+	switch value.(type) {
+	case int64, uint64:
+		sqlType, _ := d.castType("int")
+		return sqlType
+	case float64:
+		sqlType, _ := d.castType("double")
+		return sqlType
+	case time.Time:
+		return "TIMESTAMPTZ"
+	case bool:
+		return "BOOLEAN"
+	}
+	return ""
+}
+
+// valuesTable returns the subquery selecting n bound values from a VALUES
+// table constructor. When sqlType is set, the first row is cast to it so
+// that the column takes its type.
+func (d Dialect) valuesTable(n int, sqlType string) string {
+	prefix, suffix := "(", ")"
+	if d == DialectMySQL {
+		prefix = "ROW("
+	}
+
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = prefix + "?" + suffix
+	}
+	if sqlType != "" {
+		rows[0] = fmt.Sprintf("%sCAST(? AS %s)%s", prefix, sqlType, suffix)
+	}
+
+	if d == DialectSQLite {
+		// SQLite does not name the columns of derived tables
+		return "VALUES " + strings.Join(rows, ",")
+	}
+	return fmt.Sprintf("SELECT v.x FROM (VALUES %s) AS v(x)", strings.Join(rows, ","))
+}
+
+// valuesFragment returns the trusted SQL of the VALUES tables the dialect
+// renders, for auditing.
+func (d Dialect) valuesFragment() string {
+	fragments := []string{d.valuesTable(1, "")}
+	for _, value := range []interface{}{int64(0), float64(0), time.Time{}, true} {
+		if sqlType := d.valuesType(value); sqlType != "" {
+			fragments = append(fragments, d.valuesTable(1, sqlType))
+		}
+	}
+	return strings.Join(fragments, " ")
+}
@@ -0,0 +1,121 @@
+package cel2squirrel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+)
+
+func TestConverter_Convert_InValuesThreshold(t *testing.T) {
+	fields := map[string]ColumnMapping{
+		"status": {Type: cel.StringType, Column: "status"},
+		"age":    {Type: cel.IntType, Column: "age"},
+	}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		celExpr  string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "under threshold",
+			celExpr:  `status in ["a", "b"]`,
+			wantSQL:  "status IN (?,?)",
+			wantArgs: []interface{}{"a", "b"},
+		},
+		{
+			name:     "over threshold",
+			celExpr:  `status in ["a", "b", "c"]`,
+			wantSQL:  "status IN (SELECT v.x FROM (VALUES (?),(?),(?)) AS v(x))",
+			wantArgs: []interface{}{"a", "b", "c"},
+		},
+		{
+			name:     "PostgreSQL types the values",
+			dialect:  DialectPostgreSQL,
+			celExpr:  `age in [1, 2, 3]`,
+			wantSQL:  "age IN (SELECT v.x FROM (VALUES (CAST(? AS BIGINT)),(?),(?)) AS v(x))",
+			wantArgs: []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:     "MySQL row constructors",
+			dialect:  DialectMySQL,
+			celExpr:  `age in [1, 2, 3]`,
+			wantSQL:  "age IN (SELECT v.x FROM (VALUES ROW(?),ROW(?),ROW(?)) AS v(x))",
+			wantArgs: []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:     "SQLite",
+			dialect:  DialectSQLite,
+			celExpr:  `age in [1, 2, 3]`,
+			wantSQL:  "age IN (VALUES (?),(?),(?))",
+			wantArgs: []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			name:     "computed operand",
+			celExpr:  `age + 1 in [1, 2, 3]`,
+			wantSQL:  "(age + ?) IN (SELECT v.x FROM (VALUES (?),(?),(?)) AS v(x))",
+			wantArgs: []interface{}{int64(1), int64(1), int64(2), int64(3)},
+		},
+		{
+			name:     "negated",
+			celExpr:  `!(status in ["a", "b", "c"])`,
+			wantSQL:  "NOT (status IN (SELECT v.x FROM (VALUES (?),(?),(?)) AS v(x)))",
+			wantArgs: []interface{}{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewConverter(Config{
+				FieldDeclarations: fields,
+				Dialect:           tt.dialect,
+				InValuesThreshold: 2,
+				AuditSQL:          true,
+			})
+			if err != nil {
+				t.Fatalf("failed to create converter: %v", err)
+			}
+
+			result, err := converter.Convert(tt.celExpr)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			sql, args, err := result.Where.ToSql()
+			if err != nil {
+				t.Fatalf("ToSql() error = %v", err)
+			}
+			if sql != tt.wantSQL {
+				t.Errorf("ToSql() = %v, want %v", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("ToSql() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestConverter_Convert_InValuesThreshold_PushDownNot(t *testing.T) {
+	converter, err := NewConverter(Config{
+		FieldDeclarations: map[string]ColumnMapping{"status": {Type: cel.StringType, Column: "status"}},
+		InValuesThreshold: 1,
+		PushDownNot:       true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+
+	result, err := converter.Convert(`!(status in ["a", "b"])`)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	sql, _, err := result.Where.ToSql()
+	if err != nil {
+		t.Fatalf("ToSql() error = %v", err)
+	}
+	if want := "status NOT IN (SELECT v.x FROM (VALUES (?),(?)) AS v(x))"; sql != want {
+		t.Errorf("ToSql() = %v, want %v", sql, want)
+	}
+}
@@ -0,0 +1,163 @@
+package cel2squirrel
+
+import (
+	"slices"
+	"sync"
+
+	celast "github.com/google/cel-go/common/ast"
+)
+
+// fieldWalker holds the scratch state of a walk collecting the fields an
+// expression references. Walkers are pooled to keep their maps across
+// conversions.
+type fieldWalker struct {
+	fields map[string]bool
+	// Variables of the exists() and all() macros in scope, whose rows are
+	// authorized through their collection, with their nesting count
+	vars map[string]int
+	// Scratch set of the callers
+	columns map[string]bool
+}
+
+var fieldWalkers = sync.Pool{
+	New: func() any {
+		return &fieldWalker{
+			fields:  make(map[string]bool),
+			vars:    make(map[string]int),
+			columns: make(map[string]bool),
+		}
+	},
+}
+
+// walkFields collects the fields referenced by expr in a pooled walker,
+// which the caller returns with release.
+func walkFields(expr celast.Expr) *fieldWalker {
+	w := fieldWalkers.Get().(*fieldWalker)
+	w.walk(expr)
+	return w
+}
+
+// walk adds the fields referenced by expr and its subexpressions. The
+// variables of a comprehension are only in scope of its loop and result:
+// its range and initializer, and the rest of the expression, refer to
+// fields even when named alike.
+func (w *fieldWalker) walk(expr celast.Expr) {
+	if expr == nil {
+		return
+	}
+	switch expr.Kind() {
+	case celast.IdentKind:
+		if w.vars[expr.AsIdent()] == 0 {
+			w.fields[expr.AsIdent()] = true
+		}
+	case celast.SelectKind:
+		sel := expr.AsSelect()
+		if sel.Operand().Kind() != celast.IdentKind || w.vars[sel.Operand().AsIdent()] == 0 {
+			w.fields[sel.FieldName()] = true
+		}
+		w.walk(sel.Operand())
+	case celast.CallKind:
+		call := expr.AsCall()
+		if call.IsMemberFunction() {
+			w.walk(call.Target())
+		}
+		for _, arg := range call.Args() {
+			w.walk(arg)
+		}
+	case celast.ListKind:
+		for _, elem := range expr.AsList().Elements() {
+			w.walk(elem)
+		}
+	case celast.MapKind:
+		for _, entry := range expr.AsMap().Entries() {
+			w.walk(entry.AsMapEntry().Key())
+			w.walk(entry.AsMapEntry().Value())
+		}
+	case celast.StructKind:
+		for _, field := range expr.AsStruct().Fields() {
+			w.walk(field.AsStructField().Value())
+		}
+	case celast.ComprehensionKind:
+		comp := expr.AsComprehension()
+		w.walk(comp.IterRange())
+		w.walk(comp.AccuInit())
+
+		vars := []string{comp.IterVar(), comp.AccuVar()}
+		if comp.HasIterVar2() {
+			vars = append(vars, comp.IterVar2())
+		}
+		for _, name := range vars {
+			w.vars[name]++
+		}
+		w.walk(comp.LoopCondition())
+		w.walk(comp.LoopStep())
+		w.walk(comp.Result())
+		for _, name := range vars {
+			if w.vars[name]--; w.vars[name] == 0 {
+				delete(w.vars, name)
+			}
+		}
+	}
+}
+
+// release clears the walker and returns it to the pool.
+func (w *fieldWalker) release() {
+	clear(w.fields)
+	clear(w.vars)
+	clear(w.columns)
+	fieldWalkers.Put(w)
+}
+
+// walkExpr visits all expressions in the tree, parents first, without
+// allocating.
+func walkExpr(expr celast.Expr, fn func(celast.Expr)) {
+	if expr == nil {
+		return
+	}
+	fn(expr)
+	switch expr.Kind() {
+	case celast.CallKind:
+		call := expr.AsCall()
+		if call.IsMemberFunction() {
+			walkExpr(call.Target(), fn)
+		}
+		for _, arg := range call.Args() {
+			walkExpr(arg, fn)
+		}
+	case celast.SelectKind:
+		walkExpr(expr.AsSelect().Operand(), fn)
+	case celast.ListKind:
+		for _, elem := range expr.AsList().Elements() {
+			walkExpr(elem, fn)
+		}
+	case celast.MapKind:
+		for _, entry := range expr.AsMap().Entries() {
+			walkExpr(entry.AsMapEntry().Key(), fn)
+			walkExpr(entry.AsMapEntry().Value(), fn)
+		}
+	case celast.StructKind:
+		for _, field := range expr.AsStruct().Fields() {
+			walkExpr(field.AsStructField().Value(), fn)
+		}
+	case celast.ComprehensionKind:
+		comp := expr.AsComprehension()
+		walkExpr(comp.IterRange(), fn)
+		walkExpr(comp.AccuInit(), fn)
+		walkExpr(comp.LoopCondition(), fn)
+		walkExpr(comp.LoopStep(), fn)
+		walkExpr(comp.Result(), fn)
+	}
+}
+
+// sortedKeys returns the keys of a set in ascending order.
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package cel2squirrel
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+)
+
+func TestWalkFields(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("status", cel.StringType),
+		cel.Variable("age", cel.IntType),
+		cel.Variable("tags", cel.ListType(cel.StringType)),
+		cel.Variable("meta", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		want    []string
+	}{
+		{name: "comparison", celExpr: `status == "active"`, want: []string{"status"}},
+		{name: "conjunction", celExpr: `status == "active" && age > 18`, want: []string{"age", "status"}},
+		{name: "repeated", celExpr: `age > 18 && age < 65`, want: []string{"age"}},
+		{name: "macro variable", celExpr: `tags.exists(t, t == status)`, want: []string{"status", "tags"}},
+		{name: "field named as a macro variable", celExpr: `tags.exists(status, status == "x") && status == "y"`, want: []string{"status", "tags"}},
+		{name: "macro variable out of scope", celExpr: `tags.exists(t, t == "x") || tags.all(u, u != "y")`, want: []string{"tags"}},
+		{name: "select", celExpr: `meta.owner == "bob"`, want: []string{"meta", "owner"}},
+		{name: "constant", celExpr: `true`, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Compile(tt.celExpr)
+			if issues.Err() != nil {
+				t.Fatalf("failed to compile: %v", issues.Err())
+			}
+			w := walkFields(ast.NativeRep().Expr())
+			got := sortedKeys(w.fields)
+			w.release()
+			if got == nil {
+				got = []string{}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("walkFields() = %v, want %v", got, tt.want)
+			}
+
+			// Released walkers come back empty
+			w = fieldWalkers.Get().(*fieldWalker)
+			if len(w.fields) != 0 || len(w.vars) != 0 || len(w.columns) != 0 {
+				t.Errorf("pooled walker not cleared: %v %v %v", w.fields, w.vars, w.columns)
+			}
+			fieldWalkers.Put(w)
+		})
+	}
+}
+
+func TestWalkExpr(t *testing.T) {
+	env, err := cel.NewEnv(
+		cel.Variable("a", cel.IntType),
+		cel.Variable("b", cel.IntType),
+		cel.Variable("m", cel.MapType(cel.StringType, cel.IntType)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		celExpr string
+		want    []string
+	}{
+		{name: "parents first", celExpr: `a < b`, want: []string{"_<_", "a", "b"}},
+		{name: "nested", celExpr: `a < b || b > 3`, want: []string{"_||_", "_<_", "a", "b", "_>_", "b", "3"}},
+		{name: "list", celExpr: `a in [1, b]`, want: []string{"@in", "a", "[]", "1", "b"}},
+		{name: "map", celExpr: `{"k": a}["k"] == b`, want: []string{"_==_", "_[_]", "{}", "k", "a", "k", "b"}},
+		{name: "select", celExpr: `m.x > 1`, want: []string{"_>_", ".x", "m", "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, issues := env.Compile(tt.celExpr)
+			if issues.Err() != nil {
+				t.Fatalf("failed to compile: %v", issues.Err())
+			}
+			var got []string
+			walkExpr(ast.NativeRep().Expr(), func(e celast.Expr) {
+				switch e.Kind() {
+				case celast.CallKind:
+					got = append(got, e.AsCall().FunctionName())
+				case celast.IdentKind:
+					got = append(got, e.AsIdent())
+				case celast.LiteralKind:
+					got = append(got, fmt.Sprint(e.AsLiteral().Value()))
+				case celast.ListKind:
+					got = append(got, "[]")
+				case celast.MapKind:
+					got = append(got, "{}")
+				case celast.SelectKind:
+					got = append(got, "."+e.AsSelect().FieldName())
+				}
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("walkExpr() visited %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkExpr_Comprehension(t *testing.T) {
+	env, err := cel.NewEnv(cel.Variable("tags", cel.ListType(cel.StringType)))
+	if err != nil {
+		t.Fatalf("failed to create env: %v", err)
+	}
+	ast, issues := env.Compile(`tags.exists(t, t == "x")`)
+	if issues.Err() != nil {
+		t.Fatalf("failed to compile: %v", issues.Err())
+	}
+
+	var idents []string
+	walkExpr(ast.NativeRep().Expr(), func(e celast.Expr) {
+		if e.Kind() == celast.IdentKind {
+			idents = append(idents, e.AsIdent())
+		}
+	})
+	for _, want := range []string{"tags", "t"} {
+		if !slices.Contains(idents, want) {
+			t.Errorf("walkExpr() visited identifiers %v, missing %s", idents, want)
+		}
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string]bool
+		want []string
+	}{
+		{name: "nil", set: nil, want: nil},
+		{name: "empty", set: map[string]bool{}, want: nil},
+		{name: "sorted", set: map[string]bool{"b": true, "a": true, "c": true}, want: []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortedKeys(tt.set); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortedKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}